@@ -0,0 +1,854 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "text/tabwriter"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/services"
+)
+
+var commands = map[string]command{
+    "create-user":      {"create a user: -username -email -password", createUser},
+    "promote":          {"grant the admin role: -user", promote},
+    "set-tier":         {"move a user to a fee tier: -user -tier", setTier},
+    "accounts":         {"list a user's accounts and their balances: -user", listAccounts},
+    "freeze-account":   {"stop money leaving an account: -id -reason", freezeAccount},
+    "unfreeze-account": {"make a frozen account active again: -id -reason", unfreezeAccount},
+    "close-account":    {"close an account holding nothing: -id -reason", closeAccount},
+    "credit":           {"credit a user, held for approval if manual credits need one: -user -amount [-currency] -reason", credit},
+    "debit":            {"debit a user: -user -amount [-currency] -reason", debit},
+    "set-credit-limit": {"let a balance go negative down to -limit: -user -limit [-currency] -reason", setCreditLimit},
+    "recalculate":      {"rebuild a stored balance from the ledger: -user [-currency] -reason", recalculate},
+    "ledger":           {"list a user's transactions: -user [-limit -offset]", ledger},
+    "set-rate":         {"set an FX mid rate: -base -quote -rate -reason", setRate},
+    "rates":            {"list stored FX rates", listRates},
+    "limits":           {"list the transaction limits in force for a user: -user", listLimits},
+    "set-limit":        {"override a user's limits: -user [-type -currency] [-per-transaction -daily-amount -daily-count -monthly-amount -monthly-count] -reason", setLimit},
+    "clear-limit":      {"drop a user's limit override: -user [-type -currency] -reason", clearLimit},
+    "accrue-interest":  {"accrue interest for days ended before -now and post complete months: [-now YYYY-MM-DD]", accrueInterest},
+    "interest":         {"show interest accrued but not yet paid: -user [-currency]", showInterest},
+    "schedules":        {"list the scheduled transfers paying from or into a user: -user", listSchedules},
+    "cancel-schedule":  {"cancel a scheduled transfer: -id -reason", cancelSchedule},
+    "statement":        {"print a user's statement for [-from, -to]: -user -from YYYY-MM-DD -to YYYY-MM-DD [-currency] [-format json|csv|camt.053|ofx|pdf] [-output FILE]", statement},
+    "import":           {"import a CSV file or pain.001 message: -file [-format csv|pain.001] [-dry-run] -reason", importFile},
+    "approvals":        {"list the transactions awaiting approval or fraud review: [-kind approval|review]", listApprovals},
+    "approve":          {"approve a transaction initiated by another operator: -id [-reason]", approve},
+    "reject":           {"reject a transaction initiated by another operator: -id -reason", reject},
+    "reconcile":        {"report balance drift: [-user ID,...] [-format json|csv] [-output FILE] [-repair -reason]", reconcile},
+}
+
+func createUser(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("create-user", flag.ContinueOnError)
+    username := fs.String("username", "", "username")
+    email := fs.String("email", "", "email address")
+    password := fs.String("password", "", "initial password")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    user, err := a.userService.RegisterUser(ctx, *username, *email, *password)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(user)
+}
+
+func promote(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("promote", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    user, err := a.userService.PromoteToAdmin(ctx, *userID)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(user)
+}
+
+func setTier(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("set-tier", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    tier := fs.String("tier", "", "fee tier")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    user, err := a.userService.SetTier(ctx, *userID, *tier)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(user)
+}
+
+func credit(ctx context.Context, a *app, args []string) error {
+    adj, ctx, err := parseAdjustment(ctx, a, "credit", args)
+    if err != nil {
+        return err
+    }
+
+    tx, err := a.txService.Credit(ctx, adj.userID, adj.amount, adj.currency)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(tx)
+}
+
+func debit(ctx context.Context, a *app, args []string) error {
+    adj, ctx, err := parseAdjustment(ctx, a, "debit", args)
+    if err != nil {
+        return err
+    }
+
+    tx, err := a.txService.Debit(ctx, adj.userID, adj.amount, adj.currency)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(tx)
+}
+
+type adjustment struct {
+    userID   uint
+    amount   float64
+    currency string
+}
+
+// parseAdjustment reads the flags shared by credit and debit and attaches the
+// mandatory reason to the context used for auditing.
+func parseAdjustment(ctx context.Context, a *app, name string, args []string) (*adjustment, context.Context, error) {
+    fs := flag.NewFlagSet(name, flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    amount := fs.Float64("amount", 0, "amount")
+    currency := fs.String("currency", a.defaultCurrency, "ISO 4217 currency code")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return nil, nil, err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return nil, nil, errors.New("a -reason is required")
+    }
+
+    if err := requireAdmin(ctx); err != nil {
+        return nil, nil, err
+    }
+
+    adj := &adjustment{
+        userID:   *userID,
+        amount:   *amount,
+        currency: *currency,
+    }
+
+    return adj, services.WithReason(ctx, *reason), nil
+}
+
+func recalculate(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("recalculate", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    currency := fs.String("currency", a.defaultCurrency, "ISO 4217 currency code")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    if err := models.ValidateCurrency(*currency); err != nil {
+        return err
+    }
+
+    ctx = services.WithReason(ctx, *reason)
+
+    if err := a.balanceService.RecalculateBalance(ctx, *userID, *currency); err != nil {
+        return err
+    }
+
+    balance, err := a.balanceService.GetBalance(ctx, *userID, *currency)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(balance)
+}
+
+func setCreditLimit(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("set-credit-limit", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    limit := fs.Float64("limit", 0, "credit limit; 0 allows no overdraft")
+    currency := fs.String("currency", a.defaultCurrency, "currency of the balance")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    balance, err := a.balanceService.SetCreditLimit(services.WithReason(ctx, *reason), *userID, *currency, *limit)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(balance)
+}
+
+func ledger(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("ledger", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    limit := fs.Int("limit", 50, "maximum number of transactions")
+    offset := fs.Int("offset", 0, "number of transactions to skip")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    transactions, err := a.txService.GetUserTransactions(ctx, *userID, *limit, *offset)
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "ID\tCREATED\tTYPE\tSTATUS\tFROM\tTO\tAMOUNT\tCURRENCY\tCREDITED")
+
+    for i := range transactions {
+        tx := &transactions[i]
+        fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%.*f\t%s\t%.*f %s\n",
+            tx.ID,
+            tx.CreatedAt.Format("2006-01-02 15:04:05"),
+            tx.Type,
+            tx.Status,
+            tx.FromUserID,
+            tx.ToUserID,
+            models.MinorUnits(tx.Currency),
+            tx.Amount,
+            tx.Currency,
+            models.MinorUnits(tx.CreditCurrency()),
+            tx.CreditAmount(),
+            tx.CreditCurrency(),
+        )
+    }
+
+    return w.Flush()
+}
+
+func reconcile(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+    users := fs.String("user", "", "comma-separated user IDs (default: every balance)")
+    repair := fs.Bool("repair", false, "overwrite drifted balances with the derived amount")
+    reason := fs.String("reason", "", "reason recorded on repair audit logs (required with -repair)")
+    format := fs.String("format", "json", "report format: json or csv")
+    output := fs.String("output", "", "write the report to this file instead of stdout")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    opts := services.ReconciliationOptions{Repair: *repair}
+
+    if *users != "" {
+        userIDs, err := parseIDs(*users)
+        if err != nil {
+            return err
+        }
+        opts.UserIDs = userIDs
+    }
+
+    if *repair {
+        if strings.TrimSpace(*reason) == "" {
+            return errors.New("a -reason is required with -repair")
+        }
+        ctx = services.WithReason(ctx, *reason)
+    }
+
+    report, err := a.reconciler.Run(ctx, opts)
+    if err != nil {
+        return err
+    }
+
+    if *output == "" {
+        return report.Write(os.Stdout, *format)
+    }
+
+    f, err := os.Create(*output)
+    if err != nil {
+        return err
+    }
+
+    if err := report.Write(f, *format); err != nil {
+        f.Close()
+        return err
+    }
+
+    return f.Close()
+}
+
+func setRate(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("set-rate", flag.ContinueOnError)
+    base := fs.String("base", "", "currency being priced")
+    quote := fs.String("quote", "", "currency the price is expressed in")
+    value := fs.Float64("rate", 0, "units of -quote per unit of -base")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    for _, code := range []string{*base, *quote} {
+        if err := models.ValidateCurrency(code); err != nil {
+            return err
+        }
+    }
+
+    if *base == *quote || *value <= 0 {
+        return errors.New("a positive -rate between two different currencies is required")
+    }
+
+    rate := &models.FXRate{
+        BaseCurrency:  *base,
+        QuoteCurrency: *quote,
+        Rate:          *value,
+        UpdatedAt:     time.Now(),
+    }
+
+    if err := a.store.FXRates.SetRate(ctx, rate); err != nil {
+        return err
+    }
+
+    changes := map[string]interface{}{
+        "base":  rate.BaseCurrency,
+        "quote": rate.QuoteCurrency,
+        "rate":  rate.Rate,
+    }
+    if err := a.auditLogger.LogAction(services.WithReason(ctx, *reason), "fx_rate", 0, "set", changes); err != nil {
+        return fmt.Errorf("rate saved but audit failed: %w", err)
+    }
+
+    return printJSON(rate)
+}
+
+func listRates(ctx context.Context, a *app, args []string) error {
+    rates, err := a.store.FXRates.ListRates(ctx)
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "BASE\tQUOTE\tRATE\tUPDATED")
+
+    for _, rate := range rates {
+        fmt.Fprintf(w, "%s\t%s\t%.6f\t%s\n",
+            rate.BaseCurrency,
+            rate.QuoteCurrency,
+            rate.Rate,
+            rate.UpdatedAt.Format("2006-01-02 15:04:05"),
+        )
+    }
+
+    return w.Flush()
+}
+
+func listLimits(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("limits", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    limits, err := a.limitService.GetLimits(ctx, *userID)
+    if err != nil {
+        return err
+    }
+
+    orAll := func(value string) string {
+        if value == "" {
+            return "*"
+        }
+        return value
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "SOURCE\tTYPE\tCURRENCY\tPER TX\tDAILY\tDAILY #\tMONTHLY\tMONTHLY #")
+
+    for _, limit := range limits {
+        source := "default"
+        if limit.UserID != 0 {
+            source = "override"
+        }
+
+        fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%.2f\t%d\t%.2f\t%d\n",
+            source,
+            orAll(string(limit.Type)),
+            orAll(limit.Currency),
+            limit.PerTransaction,
+            limit.DailyAmount,
+            limit.DailyCount,
+            limit.MonthlyAmount,
+            limit.MonthlyCount,
+        )
+    }
+
+    return w.Flush()
+}
+
+func setLimit(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("set-limit", flag.ContinueOnError)
+    limit := &models.TransactionLimit{}
+    userID := fs.Uint("user", 0, "user ID")
+    txType := fs.String("type", "", "transaction type the limit applies to (default all)")
+    fs.StringVar(&limit.Currency, "currency", "", "currency the limit applies to (default all)")
+    fs.Float64Var(&limit.PerTransaction, "per-transaction", 0, "largest single transaction")
+    fs.Float64Var(&limit.DailyAmount, "daily-amount", 0, "total over a rolling 24 hours")
+    fs.IntVar(&limit.DailyCount, "daily-count", 0, "transactions in a rolling 24 hours")
+    fs.Float64Var(&limit.MonthlyAmount, "monthly-amount", 0, "total over a rolling 30 days")
+    fs.IntVar(&limit.MonthlyCount, "monthly-count", 0, "transactions in a rolling 30 days")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    limit.UserID = *userID
+    limit.Type = models.TransactionType(*txType)
+
+    if err := a.limitService.SetUserLimit(services.WithReason(ctx, *reason), limit); err != nil {
+        return err
+    }
+
+    return printJSON(limit)
+}
+
+func clearLimit(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("clear-limit", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    txType := fs.String("type", "", "transaction type of the override")
+    currency := fs.String("currency", "", "currency of the override")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    return a.limitService.RemoveUserLimit(services.WithReason(ctx, *reason), *userID, models.TransactionType(*txType), *currency)
+}
+
+func accrueInterest(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("accrue-interest", flag.ContinueOnError)
+    nowFlag := fs.String("now", "", "run as of this UTC date instead of today")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    now := time.Now()
+    if *nowFlag != "" {
+        t, err := time.Parse("2006-01-02", *nowFlag)
+        if err != nil {
+            return fmt.Errorf("invalid -now: %w", err)
+        }
+        now = t
+    }
+
+    return a.interestService.Run(ctx, now)
+}
+
+func showInterest(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("interest", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    currency := fs.String("currency", a.defaultCurrency, "currency of the balance")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    accrued, err := a.interestService.GetAccrued(ctx, *userID, *currency)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(accrued)
+}
+
+func listAccounts(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("accounts", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    accounts, err := a.accountService.ListByUser(ctx, *userID)
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "ID\tTYPE\tCURRENCY\tSTATUS\tAMOUNT\tCREDIT LIMIT\tOPENED")
+
+    for _, account := range accounts {
+        var amount, creditLimit float64
+        if account.Balance != nil {
+            amount, creditLimit = account.Balance.Amount, account.Balance.CreditLimit
+        }
+
+        fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%.2f\t%.2f\t%s\n",
+            account.ID,
+            account.Type,
+            account.Currency,
+            account.Status,
+            amount,
+            creditLimit,
+            account.CreatedAt.Format(time.RFC3339),
+        )
+    }
+
+    return w.Flush()
+}
+
+func freezeAccount(ctx context.Context, a *app, args []string) error {
+    return changeAccountStatus(ctx, "freeze-account", args, a.accountService.Freeze)
+}
+
+func unfreezeAccount(ctx context.Context, a *app, args []string) error {
+    return changeAccountStatus(ctx, "unfreeze-account", args, a.accountService.Unfreeze)
+}
+
+func closeAccount(ctx context.Context, a *app, args []string) error {
+    return changeAccountStatus(ctx, "close-account", args, a.accountService.Close)
+}
+
+func changeAccountStatus(ctx context.Context, name string, args []string, change func(ctx context.Context, id uint) (*models.Account, error)) error {
+    fs := flag.NewFlagSet(name, flag.ContinueOnError)
+    id := fs.Uint("id", 0, "account ID")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    account, err := change(services.WithReason(ctx, *reason), *id)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(account)
+}
+
+func listApprovals(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("approvals", flag.ContinueOnError)
+    kind := fs.String("kind", "", "approval or review; empty lists both")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    approvals, err := a.txService.ListAwaitingApproval(ctx, models.ApprovalKind(*kind))
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "ID\tKIND\tTYPE\tFROM\tTO\tAMOUNT\tCURRENCY\tINITIATED BY\tREASON\tEXPIRES")
+
+    for _, approval := range approvals {
+        tx := approval.Transaction
+        fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%.*f\t%s\t%s\t%s\t%s\n",
+            tx.ID,
+            approval.Kind,
+            tx.Type,
+            tx.FromUserID,
+            tx.ToUserID,
+            models.MinorUnits(tx.Currency),
+            tx.Amount,
+            tx.Currency,
+            approval.InitiatedBy,
+            approval.Reason,
+            approval.ExpiresAt.Format(time.RFC3339),
+        )
+    }
+
+    return w.Flush()
+}
+
+func approve(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("approve", flag.ContinueOnError)
+    id := fs.Uint("id", 0, "transaction ID")
+    reason := fs.String("reason", "", "reason recorded on the audit log")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if err := requireAdmin(ctx); err != nil {
+        return err
+    }
+
+    tx, err := a.txService.Approve(services.WithReason(ctx, *reason), *id)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(tx)
+}
+
+func reject(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("reject", flag.ContinueOnError)
+    id := fs.Uint("id", 0, "transaction ID")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    if err := requireAdmin(ctx); err != nil {
+        return err
+    }
+
+    tx, err := a.txService.Reject(services.WithReason(ctx, *reason), *id)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(tx)
+}
+
+func listSchedules(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("schedules", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    schedules, err := a.scheduleService.ListByUser(ctx, *userID)
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "ID\tFROM\tTO\tAMOUNT\tCURRENCY\tFREQUENCY\tNEXT RUN\tEND\tSTATUS")
+
+    for _, schedule := range schedules {
+        end := "-"
+        if schedule.EndDate != nil {
+            end = schedule.EndDate.Format(time.RFC3339)
+        }
+
+        fmt.Fprintf(w, "%d\t%d\t%d\t%.2f\t%s\t%s\t%s\t%s\t%s\n",
+            schedule.ID,
+            schedule.FromUserID,
+            schedule.ToUserID,
+            schedule.Amount,
+            schedule.Currency,
+            schedule.Frequency,
+            schedule.NextRunAt.Format(time.RFC3339),
+            end,
+            schedule.Status,
+        )
+    }
+
+    return w.Flush()
+}
+
+func cancelSchedule(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("cancel-schedule", flag.ContinueOnError)
+    id := fs.Uint("id", 0, "scheduled transfer ID")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if strings.TrimSpace(*reason) == "" {
+        return errors.New("a -reason is required")
+    }
+
+    schedule, err := a.scheduleService.Cancel(services.WithReason(ctx, *reason), *id)
+    if err != nil {
+        return err
+    }
+
+    return printJSON(schedule)
+}
+
+func statement(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("statement", flag.ContinueOnError)
+    userID := fs.Uint("user", 0, "user ID")
+    currency := fs.String("currency", a.defaultCurrency, "ISO 4217 currency code")
+    fromFlag := fs.String("from", "", "first day of the statement (YYYY-MM-DD)")
+    toFlag := fs.String("to", "", "last day of the statement (YYYY-MM-DD)")
+    format := fs.String("format", "json", "statement format: json, csv, camt.053, ofx or pdf")
+    output := fs.String("output", "", "write the statement to this file instead of stdout")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    from, err := time.Parse("2006-01-02", *fromFlag)
+    if err != nil {
+        return fmt.Errorf("invalid -from: %w", err)
+    }
+
+    to, err := time.Parse("2006-01-02", *toFlag)
+    if err != nil {
+        return fmt.Errorf("invalid -to: %w", err)
+    }
+
+    st, err := a.statementService.Generate(ctx, *userID, *currency, from, to.AddDate(0, 0, 1))
+    if err != nil {
+        return err
+    }
+
+    if *output == "" {
+        return st.Write(os.Stdout, *format)
+    }
+
+    f, err := os.Create(*output)
+    if err != nil {
+        return err
+    }
+
+    if err := st.Write(f, *format); err != nil {
+        f.Close()
+        return err
+    }
+
+    return f.Close()
+}
+
+func importFile(ctx context.Context, a *app, args []string) error {
+    fs := flag.NewFlagSet("import", flag.ContinueOnError)
+    path := fs.String("file", "", "file to import")
+    format := fs.String("format", "csv", "file format: csv, or pain.001 to print a pain.002 status report")
+    dryRun := fs.Bool("dry-run", false, "validate the rows without executing them")
+    reason := fs.String("reason", "", "reason recorded on the audit log (required unless -dry-run)")
+
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    if *path == "" {
+        return errors.New("a -file is required")
+    }
+
+    if !*dryRun {
+        if strings.TrimSpace(*reason) == "" {
+            return errors.New("a -reason is required")
+        }
+        if err := requireAdmin(ctx); err != nil {
+            return err
+        }
+    }
+
+    data, err := os.ReadFile(*path)
+    if err != nil {
+        return err
+    }
+
+    ctx = services.WithReason(ctx, *reason)
+    filename := filepath.Base(*path)
+
+    switch *format {
+        case "csv":
+            result, created, err := a.importService.ImportCSV(ctx, filename, data, *dryRun)
+            if err != nil {
+                return err
+            }
+            reportDuplicate(result, created)
+            return printJSON(result)
+        case "pain.001":
+            report, result, created, err := a.paymentsService.ImportPain001(ctx, filename, data, *dryRun)
+            if err != nil {
+                return err
+            }
+            reportDuplicate(result, created)
+
+            body, err := report.Marshal()
+            if err != nil {
+                return err
+            }
+            _, err = fmt.Println(string(body))
+            return err
+        default:
+            return fmt.Errorf("unknown format %q", *format)
+    }
+}
+
+func reportDuplicate(result *models.Import, created bool) {
+    if !created {
+        fmt.Fprintf(os.Stderr, "file was already imported as import %d; nothing executed\n", result.ID)
+    }
+}
+
+func parseIDs(list string) ([]uint, error) {
+    var ids []uint
+
+    for _, field := range strings.Split(list, ",") {
+        field = strings.TrimSpace(field)
+        if field == "" {
+            continue
+        }
+
+        var id uint
+        if _, err := fmt.Sscan(field, &id); err != nil || id == 0 {
+            return nil, fmt.Errorf("invalid user ID %q", field)
+        }
+
+        ids = append(ids, id)
+    }
+
+    if len(ids) == 0 {
+        return nil, errors.New("at least one -user ID is required")
+    }
+
+    return ids, nil
+}
+
+func printJSON(v interface{}) error {
+    enc := json.NewEncoder(os.Stdout)
+    enc.SetIndent("", "  ")
+    return enc.Encode(v)
+}
@@ -0,0 +1,247 @@
+// Command finctl runs operational tasks against the financial service's
+// storage using the same services as the HTTP API. Every change it makes is
+// audited under the operator's name.
+package main
+
+import (
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+    "financial-service/internal/config"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "financial-service/internal/services"
+    "financial-service/internal/storage"
+)
+
+type app struct {
+    store            *storage.Storage
+    userService      *services.UserService
+    accountService   *services.AccountService
+    txService        *services.TransactionService
+    balanceService   *services.BalanceService
+    reconciler       *services.ReconciliationService
+    limitService     *services.LimitService
+    interestService  *services.InterestService
+    scheduleService  *services.ScheduledTransferService
+    importService    *services.ImportService
+    statementService *services.StatementService
+    paymentsService  *services.PaymentInitiationService
+    auditLogger      *services.AuditLogger
+    // defaultCurrency is used when a command is given no -currency.
+    defaultCurrency  string
+}
+
+type command struct {
+    usage string
+    run   func(ctx context.Context, a *app, args []string) error
+}
+
+func main() {
+    operator := flag.String("operator", os.Getenv("USER"), "operator name recorded on audit logs, or an admin's email")
+    flag.Usage = usage
+    flag.Parse()
+
+    if flag.NArg() == 0 {
+        usage()
+        os.Exit(2)
+    }
+
+    cmd, ok := commands[flag.Arg(0)]
+    if !ok {
+        fmt.Fprintf(os.Stderr, "finctl: unknown command %q\n", flag.Arg(0))
+        usage()
+        os.Exit(2)
+    }
+
+    if *operator == "" {
+        fatal(fmt.Errorf("an operator name is required (-operator)"))
+    }
+
+    a, err := newApp(config.Load())
+    if err != nil {
+        fatal(err)
+    }
+
+    ctx, err := operatorContext(a, *operator)
+    if err == nil {
+        err = cmd.run(ctx, a, flag.Args()[1:])
+    }
+    a.close()
+
+    if err != nil {
+        fatal(err)
+    }
+}
+
+func newApp(cfg *config.Config) (*app, error) {
+    store, err := storage.Open(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    auditLogger := services.NewAuditLogger(store.AuditLogs)
+
+    userService := services.NewUserService(store.Users, store.Accounts, store.Balances, cfg.DefaultCurrency)
+    accountService := services.NewAccountService(store.Accounts, store.Balances, store.Users, store.Transactor)
+    txService := services.NewTransactionService(store.Transactions, store.Balances, store.Users, 1)
+    balanceService := services.NewBalanceService(store.Balances, store.Accounts, store.Transactions, cfg.BalanceCacheSize, cfg.BalanceCacheTTL)
+    reconciler := services.NewReconciliationService(store.Balances, store.Transactions, store.Transactor)
+
+    // Operator adjustments are not subject to limits; the service is only
+    // used to manage them.
+    var defaultLimits []models.TransactionLimit
+    if cfg.LimitsFile != "" {
+        if defaultLimits, err = services.LoadLimits(cfg.LimitsFile); err != nil {
+            store.Close()
+            return nil, err
+        }
+    }
+    limitService := services.NewLimitService(defaultLimits, store.Limits, store.Transactions, store.Users)
+
+    // Adjustments are held for approval like any other transaction, but,
+    // being made by operators, are not screened for fraud
+    approvalPolicy := &services.ApprovalPolicy{
+        ManualCredits: cfg.ApproveManualCredits,
+        TTL:           cfg.ApprovalTTL,
+    }
+    if cfg.ApprovalThresholdsFile != "" {
+        if approvalPolicy.Thresholds, err = services.LoadApprovalThresholds(cfg.ApprovalThresholdsFile); err != nil {
+            store.Close()
+            return nil, err
+        }
+    }
+
+    // Sanctions screening applies to operators as well
+    var watchlist *services.Watchlist
+    if cfg.WatchlistFile != "" {
+        entries, err := services.LoadWatchlist(cfg.WatchlistFile)
+        if err == nil {
+            watchlist, err = services.NewWatchlist(entries, cfg.WatchlistFlagScore, cfg.WatchlistBlockScore)
+        }
+        if err != nil {
+            store.Close()
+            return nil, err
+        }
+    }
+
+    var interestRates []services.InterestRate
+    if cfg.InterestRatesFile != "" {
+        if interestRates, err = services.LoadInterestRates(cfg.InterestRatesFile); err != nil {
+            store.Close()
+            return nil, err
+        }
+    }
+    historyService := services.NewBalanceHistoryService(store.Balances, store.Transactions, store.Snapshots)
+    interestService := services.NewInterestService(interestRates, store.Balances, store.Interest, historyService, txService)
+
+    // Schedules are executed by the server's scheduler; finctl only manages
+    // them.
+    scheduleService := services.NewScheduledTransferService(store.Schedules, store.Users, txService,
+        cfg.SchedulerMaxRetries, cfg.SchedulerRetryInterval)
+    statementService := services.NewStatementService(store.Users, historyService)
+    statementService.SetOFXBankID(cfg.OFXBankID)
+    statementService.SetPDFDir(cfg.StatementDir)
+    importService := services.NewImportService(store.Imports, store.Users, store.Balances, txService, store.Transactor,
+        cfg.DefaultCurrency, cfg.ImportMaxRows)
+
+    userService.SetAuditLogger(auditLogger)
+    userService.SetTransactor(store.Transactor)
+    accountService.SetAuditLogger(auditLogger)
+    scheduleService.SetAuditLogger(auditLogger)
+    importService.SetAuditLogger(auditLogger)
+    limitService.SetAuditLogger(auditLogger)
+    txService.SetAuditLogger(auditLogger)
+    txService.SetTransactor(store.Transactor)
+    txService.SetAccounts(store.Accounts)
+    txService.SetRejectFrozenCredits(cfg.FrozenAccountsRejectCredits)
+    txService.SetApprovals(approvalPolicy, store.Approvals)
+    txService.SetDecisionListener(scheduleService.Resolve)
+    if watchlist != nil {
+        userService.SetWatchlist(watchlist)
+        txService.SetWatchlist(watchlist)
+    }
+    balanceService.SetAuditLogger(auditLogger)
+    reconciler.SetAuditLogger(auditLogger)
+
+    balanceEvents := services.NewBalanceEvents()
+    balanceService.SetBalanceEvents(balanceEvents)
+    txService.SetBalanceEvents(balanceEvents)
+    reconciler.SetBalanceEvents(balanceEvents)
+    accountService.SetBalanceEvents(balanceEvents)
+
+    return &app{
+        store:            store,
+        userService:      userService,
+        accountService:   accountService,
+        txService:        txService,
+        balanceService:   balanceService,
+        reconciler:       reconciler,
+        limitService:     limitService,
+        interestService:  interestService,
+        scheduleService:  scheduleService,
+        importService:    importService,
+        statementService: statementService,
+        paymentsService:  services.NewPaymentInitiationService(importService),
+        auditLogger:      auditLogger,
+        defaultCurrency:  cfg.DefaultCurrency,
+    }, nil
+}
+
+// operatorContext attributes audit entries to operator. An operator giving
+// the email of an admin user acts as that admin, identified by user ID, which
+// moving money and deciding approvals require.
+func operatorContext(a *app, operator string) (context.Context, error) {
+    ctx := context.Background()
+
+    user, err := a.store.Users.GetByEmail(ctx, operator)
+    if err == repository.ErrNotFound {
+        return services.WithActor(ctx, operator), nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to look up operator: %w", err)
+    }
+
+    if user.Role != models.RoleAdmin {
+        return nil, fmt.Errorf("operator %s is not an admin", operator)
+    }
+
+    return services.WithActorUser(ctx, user), nil
+}
+
+// requireAdmin refuses commands that move money to operators who are not
+// identified admins, so their transactions can be told apart from those of
+// the admins approving them.
+func requireAdmin(ctx context.Context) error {
+    if services.ActorIDFromContext(ctx) == 0 {
+        return errors.New("this command needs -operator to be an admin's email")
+    }
+    return nil
+}
+
+func (a *app) close() {
+    a.txService.Cleanup()
+    a.store.Close()
+}
+
+func usage() {
+    fmt.Fprintf(os.Stderr, "usage: finctl [-operator NAME|EMAIL] COMMAND [flags]\n\ncommands:\n")
+
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    for _, name := range names {
+        fmt.Fprintf(os.Stderr, "  %-18s %s\n", name, commands[name].usage)
+    }
+}
+
+func fatal(err error) {
+    fmt.Fprintf(os.Stderr, "finctl: %v\n", err)
+    os.Exit(1)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
     "context"
+    "fmt"
     "net/http"
     "os"
     "os/signal"
@@ -11,9 +12,10 @@ import (
     "financial-service/internal/api"
     "financial-service/internal/api/handlers"
     "financial-service/internal/config"
-    "financial-service/internal/db"
-    "financial-service/internal/repository/mysql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
     "financial-service/internal/services"
+    "financial-service/internal/storage"
     
     "github.com/rs/zerolog/log"
 )
@@ -22,45 +24,212 @@ func main() {
     // Load config
     cfg := config.Load()
 
-    // Initialize DB
-    database, err := db.NewDB(cfg)
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(cfg, os.Args[2:]); err != nil {
+            log.Fatal().Err(err).Msg("Migration failed")
+        }
+        return
+    }
+
+    // Initialize storage
+    store, err := storage.Open(cfg)
     
     if err != nil {
-        log.Fatal().Err(err).Msg("Failed to connect to database")
+        log.Fatal().Err(err).Msg("Failed to initialize storage")
     }
 
-    defer database.Close()
-
-    // Run migrations
-    if err := db.MigrateDB(database, cfg); err != nil {
-        log.Fatal().Err(err).Msg("Failed to run database migrations")
-    }
+    defer store.Close()
 
     // Initialize repositories
-    userRepo := mysql.NewUserRepository(database)
-    txRepo := mysql.NewTransactionRepository(database)
-    balanceRepo := mysql.NewBalanceRepository(database)
-    auditRepo := mysql.NewAuditLogRepository(database)
+    userRepo := store.Users
+    txRepo := store.Transactions
+    balanceRepo := store.Balances
+    auditRepo := store.AuditLogs
 
     // Initialize audit logger
     auditLogger := services.NewAuditLogger(auditRepo)
 
     // Initialize services
-    userService := services.NewUserService(userRepo, balanceRepo)
+    userService := services.NewUserService(userRepo, store.Accounts, balanceRepo, cfg.DefaultCurrency)
+    accountService := services.NewAccountService(store.Accounts, balanceRepo, userRepo, store.Transactor)
     txService := services.NewTransactionService(txRepo, balanceRepo, userRepo, 5)
-    balanceService := services.NewBalanceService(balanceRepo, txRepo)
+    balanceService := services.NewBalanceService(balanceRepo, store.Accounts, txRepo, cfg.BalanceCacheSize, cfg.BalanceCacheTTL)
+    reconciler := services.NewReconciliationService(balanceRepo, txRepo, store.Transactor)
+    historyService := services.NewBalanceHistoryService(balanceRepo, txRepo, store.Snapshots)
+
+    var interestRates []services.InterestRate
+    if cfg.InterestRatesFile != "" {
+        interestRates, err = services.LoadInterestRates(cfg.InterestRatesFile)
+
+        if err != nil {
+            log.Fatal().Err(err).Msg("Failed to load interest rates")
+        }
+    }
+
+    interestService := services.NewInterestService(interestRates, balanceRepo, store.Interest, historyService, txService)
+
+    rateProvider, err := newRateProvider(cfg, store)
+
+    if err != nil {
+        log.Fatal().Err(err).Msg("Failed to initialize FX rates")
+    }
+
+    fxService := services.NewFXService(rateProvider, store.FXQuotes, userRepo, cfg.FXSpread, cfg.FXQuoteTTL)
+    bulkService := services.NewBulkTransferService(store.Batches, txService, store.Transactor, cfg.BulkMaxItems)
+    importService := services.NewImportService(store.Imports, userRepo, balanceRepo, txService, store.Transactor,
+        cfg.DefaultCurrency, cfg.ImportMaxRows)
+    statementService := services.NewStatementService(userRepo, historyService)
+    statementService.SetOFXBankID(cfg.OFXBankID)
+    statementService.SetPDFDir(cfg.StatementDir)
+    scheduleService := services.NewScheduledTransferService(store.Schedules, userRepo, txService,
+        cfg.SchedulerMaxRetries, cfg.SchedulerRetryInterval)
     
     // Set audit loggers
     userService.SetAuditLogger(auditLogger)
+    userService.SetTokenSecret(cfg.JWTSecret, cfg.JWTTTL)
+    userService.SetTransactor(store.Transactor)
+    accountService.SetAuditLogger(auditLogger)
     txService.SetAuditLogger(auditLogger)
+    balanceService.SetAuditLogger(auditLogger)
+    reconciler.SetAuditLogger(auditLogger)
+    scheduleService.SetAuditLogger(auditLogger)
+    bulkService.SetAuditLogger(auditLogger)
+    importService.SetAuditLogger(auditLogger)
+
+    // Apply balance changes atomically
+    txService.SetTransactor(store.Transactor)
+    txService.SetAccounts(store.Accounts)
+    txService.SetRejectFrozenCredits(cfg.FrozenAccountsRejectCredits)
+    txService.SetFXQuotes(store.FXQuotes)
+
+    // Charge fees
+    if cfg.FeeScheduleFile != "" {
+        feeSchedule, err := loadFeeSchedule(cfg, userRepo)
+
+        if err != nil {
+            log.Fatal().Err(err).Msg("Failed to load fee schedule")
+        }
+
+        txService.SetFeeSchedule(feeSchedule, cfg.FeeHouseUserID)
+    }
+
+    // Enforce transaction limits
+    limitService, err := newLimitService(cfg, store)
+
+    if err != nil {
+        log.Fatal().Err(err).Msg("Failed to load transaction limits")
+    }
+
+    limitService.SetAuditLogger(auditLogger)
+    txService.SetLimits(limitService)
+
+    // Hold large transactions and manual credits for a second admin
+    approvalPolicy, err := newApprovalPolicy(cfg)
+
+    if err != nil {
+        log.Fatal().Err(err).Msg("Failed to load approval thresholds")
+    }
+
+    txService.SetApprovals(approvalPolicy, store.Approvals)
+    txService.SetDecisionListener(scheduleService.Resolve)
+
+    // Screen transactions for fraud
+    if cfg.FraudRulesFile != "" {
+        fraudRules, err := services.LoadFraudRules(cfg.FraudRulesFile)
+
+        if err != nil {
+            log.Fatal().Err(err).Msg("Failed to load fraud rules")
+        }
+
+        fraudEngine, err := services.NewFraudEngineFromConfig(fraudRules, txRepo)
+
+        if err != nil {
+            log.Fatal().Err(err).Msg("Failed to build fraud rules")
+        }
+
+        txService.SetFraudEngine(fraudEngine)
+    }
+
+    // Screen users and transaction parties against sanctions
+    if cfg.WatchlistFile != "" {
+        watchlist, err := newWatchlist(cfg)
+
+        if err != nil {
+            log.Fatal().Err(err).Msg("Failed to load sanctions watchlist")
+        }
+
+        userService.SetWatchlist(watchlist)
+        txService.SetWatchlist(watchlist)
+    }
+
+    // Wire balance change events
+    balanceEvents := services.NewBalanceEvents()
+    balanceService.SetBalanceEvents(balanceEvents)
+    txService.SetBalanceEvents(balanceEvents)
+    reconciler.SetBalanceEvents(balanceEvents)
+    accountService.SetBalanceEvents(balanceEvents)
+
+    balanceEvents.SubscribeOverdraft(func(event services.OverdraftEvent) {
+        if event.Overdrawn {
+            log.Warn().Uint("account_id", event.AccountID).Uint("user_id", event.UserID).Str("currency", event.Currency).
+                Float64("amount", event.Amount).Float64("credit_limit", event.CreditLimit).
+                Msg("Balance entered overdraft")
+        } else {
+            log.Info().Uint("account_id", event.AccountID).Uint("user_id", event.UserID).Str("currency", event.Currency).
+                Float64("amount", event.Amount).Msg("Balance left overdraft")
+        }
+    })
+
+    // Start background jobs
+    if cfg.SnapshotInterval > 0 {
+        snapshotJob := historyService.SnapshotJob(cfg.SnapshotInterval)
+        snapshotJob.Start()
+        defer snapshotJob.Stop()
+    }
+
+    if cfg.InterestRatesFile != "" && cfg.InterestJobInterval > 0 {
+        interestJob := interestService.Job(cfg.InterestJobInterval)
+        interestJob.Start()
+        defer interestJob.Stop()
+    }
+
+    if cfg.SchedulerInterval > 0 {
+        scheduleJob := scheduleService.Job(cfg.SchedulerInterval)
+        scheduleJob.Start()
+        defer scheduleJob.Stop()
+    }
+
+    if cfg.StatementDir != "" && cfg.StatementJobInterval > 0 {
+        statementJob := statementService.MonthlyJob(cfg.StatementJobInterval)
+        statementJob.Start()
+        defer statementJob.Stop()
+    }
+
+    if cfg.ApprovalExpiryInterval > 0 {
+        approvalJob := txService.ApprovalExpiryJob(cfg.ApprovalExpiryInterval)
+        approvalJob.Start()
+        defer approvalJob.Stop()
+    }
+
+    if cfg.ReconciliationInterval > 0 {
+        reconciliationJob := reconciler.ReportOnlyJob(cfg.ReconciliationInterval, cfg.ReconciliationReportDir)
+        reconciliationJob.Start()
+        defer reconciliationJob.Stop()
+    }
 
     // Initialize handlers
     userHandler := handlers.NewUserHandler(userService)
-    txHandler := handlers.NewTransactionHandler(txService)
-    balanceHandler := handlers.NewBalanceHandler(balanceService)
+    statementHandler := handlers.NewStatementHandler(statementService, cfg.DefaultCurrency)
+    txHandler := handlers.NewTransactionHandler(txService, cfg.DefaultCurrency)
+    bulkHandler := handlers.NewBulkTransferHandler(bulkService, cfg.DefaultCurrency)
+    balanceHandler := handlers.NewBalanceHandler(balanceService, historyService, interestService, cfg.DefaultCurrency)
+    fxHandler := handlers.NewFXHandler(fxService)
+    scheduleHandler := handlers.NewScheduledTransferHandler(scheduleService, cfg.DefaultCurrency)
+    importHandler := handlers.NewImportHandler(importService, services.NewPaymentInitiationService(importService))
+    accountHandler := handlers.NewAccountHandler(accountService, txService, cfg.DefaultCurrency)
 
     // Initialize router
-    router := api.NewRouter(userHandler, txHandler, balanceHandler)
+    router := api.NewRouter(userHandler, statementHandler, txHandler, bulkHandler, balanceHandler, fxHandler, scheduleHandler, importHandler, accountHandler)
 
     // Create server
     srv := &http.Server{
@@ -92,3 +261,77 @@ func main() {
 
     log.Info().Msg("Server exited properly")
 }
+
+// newRateProvider returns the FX rate source selected by cfg.FXRateSource.
+func newRateProvider(cfg *config.Config, store *storage.Storage) (services.RateProvider, error) {
+    switch cfg.FXRateSource {
+        case "db":
+            return services.NewStoredRateProvider(store.FXRates, cfg.FXRateMaxAge), nil
+        case "file":
+            return services.NewFileRateProvider(cfg.FXRateFile)
+        default:
+            return nil, fmt.Errorf("unknown FX rate source: %q", cfg.FXRateSource)
+    }
+}
+
+// loadFeeSchedule reads the configured fee schedule and checks that the house
+// account fees are posted to exists.
+func loadFeeSchedule(cfg *config.Config, userRepo repository.UserRepository) (*services.FeeSchedule, error) {
+    schedule, err := services.LoadFeeSchedule(cfg.FeeScheduleFile)
+    if err != nil {
+        return nil, err
+    }
+
+    if cfg.FeeHouseUserID == 0 {
+        return nil, fmt.Errorf("FEE_HOUSE_USER_ID is required when a fee schedule is set")
+    }
+
+    if _, err := userRepo.GetByID(context.Background(), cfg.FeeHouseUserID); err != nil {
+        return nil, fmt.Errorf("house account user %d: %w", cfg.FeeHouseUserID, err)
+    }
+
+    return schedule, nil
+}
+
+// newLimitService enforces the configured default limits together with the
+// per-user overrides kept in storage.
+func newLimitService(cfg *config.Config, store *storage.Storage) (*services.LimitService, error) {
+    var defaults []models.TransactionLimit
+
+    if cfg.LimitsFile != "" {
+        var err error
+        if defaults, err = services.LoadLimits(cfg.LimitsFile); err != nil {
+            return nil, err
+        }
+    }
+
+    return services.NewLimitService(defaults, store.Limits, store.Transactions, store.Users), nil
+}
+
+// newApprovalPolicy holds transactions above the configured thresholds, and
+// manual credits if enabled, for approval.
+func newApprovalPolicy(cfg *config.Config) (*services.ApprovalPolicy, error) {
+    policy := &services.ApprovalPolicy{
+        ManualCredits: cfg.ApproveManualCredits,
+        TTL:           cfg.ApprovalTTL,
+    }
+
+    if cfg.ApprovalThresholdsFile != "" {
+        var err error
+        if policy.Thresholds, err = services.LoadApprovalThresholds(cfg.ApprovalThresholdsFile); err != nil {
+            return nil, err
+        }
+    }
+
+    return policy, nil
+}
+
+// newWatchlist loads the sanctions watchlist with the configured scores.
+func newWatchlist(cfg *config.Config) (*services.Watchlist, error) {
+    entries, err := services.LoadWatchlist(cfg.WatchlistFile)
+    if err != nil {
+        return nil, err
+    }
+
+    return services.NewWatchlist(entries, cfg.WatchlistFlagScore, cfg.WatchlistBlockScore)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+    "financial-service/internal/config"
+    "financial-service/internal/db"
+    "github.com/golang-migrate/migrate/v4"
+    "github.com/rs/zerolog/log"
+)
+
+const migrateUsage = "usage: migrate up [N] | down [N] | goto VERSION | version | force VERSION"
+
+// runMigrate implements the `migrate` subcommand so schema changes can be
+// applied independently of starting the server.
+func runMigrate(cfg *config.Config, args []string) error {
+    if len(args) == 0 {
+        return errors.New(migrateUsage)
+    }
+
+    database, err := db.Open(cfg)
+    if err != nil {
+        return err
+    }
+
+    defer database.Close()
+
+    m, err := db.NewMigrator(database, cfg)
+    if err != nil {
+        return err
+    }
+
+    switch args[0] {
+        case "up":
+            if len(args) > 1 {
+                var n int
+                if n, err = parseMigrateArg(args); err != nil {
+                    return err
+                }
+                err = m.Steps(n)
+            } else {
+                err = m.Up()
+            }
+        case "down":
+            // Default to a single step; rolling back everything must be asked for
+            // explicitly by passing the number of steps.
+            n := 1
+            if len(args) > 1 {
+                if n, err = parseMigrateArg(args); err != nil {
+                    return err
+                }
+            }
+            err = m.Steps(-n)
+        case "goto":
+            var version int
+            if version, err = parseMigrateArg(args); err != nil {
+                return err
+            }
+            err = m.Migrate(uint(version))
+        case "force":
+            var version int
+            if version, err = parseMigrateArg(args); err != nil {
+                return err
+            }
+            err = m.Force(version)
+        case "version":
+        default:
+            return errors.New(migrateUsage)
+    }
+
+    // Stepping past the first or last migration surfaces as a missing file.
+    if errors.Is(err, os.ErrNotExist) {
+        return errors.New("no migration available in that direction")
+    }
+
+    if errors.Is(err, migrate.ErrNoChange) {
+        log.Info().Msg("No migrations to apply")
+        err = nil
+    }
+
+    if err != nil {
+        return err
+    }
+
+    version, dirty, err := m.Version()
+    if errors.Is(err, migrate.ErrNilVersion) {
+        log.Info().Msg("No migrations applied")
+        return nil
+    }
+
+    if err != nil {
+        return err
+    }
+
+    log.Info().Uint("version", version).Bool("dirty", dirty).Msg("Schema version")
+
+    return nil
+}
+
+func parseMigrateArg(args []string) (int, error) {
+    if len(args) != 2 {
+        return 0, errors.New(migrateUsage)
+    }
+
+    n, err := strconv.Atoi(args[1])
+    if err != nil || n < 0 {
+        return 0, fmt.Errorf("invalid number %q: %s", args[1], migrateUsage)
+    }
+
+    return n, nil
+}
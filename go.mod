@@ -1,14 +1,17 @@
 module financial-service
 
-go 1.18
+go 1.22.0
 
 require (
 	github.com/go-chi/chi/v5 v5.2.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/go-sql-driver/mysql v1.8.1
 	github.com/golang-migrate/migrate/v4 v4.18.1
+	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/rs/zerolog v1.33.0
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/crypto v0.32.0
+	golang.org/x/sync v0.8.0
 )
 
 require (
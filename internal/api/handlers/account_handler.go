@@ -0,0 +1,251 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "financial-service/internal/services"
+    "github.com/go-chi/chi/v5"
+)
+
+type AccountHandler struct {
+    service         *services.AccountService
+    txService       *services.TransactionService
+    defaultCurrency string
+}
+
+func NewAccountHandler(service *services.AccountService, txService *services.TransactionService, defaultCurrency string) *AccountHandler {
+    return &AccountHandler{
+        service:         service,
+        txService:       txService,
+        defaultCurrency: defaultCurrency,
+    }
+}
+
+type AccountRequest struct {
+    UserID   uint   `json:"user_id"`
+    Type     string `json:"type"`
+    Currency string `json:"currency"`
+}
+
+type AccountUpdateRequest struct {
+    Type *string `json:"type"`
+}
+
+// AccountStatusRequest gives the reason for freezing, unfreezing or closing
+// an account.
+type AccountStatusRequest struct {
+    Reason string `json:"reason"`
+}
+
+type AccountTransferRequest struct {
+    FromAccountID uint    `json:"from_account_id"`
+    ToAccountID   uint    `json:"to_account_id"`
+    Amount        float64 `json:"amount"`
+}
+
+func accountID(r *http.Request) (uint, error) {
+    id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    return uint(id), err
+}
+
+func (h *AccountHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var req AccountRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if req.Type == "" {
+        req.Type = string(models.AccountTypeChecking)
+    }
+
+    if req.Currency == "" {
+        req.Currency = h.defaultCurrency
+    }
+
+    account, err := h.service.Create(r.Context(), req.UserID, models.AccountType(req.Type), req.Currency)
+
+    if err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(account)
+}
+
+func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := accountID(r)
+    if err != nil {
+        http.Error(w, "Invalid account ID", http.StatusBadRequest)
+        return
+    }
+
+    account, err := h.service.Get(r.Context(), id)
+
+    if err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(account)
+}
+
+// ListByUser returns the accounts of the user in the path.
+func (h *AccountHandler) ListByUser(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    accounts, err := h.service.ListByUser(r.Context(), uint(userID))
+
+    if err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    if accounts == nil {
+        accounts = []*models.Account{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(accounts)
+}
+
+func (h *AccountHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := accountID(r)
+    if err != nil {
+        http.Error(w, "Invalid account ID", http.StatusBadRequest)
+        return
+    }
+
+    var req AccountUpdateRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    var update services.AccountUpdate
+
+    if req.Type != nil {
+        accountType := models.AccountType(*req.Type)
+        update.Type = &accountType
+    }
+
+    account, err := h.service.Update(r.Context(), id, update)
+
+    if err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(account)
+}
+
+func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    id, err := accountID(r)
+    if err != nil {
+        http.Error(w, "Invalid account ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.Delete(r.Context(), id); err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAccountError reports a failed account operation. Missing accounts are
+// not found; accounts whose status or balance does not allow the operation
+// are a conflict, and a missing reason is a bad request.
+func writeAccountError(w http.ResponseWriter, err error) {
+    switch {
+        case errors.Is(err, services.ErrAccountNotFound) || errors.Is(err, repository.ErrNotFound):
+            http.Error(w, err.Error(), http.StatusNotFound)
+        case errors.Is(err, services.ErrAccountNotEmpty) || errors.Is(err, services.ErrAccountUsed) ||
+            errors.Is(err, services.ErrInvalidAccountStatus) || errors.Is(err, models.ErrAccountClosed) ||
+            errors.Is(err, models.ErrAccountFrozen):
+            http.Error(w, err.Error(), http.StatusConflict)
+        case errors.Is(err, services.ErrReasonRequired):
+            http.Error(w, err.Error(), http.StatusBadRequest)
+        default:
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+    }
+}
+
+// Transfer moves money between two accounts in the same currency.
+func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+    var req AccountTransferRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    tx, err := h.txService.TransferBetweenAccounts(r.Context(), req.FromAccountID, req.ToAccountID, req.Amount)
+
+    if err != nil {
+        if errors.Is(err, models.ErrCrossCurrency) {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        writeTransactionError(w, err)
+        return
+    }
+
+    writeTransaction(w, tx)
+}
+
+// Freeze, Unfreeze and Close are admin operations; the reason given is
+// recorded in the audit log.
+func (h *AccountHandler) Freeze(w http.ResponseWriter, r *http.Request) {
+    h.changeStatus(w, r, h.service.Freeze)
+}
+
+func (h *AccountHandler) Unfreeze(w http.ResponseWriter, r *http.Request) {
+    h.changeStatus(w, r, h.service.Unfreeze)
+}
+
+func (h *AccountHandler) Close(w http.ResponseWriter, r *http.Request) {
+    h.changeStatus(w, r, h.service.Close)
+}
+
+func (h *AccountHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id uint) (*models.Account, error)) {
+    id, err := accountID(r)
+    if err != nil {
+        http.Error(w, "Invalid account ID", http.StatusBadRequest)
+        return
+    }
+
+    var req AccountStatusRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if req.Reason == "" {
+        http.Error(w, "reason is required", http.StatusBadRequest)
+        return
+    }
+
+    account, err := change(services.WithReason(r.Context(), req.Reason), id)
+
+    if err != nil {
+        writeAccountError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(account)
+}
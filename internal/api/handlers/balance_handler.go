@@ -4,33 +4,112 @@ import (
     "encoding/json"
     "net/http"
     "strconv"
+    "time"
+    "financial-service/internal/models"
     "financial-service/internal/services"
-    "github.com/rs/zerolog/log"
     "github.com/go-chi/chi/v5"
 )
 
 type BalanceHandler struct {
-    balanceService *services.BalanceService
+    balanceService  *services.BalanceService
+    historyService  *services.BalanceHistoryService
+    interestService *services.InterestService
+    defaultCurrency string
 }
 
-func NewBalanceHandler(balanceService *services.BalanceService) *BalanceHandler {
+func NewBalanceHandler(
+    balanceService *services.BalanceService,
+    historyService *services.BalanceHistoryService,
+    interestService *services.InterestService,
+    defaultCurrency string,
+) *BalanceHandler {
     return &BalanceHandler{
-        balanceService: balanceService,
+        balanceService:  balanceService,
+        historyService:  historyService,
+        interestService: interestService,
+        defaultCurrency: defaultCurrency,
     }
 }
 
+// currency reads the ?currency= parameter, falling back to the default.
+func (h *BalanceHandler) currency(r *http.Request) (string, error) {
+    currency := r.URL.Query().Get("currency")
+    if currency == "" {
+        return h.defaultCurrency, nil
+    }
+    return currency, models.ValidateCurrency(currency)
+}
+
 func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
     userIDStr := chi.URLParam(r, "user_id")
     userID, err := strconv.ParseUint(userIDStr, 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    currency, err := h.currency(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    var balance *models.Balance
+
+    if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+        t, parseErr := parseTimeParam(asOf, true)
+        if parseErr != nil {
+            http.Error(w, "Invalid as_of: "+parseErr.Error(), http.StatusBadRequest)
+            return
+        }
+        balance, err = h.historyService.GetBalanceAsOf(r.Context(), uint(userID), currency, t)
+    } else {
+        balance, err = h.balanceService.GetBalance(r.Context(), uint(userID), currency)
+    }
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(balance)
+}
 
-    log.Printf("User ID: %d", userID)
+// GetBalanceHistory returns the running balance over [from, to), defaulting
+// to the last 30 days.
+func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 32)
 
     if err != nil {
         http.Error(w, "Invalid user ID", http.StatusBadRequest)
         return
     }
 
-    balance, err := h.balanceService.GetBalance(r.Context(), uint(userID))
+    currency, err := h.currency(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    to := time.Now()
+    from := to.AddDate(0, 0, -30)
+
+    if v := r.URL.Query().Get("from"); v != "" {
+        if from, err = parseTimeParam(v, false); err != nil {
+            http.Error(w, "Invalid from: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+
+    if v := r.URL.Query().Get("to"); v != "" {
+        if to, err = parseTimeParam(v, true); err != nil {
+            http.Error(w, "Invalid to: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+
+    history, err := h.historyService.GetHistory(r.Context(), uint(userID), currency, from, to)
 
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -38,5 +117,57 @@ func (h *BalanceHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(balance)
-} 
\ No newline at end of file
+    json.NewEncoder(w).Encode(history)
+}
+
+// GetUserBalances lists the user's balance in every currency they hold.
+func (h *BalanceHandler) GetUserBalances(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 32)
+
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    balances, err := h.balanceService.GetUserBalances(r.Context(), uint(userID))
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(balances)
+}
+
+// GetAccruedInterest returns the interest accrued on the balance that has
+// not been paid out yet.
+func (h *BalanceHandler) GetAccruedInterest(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "user_id"), 10, 32)
+
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    currency, err := h.currency(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    accrued, err := h.interestService.GetAccrued(r.Context(), uint(userID), currency)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(accrued)
+}
+
+func (h *BalanceHandler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(h.balanceService.CacheStats())
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "financial-service/internal/models"
+    "financial-service/internal/services"
+    "github.com/go-chi/chi/v5"
+)
+
+type BulkTransferHandler struct {
+    service         *services.BulkTransferService
+    defaultCurrency string
+}
+
+func NewBulkTransferHandler(service *services.BulkTransferService, defaultCurrency string) *BulkTransferHandler {
+    return &BulkTransferHandler{
+        service:         service,
+        defaultCurrency: defaultCurrency,
+    }
+}
+
+// BulkTransferRequest submits a batch. Mode defaults to all_or_nothing.
+type BulkTransferRequest struct {
+    ClientBatchID string            `json:"client_batch_id"`
+    Mode          string            `json:"mode"`
+    Transfers     []TransferRequest `json:"transfers"`
+}
+
+// Submit accepts a batch of transfers and answers 202 Accepted while they
+// are executed; the batch endpoint reports the outcome. Resubmitting a
+// client batch ID returns the existing batch with 200 OK.
+func (h *BulkTransferHandler) Submit(w http.ResponseWriter, r *http.Request) {
+    var req BulkTransferRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    batch := &models.TransferBatch{
+        ClientBatchID: req.ClientBatchID,
+        Mode:          models.BatchMode(req.Mode),
+    }
+
+    if batch.Mode == "" {
+        batch.Mode = models.BatchModeAllOrNothing
+    }
+
+    for _, transfer := range req.Transfers {
+        if transfer.Convert || (transfer.ToCurrency != "" && transfer.ToCurrency != transfer.Currency) {
+            http.Error(w, "conversions cannot be made in bulk", http.StatusBadRequest)
+            return
+        }
+
+        currency := transfer.Currency
+        if currency == "" {
+            currency = h.defaultCurrency
+        }
+
+        batch.Items = append(batch.Items, &models.TransferBatchItem{
+            FromUserID: transfer.FromUserID,
+            ToUserID:   transfer.ToUserID,
+            Amount:     transfer.Amount,
+            Currency:   currency,
+        })
+    }
+
+    result, created, err := h.service.Submit(r.Context(), batch)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    if created {
+        w.WriteHeader(http.StatusAccepted)
+    }
+    json.NewEncoder(w).Encode(result)
+}
+
+// Get returns a batch with the status of each of its transfers.
+func (h *BulkTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+    batch, err := h.service.Get(r.Context(), chi.URLParam(r, "batch_id"))
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(batch)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "financial-service/internal/services"
+)
+
+type FXHandler struct {
+    service *services.FXService
+}
+
+func NewFXHandler(service *services.FXService) *FXHandler {
+    return &FXHandler{
+        service: service,
+    }
+}
+
+type QuoteRequest struct {
+    UserID       uint    `json:"user_id"`
+    FromCurrency string  `json:"from_currency"`
+    ToCurrency   string  `json:"to_currency"`
+    Amount       float64 `json:"amount"`
+}
+
+// CreateQuote prices a conversion. The returned quote ID is passed to the
+// transfer endpoint with "convert": true before the quote expires.
+func (h *FXHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+    var req QuoteRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    quote, err := h.service.CreateQuote(r.Context(), req.UserID, req.FromCurrency, req.ToCurrency, req.Amount)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(quote)
+}
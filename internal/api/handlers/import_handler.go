@@ -0,0 +1,141 @@
+package handlers
+
+import (
+    "encoding/json"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "financial-service/internal/services"
+    "github.com/go-chi/chi/v5"
+)
+
+// maxImportSize caps the size of an uploaded import file
+const maxImportSize = 10 << 20
+
+type ImportHandler struct {
+    service         *services.ImportService
+    paymentsService *services.PaymentInitiationService
+}
+
+func NewImportHandler(service *services.ImportService, paymentsService *services.PaymentInitiationService) *ImportHandler {
+    return &ImportHandler{
+        service:         service,
+        paymentsService: paymentsService,
+    }
+}
+
+// readImportFile returns the uploaded file and its name. The file is either
+// the "file" field of a multipart form or the raw request body, named by the
+// filename query parameter.
+func readImportFile(w http.ResponseWriter, r *http.Request) (string, []byte, error) {
+    r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+    if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+        data, err := io.ReadAll(r.Body)
+        return r.URL.Query().Get("filename"), data, err
+    }
+
+    file, header, err := r.FormFile("file")
+    if err != nil {
+        return "", nil, err
+    }
+    defer file.Close()
+
+    data, err := io.ReadAll(file)
+    return header.Filename, data, err
+}
+
+// Create imports a CSV file of credits and transfers. With dry_run=true the
+// rows are validated and reported without being executed. A new import is
+// answered with 201 Created; uploading a file imported before returns that
+// import with 200 OK.
+func (h *ImportHandler) Create(w http.ResponseWriter, r *http.Request) {
+    filename, data, err := readImportFile(w, r)
+    if err != nil {
+        http.Error(w, "Invalid file: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    dryRun, err := dryRunParam(r)
+    if err != nil {
+        http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+        return
+    }
+
+    result, created, err := h.service.ImportCSV(r.Context(), filename, data, dryRun)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    if created && !dryRun {
+        w.WriteHeader(http.StatusCreated)
+    }
+    json.NewEncoder(w).Encode(result)
+}
+
+// CreatePain001 imports an ISO 20022 pain.001 message and answers with a
+// pain.002 status report on each of its transfers. Status codes follow
+// Create.
+func (h *ImportHandler) CreatePain001(w http.ResponseWriter, r *http.Request) {
+    filename, data, err := readImportFile(w, r)
+    if err != nil {
+        http.Error(w, "Invalid file: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    dryRun, err := dryRunParam(r)
+    if err != nil {
+        http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+        return
+    }
+
+    report, _, created, err := h.paymentsService.ImportPain001(r.Context(), filename, data, dryRun)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    body, err := report.Marshal()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/xml")
+    if created && !dryRun {
+        w.WriteHeader(http.StatusCreated)
+    }
+    w.Write(body)
+}
+
+func dryRunParam(r *http.Request) (bool, error) {
+    v := r.URL.Query().Get("dry_run")
+    if v == "" {
+        return false, nil
+    }
+    return strconv.ParseBool(v)
+}
+
+// Get returns an import with the outcome of each of its rows.
+func (h *ImportHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid import ID", http.StatusBadRequest)
+        return
+    }
+
+    result, err := h.service.Get(r.Context(), uint(id))
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(result)
+}
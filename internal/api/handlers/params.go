@@ -0,0 +1,26 @@
+package handlers
+
+import (
+    "errors"
+    "time"
+)
+
+// parseTimeParam accepts an RFC 3339 timestamp or a YYYY-MM-DD date. A bare
+// date means the start of that day in UTC, or the end of it when endOfDay is
+// set, so as_of=2024-03-31 includes everything that happened on March 31st.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return t, nil
+    }
+
+    t, err := time.Parse("2006-01-02", value)
+    if err != nil {
+        return time.Time{}, errors.New("expected RFC 3339 timestamp or YYYY-MM-DD date")
+    }
+
+    if endOfDay {
+        t = t.AddDate(0, 0, 1)
+    }
+
+    return t, nil
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/services"
+    "github.com/go-chi/chi/v5"
+)
+
+type ScheduledTransferHandler struct {
+    service         *services.ScheduledTransferService
+    defaultCurrency string
+}
+
+func NewScheduledTransferHandler(service *services.ScheduledTransferService, defaultCurrency string) *ScheduledTransferHandler {
+    return &ScheduledTransferHandler{
+        service:         service,
+        defaultCurrency: defaultCurrency,
+    }
+}
+
+// ScheduledTransferRequest creates a schedule. StartAt and EndDate take an
+// RFC 3339 timestamp or a YYYY-MM-DD date; an end date includes that day.
+type ScheduledTransferRequest struct {
+    FromUserID          uint    `json:"from_user_id"`
+    ToUserID            uint    `json:"to_user_id"`
+    Amount              float64 `json:"amount"`
+    Currency            string  `json:"currency"`
+    Frequency           string  `json:"frequency"`
+    StartAt             string  `json:"start_at"`
+    EndDate             string  `json:"end_date"`
+    OnInsufficientFunds string  `json:"on_insufficient_funds"`
+}
+
+type ScheduledTransferUpdateRequest struct {
+    Amount              *float64 `json:"amount"`
+    EndDate             *string  `json:"end_date"`
+    OnInsufficientFunds *string  `json:"on_insufficient_funds"`
+}
+
+func scheduleID(r *http.Request) (uint, error) {
+    id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    return uint(id), err
+}
+
+func (h *ScheduledTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var req ScheduledTransferRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    schedule := &models.ScheduledTransfer{
+        FromUserID:          req.FromUserID,
+        ToUserID:            req.ToUserID,
+        Amount:              req.Amount,
+        Currency:            req.Currency,
+        Frequency:           models.TransferFrequency(req.Frequency),
+        OnInsufficientFunds: req.OnInsufficientFunds,
+    }
+
+    if schedule.Currency == "" {
+        schedule.Currency = h.defaultCurrency
+    }
+
+    if req.StartAt != "" {
+        startAt, err := parseTimeParam(req.StartAt, false)
+        if err != nil {
+            http.Error(w, "Invalid start_at: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        schedule.StartAt = startAt
+    }
+
+    if req.EndDate != "" {
+        endDate, err := parseTimeParam(req.EndDate, true)
+        if err != nil {
+            http.Error(w, "Invalid end_date: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        schedule.EndDate = &endDate
+    }
+
+    if err := h.service.Create(r.Context(), schedule); err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ScheduledTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := scheduleID(r)
+    if err != nil {
+        http.Error(w, "Invalid scheduled transfer ID", http.StatusBadRequest)
+        return
+    }
+
+    schedule, err := h.service.Get(r.Context(), id)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(schedule)
+}
+
+// List returns the schedules paying from or into ?user_id=.
+func (h *ScheduledTransferHandler) List(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(r.URL.Query().Get("user_id"), 10, 32)
+
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    schedules, err := h.service.ListByUser(r.Context(), uint(userID))
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    if schedules == nil {
+        schedules = []*models.ScheduledTransfer{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(schedules)
+}
+
+func (h *ScheduledTransferHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := scheduleID(r)
+    if err != nil {
+        http.Error(w, "Invalid scheduled transfer ID", http.StatusBadRequest)
+        return
+    }
+
+    var req ScheduledTransferUpdateRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    update := services.ScheduledTransferUpdate{
+        Amount:              req.Amount,
+        OnInsufficientFunds: req.OnInsufficientFunds,
+    }
+
+    if req.EndDate != nil {
+        var endDate time.Time
+        if endDate, err = parseTimeParam(*req.EndDate, true); err != nil {
+            http.Error(w, "Invalid end_date: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+        update.EndDate = &endDate
+    }
+
+    schedule, err := h.service.Update(r.Context(), id, update)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(schedule)
+}
+
+func (h *ScheduledTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+    id, err := scheduleID(r)
+    if err != nil {
+        http.Error(w, "Invalid scheduled transfer ID", http.StatusBadRequest)
+        return
+    }
+
+    schedule, err := h.service.Cancel(r.Context(), id)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(schedule)
+}
+
+// ListRuns returns the occurrences executed so far and their outcome.
+func (h *ScheduledTransferHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+    id, err := scheduleID(r)
+    if err != nil {
+        http.Error(w, "Invalid scheduled transfer ID", http.StatusBadRequest)
+        return
+    }
+
+    runs, err := h.service.ListRuns(r.Context(), id)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    if runs == nil {
+        runs = []*models.ScheduledTransferRun{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(runs)
+}
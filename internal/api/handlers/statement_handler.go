@@ -0,0 +1,135 @@
+package handlers
+
+import (
+    "bytes"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/services"
+    "github.com/go-chi/chi/v5"
+)
+
+type StatementHandler struct {
+    service         *services.StatementService
+    defaultCurrency string
+}
+
+func NewStatementHandler(service *services.StatementService, defaultCurrency string) *StatementHandler {
+    return &StatementHandler{
+        service:         service,
+        defaultCurrency: defaultCurrency,
+    }
+}
+
+// Get returns the user's statement over [from, to), defaulting to the last
+// 30 days, as json (the default), csv, camt.053, ofx or pdf.
+func (h *StatementHandler) Get(w http.ResponseWriter, r *http.Request) {
+    format := r.URL.Query().Get("format")
+    if format == "" {
+        format = "json"
+    }
+
+    h.write(w, r, chi.URLParam(r, "id"), format)
+}
+
+// GetOFX returns the user's transactions over [from, to) as an OFX file for
+// personal finance software, next to the JSON balance history.
+func (h *StatementHandler) GetOFX(w http.ResponseWriter, r *http.Request) {
+    h.write(w, r, chi.URLParam(r, "user_id"), "ofx")
+}
+
+func (h *StatementHandler) write(w http.ResponseWriter, r *http.Request, userIDParam, format string) {
+    userID, err := strconv.ParseUint(userIDParam, 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    currency := r.URL.Query().Get("currency")
+    if currency == "" {
+        currency = h.defaultCurrency
+    } else if err := models.ValidateCurrency(currency); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    contentType := services.StatementContentType(format)
+    if contentType == "" {
+        http.Error(w, "Invalid format: expected json, csv, camt.053, ofx or pdf", http.StatusBadRequest)
+        return
+    }
+
+    to := time.Now()
+    from := to.AddDate(0, 0, -30)
+
+    if v := r.URL.Query().Get("from"); v != "" {
+        if from, err = parseTimeParam(v, false); err != nil {
+            http.Error(w, "Invalid from: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+
+    if v := r.URL.Query().Get("to"); v != "" {
+        if to, err = parseTimeParam(v, true); err != nil {
+            http.Error(w, "Invalid to: "+err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+
+    statement, err := h.service.Generate(r.Context(), uint(userID), currency, from, to)
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    var buf bytes.Buffer
+    if err := statement.Write(&buf, format); err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", contentType)
+    w.Write(buf.Bytes())
+}
+
+// GetMonthlyPDF returns the user's PDF statement for a month, given as
+// YYYY-MM.
+func (h *StatementHandler) GetMonthlyPDF(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    month, err := time.Parse("2006-01", chi.URLParam(r, "period"))
+    if err != nil {
+        http.Error(w, "Invalid period: expected YYYY-MM", http.StatusBadRequest)
+        return
+    }
+
+    currency := r.URL.Query().Get("currency")
+    if currency == "" {
+        currency = h.defaultCurrency
+    } else if err := models.ValidateCurrency(currency); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    data, err := h.service.MonthlyPDF(r.Context(), uint(userID), currency, month)
+
+    if errors.Is(err, services.ErrStatementNotReady) {
+        http.Error(w, err.Error(), http.StatusNotFound)
+        return
+    }
+
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/pdf")
+    w.Write(data)
+}
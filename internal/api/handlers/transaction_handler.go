@@ -1,27 +1,45 @@
 package handlers
 
 import (
+    "context"
     "encoding/json"
+    "errors"
     "net/http"
+    "strconv"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
     "financial-service/internal/services"
-    "github.com/rs/zerolog/log"
+    "github.com/go-chi/chi/v5"
 )
 
 type TransactionHandler struct {
-    service *services.TransactionService
+    service         *services.TransactionService
+    defaultCurrency string
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
+func NewTransactionHandler(service *services.TransactionService, defaultCurrency string) *TransactionHandler {
     return &TransactionHandler{
-        service: service,
+        service:         service,
+        defaultCurrency: defaultCurrency,
     }
 }
 
 type TransactionRequest struct {
-    UserID uint    `json:"user_id"`
-    Amount float64 `json:"amount"`
+    UserID   uint    `json:"user_id"`
+    Amount   float64 `json:"amount"`
+    Currency string  `json:"currency"`
 }
 
+// currency returns the requested currency or the default when none was given.
+func (h *TransactionHandler) currency(requested string) string {
+    if requested == "" {
+        return h.defaultCurrency
+    }
+    return requested
+}
+
+// Credit credits a user. Its route is for admins only, so a credit held for
+// approval is always attributed to the admin who made it.
 func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
     var req TransactionRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -29,17 +47,14 @@ func (h *TransactionHandler) Credit(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    tx, err := h.service.Credit(r.Context(), req.UserID, req.Amount)
-
-    log.Printf("Transaction: %+v", tx)
+    tx, err := h.service.Credit(r.Context(), req.UserID, req.Amount, h.currency(req.Currency))
 
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeTransactionError(w, err)
         return
     }
 
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(tx)
+    writeTransaction(w, tx)
 }
 
 func (h *TransactionHandler) Debit(w http.ResponseWriter, r *http.Request) {
@@ -49,23 +64,28 @@ func (h *TransactionHandler) Debit(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    tx, err := h.service.Debit(r.Context(), req.UserID, req.Amount)
+    tx, err := h.service.Debit(r.Context(), req.UserID, req.Amount, h.currency(req.Currency))
 
-    log.Printf("Transaction: %+v", tx)
-    
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeTransactionError(w, err)
         return
     }
 
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(tx)
+    writeTransaction(w, tx)
 }
 
 type TransferRequest struct {
     FromUserID uint    `json:"from_user_id"`
     ToUserID   uint    `json:"to_user_id"`
     Amount     float64 `json:"amount"`
+    Currency   string  `json:"currency"`
+    // ToCurrency is the currency credited to the recipient. It defaults to
+    // Currency; a different currency needs Convert to be set.
+    ToCurrency string  `json:"to_currency"`
+    // Convert executes the FX quote QuoteID, which fixes both amounts and
+    // currencies of the transfer.
+    Convert    bool    `json:"convert"`
+    QuoteID    uint    `json:"quote_id"`
 }
 
 func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
@@ -75,15 +95,181 @@ func (h *TransactionHandler) Transfer(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    tx, err := h.service.Transfer(r.Context(), req.FromUserID, req.ToUserID, req.Amount)
+    currency := h.currency(req.Currency)
 
-    log.Printf("Transaction: %+v", tx)
+    var tx *models.Transaction
+    var err error
+
+    if req.Convert {
+        if req.QuoteID == 0 {
+            http.Error(w, "quote_id is required to convert", http.StatusBadRequest)
+            return
+        }
+        tx, err = h.service.ConvertTransfer(r.Context(), req.FromUserID, req.ToUserID, req.QuoteID)
+    } else if req.ToCurrency != "" && req.ToCurrency != currency {
+        http.Error(w, models.ErrCrossCurrency.Error(), http.StatusBadRequest)
+        return
+    } else {
+        tx, err = h.service.Transfer(r.Context(), req.FromUserID, req.ToUserID, req.Amount, currency)
+    }
+
+    if err != nil {
+        writeTransactionError(w, err)
+        return
+    }
+
+    writeTransaction(w, tx)
+}
+
+// ApprovalRequest gives the reason for approving, rejecting or making a
+// manual credit.
+type ApprovalRequest struct {
+    Reason string `json:"reason"`
+}
+
+type ManualCreditRequest struct {
+    UserID   uint    `json:"user_id"`
+    Amount   float64 `json:"amount"`
+    Currency string  `json:"currency"`
+    Reason   string  `json:"reason"`
+}
+
+// ManualCredit credits a user on an admin's behalf. The credit is held for
+// another admin's approval if manual credits need one.
+func (h *TransactionHandler) ManualCredit(w http.ResponseWriter, r *http.Request) {
+    var req ManualCreditRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if req.Reason == "" {
+        http.Error(w, "reason is required", http.StatusBadRequest)
+        return
+    }
+
+    ctx := services.WithReason(r.Context(), req.Reason)
+    tx, err := h.service.Credit(ctx, req.UserID, req.Amount, h.currency(req.Currency))
+
+    if err != nil {
+        writeTransactionError(w, err)
+        return
+    }
+
+    writeTransaction(w, tx)
+}
+
+// ListApprovals returns the transactions awaiting approval; ?kind=review
+// narrows them to those flagged by fraud screening.
+func (h *TransactionHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+    kind := models.ApprovalKind(r.URL.Query().Get("kind"))
+    approvals, err := h.service.ListAwaitingApproval(r.Context(), kind)
 
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
     }
 
+    if approvals == nil {
+        approvals = []*models.Approval{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(approvals)
+}
+
+// Approve and Reject decide a transaction awaiting approval. The admin
+// deciding must not be the one who initiated it; a reason is required to
+// reject.
+func (h *TransactionHandler) Approve(w http.ResponseWriter, r *http.Request) {
+    h.decide(w, r, h.service.Approve, false)
+}
+
+func (h *TransactionHandler) Reject(w http.ResponseWriter, r *http.Request) {
+    h.decide(w, r, h.service.Reject, true)
+}
+
+func (h *TransactionHandler) decide(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, id uint) (*models.Transaction, error), reasonRequired bool) {
+    id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+        return
+    }
+
+    var req ApprovalRequest
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            http.Error(w, "Invalid request body", http.StatusBadRequest)
+            return
+        }
+    }
+
+    if reasonRequired && req.Reason == "" {
+        http.Error(w, "reason is required", http.StatusBadRequest)
+        return
+    }
+
+    tx, err := decide(services.WithReason(r.Context(), req.Reason), uint(id))
+
+    if err != nil {
+        writeTransactionError(w, err)
+        return
+    }
+
+    writeTransaction(w, tx)
+}
+
+// writeTransaction returns a transaction, with 202 Accepted if it is held
+// for approval rather than applied.
+func writeTransaction(w http.ResponseWriter, tx *models.Transaction) {
     w.Header().Set("Content-Type", "application/json")
+    if tx.Status == models.TransactionStatusAwaitingApproval {
+        w.WriteHeader(http.StatusAccepted)
+    }
     json.NewEncoder(w).Encode(tx)
-} 
\ No newline at end of file
+}
+
+// writeTransactionError reports a refused or failed transaction. Broken
+// limits are returned as JSON naming the limit, so clients can tell them
+// apart from other failures; frozen and closed accounts and transactions
+// decided already are a conflict, and fraud and sanctions screening refusals
+// are forbidden. Insufficient funds cannot be processed and missing users,
+// accounts and quotes are not found.
+func writeTransactionError(w http.ResponseWriter, err error) {
+    var limitErr *models.LimitExceededError
+    if errors.As(err, &limitErr) {
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusUnprocessableEntity)
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "error":   models.ErrLimitExceeded.Error(),
+            "message": limitErr.Error(),
+            "details": limitErr,
+        })
+        return
+    }
+
+    if errors.Is(err, models.ErrInsufficientFunds) {
+        http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+        return
+    }
+
+    if errors.Is(err, services.ErrUserNotFound) || errors.Is(err, services.ErrAccountNotFound) ||
+        errors.Is(err, models.ErrQuoteNotFound) || errors.Is(err, repository.ErrNotFound) {
+        http.Error(w, err.Error(), http.StatusNotFound)
+        return
+    }
+
+    if errors.Is(err, models.ErrAccountFrozen) || errors.Is(err, models.ErrAccountClosed) ||
+        errors.Is(err, models.ErrNotAwaitingApproval) {
+        http.Error(w, err.Error(), http.StatusConflict)
+        return
+    }
+
+    if errors.Is(err, models.ErrSelfApproval) || errors.Is(err, models.ErrApproverUnknown) ||
+        errors.Is(err, models.ErrInitiatorUnknown) || errors.Is(err, models.ErrFraudBlocked) || errors.Is(err, models.ErrSanctionsMatch) {
+        http.Error(w, err.Error(), http.StatusForbidden)
+        return
+    }
+
+    http.Error(w, err.Error(), http.StatusInternalServerError)
+}
\ No newline at end of file
@@ -2,9 +2,13 @@ package handlers
 
 import (
     "encoding/json"
+    "errors"
     "net/http"
+    "strconv"
+    "strings"
+    "financial-service/internal/models"
     "financial-service/internal/services"
-    "github.com/rs/zerolog/log"
+    "github.com/go-chi/chi/v5"
 )
 
 type UserHandler struct {
@@ -32,6 +36,11 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
     user, err := h.userService.RegisterUser(r.Context(), req.Username, req.Email, req.Password)
 
+    if errors.Is(err, models.ErrSanctionsMatch) {
+        http.Error(w, err.Error(), http.StatusForbidden)
+        return
+    }
+
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -54,17 +63,68 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    log.Printf("Login request: %+v", req)
-
     token, err := h.userService.LoginUser(r.Context(), req.Email, req.Password)
 
-    log.Printf("Token: %+v", token)
-    
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(token)
-}
\ No newline at end of file
+    json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password"`
+    NewPassword     string `json:"new_password"`
+}
+
+// ChangePassword replaces the password of the user in the path.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+    userID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+    if err != nil {
+        http.Error(w, "Invalid user ID", http.StatusBadRequest)
+        return
+    }
+
+    var req ChangePasswordRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request body", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.userService.ChangePassword(r.Context(), uint(userID), req.CurrentPassword, req.NewPassword); err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// RequireAdmin lets a request through only if it carries the bearer token of
+// an admin user, issued at login, whom audit entries are then attributed to.
+func (h *UserHandler) RequireAdmin(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+        if !ok {
+            w.Header().Set("WWW-Authenticate", "Bearer")
+            http.Error(w, "Bearer token required", http.StatusUnauthorized)
+            return
+        }
+
+        admin, err := h.userService.UserFromToken(r.Context(), token)
+        if err != nil {
+            w.Header().Set("WWW-Authenticate", "Bearer")
+            http.Error(w, err.Error(), http.StatusUnauthorized)
+            return
+        }
+
+        if admin.Role != models.RoleAdmin {
+            http.Error(w, "Admin role required", http.StatusForbidden)
+            return
+        }
+
+        ctx := services.WithActorUser(r.Context(), admin)
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
@@ -9,8 +9,14 @@ import (
 
 func NewRouter(
     userHandler *handlers.UserHandler,
+    statementHandler *handlers.StatementHandler,
     txHandler *handlers.TransactionHandler,
+    bulkHandler *handlers.BulkTransferHandler,
     balanceHandler *handlers.BalanceHandler,
+    fxHandler *handlers.FXHandler,
+    scheduleHandler *handlers.ScheduledTransferHandler,
+    importHandler *handlers.ImportHandler,
+    accountHandler *handlers.AccountHandler,
 ) http.Handler {
     r := chi.NewRouter()
 
@@ -24,20 +30,76 @@ func NewRouter(
         r.Route("/users", func(r chi.Router) {
             r.Post("/register", userHandler.Register)
             r.Post("/login", userHandler.Login)
+            r.Post("/{id}/password", userHandler.ChangePassword)
+            r.Get("/{id}/statements", statementHandler.Get)
+            r.Get("/{id}/statements/{period}.pdf", statementHandler.GetMonthlyPDF)
+            r.Get("/{id}/accounts", accountHandler.ListByUser)
 
             // Add other user routes as needed
         })
 
+        // Account routes
+        r.Route("/accounts", func(r chi.Router) {
+            r.Post("/", accountHandler.Create)
+            r.Post("/transfer", accountHandler.Transfer)
+            r.Get("/{id}", accountHandler.Get)
+            r.Put("/{id}", accountHandler.Update)
+            r.Delete("/{id}", accountHandler.Delete)
+        })
+
+        // Admin routes, for users with the admin role
+        r.Route("/admin", func(r chi.Router) {
+            r.Use(userHandler.RequireAdmin)
+
+            r.Post("/accounts/{id}/freeze", accountHandler.Freeze)
+            r.Post("/accounts/{id}/unfreeze", accountHandler.Unfreeze)
+            r.Post("/accounts/{id}/close", accountHandler.Close)
+
+            r.Post("/credits", txHandler.ManualCredit)
+            r.Get("/approvals", txHandler.ListApprovals)
+            r.Post("/approvals/{id}/approve", txHandler.Approve)
+            r.Post("/approvals/{id}/reject", txHandler.Reject)
+        })
+
         // Transaction routes
         r.Route("/transactions", func(r chi.Router) {
-            r.Post("/credit", txHandler.Credit)
+            r.With(userHandler.RequireAdmin).Post("/credit", txHandler.Credit)
             r.Post("/debit", txHandler.Debit)
             r.Post("/transfer", txHandler.Transfer)
+            r.Post("/bulk", bulkHandler.Submit)
+            r.Get("/bulk/{batch_id}", bulkHandler.Get)
+        })
+
+        // Scheduled transfer routes
+        r.Route("/scheduled-transfers", func(r chi.Router) {
+            r.Post("/", scheduleHandler.Create)
+            r.Get("/", scheduleHandler.List)
+            r.Get("/{id}", scheduleHandler.Get)
+            r.Put("/{id}", scheduleHandler.Update)
+            r.Delete("/{id}", scheduleHandler.Cancel)
+            r.Get("/{id}/runs", scheduleHandler.ListRuns)
+        })
+
+        // Settlement file import routes
+        r.Route("/imports", func(r chi.Router) {
+            r.Post("/", importHandler.Create)
+            r.Post("/pain001", importHandler.CreatePain001)
+            r.Get("/{id}", importHandler.Get)
+        })
+
+        // FX routes
+        r.Route("/fx", func(r chi.Router) {
+            r.Post("/quotes", fxHandler.CreateQuote)
         })
 
         // Balance routes
         r.Route("/balance", func(r chi.Router) {
+            r.Get("/cache/stats", balanceHandler.GetCacheStats)
             r.Get("/{user_id}", balanceHandler.GetBalance)
+            r.Get("/{user_id}/history", balanceHandler.GetBalanceHistory)
+            r.Get("/{user_id}/history.ofx", statementHandler.GetOFX)
+            r.Get("/{user_id}/currencies", balanceHandler.GetUserBalances)
+            r.Get("/{user_id}/interest", balanceHandler.GetAccruedInterest)
         })
     })
 
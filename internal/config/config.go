@@ -7,6 +7,10 @@ import (
 )
 
 type Config struct {
+    // Storage backend: "mysql", "sqlite" or "memory"
+    StorageDriver string
+    SQLitePath    string
+
     // Database configuration
     DBUser           string
     DBPassword       string
@@ -16,13 +20,108 @@ type Config struct {
     DBMaxOpenConns   int
     DBMaxIdleConns   int
     DBConnMaxLifetime time.Duration
+    DBAutoMigrate    bool
 
     // Server configuration
     ServerPort string
+
+    // Login tokens are signed with JWTSecret and valid for JWTTTL. Without a
+    // secret, logins and admin routes are refused.
+    JWTSecret string
+    JWTTTL    time.Duration
+
+    // ISO 4217 currency used when a request does not name one
+    DefaultCurrency string
+
+    // FX rates come from the fx_rates table ("db") or a JSON file ("file")
+    FXRateSource string
+    FXRateFile   string
+    // Stored rates older than this are refused; zero accepts any age
+    FXRateMaxAge time.Duration
+    // Fraction taken off the mid rate, e.g. 0.005 for 0.5%
+    FXSpread   float64
+    FXQuoteTTL time.Duration
+
+    // Fee schedule JSON file; empty charges no fees. Fees are posted to the
+    // house account user.
+    FeeScheduleFile string
+    FeeHouseUserID  uint
+
+    // Frozen accounts always refuse debits; this makes them refuse credits
+    // too.
+    FrozenAccountsRejectCredits bool
+
+    // Default transaction limits JSON file; empty sets none, leaving only
+    // per-user overrides.
+    LimitsFile string
+
+    // Approval thresholds JSON file; transactions above a threshold are held
+    // until a second person approves them. Empty holds none. Credits, all
+    // being made by hand, are held regardless when ApproveManualCredits is
+    // set. Undecided
+    // approvals expire after ApprovalTTL and are swept every
+    // ApprovalExpiryInterval.
+    ApprovalThresholdsFile string
+    ApproveManualCredits   bool
+    ApprovalTTL            time.Duration
+    ApprovalExpiryInterval time.Duration
+
+    // Fraud rules JSON file; empty screens nothing. Transactions flagged for
+    // review are held with the approvals.
+    FraudRulesFile string
+
+    // Sanctions watchlist file (CSV or XML); empty screens nothing. Users are
+    // screened at registration and both parties before transactions. Names
+    // scoring at least WatchlistFlagScore against an entry are flagged, and
+    // blocked from WatchlistBlockScore.
+    WatchlistFile       string
+    WatchlistFlagScore  float64
+    WatchlistBlockScore float64
+
+    // Interest rates JSON file; empty accrues no interest. The accrual job
+    // checks for complete days every InterestJobInterval.
+    InterestRatesFile   string
+    InterestJobInterval time.Duration
+
+    // Most transfers accepted in one bulk transfer batch
+    BulkMaxItems int
+
+    // Most rows accepted in one imported file
+    ImportMaxRows int
+
+    // BANKID that OFX statement exports identify accounts with
+    OFXBankID string
+
+    // Monthly PDF statements are kept in StatementDir, which should be an
+    // absolute path; empty keeps none and disables the job. The job renders
+    // the previous month's every StatementJobInterval; zero disables it.
+    StatementDir         string
+    StatementJobInterval time.Duration
+
+    // Scheduled transfer job; a zero interval disables it. Occurrences that
+    // find insufficient funds are retried up to SchedulerMaxRetries times,
+    // SchedulerRetryInterval apart, when their schedule asks for retries.
+    SchedulerInterval      time.Duration
+    SchedulerMaxRetries    int
+    SchedulerRetryInterval time.Duration
+
+    // Balance cache
+    BalanceCacheSize int
+    BalanceCacheTTL  time.Duration
+
+    // Balance snapshot job; a zero interval disables it
+    SnapshotInterval time.Duration
+
+    // Reconciliation job; a zero interval disables it
+    ReconciliationInterval  time.Duration
+    ReconciliationReportDir string
 }
 
 func Load() *Config {
     return &Config{
+        StorageDriver: getEnv("STORAGE_DRIVER", "mysql"),
+        SQLitePath:    getEnv("SQLITE_PATH", "financial_service.db"),
+
         // Database configuration
         DBUser:     getEnv("DB_USER", "root"),
         DBPassword: getEnv("DB_PASSWORD", "password"),
@@ -35,8 +134,80 @@ func Load() *Config {
         DBMaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
         DBConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
 
+        // Apply pending migrations on server start
+        DBAutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", true),
+
         // Server configuration
         ServerPort: getEnv("SERVER_PORT", "8080"),
+
+        // Token configuration
+        JWTSecret: getEnv("JWT_SECRET", ""),
+        JWTTTL:    getEnvAsDuration("JWT_TTL", 12*time.Hour),
+
+        // Currency configuration
+        DefaultCurrency: getEnv("DEFAULT_CURRENCY", "USD"),
+
+        // FX configuration
+        FXRateSource: getEnv("FX_RATE_SOURCE", "db"),
+        FXRateFile:   getEnv("FX_RATE_FILE", "fx_rates.json"),
+        FXRateMaxAge: getEnvAsDuration("FX_RATE_MAX_AGE", 0),
+        FXSpread:     getEnvAsFloat("FX_SPREAD", 0.005),
+        FXQuoteTTL:   getEnvAsDuration("FX_QUOTE_TTL", 30*time.Second),
+
+        // Fee configuration
+        FeeScheduleFile: getEnv("FEE_SCHEDULE_FILE", ""),
+        FeeHouseUserID:  uint(getEnvAsInt("FEE_HOUSE_USER_ID", 0)),
+
+        // Account configuration
+        FrozenAccountsRejectCredits: getEnvAsBool("FROZEN_ACCOUNTS_REJECT_CREDITS", false),
+
+        // Limit configuration
+        LimitsFile: getEnv("LIMITS_FILE", ""),
+
+        // Approval configuration
+        ApprovalThresholdsFile: getEnv("APPROVAL_THRESHOLDS_FILE", ""),
+        ApproveManualCredits:   getEnvAsBool("APPROVE_MANUAL_CREDITS", true),
+        ApprovalTTL:            getEnvAsDuration("APPROVAL_TTL", 72*time.Hour),
+        ApprovalExpiryInterval: getEnvAsDuration("APPROVAL_EXPIRY_INTERVAL", time.Hour),
+
+        // Fraud screening configuration
+        FraudRulesFile: getEnv("FRAUD_RULES_FILE", ""),
+
+        // Sanctions screening configuration
+        WatchlistFile:       getEnv("WATCHLIST_FILE", ""),
+        WatchlistFlagScore:  getEnvAsFloat("WATCHLIST_FLAG_SCORE", 0.8),
+        WatchlistBlockScore: getEnvAsFloat("WATCHLIST_BLOCK_SCORE", 0.95),
+
+        // Interest configuration
+        InterestRatesFile:   getEnv("INTEREST_RATES_FILE", ""),
+        InterestJobInterval: getEnvAsDuration("INTEREST_JOB_INTERVAL", time.Hour),
+
+        // Bulk transfer configuration
+        BulkMaxItems: getEnvAsInt("BULK_MAX_ITEMS", 1000),
+
+        // Import configuration
+        ImportMaxRows: getEnvAsInt("IMPORT_MAX_ROWS", 10000),
+
+        // Statement configuration
+        OFXBankID:            getEnv("OFX_BANK_ID", "000000000"),
+        StatementDir:         getEnv("STATEMENT_DIR", ""),
+        StatementJobInterval: getEnvAsDuration("STATEMENT_JOB_INTERVAL", time.Hour),
+
+        // Scheduled transfer configuration
+        SchedulerInterval:      getEnvAsDuration("SCHEDULER_INTERVAL", time.Minute),
+        SchedulerMaxRetries:    getEnvAsInt("SCHEDULER_MAX_RETRIES", 3),
+        SchedulerRetryInterval: getEnvAsDuration("SCHEDULER_RETRY_INTERVAL", time.Hour),
+
+        // Balance cache configuration
+        BalanceCacheSize: getEnvAsInt("BALANCE_CACHE_SIZE", 10000),
+        BalanceCacheTTL:  getEnvAsDuration("BALANCE_CACHE_TTL", 30*time.Second),
+
+        // Balance snapshot job configuration
+        SnapshotInterval: getEnvAsDuration("SNAPSHOT_INTERVAL", time.Hour),
+
+        // Reconciliation job configuration
+        ReconciliationInterval:  getEnvAsDuration("RECONCILIATION_INTERVAL", 0),
+        ReconciliationReportDir: getEnv("RECONCILIATION_REPORT_DIR", ""),
     }
 }
 
@@ -56,6 +227,22 @@ func getEnvAsInt(key string, defaultValue int) int {
     return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+    valueStr := os.Getenv(key)
+    if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+        return value
+    }
+    return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+    valueStr := os.Getenv(key)
+    if value, err := strconv.ParseBool(valueStr); err == nil {
+        return value
+    }
+    return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
     valueStr := os.Getenv(key)
     if value, err := time.ParseDuration(valueStr); err == nil {
@@ -2,13 +2,9 @@ package db
 
 import (
     "database/sql"
-    "errors"
     "fmt"
     "financial-service/internal/config"
     _ "github.com/go-sql-driver/mysql"
-    "github.com/golang-migrate/migrate/v4"
-    "github.com/golang-migrate/migrate/v4/database/mysql"
-    _ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 func NewDB(cfg *config.Config) (*sql.DB, error) {
@@ -38,25 +34,14 @@ func NewDB(cfg *config.Config) (*sql.DB, error) {
     return db, nil
 }
 
-func MigrateDB(db *sql.DB, cfg *config.Config) error {
-    driver, err := mysql.WithInstance(db, &mysql.Config{})
-    if err != nil {
-        return fmt.Errorf("could not create migration driver: %w", err)
-    }
-
-    m, err := migrate.NewWithDatabaseInstance(
-        "file://internal/db/migrations",
-        "mysql", 
-        driver,
-    )
-    
-    if err != nil {
-        return fmt.Errorf("could not create migrate instance: %w", err)
+// Open connects to the SQL database selected by cfg.StorageDriver.
+func Open(cfg *config.Config) (*sql.DB, error) {
+    switch cfg.StorageDriver {
+        case "mysql":
+            return NewDB(cfg)
+        case "sqlite":
+            return NewSQLiteDB(cfg)
+        default:
+            return nil, fmt.Errorf("storage driver %q is not backed by a SQL database", cfg.StorageDriver)
     }
-
-    if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-        return fmt.Errorf("could not run migrations: %w", err)
-    }
-
-    return nil
-} 
\ No newline at end of file
+}
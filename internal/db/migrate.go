@@ -2,29 +2,66 @@ package db
 
 import (
     "database/sql"
+    "embed"
     "errors"
     "fmt"
     "financial-service/internal/config"
     "github.com/golang-migrate/migrate/v4"
+    "github.com/golang-migrate/migrate/v4/database"
     "github.com/golang-migrate/migrate/v4/database/mysql"
-    _ "github.com/golang-migrate/migrate/v4/source/file"
+    "github.com/golang-migrate/migrate/v4/database/sqlite3"
+    "github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-func RunMigrations(db *sql.DB, cfg *config.Config) error {
-    driver, err := mysql.WithInstance(db, &mysql.Config{})
-    
+//go:embed migrations/*.sql
+var mysqlMigrations embed.FS
+
+//go:embed migrations_sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// NewMigrator returns a migrate instance for the configured storage driver,
+// reading migrations embedded in the binary.
+func NewMigrator(db *sql.DB, cfg *config.Config) (*migrate.Migrate, error) {
+    var (
+        migrations embed.FS
+        dir        string
+        driver     database.Driver
+        err        error
+    )
+
+    switch cfg.StorageDriver {
+        case "mysql":
+            migrations, dir = mysqlMigrations, "migrations"
+            driver, err = mysql.WithInstance(db, &mysql.Config{})
+        case "sqlite":
+            migrations, dir = sqliteMigrations, "migrations_sqlite"
+            driver, err = sqlite3.WithInstance(db, &sqlite3.Config{})
+        default:
+            return nil, fmt.Errorf("storage driver %q has no migrations", cfg.StorageDriver)
+    }
+
     if err != nil {
-        return fmt.Errorf("could not create migration driver: %w", err)
+        return nil, fmt.Errorf("could not create migration driver: %w", err)
     }
 
-    m, err := migrate.NewWithDatabaseInstance(
-        "file://internal/db/migrations",
-        "mysql", 
-        driver,
-    )
+    source, err := iofs.New(migrations, dir)
+    if err != nil {
+        return nil, fmt.Errorf("could not read embedded migrations: %w", err)
+    }
+
+    m, err := migrate.NewWithInstance("iofs", source, cfg.StorageDriver, driver)
+    if err != nil {
+        return nil, fmt.Errorf("could not create migrate instance: %w", err)
+    }
+
+    return m, nil
+}
 
+// MigrateDB applies all pending migrations.
+func MigrateDB(db *sql.DB, cfg *config.Config) error {
+    m, err := NewMigrator(db, cfg)
     if err != nil {
-        return fmt.Errorf("could not create migrate instance: %w", err)
+        return err
     }
 
     if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
@@ -32,4 +69,4 @@ func RunMigrations(db *sql.DB, cfg *config.Config) error {
     }
 
     return nil
-} 
\ No newline at end of file
+}
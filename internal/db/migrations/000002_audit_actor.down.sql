@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs
+    DROP COLUMN reason,
+    DROP COLUMN actor;
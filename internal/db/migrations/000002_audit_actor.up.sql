@@ -0,0 +1,3 @@
+ALTER TABLE audit_logs
+    ADD COLUMN actor  VARCHAR(255)  NOT NULL DEFAULT '' AFTER changes,
+    ADD COLUMN reason VARCHAR(1024) NOT NULL DEFAULT '' AFTER actor;
@@ -0,0 +1,18 @@
+DELETE FROM balance_snapshots WHERE currency <> 'USD';
+DELETE FROM balances WHERE currency <> 'USD';
+
+ALTER TABLE balance_snapshots
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, as_of),
+    DROP COLUMN currency,
+    MODIFY amount DECIMAL(20,2) NOT NULL;
+
+ALTER TABLE transactions
+    DROP COLUMN currency,
+    MODIFY amount DECIMAL(20,2) NOT NULL;
+
+ALTER TABLE balances
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id),
+    DROP COLUMN currency,
+    MODIFY amount DECIMAL(20,2) NOT NULL DEFAULT 0.00;
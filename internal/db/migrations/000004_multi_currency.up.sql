@@ -0,0 +1,24 @@
+-- Balances and transactions created before multi-currency support are
+-- assumed to be in USD. Amounts get four decimal places so currencies with
+-- three minor-unit digits fit.
+ALTER TABLE balances
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER user_id,
+    MODIFY amount DECIMAL(20,4) NOT NULL DEFAULT 0,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, currency);
+
+ALTER TABLE balances ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE transactions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER amount,
+    MODIFY amount DECIMAL(20,4) NOT NULL;
+
+ALTER TABLE transactions ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE balance_snapshots
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD' AFTER user_id,
+    MODIFY amount DECIMAL(20,4) NOT NULL,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, currency, as_of);
+
+ALTER TABLE balance_snapshots ALTER COLUMN currency DROP DEFAULT;
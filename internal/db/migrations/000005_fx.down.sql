@@ -0,0 +1,9 @@
+ALTER TABLE transactions
+    DROP COLUMN quote_id,
+    DROP COLUMN fx_spread,
+    DROP COLUMN fx_rate,
+    DROP COLUMN to_currency,
+    DROP COLUMN to_amount;
+
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS fx_rates;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency  CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id             BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id        BIGINT UNSIGNED NOT NULL,
+    from_currency  CHAR(3) NOT NULL,
+    to_currency    CHAR(3) NOT NULL,
+    from_amount    DECIMAL(20,4) NOT NULL,
+    to_amount      DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    spread         DECIMAL(10,6) NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at     TIMESTAMP NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Conversions credit the recipient in to_currency; rate and spread are the
+-- ones quoted to the user.
+ALTER TABLE transactions
+    ADD COLUMN to_amount DECIMAL(20,4) NULL AFTER currency,
+    ADD COLUMN to_currency CHAR(3) NULL AFTER to_amount,
+    ADD COLUMN fx_rate DECIMAL(20,10) NULL AFTER to_currency,
+    ADD COLUMN fx_spread DECIMAL(10,6) NULL AFTER fx_rate,
+    ADD COLUMN quote_id BIGINT UNSIGNED NULL AFTER fx_spread;
@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+    DROP INDEX idx_parent,
+    DROP COLUMN description,
+    DROP COLUMN parent_id;
+
+ALTER TABLE users DROP COLUMN tier;
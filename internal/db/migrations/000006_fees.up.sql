@@ -0,0 +1,10 @@
+-- Fee schedules are chosen by user tier.
+ALTER TABLE users
+    ADD COLUMN tier VARCHAR(50) NOT NULL DEFAULT 'standard' AFTER role;
+
+-- Fees are posted as their own transactions pointing at the transaction they
+-- were charged on; description names the fee rule.
+ALTER TABLE transactions
+    ADD COLUMN parent_id BIGINT UNSIGNED NULL AFTER quote_id,
+    ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '' AFTER parent_id,
+    ADD INDEX idx_parent (parent_id);
@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP INDEX idx_to_created;
+ALTER TABLE transactions DROP INDEX idx_from_created;
+
+DROP TABLE IF EXISTS user_limits;
@@ -0,0 +1,19 @@
+-- Per-user overrides of the default transaction limits. An empty type or
+-- currency applies to every type or currency; zero leaves a limit unset.
+CREATE TABLE IF NOT EXISTS user_limits (
+    user_id         BIGINT UNSIGNED NOT NULL,
+    type            VARCHAR(50) NOT NULL DEFAULT '',
+    currency        VARCHAR(3) NOT NULL DEFAULT '',
+    per_transaction DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_amount    DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_count     INT NOT NULL DEFAULT 0,
+    monthly_amount  DECIMAL(20,4) NOT NULL DEFAULT 0,
+    monthly_count   INT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type, currency),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Limits sum a user's recent transactions.
+ALTER TABLE transactions ADD INDEX idx_from_created (from_user_id, created_at);
+ALTER TABLE transactions ADD INDEX idx_to_created (to_user_id, created_at);
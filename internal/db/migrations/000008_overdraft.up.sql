@@ -0,0 +1,3 @@
+-- A credit limit lets a balance go negative down to -credit_limit.
+ALTER TABLE balances
+    ADD COLUMN credit_limit DECIMAL(20,4) NOT NULL DEFAULT 0 AFTER amount;
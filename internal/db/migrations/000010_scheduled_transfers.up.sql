@@ -0,0 +1,37 @@
+-- Standing orders. next_run_at is the next occurrence and due_at when the
+-- scheduler next tries it, which is later while a retry is pending.
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                    BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    from_user_id          BIGINT UNSIGNED NOT NULL,
+    to_user_id            BIGINT UNSIGNED NOT NULL,
+    amount                DECIMAL(20,4) NOT NULL,
+    currency              CHAR(3) NOT NULL,
+    frequency             VARCHAR(10) NOT NULL,
+    start_at              TIMESTAMP NOT NULL,
+    end_date              TIMESTAMP NULL,
+    next_run_at           TIMESTAMP NOT NULL,
+    due_at                TIMESTAMP NOT NULL,
+    on_insufficient_funds VARCHAR(10) NOT NULL,
+    status                VARCHAR(20) NOT NULL,
+    created_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_status_due (status, due_at),
+    INDEX idx_from_user (from_user_id),
+    INDEX idx_to_user (to_user_id),
+    FOREIGN KEY (from_user_id) REFERENCES users(id),
+    FOREIGN KEY (to_user_id) REFERENCES users(id)
+);
+
+-- One row per executed occurrence; the primary key keeps an occurrence from
+-- running twice.
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    schedule_id    BIGINT UNSIGNED NOT NULL,
+    occurrence     TIMESTAMP NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    attempts       INT NOT NULL DEFAULT 0,
+    transaction_id BIGINT UNSIGNED NULL,
+    error          VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (schedule_id, occurrence),
+    FOREIGN KEY (schedule_id) REFERENCES scheduled_transfers(id)
+);
@@ -0,0 +1,28 @@
+-- Bulk transfer batches, unique per client batch ID.
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id              BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    client_batch_id VARCHAR(100) NOT NULL UNIQUE,
+    mode            VARCHAR(20) NOT NULL,
+    status          VARCHAR(30) NOT NULL,
+    item_count      INT NOT NULL,
+    succeeded_count INT NOT NULL DEFAULT 0,
+    failed_count    INT NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at    TIMESTAMP NULL
+);
+
+-- The transfers of a batch as submitted, with their outcome. Users are not
+-- foreign keys so that transfers naming unknown users can be reported.
+CREATE TABLE IF NOT EXISTS transfer_batch_items (
+    batch_id       BIGINT UNSIGNED NOT NULL,
+    item_index     INT NOT NULL,
+    from_user_id   BIGINT UNSIGNED NOT NULL,
+    to_user_id     BIGINT UNSIGNED NOT NULL,
+    amount         DECIMAL(20,4) NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    error          VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (batch_id, item_index),
+    FOREIGN KEY (batch_id) REFERENCES transfer_batches(id)
+);
@@ -0,0 +1,41 @@
+-- Only each user's default account in a currency fits the old keys; the
+-- others are dropped.
+CREATE TEMPORARY TABLE default_accounts AS
+SELECT MIN(id) AS id FROM accounts GROUP BY user_id, currency;
+
+DELETE FROM interest_accruals WHERE account_id NOT IN (SELECT id FROM default_accounts);
+DELETE FROM balance_snapshots WHERE account_id NOT IN (SELECT id FROM default_accounts);
+DELETE FROM balances WHERE account_id NOT IN (SELECT id FROM default_accounts);
+
+DROP TEMPORARY TABLE default_accounts;
+
+ALTER TABLE interest_accruals
+    DROP FOREIGN KEY fk_interest_accruals_account,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, currency, accrual_date),
+    DROP COLUMN account_id,
+    DROP INDEX idx_user_currency;
+
+ALTER TABLE balance_snapshots
+    DROP FOREIGN KEY fk_balance_snapshots_account,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, currency, as_of),
+    DROP COLUMN account_id,
+    DROP INDEX idx_user_currency;
+
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_from_account,
+    DROP FOREIGN KEY fk_transactions_to_account,
+    DROP INDEX idx_from_account,
+    DROP INDEX idx_to_account,
+    DROP COLUMN from_account_id,
+    DROP COLUMN to_account_id;
+
+ALTER TABLE balances
+    DROP FOREIGN KEY fk_balances_account,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (user_id, currency),
+    DROP COLUMN account_id,
+    DROP INDEX idx_user_currency;
+
+DROP TABLE IF EXISTS accounts;
@@ -0,0 +1,76 @@
+-- Balances move into accounts. Every existing balance becomes its owner's
+-- default checking account in its currency, and the transactions, snapshots
+-- and interest accruals of that balance are pointed at it.
+CREATE TABLE IF NOT EXISTS accounts (
+    id         BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT UNSIGNED NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    status     VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user_currency (user_id, currency)
+);
+
+INSERT INTO accounts (user_id, type, currency, status, created_at, updated_at)
+SELECT user_id, 'checking', currency, 'active', last_updated_at, last_updated_at
+FROM balances
+ORDER BY user_id, currency;
+
+-- The user foreign keys keep an index once the primary keys change
+ALTER TABLE balances ADD INDEX idx_user_currency (user_id, currency);
+ALTER TABLE balance_snapshots ADD INDEX idx_user_currency (user_id, currency);
+ALTER TABLE interest_accruals ADD INDEX idx_user_currency (user_id, currency);
+
+ALTER TABLE balances ADD COLUMN account_id BIGINT UNSIGNED NULL FIRST;
+
+UPDATE balances b
+JOIN accounts a ON a.user_id = b.user_id AND a.currency = b.currency
+SET b.account_id = a.id, b.last_updated_at = b.last_updated_at;
+
+ALTER TABLE balances
+    MODIFY account_id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (account_id),
+    ADD CONSTRAINT fk_balances_account FOREIGN KEY (account_id) REFERENCES accounts(id);
+
+ALTER TABLE transactions
+    ADD COLUMN from_account_id BIGINT UNSIGNED NULL AFTER to_user_id,
+    ADD COLUMN to_account_id BIGINT UNSIGNED NULL AFTER from_account_id,
+    ADD INDEX idx_from_account (from_account_id),
+    ADD INDEX idx_to_account (to_account_id),
+    ADD CONSTRAINT fk_transactions_from_account FOREIGN KEY (from_account_id) REFERENCES accounts(id),
+    ADD CONSTRAINT fk_transactions_to_account FOREIGN KEY (to_account_id) REFERENCES accounts(id);
+
+UPDATE transactions t
+JOIN accounts a ON a.user_id = t.from_user_id AND a.currency = t.currency
+SET t.from_account_id = a.id;
+
+UPDATE transactions t
+JOIN accounts a ON a.user_id = t.to_user_id AND a.currency = COALESCE(t.to_currency, t.currency)
+SET t.to_account_id = a.id;
+
+ALTER TABLE balance_snapshots ADD COLUMN account_id BIGINT UNSIGNED NULL FIRST;
+
+UPDATE balance_snapshots s
+JOIN accounts a ON a.user_id = s.user_id AND a.currency = s.currency
+SET s.account_id = a.id;
+
+ALTER TABLE balance_snapshots
+    MODIFY account_id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (account_id, as_of),
+    ADD CONSTRAINT fk_balance_snapshots_account FOREIGN KEY (account_id) REFERENCES accounts(id);
+
+ALTER TABLE interest_accruals ADD COLUMN account_id BIGINT UNSIGNED NULL FIRST;
+
+UPDATE interest_accruals i
+JOIN accounts a ON a.user_id = i.user_id AND a.currency = i.currency
+SET i.account_id = a.id;
+
+ALTER TABLE interest_accruals
+    MODIFY account_id BIGINT UNSIGNED NOT NULL,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (account_id, accrual_date),
+    ADD CONSTRAINT fk_interest_accruals_account FOREIGN KEY (account_id) REFERENCES accounts(id);
@@ -0,0 +1,3 @@
+ALTER TABLE transaction_approvals DROP COLUMN kind;
+
+ALTER TABLE users DROP COLUMN password_changed_at;
@@ -0,0 +1,3 @@
+ALTER TABLE transaction_approvals
+    DROP COLUMN decided_by_id,
+    DROP COLUMN initiated_by_id;
@@ -0,0 +1,5 @@
+-- Approvals are keyed on the user IDs of who initiated and decided them, so
+-- nobody can approve their own transaction under another name.
+ALTER TABLE transaction_approvals
+    ADD COLUMN initiated_by_id BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER initiated_by,
+    ADD COLUMN decided_by_id BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER decided_by;
@@ -0,0 +1,3 @@
+ALTER TABLE transactions
+    DROP INDEX idx_booked_at,
+    DROP COLUMN booked_at;
@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_transfer_runs
+    DROP INDEX idx_transaction_id;
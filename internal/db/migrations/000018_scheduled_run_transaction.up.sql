@@ -0,0 +1,3 @@
+-- Deciding a held transaction resolves the scheduled run that made it.
+ALTER TABLE scheduled_transfer_runs
+    ADD INDEX idx_transaction_id (transaction_id);
@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs DROP COLUMN reason;
+ALTER TABLE audit_logs DROP COLUMN actor;
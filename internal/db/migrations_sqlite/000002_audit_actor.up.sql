@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs ADD COLUMN actor VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE audit_logs ADD COLUMN reason VARCHAR(1024) NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    user_id    INTEGER NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, as_of),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
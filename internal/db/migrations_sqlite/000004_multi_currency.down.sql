@@ -0,0 +1,29 @@
+CREATE TABLE balance_snapshots_old (
+    user_id    INTEGER NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, as_of),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balance_snapshots_old (user_id, as_of, amount, created_at)
+SELECT user_id, as_of, amount, created_at FROM balance_snapshots WHERE currency = 'USD';
+
+DROP TABLE balance_snapshots;
+ALTER TABLE balance_snapshots_old RENAME TO balance_snapshots;
+
+ALTER TABLE transactions DROP COLUMN currency;
+
+CREATE TABLE balances_old (
+    user_id         INTEGER PRIMARY KEY,
+    amount          DECIMAL(20,2) NOT NULL DEFAULT 0.00,
+    last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balances_old (user_id, amount, last_updated_at)
+SELECT user_id, amount, last_updated_at FROM balances WHERE currency = 'USD';
+
+DROP TABLE balances;
+ALTER TABLE balances_old RENAME TO balances;
@@ -0,0 +1,35 @@
+-- Balances and transactions created before multi-currency support are
+-- assumed to be in USD. SQLite cannot change a primary key in place, so the
+-- keyed tables are rebuilt.
+CREATE TABLE balances_new (
+    user_id         INTEGER NOT NULL,
+    currency        CHAR(3) NOT NULL,
+    amount          DECIMAL(20,4) NOT NULL DEFAULT 0,
+    last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balances_new (user_id, currency, amount, last_updated_at)
+SELECT user_id, 'USD', amount, last_updated_at FROM balances;
+
+DROP TABLE balances;
+ALTER TABLE balances_new RENAME TO balances;
+
+ALTER TABLE transactions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE balance_snapshots_new (
+    user_id    INTEGER NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency, as_of),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balance_snapshots_new (user_id, currency, as_of, amount, created_at)
+SELECT user_id, 'USD', as_of, amount, created_at FROM balance_snapshots;
+
+DROP TABLE balance_snapshots;
+ALTER TABLE balance_snapshots_new RENAME TO balance_snapshots;
@@ -0,0 +1,8 @@
+ALTER TABLE transactions DROP COLUMN quote_id;
+ALTER TABLE transactions DROP COLUMN fx_spread;
+ALTER TABLE transactions DROP COLUMN fx_rate;
+ALTER TABLE transactions DROP COLUMN to_currency;
+ALTER TABLE transactions DROP COLUMN to_amount;
+
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS fx_rates;
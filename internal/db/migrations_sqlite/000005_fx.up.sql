@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency  CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id        INTEGER NOT NULL,
+    from_currency  CHAR(3) NOT NULL,
+    to_currency    CHAR(3) NOT NULL,
+    from_amount    DECIMAL(20,4) NOT NULL,
+    to_amount      DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    spread         DECIMAL(10,6) NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at     TIMESTAMP NOT NULL,
+    transaction_id INTEGER NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Conversions credit the recipient in to_currency; rate and spread are the
+-- ones quoted to the user.
+ALTER TABLE transactions ADD COLUMN to_amount DECIMAL(20,4) NULL;
+ALTER TABLE transactions ADD COLUMN to_currency CHAR(3) NULL;
+ALTER TABLE transactions ADD COLUMN fx_rate DECIMAL(20,10) NULL;
+ALTER TABLE transactions ADD COLUMN fx_spread DECIMAL(10,6) NULL;
+ALTER TABLE transactions ADD COLUMN quote_id INTEGER NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_parent;
+
+ALTER TABLE transactions DROP COLUMN description;
+ALTER TABLE transactions DROP COLUMN parent_id;
+
+ALTER TABLE users DROP COLUMN tier;
@@ -0,0 +1,9 @@
+-- Fee schedules are chosen by user tier.
+ALTER TABLE users ADD COLUMN tier VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+-- Fees are posted as their own transactions pointing at the transaction they
+-- were charged on; description names the fee rule.
+ALTER TABLE transactions ADD COLUMN parent_id INTEGER NULL;
+ALTER TABLE transactions ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_parent ON transactions (parent_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_to_created;
+DROP INDEX IF EXISTS idx_from_created;
+
+DROP TABLE IF EXISTS user_limits;
@@ -0,0 +1,19 @@
+-- Per-user overrides of the default transaction limits. An empty type or
+-- currency applies to every type or currency; zero leaves a limit unset.
+CREATE TABLE IF NOT EXISTS user_limits (
+    user_id         INTEGER NOT NULL,
+    type            VARCHAR(50) NOT NULL DEFAULT '',
+    currency        VARCHAR(3) NOT NULL DEFAULT '',
+    per_transaction DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_amount    DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_count     INTEGER NOT NULL DEFAULT 0,
+    monthly_amount  DECIMAL(20,4) NOT NULL DEFAULT 0,
+    monthly_count   INTEGER NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type, currency),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Limits sum a user's recent transactions.
+CREATE INDEX IF NOT EXISTS idx_from_created ON transactions (from_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_to_created ON transactions (to_user_id, created_at);
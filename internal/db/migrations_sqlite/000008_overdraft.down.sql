@@ -0,0 +1 @@
+ALTER TABLE balances DROP COLUMN credit_limit;
@@ -0,0 +1,21 @@
+-- Interest earned or charged on each day's closing balance. amount keeps
+-- the unrounded accrual; transaction_id is set once it has been paid out.
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    user_id        INTEGER NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    accrual_date   TIMESTAMP NOT NULL,
+    balance        DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(10,6) NOT NULL,
+    day_count      VARCHAR(10) NOT NULL,
+    amount         DECIMAL(30,10) NOT NULL,
+    transaction_id INTEGER NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency, accrual_date),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- Days the accrual job has finished for every balance.
+CREATE TABLE IF NOT EXISTS interest_runs (
+    accrual_date TIMESTAMP NOT NULL PRIMARY KEY,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
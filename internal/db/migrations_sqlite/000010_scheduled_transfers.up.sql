@@ -0,0 +1,38 @@
+-- Standing orders. next_run_at is the next occurrence and due_at when the
+-- scheduler next tries it, which is later while a retry is pending.
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+    from_user_id          INTEGER NOT NULL,
+    to_user_id            INTEGER NOT NULL,
+    amount                DECIMAL(20,4) NOT NULL,
+    currency              CHAR(3) NOT NULL,
+    frequency             VARCHAR(10) NOT NULL,
+    start_at              TIMESTAMP NOT NULL,
+    end_date              TIMESTAMP NULL,
+    next_run_at           TIMESTAMP NOT NULL,
+    due_at                TIMESTAMP NOT NULL,
+    on_insufficient_funds VARCHAR(10) NOT NULL,
+    status                VARCHAR(20) NOT NULL,
+    created_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (from_user_id) REFERENCES users(id),
+    FOREIGN KEY (to_user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_status_due ON scheduled_transfers (status, due_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_from_user ON scheduled_transfers (from_user_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_to_user ON scheduled_transfers (to_user_id);
+
+-- One row per executed occurrence; the primary key keeps an occurrence from
+-- running twice.
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    schedule_id    INTEGER NOT NULL,
+    occurrence     TIMESTAMP NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    transaction_id INTEGER NULL,
+    error          VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (schedule_id, occurrence),
+    FOREIGN KEY (schedule_id) REFERENCES scheduled_transfers(id)
+);
@@ -0,0 +1,30 @@
+-- Imported files, unique per SHA-256 of their content.
+CREATE TABLE IF NOT EXISTS imports (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_hash      CHAR(64) NOT NULL UNIQUE,
+    filename       VARCHAR(255) NOT NULL DEFAULT '',
+    status         VARCHAR(30) NOT NULL,
+    row_count      INTEGER NOT NULL,
+    executed_count INTEGER NOT NULL DEFAULT 0,
+    failed_count   INTEGER NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at   TIMESTAMP NULL
+);
+
+-- The rows of an imported file with their outcome. Users are not foreign
+-- keys so that rows naming unknown users can be reported.
+CREATE TABLE IF NOT EXISTS import_rows (
+    import_id      INTEGER NOT NULL,
+    line           INTEGER NOT NULL,
+    type           VARCHAR(20) NOT NULL,
+    from_user_id   INTEGER NOT NULL DEFAULT 0,
+    to_user_id     INTEGER NOT NULL DEFAULT 0,
+    amount         DECIMAL(20,4) NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    reference      VARCHAR(255) NOT NULL DEFAULT '',
+    status         VARCHAR(20) NOT NULL,
+    transaction_id INTEGER NULL,
+    error          VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (import_id, line),
+    FOREIGN KEY (import_id) REFERENCES imports(id)
+);
@@ -0,0 +1,67 @@
+-- Only each user's default account in a currency fits the old keys; the
+-- others are dropped.
+CREATE TABLE interest_accruals_old (
+    user_id        INTEGER NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    accrual_date   TIMESTAMP NOT NULL,
+    balance        DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(10,6) NOT NULL,
+    day_count      VARCHAR(10) NOT NULL,
+    amount         DECIMAL(30,10) NOT NULL,
+    transaction_id INTEGER NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency, accrual_date),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO interest_accruals_old
+(user_id, currency, accrual_date, balance, rate, day_count, amount, transaction_id, created_at)
+SELECT user_id, currency, accrual_date, balance, rate, day_count, amount, transaction_id, created_at
+FROM interest_accruals
+WHERE account_id IN (SELECT MIN(id) FROM accounts GROUP BY user_id, currency);
+
+DROP TABLE interest_accruals;
+ALTER TABLE interest_accruals_old RENAME TO interest_accruals;
+
+CREATE TABLE balance_snapshots_old (
+    user_id    INTEGER NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency, as_of),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balance_snapshots_old (user_id, currency, as_of, amount, created_at)
+SELECT user_id, currency, as_of, amount, created_at
+FROM balance_snapshots
+WHERE account_id IN (SELECT MIN(id) FROM accounts GROUP BY user_id, currency);
+
+DROP TABLE balance_snapshots;
+ALTER TABLE balance_snapshots_old RENAME TO balance_snapshots;
+
+DROP INDEX IF EXISTS idx_from_account;
+DROP INDEX IF EXISTS idx_to_account;
+ALTER TABLE transactions DROP COLUMN from_account_id;
+ALTER TABLE transactions DROP COLUMN to_account_id;
+
+CREATE TABLE balances_old (
+    user_id         INTEGER NOT NULL,
+    currency        CHAR(3) NOT NULL,
+    amount          DECIMAL(20,4) NOT NULL DEFAULT 0,
+    credit_limit    DECIMAL(20,4) NOT NULL DEFAULT 0,
+    last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, currency),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balances_old (user_id, currency, amount, credit_limit, last_updated_at)
+SELECT user_id, currency, amount, credit_limit, last_updated_at
+FROM balances
+WHERE account_id IN (SELECT MIN(id) FROM accounts GROUP BY user_id, currency);
+
+DROP TABLE balances;
+ALTER TABLE balances_old RENAME TO balances;
+
+DROP TABLE IF EXISTS accounts;
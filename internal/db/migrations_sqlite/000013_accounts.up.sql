@@ -0,0 +1,104 @@
+-- Balances move into accounts. Every existing balance becomes its owner's
+-- default checking account in its currency, and the transactions, snapshots
+-- and interest accruals of that balance are pointed at it. SQLite cannot
+-- change a primary key in place, so the keyed tables are rebuilt.
+CREATE TABLE IF NOT EXISTS accounts (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    status     VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_user_currency ON accounts (user_id, currency);
+
+INSERT INTO accounts (user_id, type, currency, status, created_at, updated_at)
+SELECT user_id, 'checking', currency, 'active', last_updated_at, last_updated_at
+FROM balances
+ORDER BY user_id, currency;
+
+CREATE TABLE balances_new (
+    account_id      INTEGER NOT NULL PRIMARY KEY,
+    user_id         INTEGER NOT NULL,
+    currency        CHAR(3) NOT NULL,
+    amount          DECIMAL(20,4) NOT NULL DEFAULT 0,
+    credit_limit    DECIMAL(20,4) NOT NULL DEFAULT 0,
+    last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balances_new (account_id, user_id, currency, amount, credit_limit, last_updated_at)
+SELECT a.id, b.user_id, b.currency, b.amount, b.credit_limit, b.last_updated_at
+FROM balances b
+JOIN accounts a ON a.user_id = b.user_id AND a.currency = b.currency;
+
+DROP TABLE balances;
+ALTER TABLE balances_new RENAME TO balances;
+
+CREATE INDEX IF NOT EXISTS idx_balances_user_currency ON balances (user_id, currency);
+
+ALTER TABLE transactions ADD COLUMN from_account_id INTEGER NULL REFERENCES accounts(id);
+ALTER TABLE transactions ADD COLUMN to_account_id INTEGER NULL REFERENCES accounts(id);
+
+UPDATE transactions SET from_account_id = (
+    SELECT a.id FROM accounts a
+    WHERE a.user_id = transactions.from_user_id AND a.currency = transactions.currency
+);
+
+UPDATE transactions SET to_account_id = (
+    SELECT a.id FROM accounts a
+    WHERE a.user_id = transactions.to_user_id
+        AND a.currency = COALESCE(transactions.to_currency, transactions.currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_from_account ON transactions (from_account_id);
+CREATE INDEX IF NOT EXISTS idx_to_account ON transactions (to_account_id);
+
+CREATE TABLE balance_snapshots_new (
+    account_id INTEGER NOT NULL,
+    user_id    INTEGER NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, as_of),
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO balance_snapshots_new (account_id, user_id, currency, as_of, amount, created_at)
+SELECT a.id, s.user_id, s.currency, s.as_of, s.amount, s.created_at
+FROM balance_snapshots s
+JOIN accounts a ON a.user_id = s.user_id AND a.currency = s.currency;
+
+DROP TABLE balance_snapshots;
+ALTER TABLE balance_snapshots_new RENAME TO balance_snapshots;
+
+CREATE TABLE interest_accruals_new (
+    account_id     INTEGER NOT NULL,
+    user_id        INTEGER NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    accrual_date   TIMESTAMP NOT NULL,
+    balance        DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(10,6) NOT NULL,
+    day_count      VARCHAR(10) NOT NULL,
+    amount         DECIMAL(30,10) NOT NULL,
+    transaction_id INTEGER NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, accrual_date),
+    FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+INSERT INTO interest_accruals_new
+(account_id, user_id, currency, accrual_date, balance, rate, day_count, amount, transaction_id, created_at)
+SELECT a.id, i.user_id, i.currency, i.accrual_date, i.balance, i.rate, i.day_count, i.amount, i.transaction_id, i.created_at
+FROM interest_accruals i
+JOIN accounts a ON a.user_id = i.user_id AND a.currency = i.currency;
+
+DROP TABLE interest_accruals;
+ALTER TABLE interest_accruals_new RENAME TO interest_accruals;
@@ -0,0 +1,16 @@
+-- Transactions held for a second person's approval (maker-checker). The
+-- transaction is stored with status awaiting_approval until it is decided.
+CREATE TABLE IF NOT EXISTS transaction_approvals (
+    transaction_id INTEGER PRIMARY KEY,
+    status         VARCHAR(20) NOT NULL,
+    initiated_by   VARCHAR(255) NOT NULL DEFAULT '',
+    reason         VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at     TIMESTAMP NOT NULL,
+    decided_by     VARCHAR(255) NOT NULL DEFAULT '',
+    decided_at     TIMESTAMP NULL,
+    note           VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_approvals_status_expires ON transaction_approvals (status, expires_at);
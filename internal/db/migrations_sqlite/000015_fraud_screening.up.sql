@@ -0,0 +1,6 @@
+-- Fraud rules can look at how recently a user changed their password.
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMP NULL;
+
+-- Transactions flagged by fraud screening are held for review alongside
+-- those awaiting a second person's approval.
+ALTER TABLE transaction_approvals ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'approval';
@@ -0,0 +1,2 @@
+ALTER TABLE transaction_approvals DROP COLUMN decided_by_id;
+ALTER TABLE transaction_approvals DROP COLUMN initiated_by_id;
@@ -0,0 +1,4 @@
+-- Approvals are keyed on the user IDs of who initiated and decided them, so
+-- nobody can approve their own transaction under another name.
+ALTER TABLE transaction_approvals ADD COLUMN initiated_by_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE transaction_approvals ADD COLUMN decided_by_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_booked_at;
+ALTER TABLE transactions DROP COLUMN booked_at;
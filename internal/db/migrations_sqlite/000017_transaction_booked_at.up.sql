@@ -0,0 +1,7 @@
+-- Point-in-time balances replay transactions by when they were booked, which
+-- for one held for approval is its approval. Transactions completed so far
+-- were booked when they were created.
+ALTER TABLE transactions ADD COLUMN booked_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_booked_at ON transactions (booked_at);
+
+UPDATE transactions SET booked_at = created_at WHERE status = 'completed';
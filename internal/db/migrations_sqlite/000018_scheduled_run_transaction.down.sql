@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_scheduled_run_transaction;
@@ -0,0 +1,2 @@
+-- Deciding a held transaction resolves the scheduled run that made it.
+CREATE INDEX IF NOT EXISTS idx_scheduled_run_transaction ON scheduled_transfer_runs (transaction_id);
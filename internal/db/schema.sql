@@ -4,30 +4,69 @@ CREATE TABLE IF NOT EXISTS users (
     email         VARCHAR(255) NOT NULL UNIQUE,
     password_hash VARCHAR(255) NOT NULL,
     role          VARCHAR(50) NOT NULL DEFAULT 'user',
+    tier          VARCHAR(50) NOT NULL DEFAULT 'standard',
+    password_changed_at TIMESTAMP NULL,
     created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_email (email)
 );
 
+CREATE TABLE IF NOT EXISTS accounts (
+    id         BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT UNSIGNED NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    status     VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user_currency (user_id, currency)
+);
+
 CREATE TABLE IF NOT EXISTS balances (
-    user_id         BIGINT UNSIGNED PRIMARY KEY,
-    amount          DECIMAL(20,2) NOT NULL DEFAULT 0.00,
+    account_id      BIGINT UNSIGNED NOT NULL,
+    user_id         BIGINT UNSIGNED NOT NULL,
+    currency        CHAR(3) NOT NULL,
+    amount          DECIMAL(20,4) NOT NULL DEFAULT 0,
+    credit_limit    DECIMAL(20,4) NOT NULL DEFAULT 0,
     last_updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id)
+    PRIMARY KEY (account_id),
+    CONSTRAINT fk_balances_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user_currency (user_id, currency)
 );
 
 CREATE TABLE IF NOT EXISTS transactions (
     id           BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     from_user_id BIGINT UNSIGNED,
     to_user_id   BIGINT UNSIGNED,
-    amount       DECIMAL(20,2) NOT NULL,
+    from_account_id BIGINT UNSIGNED NULL,
+    to_account_id   BIGINT UNSIGNED NULL,
+    amount       DECIMAL(20,4) NOT NULL,
+    currency     CHAR(3) NOT NULL,
+    to_amount    DECIMAL(20,4) NULL,
+    to_currency  CHAR(3) NULL,
+    fx_rate      DECIMAL(20,10) NULL,
+    fx_spread    DECIMAL(10,6) NULL,
+    quote_id     BIGINT UNSIGNED NULL,
+    parent_id    BIGINT UNSIGNED NULL,
+    description  VARCHAR(255) NOT NULL DEFAULT '',
     type         VARCHAR(50) NOT NULL,
     status       VARCHAR(50) NOT NULL,
     created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    booked_at    TIMESTAMP NULL DEFAULT NULL,
     FOREIGN KEY (from_user_id) REFERENCES users(id),
     FOREIGN KEY (to_user_id) REFERENCES users(id),
+    CONSTRAINT fk_transactions_from_account FOREIGN KEY (from_account_id) REFERENCES accounts(id),
+    CONSTRAINT fk_transactions_to_account FOREIGN KEY (to_account_id) REFERENCES accounts(id),
     INDEX idx_users (from_user_id, to_user_id),
-    INDEX idx_created_at (created_at)
+    INDEX idx_created_at (created_at),
+    INDEX idx_parent (parent_id),
+    INDEX idx_from_created (from_user_id, created_at),
+    INDEX idx_to_created (to_user_id, created_at),
+    INDEX idx_from_account (from_account_id),
+    INDEX idx_to_account (to_account_id),
+    INDEX idx_booked_at (booked_at)
 );
 
 CREATE TABLE IF NOT EXISTS audit_logs (
@@ -36,6 +75,192 @@ CREATE TABLE IF NOT EXISTS audit_logs (
     entity_id   BIGINT UNSIGNED NOT NULL,
     action      VARCHAR(50) NOT NULL,
     changes     TEXT,
+    actor       VARCHAR(255) NOT NULL DEFAULT '',
+    reason      VARCHAR(1024) NOT NULL DEFAULT '',
     created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     INDEX idx_entity (entity_type, entity_id)
-); 
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    account_id BIGINT UNSIGNED NOT NULL,
+    user_id    BIGINT UNSIGNED NOT NULL,
+    currency   CHAR(3) NOT NULL,
+    as_of      TIMESTAMP NOT NULL,
+    amount     DECIMAL(20,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, as_of),
+    CONSTRAINT fk_balance_snapshots_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user_currency (user_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency  CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id             BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id        BIGINT UNSIGNED NOT NULL,
+    from_currency  CHAR(3) NOT NULL,
+    to_currency    CHAR(3) NOT NULL,
+    from_amount    DECIMAL(20,4) NOT NULL,
+    to_amount      DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(20,10) NOT NULL,
+    spread         DECIMAL(10,6) NOT NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at     TIMESTAMP NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS user_limits (
+    user_id         BIGINT UNSIGNED NOT NULL,
+    type            VARCHAR(50) NOT NULL DEFAULT '',
+    currency        VARCHAR(3) NOT NULL DEFAULT '',
+    per_transaction DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_amount    DECIMAL(20,4) NOT NULL DEFAULT 0,
+    daily_count     INT NOT NULL DEFAULT 0,
+    monthly_amount  DECIMAL(20,4) NOT NULL DEFAULT 0,
+    monthly_count   INT NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type, currency),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    account_id     BIGINT UNSIGNED NOT NULL,
+    user_id        BIGINT UNSIGNED NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    accrual_date   TIMESTAMP NOT NULL,
+    balance        DECIMAL(20,4) NOT NULL,
+    rate           DECIMAL(10,6) NOT NULL,
+    day_count      VARCHAR(10) NOT NULL,
+    amount         DECIMAL(30,10) NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, accrual_date),
+    CONSTRAINT fk_interest_accruals_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user_currency (user_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS interest_runs (
+    accrual_date TIMESTAMP NOT NULL PRIMARY KEY,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id                    BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    from_user_id          BIGINT UNSIGNED NOT NULL,
+    to_user_id            BIGINT UNSIGNED NOT NULL,
+    amount                DECIMAL(20,4) NOT NULL,
+    currency              CHAR(3) NOT NULL,
+    frequency             VARCHAR(10) NOT NULL,
+    start_at              TIMESTAMP NOT NULL,
+    end_date              TIMESTAMP NULL,
+    next_run_at           TIMESTAMP NOT NULL,
+    due_at                TIMESTAMP NOT NULL,
+    on_insufficient_funds VARCHAR(10) NOT NULL,
+    status                VARCHAR(20) NOT NULL,
+    created_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at            TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_status_due (status, due_at),
+    INDEX idx_from_user (from_user_id),
+    INDEX idx_to_user (to_user_id),
+    FOREIGN KEY (from_user_id) REFERENCES users(id),
+    FOREIGN KEY (to_user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    schedule_id    BIGINT UNSIGNED NOT NULL,
+    occurrence     TIMESTAMP NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    attempts       INT NOT NULL DEFAULT 0,
+    transaction_id BIGINT UNSIGNED NULL,
+    error          VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (schedule_id, occurrence),
+    INDEX idx_transaction_id (transaction_id),
+    FOREIGN KEY (schedule_id) REFERENCES scheduled_transfers(id)
+);
+
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id              BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    client_batch_id VARCHAR(100) NOT NULL UNIQUE,
+    mode            VARCHAR(20) NOT NULL,
+    status          VARCHAR(30) NOT NULL,
+    item_count      INT NOT NULL,
+    succeeded_count INT NOT NULL DEFAULT 0,
+    failed_count    INT NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at    TIMESTAMP NULL
+);
+
+CREATE TABLE IF NOT EXISTS transfer_batch_items (
+    batch_id       BIGINT UNSIGNED NOT NULL,
+    item_index     INT NOT NULL,
+    from_user_id   BIGINT UNSIGNED NOT NULL,
+    to_user_id     BIGINT UNSIGNED NOT NULL,
+    amount         DECIMAL(20,4) NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    status         VARCHAR(20) NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    error          VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (batch_id, item_index),
+    FOREIGN KEY (batch_id) REFERENCES transfer_batches(id)
+);
+
+-- Imported files, unique per SHA-256 of their content.
+CREATE TABLE IF NOT EXISTS imports (
+    id             BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    file_hash      CHAR(64) NOT NULL UNIQUE,
+    filename       VARCHAR(255) NOT NULL DEFAULT '',
+    status         VARCHAR(30) NOT NULL,
+    row_count      INT NOT NULL,
+    executed_count INT NOT NULL DEFAULT 0,
+    failed_count   INT NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at   TIMESTAMP NULL
+);
+
+-- The rows of an imported file with their outcome. Users are not foreign
+-- keys so that rows naming unknown users can be reported.
+CREATE TABLE IF NOT EXISTS import_rows (
+    import_id      BIGINT UNSIGNED NOT NULL,
+    line           INT NOT NULL,
+    type           VARCHAR(20) NOT NULL,
+    from_user_id   BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    to_user_id     BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    amount         DECIMAL(20,4) NOT NULL,
+    currency       CHAR(3) NOT NULL,
+    reference      VARCHAR(255) NOT NULL DEFAULT '',
+    status         VARCHAR(20) NOT NULL,
+    transaction_id BIGINT UNSIGNED NULL,
+    error          VARCHAR(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (import_id, line),
+    FOREIGN KEY (import_id) REFERENCES imports(id)
+);
+
+-- Transactions held for a second person's approval (maker-checker) or for
+-- review after fraud screening flagged them. The transaction is stored with
+-- status awaiting_approval until it is decided.
+CREATE TABLE IF NOT EXISTS transaction_approvals (
+    transaction_id BIGINT UNSIGNED PRIMARY KEY,
+    kind           VARCHAR(20) NOT NULL DEFAULT 'approval',
+    status         VARCHAR(20) NOT NULL,
+    initiated_by   VARCHAR(255) NOT NULL DEFAULT '',
+    initiated_by_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    reason         VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at     TIMESTAMP NOT NULL,
+    decided_by     VARCHAR(255) NOT NULL DEFAULT '',
+    decided_by_id  BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    decided_at     TIMESTAMP NULL,
+    note           VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_status_expires (status, expires_at),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
@@ -0,0 +1,29 @@
+package db
+
+import (
+    "database/sql"
+    "fmt"
+    "financial-service/internal/config"
+    _ "github.com/mattn/go-sqlite3"
+)
+
+func NewSQLiteDB(cfg *config.Config) (*sql.DB, error) {
+    dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate", cfg.SQLitePath)
+
+    db, err := sql.Open("sqlite3", dsn)
+    if err != nil {
+        return nil, fmt.Errorf("error opening database: %w", err)
+    }
+
+    // SQLite allows a single writer; one connection keeps transactions from
+    // contending for the lock and lets ":memory:" databases be shared. A
+    // transaction holds that connection until it ends, so statements inside it
+    // must use the ctx carrying it (see repository.Transactor).
+    db.SetMaxOpenConns(1)
+
+    if err := db.Ping(); err != nil {
+        return nil, fmt.Errorf("error connecting to the database: %w", err)
+    }
+
+    return db, nil
+}
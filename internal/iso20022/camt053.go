@@ -0,0 +1,88 @@
+package iso20022
+
+import "encoding/xml"
+
+// Camt053Namespace is the namespace of the account statements produced.
+const Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// Balance type, credit/debit indicator and entry status codes
+const (
+    BalanceOpeningBooked = "OPBD"
+    BalanceClosingBooked = "CLBD"
+
+    Credit = "CRDT"
+    Debit  = "DBIT"
+
+    EntryBooked = "BOOK"
+)
+
+// Camt053 is a BankToCustomerStatement message.
+type Camt053 struct {
+    XMLName   xml.Name                `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+    Statement BankToCustomerStatement `xml:"BkToCstmrStmt"`
+}
+
+type BankToCustomerStatement struct {
+    GroupHeader ReportGroupHeader  `xml:"GrpHdr"`
+    Statements  []AccountStatement `xml:"Stmt"`
+}
+
+type AccountStatement struct {
+    ID               string               `xml:"Id"`
+    CreationDateTime string               `xml:"CreDtTm"`
+    FromDateTime     string               `xml:"FrToDt>FrDtTm"`
+    ToDateTime       string               `xml:"FrToDt>ToDtTm"`
+    Account          StatementAccount     `xml:"Acct"`
+    Balances         []CashBalance        `xml:"Bal"`
+    Summary          *TransactionsSummary `xml:"TxsSummry,omitempty"`
+    Entries          []ReportEntry        `xml:"Ntry"`
+}
+
+type StatementAccount struct {
+    ID        string `xml:"Id>Othr>Id"`
+    Currency  string `xml:"Ccy"`
+    OwnerName string `xml:"Ownr>Nm,omitempty"`
+}
+
+type CashBalance struct {
+    Type        string `xml:"Tp>CdOrPrtry>Cd"`
+    Amount      Amount `xml:"Amt"`
+    CreditDebit string `xml:"CdtDbtInd"`
+    DateTime    string `xml:"Dt>DtTm"`
+}
+
+type TransactionsSummary struct {
+    Credits EntrySummary `xml:"TtlCdtNtries"`
+    Debits  EntrySummary `xml:"TtlDbtNtries"`
+}
+
+type EntrySummary struct {
+    Count int    `xml:"NbOfNtries"`
+    Sum   string `xml:"Sum"`
+}
+
+type ReportEntry struct {
+    Reference         string        `xml:"NtryRef"`
+    Amount            Amount        `xml:"Amt"`
+    CreditDebit       string        `xml:"CdtDbtInd"`
+    Status            string        `xml:"Sts"`
+    BookingDateTime   string        `xml:"BookgDt>DtTm"`
+    ValueDateTime     string        `xml:"ValDt>DtTm"`
+    ServicerReference string        `xml:"AcctSvcrRef"`
+    TransactionCode   string        `xml:"BkTxCd>Prtry>Cd"`
+    Details           *EntryDetails `xml:"NtryDtls>TxDtls,omitempty"`
+    AdditionalInfo    string        `xml:"AddtlNtryInf,omitempty"`
+}
+
+type EntryDetails struct {
+    Remittance string `xml:"RmtInf>Ustrd"`
+}
+
+// Marshal encodes the statement with an XML declaration.
+func (d *Camt053) Marshal() ([]byte, error) {
+    body, err := xml.MarshalIndent(d, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,141 @@
+// Package iso20022 reads and writes the ISO 20022 XML messages exchanged
+// with corporate clients. Only the elements the service uses are mapped.
+package iso20022
+
+import (
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+)
+
+// Pain001Namespace prefixes the namespace of every pain.001 version.
+const Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001."
+
+// Pain001 is a CustomerCreditTransferInitiation message.
+type Pain001 struct {
+    XMLName    xml.Name                 `xml:"Document"`
+    Initiation CreditTransferInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type CreditTransferInitiation struct {
+    GroupHeader GroupHeader          `xml:"GrpHdr"`
+    Payments    []PaymentInformation `xml:"PmtInf"`
+}
+
+type GroupHeader struct {
+    MessageID            string `xml:"MsgId"`
+    CreationDateTime     string `xml:"CreDtTm"`
+    NumberOfTransactions string `xml:"NbOfTxs"`
+    ControlSum           string `xml:"CtrlSum"`
+}
+
+// PaymentInformation groups the credit transfers paid from one debtor
+// account.
+type PaymentInformation struct {
+    PaymentInformationID string           `xml:"PmtInfId"`
+    PaymentMethod        string           `xml:"PmtMtd"`
+    Debtor               Party            `xml:"Dbtr"`
+    DebtorAccount        Account          `xml:"DbtrAcct"`
+    Transfers            []CreditTransfer `xml:"CdtTrfTxInf"`
+}
+
+type Party struct {
+    Name string `xml:"Nm"`
+}
+
+// Account is identified either by IBAN or by another scheme's identifier.
+type Account struct {
+    IBAN  string `xml:"Id>IBAN"`
+    Other string `xml:"Id>Othr>Id"`
+}
+
+// CreditTransfer is a CreditTransferTransactionInformation entry.
+type CreditTransfer struct {
+    InstructionID   string   `xml:"PmtId>InstrId"`
+    EndToEndID      string   `xml:"PmtId>EndToEndId"`
+    Amount          Amount   `xml:"Amt>InstdAmt"`
+    Creditor        Party    `xml:"Cdtr"`
+    CreditorAccount Account  `xml:"CdtrAcct"`
+    Remittance      []string `xml:"RmtInf>Ustrd"`
+}
+
+type Amount struct {
+    Value    string `xml:",chardata"`
+    Currency string `xml:"Ccy,attr"`
+}
+
+// Float parses the amount.
+func (a Amount) Float() (float64, error) {
+    value, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid amount %q", a.Value)
+    }
+    return value, nil
+}
+
+// Version returns the message version, such as "pain.001.001.03".
+func (d *Pain001) Version() string {
+    return strings.TrimPrefix(d.XMLName.Space, "urn:iso:std:iso:20022:tech:xsd:")
+}
+
+// Transfers returns the number of credit transfers in the message.
+func (d *Pain001) Transfers() int {
+    var n int
+    for _, payment := range d.Initiation.Payments {
+        n += len(payment.Transfers)
+    }
+    return n
+}
+
+// ParsePain001 decodes a pain.001 message and checks its group header
+// against the transfers it carries. Problems with single transfers are left
+// to the caller.
+func ParsePain001(data []byte) (*Pain001, error) {
+    var doc Pain001
+    if err := xml.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("invalid pain.001 message: %w", err)
+    }
+
+    if !strings.HasPrefix(doc.XMLName.Space, Pain001Namespace) {
+        return nil, fmt.Errorf("not a pain.001 message: namespace %q", doc.XMLName.Space)
+    }
+
+    header := doc.Initiation.GroupHeader
+    if header.MessageID == "" {
+        return nil, errors.New("pain.001 message has no MsgId")
+    }
+
+    count := doc.Transfers()
+    if count == 0 {
+        return nil, errors.New("pain.001 message has no transfers")
+    }
+
+    if n, err := strconv.Atoi(strings.TrimSpace(header.NumberOfTransactions)); err != nil || n != count {
+        return nil, fmt.Errorf("NbOfTxs %q does not match the %d transfers in the message", header.NumberOfTransactions, count)
+    }
+
+    if header.ControlSum != "" {
+        controlSum, err := strconv.ParseFloat(strings.TrimSpace(header.ControlSum), 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid CtrlSum %q", header.ControlSum)
+        }
+
+        var sum float64
+        for _, payment := range doc.Initiation.Payments {
+            for _, transfer := range payment.Transfers {
+                // Unparseable amounts are reported on their transfer
+                amount, _ := transfer.Amount.Float()
+                sum += amount
+            }
+        }
+
+        if math.Abs(sum-controlSum) > 1e-6 {
+            return nil, fmt.Errorf("CtrlSum %s does not match the transfers' total of %g", header.ControlSum, sum)
+        }
+    }
+
+    return &doc, nil
+}
@@ -0,0 +1,106 @@
+package iso20022
+
+import "encoding/xml"
+
+// Pain002Namespace is the namespace of the status reports produced.
+const Pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+
+// Group, payment and transaction status codes
+const (
+    StatusAcceptedTechnicalValidation = "ACTC"
+    StatusAcceptedSettlementCompleted = "ACSC"
+    StatusPending                     = "PDNG"
+    StatusPartiallyAccepted           = "PART"
+    StatusRejected                    = "RJCT"
+)
+
+// Status reason codes
+const (
+    ReasonIncorrectAccountNumber = "AC01"
+    ReasonNotAllowedAmount       = "AM02"
+    ReasonNotAllowedCurrency     = "AM03"
+    ReasonInsufficientFunds      = "AM04"
+    ReasonInvalidAmount          = "AM12"
+    ReasonNarrative              = "NARR"
+)
+
+// maxAdditionalInfo is the longest AddtlInf the schema allows.
+const maxAdditionalInfo = 105
+
+// Pain002 is a CustomerPaymentStatusReport message.
+type Pain002 struct {
+    XMLName xml.Name            `xml:"urn:iso:std:iso:20022:tech:xsd:pain.002.001.03 Document"`
+    Report  PaymentStatusReport `xml:"CstmrPmtStsRpt"`
+}
+
+type PaymentStatusReport struct {
+    GroupHeader      ReportGroupHeader       `xml:"GrpHdr"`
+    OriginalGroup    OriginalGroupStatus     `xml:"OrgnlGrpInfAndSts"`
+    OriginalPayments []OriginalPaymentStatus `xml:"OrgnlPmtInfAndSts"`
+}
+
+type ReportGroupHeader struct {
+    MessageID        string `xml:"MsgId"`
+    CreationDateTime string `xml:"CreDtTm"`
+}
+
+type OriginalGroupStatus struct {
+    OriginalMessageID            string `xml:"OrgnlMsgId"`
+    OriginalMessageNameID        string `xml:"OrgnlMsgNmId"`
+    OriginalNumberOfTransactions string `xml:"OrgnlNbOfTxs,omitempty"`
+    OriginalControlSum           string `xml:"OrgnlCtrlSum,omitempty"`
+    GroupStatus                  string `xml:"GrpSts"`
+}
+
+type OriginalPaymentStatus struct {
+    OriginalPaymentInformationID string              `xml:"OrgnlPmtInfId"`
+    PaymentInformationStatus     string              `xml:"PmtInfSts"`
+    Transactions                 []TransactionStatus `xml:"TxInfAndSts"`
+}
+
+type TransactionStatus struct {
+    StatusID              string        `xml:"StsId,omitempty"`
+    OriginalInstructionID string        `xml:"OrgnlInstrId,omitempty"`
+    OriginalEndToEndID    string        `xml:"OrgnlEndToEndId"`
+    Status                string        `xml:"TxSts"`
+    Reason                *StatusReason `xml:"StsRsnInf,omitempty"`
+}
+
+type StatusReason struct {
+    Code           string `xml:"Rsn>Cd"`
+    AdditionalInfo string `xml:"AddtlInf,omitempty"`
+}
+
+// NewStatusReason returns a reason with the given code, shortening info to
+// fit the schema.
+func NewStatusReason(code, info string) *StatusReason {
+    if len(info) > maxAdditionalInfo {
+        info = info[:maxAdditionalInfo]
+    }
+    return &StatusReason{Code: code, AdditionalInfo: info}
+}
+
+// CombinedStatus is the status of a group whose members have the given
+// statuses: the common status if they agree, otherwise partially accepted.
+func CombinedStatus(statuses []string) string {
+    if len(statuses) == 0 {
+        return StatusRejected
+    }
+
+    for _, status := range statuses[1:] {
+        if status != statuses[0] {
+            return StatusPartiallyAccepted
+        }
+    }
+
+    return statuses[0]
+}
+
+// Marshal encodes the report with an XML declaration.
+func (d *Pain002) Marshal() ([]byte, error) {
+    body, err := xml.MarshalIndent(d, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), body...), nil
+}
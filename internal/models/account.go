@@ -0,0 +1,98 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "time"
+)
+
+type AccountType string
+type AccountStatus string
+
+const (
+    AccountTypeChecking AccountType = "checking"
+    AccountTypeSavings  AccountType = "savings"
+
+    AccountStatusActive AccountStatus = "active"
+    // Frozen accounts refuse debits and, if so configured, credits, until
+    // an admin unfreezes them.
+    AccountStatusFrozen AccountStatus = "frozen"
+    // Closed accounts refuse all activity and are never reopened.
+    AccountStatusClosed AccountStatus = "closed"
+)
+
+var (
+    ErrAccountFrozen = errors.New("account is frozen")
+    ErrAccountClosed = errors.New("account is closed")
+)
+
+// Account holds a user's money in one currency. A user may have several
+// accounts in a currency; the first one opened that is not closed is their
+// default account in it, which transactions naming only the user are booked
+// to.
+type Account struct {
+    ID        uint          `json:"id"`
+    UserID    uint          `json:"user_id"`
+    Type      AccountType   `json:"type"`
+    Currency  string        `json:"currency"`
+    Status    AccountStatus `json:"status"`
+    CreatedAt time.Time     `json:"created_at"`
+    UpdatedAt time.Time     `json:"updated_at"`
+    // Balance is loaded separately and is not stored with the account.
+    Balance *Balance `json:"balance,omitempty"`
+}
+
+func (a *Account) Validate() error {
+    switch a.Type {
+        case AccountTypeChecking, AccountTypeSavings:
+        default:
+            return fmt.Errorf("invalid account type %q", a.Type)
+    }
+
+    if a.UserID == 0 {
+        return errors.New("user_id is required")
+    }
+
+    return ValidateCurrency(a.Currency)
+}
+
+// CheckOwner reports an error unless the account belongs to userID and holds
+// currency.
+func (a *Account) CheckOwner(userID uint, currency string) error {
+    if a.UserID != userID {
+        return fmt.Errorf("account %d does not belong to user %d", a.ID, userID)
+    }
+
+    if a.Currency != currency {
+        return fmt.Errorf("account %d holds %s, not %s", a.ID, a.Currency, currency)
+    }
+
+    return nil
+}
+
+// CheckDebit reports an error if money cannot be taken from the account.
+func (a *Account) CheckDebit() error {
+    switch a.Status {
+        case AccountStatusFrozen:
+            return fmt.Errorf("account %d: %w", a.ID, ErrAccountFrozen)
+        case AccountStatusClosed:
+            return fmt.Errorf("account %d: %w", a.ID, ErrAccountClosed)
+    }
+
+    return nil
+}
+
+// CheckCredit reports an error if money cannot be paid into the account.
+// Frozen accounts accept credits unless rejectFrozen is set.
+func (a *Account) CheckCredit(rejectFrozen bool) error {
+    switch a.Status {
+        case AccountStatusFrozen:
+            if rejectFrozen {
+                return fmt.Errorf("account %d: %w", a.ID, ErrAccountFrozen)
+            }
+        case AccountStatusClosed:
+            return fmt.Errorf("account %d: %w", a.ID, ErrAccountClosed)
+    }
+
+    return nil
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "time"
+)
+
+type ApprovalStatus string
+
+const (
+    ApprovalPending  ApprovalStatus = "pending"
+    ApprovalApproved ApprovalStatus = "approved"
+    ApprovalRejected ApprovalStatus = "rejected"
+    // Expired approvals were not decided before ExpiresAt.
+    ApprovalExpired ApprovalStatus = "expired"
+)
+
+var (
+    ErrNotAwaitingApproval = errors.New("transaction is not awaiting approval")
+    ErrSelfApproval        = errors.New("a transaction cannot be approved or rejected by its initiator")
+    ErrApproverUnknown     = errors.New("approvals can only be decided by an identified admin user")
+    ErrInitiatorUnknown    = errors.New("transactions held for approval must be initiated by an identified user")
+)
+
+// ApprovalKind tells why a transaction was held: for a second person's
+// approval, or for review after fraud screening flagged it.
+type ApprovalKind string
+
+const (
+    ApprovalKindApproval ApprovalKind = "approval"
+    ApprovalKindReview   ApprovalKind = "review"
+)
+
+// ApprovalThreshold holds transactions of Type in Currency whose amount is
+// above Amount for approval. An empty type or currency matches any.
+type ApprovalThreshold struct {
+    Type     TransactionType `json:"type,omitempty"`
+    Currency string          `json:"currency,omitempty"`
+    Amount   float64         `json:"amount"`
+}
+
+func (t *ApprovalThreshold) Validate() error {
+    if t.Currency != "" {
+        if err := ValidateCurrency(t.Currency); err != nil {
+            return err
+        }
+    }
+
+    if t.Amount < 0 {
+        return errors.New("threshold amount cannot be negative")
+    }
+
+    return nil
+}
+
+// Matches reports whether tx is above the threshold.
+func (t *ApprovalThreshold) Matches(tx *Transaction) bool {
+    if t.Type != "" && t.Type != tx.Type {
+        return false
+    }
+
+    if t.Currency != "" && t.Currency != tx.Currency {
+        return false
+    }
+
+    return tx.Amount > t.Amount
+}
+
+func (t *ApprovalThreshold) String() string {
+    kind := string(t.Type)
+    if kind == "" {
+        kind = "transaction"
+    }
+
+    currency := t.Currency
+    if currency == "" {
+        currency = "any currency"
+    }
+
+    return fmt.Sprintf("%s above %v %s", kind, t.Amount, currency)
+}
+
+// Approval is the maker-checker record of a transaction held for a second
+// person's approval or for fraud review. The transaction is only applied once
+// an admin other than the one who initiated it approves it before ExpiresAt.
+type Approval struct {
+    TransactionID uint           `json:"transaction_id"`
+    Kind          ApprovalKind   `json:"kind"`
+    Status        ApprovalStatus `json:"status"`
+    InitiatedBy   string         `json:"initiated_by"`
+    // InitiatedByID and DecidedByID are the user IDs of the admins who
+    // initiated and decided the transaction; zero if not made by an admin.
+    InitiatedByID uint `json:"initiated_by_id,omitempty"`
+    // Reason says why the transaction was held.
+    Reason      string     `json:"reason"`
+    ExpiresAt   time.Time  `json:"expires_at"`
+    DecidedBy   string     `json:"decided_by,omitempty"`
+    DecidedByID uint       `json:"decided_by_id,omitempty"`
+    DecidedAt   *time.Time `json:"decided_at,omitempty"`
+    // Note is what the approver or rejecter gave as their reason.
+    Note      string    `json:"note,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    // Transaction is loaded separately and is not stored with the approval.
+    Transaction *Transaction `json:"transaction,omitempty"`
+}
@@ -12,5 +12,7 @@ type AuditLog struct {
     Action     string         `json:"action"`
     Details    json.RawMessage `json:"details"`
     Changes    string         `json:"changes"`
+    Actor      string         `json:"actor,omitempty"`
+    Reason     string         `json:"reason,omitempty"`
     CreatedAt  time.Time      `json:"created_at"`
 } 
\ No newline at end of file
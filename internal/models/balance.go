@@ -1,18 +1,59 @@
 package models
 
 import (
+    "encoding/json"
     "sync"
     "time"
     "errors"
 )
 
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Balance is the money held in an account, whose owner and currency it
+// repeats. A CreditLimit lets Amount go negative down to -CreditLimit; a
+// negative Amount is an overdraft.
 type Balance struct {
     mu            sync.RWMutex `json:"-"`
+    AccountID     uint      `json:"account_id"`
     UserID        uint      `json:"user_id"`
+    Currency      string    `json:"currency"`
     Amount        float64   `json:"amount"`
+    CreditLimit   float64   `json:"credit_limit"`
     LastUpdatedAt time.Time `json:"last_updated_at"`
 }
 
+// Available is what can still be spent: the amount plus the credit limit.
+func (b *Balance) Available() float64 {
+    return RoundAmount(b.Amount+b.CreditLimit, b.Currency)
+}
+
+func (b *Balance) Overdrawn() bool {
+    return b.Amount < 0
+}
+
+// MarshalJSON adds the available balance to the stored fields.
+func (b *Balance) MarshalJSON() ([]byte, error) {
+    return json.Marshal(struct {
+        AccountID     uint      `json:"account_id"`
+        UserID        uint      `json:"user_id"`
+        Currency      string    `json:"currency"`
+        Amount        float64   `json:"amount"`
+        CreditLimit   float64   `json:"credit_limit"`
+        Available     float64   `json:"available"`
+        Overdrawn     bool      `json:"overdrawn"`
+        LastUpdatedAt time.Time `json:"last_updated_at"`
+    }{
+        AccountID:     b.AccountID,
+        UserID:        b.UserID,
+        Currency:      b.Currency,
+        Amount:        b.Amount,
+        CreditLimit:   b.CreditLimit,
+        Available:     b.Available(),
+        Overdrawn:     b.Overdrawn(),
+        LastUpdatedAt: b.LastUpdatedAt,
+    })
+}
+
 func (b *Balance) GetAmount() float64 {
     b.mu.RLock()
     defer b.mu.RUnlock()
@@ -38,7 +79,7 @@ func (b *Balance) SubtractAmount(amount float64) error {
     b.mu.Lock()
     defer b.mu.Unlock()
 
-    if b.Amount < amount {
+    if b.Amount+b.CreditLimit < amount {
         return errors.New("insufficient balance")
     }
 
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// BalanceSnapshot records an account's balance as of a point in time: the
+// sum of its completed transactions created before AsOf.
+type BalanceSnapshot struct {
+    AccountID uint      `json:"account_id"`
+    UserID    uint      `json:"user_id"`
+    Currency  string    `json:"currency"`
+    AsOf      time.Time `json:"as_of"`
+    Amount    float64   `json:"amount"`
+    CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "math"
+)
+
+var (
+    ErrUnknownCurrency = errors.New("unknown currency")
+    ErrCrossCurrency   = errors.New("cross-currency operation requires an explicit conversion")
+)
+
+// minorUnits maps supported ISO 4217 currency codes to the number of digits
+// after the decimal separator.
+var minorUnits = map[string]int{
+    "AUD": 2,
+    "BHD": 3,
+    "BRL": 2,
+    "CAD": 2,
+    "CHF": 2,
+    "CNY": 2,
+    "CZK": 2,
+    "DKK": 2,
+    "EUR": 2,
+    "GBP": 2,
+    "HKD": 2,
+    "HUF": 2,
+    "INR": 2,
+    "JOD": 3,
+    "JPY": 0,
+    "KRW": 0,
+    "KWD": 3,
+    "MXN": 2,
+    "NOK": 2,
+    "NZD": 2,
+    "OMR": 3,
+    "PLN": 2,
+    "SEK": 2,
+    "SGD": 2,
+    "TND": 3,
+    "TRY": 2,
+    "USD": 2,
+    "ZAR": 2,
+}
+
+// ValidateCurrency checks that code is a supported ISO 4217 currency code.
+func ValidateCurrency(code string) error {
+    if _, ok := minorUnits[code]; !ok {
+        return fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+    }
+    return nil
+}
+
+// MinorUnits returns the number of decimal places used by the currency.
+func MinorUnits(code string) int {
+    return minorUnits[code]
+}
+
+// RoundAmount rounds amount to the currency's minor unit.
+func RoundAmount(amount float64, code string) float64 {
+    scale := math.Pow10(MinorUnits(code))
+    return math.Round(amount*scale) / scale
+}
+
+// ValidateAmount checks that amount is positive and has no more decimal
+// places than the currency allows.
+func ValidateAmount(amount float64, code string) error {
+    if err := ValidateCurrency(code); err != nil {
+        return err
+    }
+
+    if amount <= 0 {
+        return errors.New("amount must be positive")
+    }
+
+    scale := math.Pow10(MinorUnits(code))
+    if math.Abs(amount*scale-math.Round(amount*scale)) > 1e-6 {
+        return fmt.Errorf("amount %v has more than %d decimal places for %s", amount, MinorUnits(code), code)
+    }
+
+    return nil
+}
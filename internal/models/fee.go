@@ -0,0 +1,10 @@
+package models
+
+// FeeLine is one fee charged on a transaction: the rule that produced it and
+// the fee transaction that posted it to the house account.
+type FeeLine struct {
+    TransactionID uint    `json:"transaction_id,omitempty"`
+    Rule          string  `json:"rule"`
+    Amount        float64 `json:"amount"`
+    Currency      string  `json:"currency"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "time"
+)
+
+type FraudOutcome string
+
+const (
+    FraudAllow  FraudOutcome = "allow"
+    FraudReview FraudOutcome = "review"
+    FraudBlock  FraudOutcome = "block"
+)
+
+// Stricter reports whether o is stricter than other.
+func (o FraudOutcome) Stricter(other FraudOutcome) bool {
+    rank := map[FraudOutcome]int{FraudAllow: 0, FraudReview: 1, FraudBlock: 2}
+    return rank[o] > rank[other]
+}
+
+type FraudRuleKind string
+
+const (
+    // Amount rules are hit by transactions above an amount.
+    FraudRuleAmount FraudRuleKind = "amount"
+    // Velocity rules are hit when a user's transactions within a window
+    // exceed a count or total.
+    FraudRuleVelocity FraudRuleKind = "velocity"
+    // New counterparty rules are hit by payments to users the payer never
+    // paid before.
+    FraudRuleNewCounterparty FraudRuleKind = "new_counterparty"
+)
+
+var ErrFraudBlocked = errors.New("transaction blocked by fraud screening")
+
+// FraudRuleConfig defines a fraud rule. Type and Currency narrow the
+// transactions it looks at; empty matches any. Window and
+// AfterPasswordChange are durations such as "1h".
+type FraudRuleConfig struct {
+    Name     string          `json:"name"`
+    Kind     FraudRuleKind   `json:"kind"`
+    Type     TransactionType `json:"type,omitempty"`
+    Currency string          `json:"currency,omitempty"`
+    // Amount is the threshold of an amount rule and the most a velocity
+    // rule allows in total; new counterparty rules only look at payments
+    // above it.
+    Amount float64 `json:"amount,omitempty"`
+    // Count is the most transactions a velocity rule allows in the window,
+    // or the most new counterparties a new counterparty rule allows in it.
+    Count  int    `json:"count,omitempty"`
+    Window string `json:"window,omitempty"`
+    // AfterPasswordChange makes the rule apply only this soon after the
+    // user changed their password.
+    AfterPasswordChange string       `json:"after_password_change,omitempty"`
+    Outcome             FraudOutcome `json:"outcome"`
+}
+
+func (c *FraudRuleConfig) Validate() error {
+    if c.Name == "" {
+        return errors.New("name is required")
+    }
+
+    switch c.Kind {
+        case FraudRuleAmount, FraudRuleVelocity, FraudRuleNewCounterparty:
+        default:
+            return fmt.Errorf("unknown rule kind: %q", c.Kind)
+    }
+
+    switch c.Outcome {
+        case FraudReview, FraudBlock:
+        default:
+            return fmt.Errorf("outcome must be %s or %s", FraudReview, FraudBlock)
+    }
+
+    if c.Currency != "" {
+        if err := ValidateCurrency(c.Currency); err != nil {
+            return err
+        }
+    }
+
+    if c.Amount < 0 || c.Count < 0 {
+        return errors.New("amount and count cannot be negative")
+    }
+
+    if _, err := c.WindowDuration(); err != nil {
+        return err
+    }
+
+    if _, err := c.PasswordChangeDuration(); err != nil {
+        return err
+    }
+
+    if c.Kind == FraudRuleVelocity {
+        if c.Window == "" {
+            return errors.New("velocity rules need a window")
+        }
+        if c.Count == 0 && c.Amount == 0 {
+            return errors.New("velocity rules need a count or an amount")
+        }
+    }
+
+    if c.Kind == FraudRuleNewCounterparty && c.Count > 0 && c.Window == "" {
+        return errors.New("counting new counterparties needs a window")
+    }
+
+    return nil
+}
+
+func (c *FraudRuleConfig) WindowDuration() (time.Duration, error) {
+    return parseRuleDuration("window", c.Window)
+}
+
+func (c *FraudRuleConfig) PasswordChangeDuration() (time.Duration, error) {
+    return parseRuleDuration("after_password_change", c.AfterPasswordChange)
+}
+
+func parseRuleDuration(name, value string) (time.Duration, error) {
+    if value == "" {
+        return 0, nil
+    }
+
+    d, err := time.ParseDuration(value)
+    if err != nil || d <= 0 {
+        return 0, fmt.Errorf("invalid %s: %q", name, value)
+    }
+
+    return d, nil
+}
+
+// FraudAssessment is the result of screening a transaction: the strictest
+// outcome of the rules it hit, and their names.
+type FraudAssessment struct {
+    Outcome FraudOutcome `json:"outcome"`
+    Rules   []string     `json:"rules,omitempty"`
+}
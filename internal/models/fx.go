@@ -0,0 +1,42 @@
+package models
+
+import (
+    "errors"
+    "time"
+)
+
+var (
+    ErrQuoteNotFound = errors.New("fx quote not found")
+    ErrQuoteExpired  = errors.New("fx quote has expired")
+    ErrQuoteUsed     = errors.New("fx quote has already been executed")
+)
+
+// FXRate is the mid-market price of one unit of BaseCurrency in
+// QuoteCurrency.
+type FXRate struct {
+    BaseCurrency  string    `json:"base"`
+    QuoteCurrency string    `json:"quote"`
+    Rate          float64   `json:"rate"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// FXQuote is a conversion price offered to a user. Rate already includes the
+// Spread, so ToAmount is FromAmount * Rate rounded to ToCurrency. A quote can
+// be executed once, before ExpiresAt.
+type FXQuote struct {
+    ID            uint      `json:"id"`
+    UserID        uint      `json:"user_id"`
+    FromCurrency  string    `json:"from_currency"`
+    ToCurrency    string    `json:"to_currency"`
+    FromAmount    float64   `json:"from_amount"`
+    ToAmount      float64   `json:"to_amount"`
+    Rate          float64   `json:"rate"`
+    Spread        float64   `json:"spread"`
+    CreatedAt     time.Time `json:"created_at"`
+    ExpiresAt     time.Time `json:"expires_at"`
+    TransactionID uint      `json:"transaction_id,omitempty"`
+}
+
+func (q *FXQuote) Expired(now time.Time) bool {
+    return !now.Before(q.ExpiresAt)
+}
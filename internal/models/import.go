@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type ImportStatus string
+type ImportRowStatus string
+
+const (
+    // A dry run validates a file without storing or executing it
+    ImportStatusValidated           ImportStatus = "validated"
+    ImportStatusProcessing          ImportStatus = "processing"
+    ImportStatusCompleted           ImportStatus = "completed"
+    ImportStatusCompletedWithErrors ImportStatus = "completed_with_errors"
+    ImportStatusFailed              ImportStatus = "failed"
+
+    ImportRowPending   ImportRowStatus = "pending"
+    ImportRowValid     ImportRowStatus = "valid"
+    ImportRowInvalid   ImportRowStatus = "invalid"
+    ImportRowCompleted ImportRowStatus = "completed"
+    ImportRowFailed    ImportRowStatus = "failed"
+    // Rows whose transaction was held for approval
+    ImportRowAwaitingApproval ImportRowStatus = "awaiting_approval"
+)
+
+// Import is an uploaded file of credits and transfers. Files are identified
+// by the SHA-256 of their content, so uploading the same file twice executes
+// it once.
+type Import struct {
+    ID            uint         `json:"id,omitempty"`
+    FileHash      string       `json:"file_hash"`
+    Filename      string       `json:"filename"`
+    Status        ImportStatus `json:"status"`
+    RowCount      int          `json:"row_count"`
+    ExecutedCount int          `json:"executed_count"`
+    // FailedCount counts rows refused by validation or failing to execute
+    FailedCount int        `json:"failed_count"`
+    CreatedAt   time.Time  `json:"created_at"`
+    CompletedAt *time.Time `json:"completed_at,omitempty"`
+    // Rows is loaded separately and is not stored with the import.
+    Rows []*ImportRow `json:"rows,omitempty"`
+}
+
+// ImportRow is one entry of an import file and its outcome. Line is its line
+// in a CSV file, counting the header as line 1, or its position from 1 in
+// an XML message.
+type ImportRow struct {
+    ImportID      uint            `json:"-"`
+    Line          int             `json:"line"`
+    Type          TransactionType `json:"type"`
+    FromUserID    uint            `json:"from_user_id,omitempty"`
+    ToUserID      uint            `json:"to_user_id"`
+    Amount        float64         `json:"amount"`
+    Currency      string          `json:"currency"`
+    Reference     string          `json:"reference,omitempty"`
+    Status        ImportRowStatus `json:"status"`
+    TransactionID uint            `json:"transaction_id,omitempty"`
+    Error         string          `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// InterestAccrual is the interest earned, or charged when negative, on one
+// day's closing balance of an account. Accruals are kept unrounded and summed when posted;
+// TransactionID is the interest transaction that paid them out.
+type InterestAccrual struct {
+    AccountID     uint      `json:"account_id"`
+    UserID        uint      `json:"user_id"`
+    Currency      string    `json:"currency"`
+    Date          time.Time `json:"date"`
+    Balance       float64   `json:"balance"`
+    Rate          float64   `json:"rate"`
+    DayCount      string    `json:"day_count"`
+    Amount        float64   `json:"amount"`
+    TransactionID uint      `json:"transaction_id,omitempty"`
+    CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "time"
+)
+
+var ErrLimitExceeded = errors.New("limit_exceeded")
+
+// Limits a TransactionLimit can set. Daily and monthly limits are rolling
+// windows of 24 hours and 30 days.
+const (
+    LimitPerTransaction = "per_transaction"
+    LimitDailyAmount    = "daily_amount"
+    LimitDailyCount     = "daily_count"
+    LimitMonthlyAmount  = "monthly_amount"
+    LimitMonthlyCount   = "monthly_count"
+)
+
+// TransactionLimit caps the transactions a user initiates: the recipient of
+// a credit, the sender of anything else. An empty Type or Currency matches
+// any; amounts are in the transaction's currency and totals are kept per
+// currency. Zero leaves a limit unset. Defaults have no UserID; a per-user
+// override replaces the default with the same Type and Currency.
+type TransactionLimit struct {
+    UserID         uint            `json:"user_id,omitempty"`
+    Type           TransactionType `json:"type"`
+    Currency       string          `json:"currency"`
+    PerTransaction float64         `json:"per_transaction"`
+    DailyAmount    float64         `json:"daily_amount"`
+    DailyCount     int             `json:"daily_count"`
+    MonthlyAmount  float64         `json:"monthly_amount"`
+    MonthlyCount   int             `json:"monthly_count"`
+    UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func (l *TransactionLimit) Validate() error {
+    if l.Currency != "" {
+        if err := ValidateCurrency(l.Currency); err != nil {
+            return err
+        }
+    }
+
+    if l.PerTransaction < 0 || l.DailyAmount < 0 || l.DailyCount < 0 || l.MonthlyAmount < 0 || l.MonthlyCount < 0 {
+        return errors.New("limits cannot be negative")
+    }
+
+    return nil
+}
+
+// LimitExceededError reports the limit a transaction would have broken. Used
+// is what the window already holds, not counting the refused transaction.
+type LimitExceededError struct {
+    Limit    string          `json:"limit"`
+    Type     TransactionType `json:"type,omitempty"`
+    Currency string          `json:"currency"`
+    Max      float64         `json:"max"`
+    Used     float64         `json:"used"`
+}
+
+func (e *LimitExceededError) Error() string {
+    scope := "all transactions"
+    if e.Type != "" {
+        scope = string(e.Type) + " transactions"
+    }
+
+    return fmt.Sprintf("%s: %s limit of %g reached for %s in %s", ErrLimitExceeded, e.Limit, e.Max, scope, e.Currency)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+    return ErrLimitExceeded
+}
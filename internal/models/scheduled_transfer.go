@@ -0,0 +1,124 @@
+package models
+
+import (
+    "errors"
+    "time"
+)
+
+type TransferFrequency string
+type ScheduleStatus string
+type ScheduledRunStatus string
+
+const (
+    FrequencyOnce    TransferFrequency = "once"
+    FrequencyWeekly  TransferFrequency = "weekly"
+    FrequencyMonthly TransferFrequency = "monthly"
+
+    ScheduleStatusActive    ScheduleStatus = "active"
+    ScheduleStatusCompleted ScheduleStatus = "completed"
+    ScheduleStatusCancelled ScheduleStatus = "cancelled"
+
+    // What to do with an occurrence that finds insufficient funds
+    OnInsufficientFundsRetry = "retry"
+    OnInsufficientFundsSkip  = "skip"
+
+    ScheduledRunPending   ScheduledRunStatus = "pending"
+    ScheduledRunCompleted ScheduledRunStatus = "completed"
+    ScheduledRunRetrying  ScheduledRunStatus = "retrying"
+    ScheduledRunSkipped   ScheduledRunStatus = "skipped"
+    ScheduledRunFailed    ScheduledRunStatus = "failed"
+    // The occurrence's transfer is held until someone approves it
+    ScheduledRunAwaitingApproval ScheduledRunStatus = "awaiting_approval"
+)
+
+// ScheduledTransfer is a standing order. NextRunAt is the date of the next
+// occurrence; DueAt is when the scheduler next tries it, which is later than
+// NextRunAt while an occurrence waits to be retried. Monthly occurrences
+// fall on StartAt's day of the month, or the month's last day if shorter.
+// Occurrences stop before EndDate.
+type ScheduledTransfer struct {
+    ID                  uint              `json:"id"`
+    FromUserID          uint              `json:"from_user_id"`
+    ToUserID            uint              `json:"to_user_id"`
+    Amount              float64           `json:"amount"`
+    Currency            string            `json:"currency"`
+    Frequency           TransferFrequency `json:"frequency"`
+    StartAt             time.Time         `json:"start_at"`
+    EndDate             *time.Time        `json:"end_date,omitempty"`
+    NextRunAt           time.Time         `json:"next_run_at"`
+    DueAt               time.Time         `json:"due_at"`
+    OnInsufficientFunds string            `json:"on_insufficient_funds"`
+    Status              ScheduleStatus    `json:"status"`
+    CreatedAt           time.Time         `json:"created_at"`
+    UpdatedAt           time.Time         `json:"updated_at"`
+}
+
+func (s *ScheduledTransfer) Validate() error {
+    if s.FromUserID == 0 || s.ToUserID == 0 {
+        return errors.New("both from_user_id and to_user_id are required")
+    }
+
+    if s.FromUserID == s.ToUserID {
+        return errors.New("cannot schedule a transfer to the same user")
+    }
+
+    if err := ValidateAmount(s.Amount, s.Currency); err != nil {
+        return err
+    }
+
+    switch s.Frequency {
+        case FrequencyOnce, FrequencyWeekly, FrequencyMonthly:
+        default:
+            return errors.New("frequency must be once, weekly or monthly")
+    }
+
+    switch s.OnInsufficientFunds {
+        case OnInsufficientFundsRetry, OnInsufficientFundsSkip:
+        default:
+            return errors.New("on_insufficient_funds must be retry or skip")
+    }
+
+    if s.EndDate != nil && !s.EndDate.After(s.StartAt) {
+        return errors.New("end_date must be after start_at")
+    }
+
+    return nil
+}
+
+// Next returns the occurrence following the given one. Once-off schedules
+// have none and return the zero time.
+func (s *ScheduledTransfer) Next(occurrence time.Time) time.Time {
+    switch s.Frequency {
+        case FrequencyWeekly:
+            return occurrence.AddDate(0, 0, 7)
+        case FrequencyMonthly:
+            first := time.Date(occurrence.Year(), occurrence.Month()+1, 1,
+                s.StartAt.Hour(), s.StartAt.Minute(), s.StartAt.Second(), s.StartAt.Nanosecond(), occurrence.Location())
+            day := s.StartAt.Day()
+            if last := first.AddDate(0, 1, -1).Day(); day > last {
+                day = last
+            }
+            return first.AddDate(0, 0, day-1)
+        default:
+            return time.Time{}
+    }
+}
+
+// Ends reports whether the schedule has no occurrence at or after t, either
+// because it runs once or because t is past its end date.
+func (s *ScheduledTransfer) Ends(t time.Time) bool {
+    return t.IsZero() || (s.EndDate != nil && !t.Before(*s.EndDate))
+}
+
+// ScheduledTransferRun records the execution of one occurrence. There is at
+// most one run per occurrence, which is what keeps an occurrence from being
+// executed twice.
+type ScheduledTransferRun struct {
+    ScheduleID    uint               `json:"schedule_id"`
+    Occurrence    time.Time          `json:"occurrence"`
+    Status        ScheduledRunStatus `json:"status"`
+    Attempts      int                `json:"attempts"`
+    TransactionID uint               `json:"transaction_id,omitempty"`
+    Error         string             `json:"error,omitempty"`
+    UpdatedAt     time.Time          `json:"updated_at"`
+}
@@ -13,10 +13,24 @@ const (
     TransactionTypeCredit   TransactionType = "credit"
     TransactionTypeDebit    TransactionType = "debit"
     TransactionTypeTransfer TransactionType = "transfer"
+    // A conversion debits Amount in Currency and credits ToAmount in
+    // ToCurrency at the rate of an executed FX quote.
+    TransactionTypeConversion TransactionType = "conversion"
+    // A fee moves a charge from the payer to the house account. ParentID is
+    // the transaction it was charged on.
+    TransactionTypeFee TransactionType = "fee"
+    // Interest pays accrued interest to ToUserID, or charges overdraft
+    // interest to FromUserID; the other side is left empty.
+    TransactionTypeInterest TransactionType = "interest"
 
     TransactionStatusPending   TransactionStatus = "pending"
     TransactionStatusCompleted TransactionStatus = "completed"
     TransactionStatusFailed    TransactionStatus = "failed"
+    // Transactions awaiting approval are stored but not applied until a
+    // second person approves them; rejected and expired ones never are.
+    TransactionStatusAwaitingApproval TransactionStatus = "awaiting_approval"
+    TransactionStatusRejected         TransactionStatus = "rejected"
+    TransactionStatusExpired          TransactionStatus = "expired"
 )
 
 type Transaction struct {
@@ -24,10 +38,29 @@ type Transaction struct {
     ID          uint             `json:"id"`
     FromUserID  uint             `json:"from_user_id"`
     ToUserID    uint             `json:"to_user_id"`
+    // FromAccountID and ToAccountID are the accounts debited and credited.
+    // A side left zero is booked to the user's default account in its
+    // currency when the transaction is applied.
+    FromAccountID uint           `json:"from_account_id,omitempty"`
+    ToAccountID   uint           `json:"to_account_id,omitempty"`
     Amount      float64          `json:"amount"`
+    Currency    string           `json:"currency"`
+    ToAmount    float64          `json:"to_amount,omitempty"`
+    ToCurrency  string           `json:"to_currency,omitempty"`
+    Rate        float64          `json:"rate,omitempty"`
+    Spread      float64          `json:"spread,omitempty"`
+    QuoteID     uint             `json:"quote_id,omitempty"`
+    ParentID    uint             `json:"parent_id,omitempty"`
+    Description string           `json:"description,omitempty"`
+    // Fees lists the fees charged on this transaction. It is filled in when
+    // the transaction is created and is not stored with it.
+    Fees        []FeeLine        `json:"fees,omitempty"`
     Type        TransactionType  `json:"type"`
     Status      TransactionStatus `json:"status"`
     CreatedAt   time.Time        `json:"created_at"`
+    // BookedAt is when a completed transaction was applied to the balances,
+    // which for one held for approval is well after it was created.
+    BookedAt    *time.Time       `json:"booked_at,omitempty"`
 }
 
 func (t *Transaction) SetStatus(status TransactionStatus) {
@@ -44,6 +77,22 @@ func (t *Transaction) GetStatus() TransactionStatus {
     return t.Status
 }
 
+// CreditCurrency is the currency the recipient is credited in.
+func (t *Transaction) CreditCurrency() string {
+    if t.Type == TransactionTypeConversion {
+        return t.ToCurrency
+    }
+    return t.Currency
+}
+
+// CreditAmount is the amount the recipient is credited with.
+func (t *Transaction) CreditAmount() float64 {
+    if t.Type == TransactionTypeConversion {
+        return t.ToAmount
+    }
+    return t.Amount
+}
+
 func (t *Transaction) Validate() error {
     switch t.Type {
         case TransactionTypeCredit:
@@ -54,17 +103,27 @@ func (t *Transaction) Validate() error {
             if t.FromUserID == 0 {
                 return errors.New("from_user_id is required for debit transactions")
             }
-        case TransactionTypeTransfer:
+        case TransactionTypeTransfer, TransactionTypeFee:
             if t.FromUserID == 0 || t.ToUserID == 0 {
                 return errors.New("both from_user_id and to_user_id are required for transfers")
             }
+        case TransactionTypeInterest:
+            if (t.FromUserID == 0) == (t.ToUserID == 0) {
+                return errors.New("interest is either paid to to_user_id or charged to from_user_id")
+            }
+        case TransactionTypeConversion:
+            if t.FromUserID == 0 || t.ToUserID == 0 {
+                return errors.New("both from_user_id and to_user_id are required for conversions")
+            }
+            if t.ToCurrency == t.Currency {
+                return errors.New("a conversion needs two different currencies")
+            }
+            if err := ValidateAmount(t.ToAmount, t.ToCurrency); err != nil {
+                return err
+            }
         default:
             return errors.New("invalid transaction type")
     }
 
-    if t.Amount <= 0 {
-        return errors.New("amount must be positive")
-    }
-
-    return nil
+    return ValidateAmount(t.Amount, t.Currency)
 } 
\ No newline at end of file
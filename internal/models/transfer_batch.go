@@ -0,0 +1,92 @@
+package models
+
+import (
+    "errors"
+    "fmt"
+    "time"
+)
+
+type BatchMode string
+type BatchStatus string
+type BatchItemStatus string
+
+const (
+    // All-or-nothing batches apply every transfer in one storage
+    // transaction; best-effort batches apply each transfer on its own.
+    BatchModeAllOrNothing BatchMode = "all_or_nothing"
+    BatchModeBestEffort   BatchMode = "best_effort"
+
+    BatchStatusProcessing         BatchStatus = "processing"
+    BatchStatusCompleted          BatchStatus = "completed"
+    BatchStatusPartiallyCompleted BatchStatus = "partially_completed"
+    BatchStatusFailed             BatchStatus = "failed"
+
+    BatchItemPending   BatchItemStatus = "pending"
+    BatchItemCompleted BatchItemStatus = "completed"
+    BatchItemFailed    BatchItemStatus = "failed"
+    // Not executed because another item of an all-or-nothing batch failed
+    BatchItemNotExecuted BatchItemStatus = "not_executed"
+)
+
+// TransferBatch is a set of transfers submitted together under an ID chosen
+// by the client, which makes resubmitting the same batch harmless.
+type TransferBatch struct {
+    ID             uint        `json:"id"`
+    ClientBatchID  string      `json:"client_batch_id"`
+    Mode           BatchMode   `json:"mode"`
+    Status         BatchStatus `json:"status"`
+    ItemCount      int         `json:"item_count"`
+    SucceededCount int         `json:"succeeded_count"`
+    FailedCount    int         `json:"failed_count"`
+    CreatedAt      time.Time   `json:"created_at"`
+    CompletedAt    *time.Time  `json:"completed_at,omitempty"`
+    // Items is loaded separately and is not stored with the batch.
+    Items []*TransferBatchItem `json:"items,omitempty"`
+}
+
+// TransferBatchItem is one transfer of a batch and its outcome.
+type TransferBatchItem struct {
+    BatchID       uint            `json:"-"`
+    Index         int             `json:"index"`
+    FromUserID    uint            `json:"from_user_id"`
+    ToUserID      uint            `json:"to_user_id"`
+    Amount        float64         `json:"amount"`
+    Currency      string          `json:"currency"`
+    Status        BatchItemStatus `json:"status"`
+    TransactionID uint            `json:"transaction_id,omitempty"`
+    Error         string          `json:"error,omitempty"`
+}
+
+// Validate checks the batch itself and the currency of its items; the rest
+// of each item is checked as it is executed so it gets its own result.
+func (b *TransferBatch) Validate(maxItems int) error {
+    if b.ClientBatchID == "" {
+        return errors.New("client_batch_id is required")
+    }
+
+    if len(b.ClientBatchID) > 100 {
+        return errors.New("client_batch_id is longer than 100 characters")
+    }
+
+    switch b.Mode {
+        case BatchModeAllOrNothing, BatchModeBestEffort:
+        default:
+            return errors.New("mode must be all_or_nothing or best_effort")
+    }
+
+    if len(b.Items) == 0 {
+        return errors.New("a batch needs at least one transfer")
+    }
+
+    if maxItems > 0 && len(b.Items) > maxItems {
+        return fmt.Errorf("a batch holds at most %d transfers", maxItems)
+    }
+
+    for i, item := range b.Items {
+        if err := ValidateCurrency(item.Currency); err != nil {
+            return fmt.Errorf("transfer %d: %w", i, err)
+        }
+    }
+
+    return nil
+}
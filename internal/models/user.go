@@ -13,12 +13,19 @@ const (
     RoleAdmin Role = "admin"
 )
 
+// TierStandard is the fee tier users are registered with.
+const TierStandard = "standard"
+
 type User struct {
     ID           uint      `json:"id"`
     Username     string    `json:"username"`
     Email        string    `json:"email"`
     PasswordHash string    `json:"-"`
     Role         Role      `json:"role"`
+    Tier         string    `json:"tier"`
+    // PasswordChangedAt is when the password was last changed, nil if never
+    // since registration.
+    PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
     CreatedAt    time.Time `json:"created_at"`
     UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import "errors"
+
+var ErrSanctionsMatch = errors.New("name matches the sanctions watchlist")
+
+// WatchlistEntry is a sanctioned party from the watchlist file, listed under
+// its name and any aliases.
+type WatchlistEntry struct {
+    ID      string   `json:"id"`
+    Name    string   `json:"name"`
+    Aliases []string `json:"aliases,omitempty"`
+    // Program is the sanctions program or list the party is on.
+    Program string `json:"program,omitempty"`
+}
+
+// WatchlistMatch is a watchlist entry a screened name matched. Name is the
+// entry's name or alias that scored best; Score runs from 0 to 1.
+type WatchlistMatch struct {
+    EntryID string  `json:"entry_id"`
+    Name    string  `json:"name"`
+    Program string  `json:"program,omitempty"`
+    Score   float64 `json:"score"`
+}
+
+// ScreeningResult is the decision of screening a name against the
+// watchlist: allow, review for a possible match, or block for a strong one.
+// Matches are ordered by score, best first.
+type ScreeningResult struct {
+    Name    string           `json:"name"`
+    Outcome FraudOutcome     `json:"outcome"`
+    Matches []WatchlistMatch `json:"matches,omitempty"`
+}
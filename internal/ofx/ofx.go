@@ -0,0 +1,109 @@
+// Package ofx writes Open Financial Exchange 2.x bank statement responses,
+// the format desktop finance software imports (QFX is the same format).
+package ofx
+
+import (
+    "encoding/xml"
+    "time"
+)
+
+// header is the XML prolog and OFX processing instruction of an OFX 2.2 file.
+const header = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// Transaction types
+const (
+    TypeCredit   = "CREDIT"
+    TypeDebit    = "DEBIT"
+    TypeInterest = "INT"
+    TypeFee      = "FEE"
+    TypeTransfer = "XFER"
+)
+
+// maxNameLength is the longest NAME the specification allows.
+const maxNameLength = 32
+
+type Document struct {
+    XMLName xml.Name     `xml:"OFX"`
+    SignOn  SignOn       `xml:"SIGNONMSGSRSV1>SONRS"`
+    Bank    StatementSet `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type Status struct {
+    Code     int    `xml:"CODE"`
+    Severity string `xml:"SEVERITY"`
+}
+
+type SignOn struct {
+    Status     Status `xml:"STATUS"`
+    ServerTime string `xml:"DTSERVER"`
+    Language   string `xml:"LANGUAGE"`
+}
+
+type StatementSet struct {
+    TransactionUID string            `xml:"TRNUID"`
+    Status         Status            `xml:"STATUS"`
+    Statement      StatementResponse `xml:"STMTRS"`
+}
+
+type StatementResponse struct {
+    Currency         string          `xml:"CURDEF"`
+    Account          BankAccount     `xml:"BANKACCTFROM"`
+    Transactions     TransactionList `xml:"BANKTRANLIST"`
+    LedgerBalance    Balance         `xml:"LEDGERBAL"`
+    AvailableBalance *Balance        `xml:"AVAILBAL,omitempty"`
+}
+
+type BankAccount struct {
+    BankID      string `xml:"BANKID"`
+    AccountID   string `xml:"ACCTID"`
+    AccountType string `xml:"ACCTTYPE"`
+}
+
+type TransactionList struct {
+    Start        string        `xml:"DTSTART"`
+    End          string        `xml:"DTEND"`
+    Transactions []Transaction `xml:"STMTTRN"`
+}
+
+// Transaction is a STMTTRN entry. Amount is signed: negative amounts leave
+// the account.
+type Transaction struct {
+    Type   string `xml:"TRNTYPE"`
+    Posted string `xml:"DTPOSTED"`
+    Amount string `xml:"TRNAMT"`
+    FITID  string `xml:"FITID"`
+    Name   string `xml:"NAME,omitempty"`
+    Memo   string `xml:"MEMO,omitempty"`
+}
+
+type Balance struct {
+    Amount string `xml:"BALAMT"`
+    AsOf   string `xml:"DTASOF"`
+}
+
+// OK is the status of a successful response.
+var OK = Status{Code: 0, Severity: "INFO"}
+
+// Time formats t as an OFX date and time in UTC.
+func Time(t time.Time) string {
+    return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// Name shortens name to the length allowed in a NAME element.
+func Name(name string) string {
+    if len(name) > maxNameLength {
+        return name[:maxNameLength]
+    }
+    return name
+}
+
+// Marshal encodes the document with the OFX 2.2 header.
+func (d *Document) Marshal() ([]byte, error) {
+    body, err := xml.MarshalIndent(d, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(header), body...), nil
+}
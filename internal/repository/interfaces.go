@@ -4,6 +4,7 @@ import (
     "context"
     "financial-service/internal/models"
     "errors"
+    "time"
 )
 
 type UserRepository interface {
@@ -17,13 +18,178 @@ type TransactionRepository interface {
     Create(ctx context.Context, tx *models.Transaction) error
     GetByID(ctx context.Context, id uint) (*models.Transaction, error)
     UpdateStatus(ctx context.Context, id uint, status models.TransactionStatus) error
+    // Complete marks a transaction completed, booked to the balances at
+    // bookedAt.
+    Complete(ctx context.Context, id uint, bookedAt time.Time) error
     GetUserTransactions(ctx context.Context, userID uint, limit, offset int) ([]models.Transaction, error)
+    // GetUserTransactionsAfter pages through a user's transactions in ID
+    // order, returning up to limit rows with an ID greater than afterID.
+    GetUserTransactionsAfter(ctx context.Context, userID uint, afterID uint, limit int) ([]models.Transaction, error)
+    // GetUserTransactionsBookedBetween pages in ID order through a user's
+    // transactions booked in [from, to), so only completed ones.
+    GetUserTransactionsBookedBetween(ctx context.Context, userID uint, from, to time.Time, afterID uint, limit int) ([]models.Transaction, error)
+    // SetAccounts records the accounts a transaction was booked to.
+    SetAccounts(ctx context.Context, id uint, fromAccountID, toAccountID uint) error
+    // SumUserTransactions totals the amount and number of transactions in
+    // currency the user initiated since the given time: credits they
+    // received and everything else they sent. Failed, rejected and expired
+    // transactions, fees and interest are left out; an empty txType matches
+    // every other type.
+    SumUserTransactions(ctx context.Context, userID uint, txType models.TransactionType, currency string, since time.Time) (float64, int, error)
+    // HasPaid reports whether a transfer or conversion from fromUserID to
+    // toUserID has completed.
+    HasPaid(ctx context.Context, fromUserID, toUserID uint) (bool, error)
+    // CountNewCounterparties returns how many users userID started paying
+    // since the given time: recipients of its transfers and conversions
+    // since then that no completed one paid before. Failed, rejected and
+    // expired transactions are left out.
+    CountNewCounterparties(ctx context.Context, userID uint, since time.Time) (int, error)
 }
 
+// AccountRepository stores accounts. A user's default account in a currency
+// is the first one opened that is not closed, i.e. the one with the lowest
+// ID.
+type AccountRepository interface {
+    Create(ctx context.Context, account *models.Account) error
+    GetByID(ctx context.Context, id uint) (*models.Account, error)
+    GetDefault(ctx context.Context, userID uint, currency string) (*models.Account, error)
+    ListByUser(ctx context.Context, userID uint) ([]*models.Account, error)
+    // Update saves the account's type, status and update time.
+    Update(ctx context.Context, account *models.Account) error
+    // Delete removes the account with its balance, snapshots and accruals.
+    Delete(ctx context.Context, id uint) error
+    // HasTransactions reports whether any transaction was booked to the
+    // account.
+    HasTransactions(ctx context.Context, id uint) (bool, error)
+}
+
+// Balances are keyed by account. Those looked up by user and ISO 4217
+// currency code are the user's default account in that currency.
 type BalanceRepository interface {
-    GetBalance(ctx context.Context, userID uint) (*models.Balance, error)
+    GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error)
+    GetAccountBalance(ctx context.Context, accountID uint) (*models.Balance, error)
     UpdateBalance(ctx context.Context, balance *models.Balance) error
     CreateBalance(ctx context.Context, balance *models.Balance) error
+    // SetCreditLimit changes how far below zero the balance may go.
+    // UpdateBalance leaves the credit limit untouched.
+    SetCreditLimit(ctx context.Context, accountID uint, creditLimit float64) error
+    // GetUserBalances returns the balance of every account of a user.
+    GetUserBalances(ctx context.Context, userID uint) ([]*models.Balance, error)
+    // ListBalances pages through all balances ordered by account ID,
+    // returning those after afterAccountID.
+    ListBalances(ctx context.Context, afterAccountID uint, limit int) ([]*models.Balance, error)
+}
+
+type BalanceSnapshotRepository interface {
+    Create(ctx context.Context, snapshot *models.BalanceSnapshot) error
+    // GetLatest returns the account's most recent snapshot taken as of asOf
+    // or earlier.
+    GetLatest(ctx context.Context, accountID uint, asOf time.Time) (*models.BalanceSnapshot, error)
+}
+
+type FXRateRepository interface {
+    GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.FXRate, error)
+    // SetRate inserts the pair or replaces its current rate.
+    SetRate(ctx context.Context, rate *models.FXRate) error
+    ListRates(ctx context.Context) ([]*models.FXRate, error)
+}
+
+type FXQuoteRepository interface {
+    Create(ctx context.Context, quote *models.FXQuote) error
+    GetByID(ctx context.Context, id uint) (*models.FXQuote, error)
+    // MarkExecuted links an unexecuted quote to the transaction that used it.
+    // It returns ErrNotFound if the quote does not exist or was already
+    // executed.
+    MarkExecuted(ctx context.Context, id, transactionID uint) error
+}
+
+// LimitRepository stores per-user overrides of the default transaction
+// limits, keyed by user, transaction type and currency.
+type LimitRepository interface {
+    GetUserLimits(ctx context.Context, userID uint) ([]*models.TransactionLimit, error)
+    // SetUserLimit inserts the override or replaces the existing one.
+    SetUserLimit(ctx context.Context, limit *models.TransactionLimit) error
+    DeleteUserLimit(ctx context.Context, userID uint, txType models.TransactionType, currency string) error
+}
+
+// InterestRepository stores daily interest accruals, keyed by account and
+// day, and the days the accrual job has completed.
+type InterestRepository interface {
+    CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error
+    // GetUnposted returns the account's accruals dated before the given day
+    // that no interest transaction has paid out yet.
+    GetUnposted(ctx context.Context, accountID uint, before time.Time) ([]*models.InterestAccrual, error)
+    // MarkPosted links those accruals to the transaction that paid them out.
+    MarkPosted(ctx context.Context, accountID uint, before time.Time, transactionID uint) error
+    // GetLastRun returns the latest day accrued for every balance, or
+    // ErrNotFound before the first run.
+    GetLastRun(ctx context.Context) (time.Time, error)
+    CreateRun(ctx context.Context, date time.Time) error
+}
+
+// ScheduledTransferRepository stores standing orders together with one run
+// per occurrence executed.
+type ScheduledTransferRepository interface {
+    Create(ctx context.Context, schedule *models.ScheduledTransfer) error
+    GetByID(ctx context.Context, id uint) (*models.ScheduledTransfer, error)
+    Update(ctx context.Context, schedule *models.ScheduledTransfer) error
+    // ListByUser returns the schedules the user pays from or into.
+    ListByUser(ctx context.Context, userID uint) ([]*models.ScheduledTransfer, error)
+    // ListDue returns up to limit active schedules due at or before now,
+    // earliest first.
+    ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error)
+    // CreateRun returns ErrDuplicateKey if the occurrence already has a run.
+    CreateRun(ctx context.Context, run *models.ScheduledTransferRun) error
+    GetRun(ctx context.Context, scheduleID uint, occurrence time.Time) (*models.ScheduledTransferRun, error)
+    // GetRunByTransaction returns the run that made the transaction.
+    GetRunByTransaction(ctx context.Context, transactionID uint) (*models.ScheduledTransferRun, error)
+    UpdateRun(ctx context.Context, run *models.ScheduledTransferRun) error
+    // ClaimRetry moves a retrying run back to pending. It returns
+    // ErrNotFound if the run is not waiting to be retried, so only one
+    // caller gets to retry it.
+    ClaimRetry(ctx context.Context, scheduleID uint, occurrence time.Time) error
+    ListRuns(ctx context.Context, scheduleID uint) ([]*models.ScheduledTransferRun, error)
+}
+
+// TransferBatchRepository stores bulk transfer batches and their items.
+type TransferBatchRepository interface {
+    // Create returns ErrDuplicateKey if the client batch ID is taken.
+    Create(ctx context.Context, batch *models.TransferBatch) error
+    GetByClientID(ctx context.Context, clientBatchID string) (*models.TransferBatch, error)
+    // Update saves the batch's status, counts and completion time.
+    Update(ctx context.Context, batch *models.TransferBatch) error
+    CreateItem(ctx context.Context, item *models.TransferBatchItem) error
+    // UpdateItem saves the item's status, transaction and error.
+    UpdateItem(ctx context.Context, item *models.TransferBatchItem) error
+    GetItems(ctx context.Context, batchID uint) ([]*models.TransferBatchItem, error)
+}
+
+// ImportRepository stores imported files and their rows.
+type ImportRepository interface {
+    // Create returns ErrDuplicateKey if a file with the same hash was
+    // imported before.
+    Create(ctx context.Context, imp *models.Import) error
+    GetByID(ctx context.Context, id uint) (*models.Import, error)
+    GetByHash(ctx context.Context, fileHash string) (*models.Import, error)
+    // Update saves the import's status, counts and completion time.
+    Update(ctx context.Context, imp *models.Import) error
+    CreateRow(ctx context.Context, row *models.ImportRow) error
+    // UpdateRow saves the row's status, transaction and error.
+    UpdateRow(ctx context.Context, row *models.ImportRow) error
+    GetRows(ctx context.Context, importID uint) ([]*models.ImportRow, error)
+}
+
+// ApprovalRepository stores the approvals of transactions held for a second
+// person's approval, keyed by transaction.
+type ApprovalRepository interface {
+    Create(ctx context.Context, approval *models.Approval) error
+    GetByTransactionID(ctx context.Context, transactionID uint) (*models.Approval, error)
+    // ListPending returns the undecided approvals, oldest first.
+    ListPending(ctx context.Context) ([]*models.Approval, error)
+    // Decide saves the approval's status, decider, decision time and note.
+    // It returns ErrNotFound if the approval is not pending any more, so only
+    // one caller gets to decide it.
+    Decide(ctx context.Context, approval *models.Approval) error
 }
 
 type AuditLogRepository interface {
@@ -31,6 +197,17 @@ type AuditLogRepository interface {
     GetByEntityID(ctx context.Context, entityType string, entityID uint) ([]*models.AuditLog, error)
 }
 
+// Transactor runs fn inside a single storage transaction. Repository calls
+// made with the ctx passed to fn take part in it and are rolled back together
+// when fn returns an error.
+//
+// Every repository call made while fn runs must use that ctx. The memory store
+// holds its lock, and SQLite its only connection, until fn returns, so a call
+// made with a ctx that does not carry the transaction waits on fn forever.
+type Transactor interface {
+    WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // Custom errors
 var (
     ErrNotFound      = errors.New("record not found")
@@ -0,0 +1,163 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type AccountRepository struct {
+    store *Store
+}
+
+func NewAccountRepository(store *Store) *AccountRepository {
+    return &AccountRepository{store: store}
+}
+
+// cloneAccount copies the stored part of an account, leaving out its
+// balance.
+func cloneAccount(a *models.Account) *models.Account {
+    c := *a
+    c.Balance = nil
+    return &c
+}
+
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[account.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    r.store.nextAcctID++
+    account.ID = r.store.nextAcctID
+
+    r.store.accounts[account.ID] = cloneAccount(account)
+
+    id := account.ID
+    tx.record(func() {
+        delete(r.store.accounts, id)
+    })
+
+    return nil
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    account, ok := r.store.accounts[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneAccount(account), nil
+}
+
+func (r *AccountRepository) GetDefault(ctx context.Context, userID uint, currency string) (*models.Account, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    id, ok := r.store.defaultAccount(userID, currency)
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneAccount(r.store.accounts[id]), nil
+}
+
+func (r *AccountRepository) ListByUser(ctx context.Context, userID uint) ([]*models.Account, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var accounts []*models.Account
+    for _, account := range r.store.accounts {
+        if account.UserID == userID {
+            accounts = append(accounts, cloneAccount(account))
+        }
+    }
+
+    sort.Slice(accounts, func(i, j int) bool {
+        return accounts[i].ID < accounts[j].ID
+    })
+
+    return accounts, nil
+}
+
+func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.accounts[account.ID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    // As in SQL, the owner and currency are fixed at creation
+    updated := cloneAccount(existing)
+    updated.Type = account.Type
+    updated.Status = account.Status
+    updated.UpdatedAt = account.UpdatedAt
+    r.store.accounts[account.ID] = updated
+
+    tx.record(func() {
+        r.store.accounts[account.ID] = existing
+    })
+
+    return nil
+}
+
+func (r *AccountRepository) Delete(ctx context.Context, id uint) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    account, ok := r.store.accounts[id]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    balance, hasBalance := r.store.balances[id]
+    snapshots := r.store.snapshots[id]
+
+    accruals := make(map[accrualKey]*models.InterestAccrual)
+    for key, accrual := range r.store.accruals {
+        if key.accountID == id {
+            accruals[key] = accrual
+            delete(r.store.accruals, key)
+        }
+    }
+
+    delete(r.store.accounts, id)
+    delete(r.store.balances, id)
+    delete(r.store.snapshots, id)
+
+    tx.record(func() {
+        r.store.accounts[id] = account
+        if hasBalance {
+            r.store.balances[id] = balance
+        }
+        if snapshots != nil {
+            r.store.snapshots[id] = snapshots
+        }
+        for key, accrual := range accruals {
+            r.store.accruals[key] = accrual
+        }
+    })
+
+    return nil
+}
+
+func (r *AccountRepository) HasTransactions(ctx context.Context, id uint) (bool, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, tx := range r.store.transactions {
+        if tx.FromAccountID == id || tx.ToAccountID == id {
+            return true, nil
+        }
+    }
+
+    return false, nil
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type ApprovalRepository struct {
+    store *Store
+}
+
+func NewApprovalRepository(store *Store) *ApprovalRepository {
+    return &ApprovalRepository{store: store}
+}
+
+// cloneApproval copies the stored part of an approval, leaving out its
+// transaction.
+func cloneApproval(a *models.Approval) *models.Approval {
+    c := *a
+    c.Transaction = nil
+    if a.DecidedAt != nil {
+        decidedAt := *a.DecidedAt
+        c.DecidedAt = &decidedAt
+    }
+    return &c
+}
+
+func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approval) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.transactions[approval.TransactionID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    if _, ok := r.store.approvals[approval.TransactionID]; ok {
+        return repository.ErrDuplicateKey
+    }
+
+    r.store.approvals[approval.TransactionID] = cloneApproval(approval)
+
+    id := approval.TransactionID
+    tx.record(func() {
+        delete(r.store.approvals, id)
+    })
+
+    return nil
+}
+
+func (r *ApprovalRepository) GetByTransactionID(ctx context.Context, transactionID uint) (*models.Approval, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    approval, ok := r.store.approvals[transactionID]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneApproval(approval), nil
+}
+
+func (r *ApprovalRepository) ListPending(ctx context.Context) ([]*models.Approval, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var approvals []*models.Approval
+    for _, approval := range r.store.approvals {
+        if approval.Status == models.ApprovalPending {
+            approvals = append(approvals, cloneApproval(approval))
+        }
+    }
+
+    sort.Slice(approvals, func(i, j int) bool {
+        return approvals[i].TransactionID < approvals[j].TransactionID
+    })
+
+    return approvals, nil
+}
+
+func (r *ApprovalRepository) Decide(ctx context.Context, approval *models.Approval) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.approvals[approval.TransactionID]
+    if !ok || existing.Status != models.ApprovalPending {
+        return repository.ErrNotFound
+    }
+
+    updated := cloneApproval(existing)
+    updated.Status = approval.Status
+    updated.DecidedBy = approval.DecidedBy
+    updated.DecidedByID = approval.DecidedByID
+    updated.DecidedAt = approval.DecidedAt
+    updated.Note = approval.Note
+    r.store.approvals[approval.TransactionID] = updated
+
+    tx.record(func() {
+        r.store.approvals[approval.TransactionID] = existing
+    })
+
+    return nil
+}
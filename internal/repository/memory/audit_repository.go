@@ -0,0 +1,49 @@
+package memory
+
+import (
+    "context"
+    "financial-service/internal/models"
+)
+
+type AuditLogRepository struct {
+    store *Store
+}
+
+func NewAuditLogRepository(store *Store) *AuditLogRepository {
+    return &AuditLogRepository{store: store}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    r.store.nextAuditID++
+    log.ID = r.store.nextAuditID
+
+    r.store.auditLogs = append(r.store.auditLogs, cloneAuditLog(log))
+
+    count := len(r.store.auditLogs) - 1
+    tx.record(func() {
+        r.store.auditLogs = r.store.auditLogs[:count]
+    })
+
+    return nil
+}
+
+func (r *AuditLogRepository) GetByEntityID(ctx context.Context, entityType string, entityID uint) ([]*models.AuditLog, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var logs []*models.AuditLog
+
+    // Entries are appended in creation order, so walking backwards yields
+    // the newest first like the SQL implementation.
+    for i := len(r.store.auditLogs) - 1; i >= 0; i-- {
+        log := r.store.auditLogs[i]
+        if log.EntityType == entityType && log.EntityID == entityID {
+            logs = append(logs, cloneAuditLog(log))
+        }
+    }
+
+    return logs, nil
+}
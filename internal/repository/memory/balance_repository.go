@@ -0,0 +1,162 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type BalanceRepository struct {
+    store *Store
+}
+
+func NewBalanceRepository(store *Store) *BalanceRepository {
+    return &BalanceRepository{store: store}
+}
+
+func (r *BalanceRepository) GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    accountID, ok := r.store.defaultAccount(userID, currency)
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    balance, ok := r.store.balances[accountID]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneBalance(balance), nil
+}
+
+func (r *BalanceRepository) GetAccountBalance(ctx context.Context, accountID uint) (*models.Balance, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    balance, ok := r.store.balances[accountID]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneBalance(balance), nil
+}
+
+func (r *BalanceRepository) UpdateBalance(ctx context.Context, balance *models.Balance) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    key := balance.AccountID
+
+    existing, ok := r.store.balances[key]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    previous := cloneBalance(existing)
+
+    // As in SQL, the credit limit is only changed by SetCreditLimit and the
+    // owner and currency never change
+    updated := cloneBalance(balance)
+    updated.UserID = existing.UserID
+    updated.Currency = existing.Currency
+    updated.CreditLimit = existing.CreditLimit
+    r.store.balances[key] = updated
+
+    tx.record(func() {
+        r.store.balances[key] = previous
+    })
+
+    return nil
+}
+
+func (r *BalanceRepository) CreateBalance(ctx context.Context, balance *models.Balance) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[balance.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    if _, ok := r.store.accounts[balance.AccountID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    key := balance.AccountID
+
+    if _, exists := r.store.balances[key]; exists {
+        return repository.ErrDuplicateKey
+    }
+
+    r.store.balances[key] = cloneBalance(balance)
+
+    tx.record(func() {
+        delete(r.store.balances, key)
+    })
+
+    return nil
+}
+
+func (r *BalanceRepository) SetCreditLimit(ctx context.Context, accountID uint, creditLimit float64) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    balance, ok := r.store.balances[accountID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    previous := balance.CreditLimit
+    balance.CreditLimit = creditLimit
+
+    tx.record(func() {
+        r.store.balances[accountID].CreditLimit = previous
+    })
+
+    return nil
+}
+
+func (r *BalanceRepository) GetUserBalances(ctx context.Context, userID uint) ([]*models.Balance, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var balances []*models.Balance
+    for _, balance := range r.store.balances {
+        if balance.UserID == userID {
+            balances = append(balances, cloneBalance(balance))
+        }
+    }
+
+    sort.Slice(balances, func(i, j int) bool {
+        if balances[i].Currency != balances[j].Currency {
+            return balances[i].Currency < balances[j].Currency
+        }
+        return balances[i].AccountID < balances[j].AccountID
+    })
+
+    return balances, nil
+}
+
+func (r *BalanceRepository) ListBalances(ctx context.Context, afterAccountID uint, limit int) ([]*models.Balance, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var balances []*models.Balance
+    for key, balance := range r.store.balances {
+        if key > afterAccountID {
+            balances = append(balances, cloneBalance(balance))
+        }
+    }
+
+    sort.Slice(balances, func(i, j int) bool {
+        return balances[i].AccountID < balances[j].AccountID
+    })
+
+    if limit < len(balances) {
+        balances = balances[:limit]
+    }
+
+    return balances, nil
+}
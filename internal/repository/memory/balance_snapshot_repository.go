@@ -0,0 +1,61 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type BalanceSnapshotRepository struct {
+    store *Store
+}
+
+func NewBalanceSnapshotRepository(store *Store) *BalanceSnapshotRepository {
+    return &BalanceSnapshotRepository{store: store}
+}
+
+func (r *BalanceSnapshotRepository) Create(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[snapshot.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    previous := r.store.snapshots[snapshot.AccountID]
+    for _, existing := range previous {
+        if existing.AsOf.Equal(snapshot.AsOf) {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    c := *snapshot
+    snapshots := append(append([]*models.BalanceSnapshot{}, previous...), &c)
+    sort.Slice(snapshots, func(i, j int) bool {
+        return snapshots[i].AsOf.Before(snapshots[j].AsOf)
+    })
+    r.store.snapshots[snapshot.AccountID] = snapshots
+
+    tx.record(func() {
+        r.store.snapshots[snapshot.AccountID] = previous
+    })
+
+    return nil
+}
+
+func (r *BalanceSnapshotRepository) GetLatest(ctx context.Context, accountID uint, asOf time.Time) (*models.BalanceSnapshot, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    snapshots := r.store.snapshots[accountID]
+    for i := len(snapshots) - 1; i >= 0; i-- {
+        if !snapshots[i].AsOf.After(asOf) {
+            c := *snapshots[i]
+            return &c, nil
+        }
+    }
+
+    return nil, repository.ErrNotFound
+}
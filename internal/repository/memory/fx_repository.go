@@ -0,0 +1,131 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type FXRateRepository struct {
+    store *Store
+}
+
+func NewFXRateRepository(store *Store) *FXRateRepository {
+    return &FXRateRepository{store: store}
+}
+
+func (r *FXRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.FXRate, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    rate, ok := r.store.fxRates[currencyPair{baseCurrency, quoteCurrency}]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    c := *rate
+    return &c, nil
+}
+
+func (r *FXRateRepository) SetRate(ctx context.Context, rate *models.FXRate) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    key := currencyPair{rate.BaseCurrency, rate.QuoteCurrency}
+    previous, existed := r.store.fxRates[key]
+
+    c := *rate
+    r.store.fxRates[key] = &c
+
+    tx.record(func() {
+        if existed {
+            r.store.fxRates[key] = previous
+        } else {
+            delete(r.store.fxRates, key)
+        }
+    })
+
+    return nil
+}
+
+func (r *FXRateRepository) ListRates(ctx context.Context) ([]*models.FXRate, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    rates := make([]*models.FXRate, 0, len(r.store.fxRates))
+    for _, rate := range r.store.fxRates {
+        c := *rate
+        rates = append(rates, &c)
+    }
+
+    sort.Slice(rates, func(i, j int) bool {
+        if rates[i].BaseCurrency != rates[j].BaseCurrency {
+            return rates[i].BaseCurrency < rates[j].BaseCurrency
+        }
+        return rates[i].QuoteCurrency < rates[j].QuoteCurrency
+    })
+
+    return rates, nil
+}
+
+type FXQuoteRepository struct {
+    store *Store
+}
+
+func NewFXQuoteRepository(store *Store) *FXQuoteRepository {
+    return &FXQuoteRepository{store: store}
+}
+
+func (r *FXQuoteRepository) Create(ctx context.Context, quote *models.FXQuote) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[quote.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    r.store.nextQuoteID++
+    quote.ID = r.store.nextQuoteID
+
+    c := *quote
+    r.store.fxQuotes[quote.ID] = &c
+
+    id := quote.ID
+    tx.record(func() {
+        delete(r.store.fxQuotes, id)
+    })
+
+    return nil
+}
+
+func (r *FXQuoteRepository) GetByID(ctx context.Context, id uint) (*models.FXQuote, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    quote, ok := r.store.fxQuotes[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    c := *quote
+    return &c, nil
+}
+
+func (r *FXQuoteRepository) MarkExecuted(ctx context.Context, id, transactionID uint) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    quote, ok := r.store.fxQuotes[id]
+    if !ok || quote.TransactionID != 0 {
+        return repository.ErrNotFound
+    }
+
+    quote.TransactionID = transactionID
+
+    tx.record(func() {
+        quote.TransactionID = 0
+    })
+
+    return nil
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type ImportRepository struct {
+    store *Store
+}
+
+func NewImportRepository(store *Store) *ImportRepository {
+    return &ImportRepository{store: store}
+}
+
+// cloneImport copies the stored part of an import, leaving out its rows.
+func cloneImport(imp *models.Import) *models.Import {
+    c := *imp
+    c.Rows = nil
+    if imp.CompletedAt != nil {
+        completedAt := *imp.CompletedAt
+        c.CompletedAt = &completedAt
+    }
+    return &c
+}
+
+func (r *ImportRepository) Create(ctx context.Context, imp *models.Import) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, existing := range r.store.imports {
+        if existing.FileHash == imp.FileHash {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    r.store.nextImportID++
+    imp.ID = r.store.nextImportID
+
+    r.store.imports[imp.ID] = cloneImport(imp)
+
+    id := imp.ID
+    tx.record(func() {
+        delete(r.store.imports, id)
+    })
+
+    return nil
+}
+
+func (r *ImportRepository) GetByID(ctx context.Context, id uint) (*models.Import, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    imp, ok := r.store.imports[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneImport(imp), nil
+}
+
+func (r *ImportRepository) GetByHash(ctx context.Context, fileHash string) (*models.Import, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, imp := range r.store.imports {
+        if imp.FileHash == fileHash {
+            return cloneImport(imp), nil
+        }
+    }
+
+    return nil, repository.ErrNotFound
+}
+
+func (r *ImportRepository) Update(ctx context.Context, imp *models.Import) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.imports[imp.ID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    updated := cloneImport(existing)
+    updated.Status = imp.Status
+    updated.ExecutedCount = imp.ExecutedCount
+    updated.FailedCount = imp.FailedCount
+    updated.CompletedAt = cloneImport(imp).CompletedAt
+
+    r.store.imports[imp.ID] = updated
+
+    id := imp.ID
+    tx.record(func() {
+        r.store.imports[id] = existing
+    })
+
+    return nil
+}
+
+func (r *ImportRepository) CreateRow(ctx context.Context, row *models.ImportRow) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.imports[row.ImportID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    for _, existing := range r.store.importRows[row.ImportID] {
+        if existing.Line == row.Line {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    c := *row
+    r.store.importRows[row.ImportID] = append(r.store.importRows[row.ImportID], &c)
+
+    importID := row.ImportID
+    tx.record(func() {
+        rows := r.store.importRows[importID]
+        r.store.importRows[importID] = rows[:len(rows)-1]
+    })
+
+    return nil
+}
+
+func (r *ImportRepository) UpdateRow(ctx context.Context, row *models.ImportRow) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for i, existing := range r.store.importRows[row.ImportID] {
+        if existing.Line != row.Line {
+            continue
+        }
+
+        updated := *existing
+        updated.Status = row.Status
+        updated.TransactionID = row.TransactionID
+        updated.Error = row.Error
+
+        rows := r.store.importRows[row.ImportID]
+        rows[i] = &updated
+
+        previous := existing
+        tx.record(func() {
+            rows[i] = previous
+        })
+
+        return nil
+    }
+
+    return repository.ErrNotFound
+}
+
+func (r *ImportRepository) GetRows(ctx context.Context, importID uint) ([]*models.ImportRow, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var rows []*models.ImportRow
+    for _, row := range r.store.importRows[importID] {
+        c := *row
+        rows = append(rows, &c)
+    }
+
+    sort.Slice(rows, func(i, j int) bool {
+        return rows[i].Line < rows[j].Line
+    })
+
+    return rows, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type InterestRepository struct {
+    store *Store
+}
+
+func NewInterestRepository(store *Store) *InterestRepository {
+    return &InterestRepository{store: store}
+}
+
+func (r *InterestRepository) CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[accrual.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    key := accrualKey{accrual.AccountID, accrual.Date.Unix()}
+    if _, exists := r.store.accruals[key]; exists {
+        return repository.ErrDuplicateKey
+    }
+
+    c := *accrual
+    r.store.accruals[key] = &c
+
+    tx.record(func() {
+        delete(r.store.accruals, key)
+    })
+
+    return nil
+}
+
+func (r *InterestRepository) GetUnposted(ctx context.Context, accountID uint, before time.Time) ([]*models.InterestAccrual, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var accruals []*models.InterestAccrual
+    for key, accrual := range r.store.accruals {
+        if key.accountID == accountID && accrual.Date.Before(before) && accrual.TransactionID == 0 {
+            c := *accrual
+            accruals = append(accruals, &c)
+        }
+    }
+
+    sort.Slice(accruals, func(i, j int) bool {
+        return accruals[i].Date.Before(accruals[j].Date)
+    })
+
+    return accruals, nil
+}
+
+func (r *InterestRepository) MarkPosted(ctx context.Context, accountID uint, before time.Time, transactionID uint) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for key, accrual := range r.store.accruals {
+        if key.accountID != accountID || !accrual.Date.Before(before) || accrual.TransactionID != 0 {
+            continue
+        }
+
+        accrual.TransactionID = transactionID
+
+        posted := accrual
+        tx.record(func() {
+            posted.TransactionID = 0
+        })
+    }
+
+    return nil
+}
+
+func (r *InterestRepository) GetLastRun(ctx context.Context) (time.Time, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var last time.Time
+    for _, date := range r.store.interestRuns {
+        if date.After(last) {
+            last = date
+        }
+    }
+
+    if last.IsZero() {
+        return time.Time{}, repository.ErrNotFound
+    }
+
+    return last, nil
+}
+
+func (r *InterestRepository) CreateRun(ctx context.Context, date time.Time) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, existing := range r.store.interestRuns {
+        if existing.Equal(date) {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    r.store.interestRuns = append(r.store.interestRuns, date)
+
+    tx.record(func() {
+        r.store.interestRuns = r.store.interestRuns[:len(r.store.interestRuns)-1]
+    })
+
+    return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type LimitRepository struct {
+    store *Store
+}
+
+func NewLimitRepository(store *Store) *LimitRepository {
+    return &LimitRepository{store: store}
+}
+
+func (r *LimitRepository) GetUserLimits(ctx context.Context, userID uint) ([]*models.TransactionLimit, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var limits []*models.TransactionLimit
+    for key, limit := range r.store.limits {
+        if key.userID == userID {
+            c := *limit
+            limits = append(limits, &c)
+        }
+    }
+
+    sort.Slice(limits, func(i, j int) bool {
+        if limits[i].Type != limits[j].Type {
+            return limits[i].Type < limits[j].Type
+        }
+        return limits[i].Currency < limits[j].Currency
+    })
+
+    return limits, nil
+}
+
+func (r *LimitRepository) SetUserLimit(ctx context.Context, limit *models.TransactionLimit) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[limit.UserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    key := limitKey{limit.UserID, limit.Type, limit.Currency}
+    previous, existed := r.store.limits[key]
+
+    c := *limit
+    r.store.limits[key] = &c
+
+    tx.record(func() {
+        if existed {
+            r.store.limits[key] = previous
+        } else {
+            delete(r.store.limits, key)
+        }
+    })
+
+    return nil
+}
+
+func (r *LimitRepository) DeleteUserLimit(ctx context.Context, userID uint, txType models.TransactionType, currency string) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    key := limitKey{userID, txType, currency}
+    previous, ok := r.store.limits[key]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    delete(r.store.limits, key)
+
+    tx.record(func() {
+        r.store.limits[key] = previous
+    })
+
+    return nil
+}
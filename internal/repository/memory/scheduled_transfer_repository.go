@@ -0,0 +1,251 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type ScheduledTransferRepository struct {
+    store *Store
+}
+
+func NewScheduledTransferRepository(store *Store) *ScheduledTransferRepository {
+    return &ScheduledTransferRepository{store: store}
+}
+
+func cloneSchedule(s *models.ScheduledTransfer) *models.ScheduledTransfer {
+    c := *s
+    if s.EndDate != nil {
+        endDate := *s.EndDate
+        c.EndDate = &endDate
+    }
+    return &c
+}
+
+func (r *ScheduledTransferRepository) Create(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.users[schedule.FromUserID]; !ok {
+        return repository.ErrInvalidData
+    }
+    if _, ok := r.store.users[schedule.ToUserID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    r.store.nextSchedID++
+    schedule.ID = r.store.nextSchedID
+
+    r.store.schedules[schedule.ID] = cloneSchedule(schedule)
+
+    id := schedule.ID
+    tx.record(func() {
+        delete(r.store.schedules, id)
+    })
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) GetByID(ctx context.Context, id uint) (*models.ScheduledTransfer, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    schedule, ok := r.store.schedules[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneSchedule(schedule), nil
+}
+
+func (r *ScheduledTransferRepository) Update(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.schedules[schedule.ID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    // As in SQL, the parties, currency and timing are fixed at creation
+    updated := cloneSchedule(existing)
+    updated.Amount = schedule.Amount
+    updated.EndDate = cloneSchedule(schedule).EndDate
+    updated.NextRunAt = schedule.NextRunAt
+    updated.DueAt = schedule.DueAt
+    updated.OnInsufficientFunds = schedule.OnInsufficientFunds
+    updated.Status = schedule.Status
+    updated.UpdatedAt = schedule.UpdatedAt
+
+    r.store.schedules[schedule.ID] = updated
+
+    id := schedule.ID
+    tx.record(func() {
+        r.store.schedules[id] = existing
+    })
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ListByUser(ctx context.Context, userID uint) ([]*models.ScheduledTransfer, error) {
+    return r.list(ctx, func(s *models.ScheduledTransfer) bool {
+        return s.FromUserID == userID || s.ToUserID == userID
+    }, func(a, b *models.ScheduledTransfer) bool {
+        return a.ID < b.ID
+    }, 0)
+}
+
+func (r *ScheduledTransferRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+    return r.list(ctx, func(s *models.ScheduledTransfer) bool {
+        return s.Status == models.ScheduleStatusActive && !s.DueAt.After(now)
+    }, func(a, b *models.ScheduledTransfer) bool {
+        if !a.DueAt.Equal(b.DueAt) {
+            return a.DueAt.Before(b.DueAt)
+        }
+        return a.ID < b.ID
+    }, limit)
+}
+
+// list returns the matching schedules in order, at most limit of them unless
+// limit is zero.
+func (r *ScheduledTransferRepository) list(
+    ctx context.Context,
+    match func(*models.ScheduledTransfer) bool,
+    less func(a, b *models.ScheduledTransfer) bool,
+    limit int,
+) ([]*models.ScheduledTransfer, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var schedules []*models.ScheduledTransfer
+    for _, schedule := range r.store.schedules {
+        if match(schedule) {
+            schedules = append(schedules, cloneSchedule(schedule))
+        }
+    }
+
+    sort.Slice(schedules, func(i, j int) bool {
+        return less(schedules[i], schedules[j])
+    })
+
+    if limit > 0 && limit < len(schedules) {
+        schedules = schedules[:limit]
+    }
+
+    return schedules, nil
+}
+
+func (r *ScheduledTransferRepository) CreateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.schedules[run.ScheduleID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    key := runKey{run.ScheduleID, run.Occurrence.UnixNano()}
+    if _, exists := r.store.scheduleRuns[key]; exists {
+        return repository.ErrDuplicateKey
+    }
+
+    c := *run
+    r.store.scheduleRuns[key] = &c
+
+    tx.record(func() {
+        delete(r.store.scheduleRuns, key)
+    })
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) GetRun(ctx context.Context, scheduleID uint, occurrence time.Time) (*models.ScheduledTransferRun, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    run, ok := r.store.scheduleRuns[runKey{scheduleID, occurrence.UnixNano()}]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    c := *run
+    return &c, nil
+}
+
+func (r *ScheduledTransferRepository) GetRunByTransaction(ctx context.Context, transactionID uint) (*models.ScheduledTransferRun, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, run := range r.store.scheduleRuns {
+        if run.TransactionID == transactionID {
+            c := *run
+            return &c, nil
+        }
+    }
+
+    return nil, repository.ErrNotFound
+}
+
+func (r *ScheduledTransferRepository) UpdateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    key := runKey{run.ScheduleID, run.Occurrence.UnixNano()}
+
+    existing, ok := r.store.scheduleRuns[key]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    c := *run
+    r.store.scheduleRuns[key] = &c
+
+    tx.record(func() {
+        r.store.scheduleRuns[key] = existing
+    })
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ClaimRetry(ctx context.Context, scheduleID uint, occurrence time.Time) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    key := runKey{scheduleID, occurrence.UnixNano()}
+
+    existing, ok := r.store.scheduleRuns[key]
+    if !ok || existing.Status != models.ScheduledRunRetrying {
+        return repository.ErrNotFound
+    }
+
+    c := *existing
+    c.Status = models.ScheduledRunPending
+    r.store.scheduleRuns[key] = &c
+
+    tx.record(func() {
+        r.store.scheduleRuns[key] = existing
+    })
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ListRuns(ctx context.Context, scheduleID uint) ([]*models.ScheduledTransferRun, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var runs []*models.ScheduledTransferRun
+    for key, run := range r.store.scheduleRuns {
+        if key.scheduleID == scheduleID {
+            c := *run
+            runs = append(runs, &c)
+        }
+    }
+
+    sort.Slice(runs, func(i, j int) bool {
+        return runs[i].Occurrence.Before(runs[j].Occurrence)
+    })
+
+    return runs, nil
+}
@@ -0,0 +1,227 @@
+package memory
+
+import (
+    "context"
+    "sync"
+    "time"
+    "financial-service/internal/models"
+)
+
+// Store holds every table of the in-memory backend. All repositories built on
+// the same Store share its data and its lock, so a transaction started through
+// WithinTransaction covers writes made by any of them.
+type Store struct {
+    mu           sync.Mutex
+    users        map[uint]*models.User
+    emails       map[string]uint
+    accounts     map[uint]*models.Account
+    // balances and snapshots are keyed by account ID
+    balances     map[uint]*models.Balance
+    transactions map[uint]*models.Transaction
+    auditLogs    []*models.AuditLog
+    snapshots    map[uint][]*models.BalanceSnapshot
+    fxRates      map[currencyPair]*models.FXRate
+    fxQuotes     map[uint]*models.FXQuote
+    limits       map[limitKey]*models.TransactionLimit
+    accruals     map[accrualKey]*models.InterestAccrual
+    interestRuns []time.Time
+    schedules    map[uint]*models.ScheduledTransfer
+    scheduleRuns map[runKey]*models.ScheduledTransferRun
+    batches      map[uint]*models.TransferBatch
+    batchItems   map[uint][]*models.TransferBatchItem
+    imports      map[uint]*models.Import
+    importRows   map[uint][]*models.ImportRow
+    // approvals are keyed by transaction ID
+    approvals    map[uint]*models.Approval
+    nextUserID   uint
+    nextAcctID   uint
+    nextTxID     uint
+    nextAuditID  uint
+    nextQuoteID  uint
+    nextSchedID  uint
+    nextBatchID  uint
+    nextImportID uint
+}
+
+type currencyPair struct {
+    base  string
+    quote string
+}
+
+type limitKey struct {
+    userID   uint
+    txType   models.TransactionType
+    currency string
+}
+
+type accrualKey struct {
+    accountID uint
+    date      int64
+}
+
+type runKey struct {
+    scheduleID uint
+    occurrence int64
+}
+
+type txKey struct{}
+
+type txState struct {
+    store *Store
+    undo  []func()
+}
+
+func NewStore() *Store {
+    return &Store{
+        users:        make(map[uint]*models.User),
+        emails:       make(map[string]uint),
+        accounts:     make(map[uint]*models.Account),
+        balances:     make(map[uint]*models.Balance),
+        transactions: make(map[uint]*models.Transaction),
+        snapshots:    make(map[uint][]*models.BalanceSnapshot),
+        fxRates:      make(map[currencyPair]*models.FXRate),
+        fxQuotes:     make(map[uint]*models.FXQuote),
+        limits:       make(map[limitKey]*models.TransactionLimit),
+        accruals:     make(map[accrualKey]*models.InterestAccrual),
+        schedules:    make(map[uint]*models.ScheduledTransfer),
+        scheduleRuns: make(map[runKey]*models.ScheduledTransferRun),
+        batches:      make(map[uint]*models.TransferBatch),
+        batchItems:   make(map[uint][]*models.TransferBatchItem),
+        imports:      make(map[uint]*models.Import),
+        importRows:   make(map[uint][]*models.ImportRow),
+        approvals:    make(map[uint]*models.Approval),
+    }
+}
+
+// WithinTransaction runs fn while holding the store lock. Repository calls made
+// with the ctx handed to fn join the transaction; if fn returns an error or
+// panics, their writes are undone in reverse order. Nested calls join the
+// outer transaction. A repository call made inside fn with any other ctx waits
+// for the lock fn holds and deadlocks.
+func (s *Store) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+    if s.current(ctx) != nil {
+        return fn(ctx)
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    state := &txState{store: s}
+
+    defer func() {
+        if p := recover(); p != nil {
+            state.rollback()
+            panic(p)
+        }
+    }()
+
+    if err = fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+        state.rollback()
+    }
+
+    return err
+}
+
+func (s *Store) current(ctx context.Context) *txState {
+    state, ok := ctx.Value(txKey{}).(*txState)
+    if !ok || state.store != s {
+        return nil
+    }
+
+    return state
+}
+
+// lock acquires the store lock unless ctx already belongs to a transaction on
+// this store, and returns the release func together with the active
+// transaction, if any.
+func (s *Store) lock(ctx context.Context) (*txState, func()) {
+    if state := s.current(ctx); state != nil {
+        return state, func() {}
+    }
+
+    s.mu.Lock()
+
+    return nil, s.mu.Unlock
+}
+
+func (t *txState) record(undo func()) {
+    if t != nil {
+        t.undo = append(t.undo, undo)
+    }
+}
+
+func (t *txState) rollback() {
+    for i := len(t.undo) - 1; i >= 0; i-- {
+        t.undo[i]()
+    }
+    t.undo = nil
+}
+
+func cloneUser(u *models.User) *models.User {
+    c := *u
+    if u.PasswordChangedAt != nil {
+        changedAt := *u.PasswordChangedAt
+        c.PasswordChangedAt = &changedAt
+    }
+    return &c
+}
+
+// defaultAccount returns the ID of the user's first open account in
+// currency.
+func (s *Store) defaultAccount(userID uint, currency string) (uint, bool) {
+    var id uint
+    for _, account := range s.accounts {
+        if account.Status == models.AccountStatusClosed {
+            continue
+        }
+        if account.UserID == userID && account.Currency == currency && (id == 0 || account.ID < id) {
+            id = account.ID
+        }
+    }
+
+    return id, id != 0
+}
+
+func cloneBalance(b *models.Balance) *models.Balance {
+    return &models.Balance{
+        AccountID:     b.AccountID,
+        UserID:        b.UserID,
+        Currency:      b.Currency,
+        Amount:        b.Amount,
+        CreditLimit:   b.CreditLimit,
+        LastUpdatedAt: b.LastUpdatedAt,
+    }
+}
+
+func cloneTransaction(t *models.Transaction) *models.Transaction {
+    c := copyTransaction(t)
+    return &c
+}
+
+func copyTransaction(t *models.Transaction) models.Transaction {
+    return models.Transaction{
+        ID:          t.ID,
+        FromUserID:  t.FromUserID,
+        ToUserID:    t.ToUserID,
+        FromAccountID: t.FromAccountID,
+        ToAccountID:   t.ToAccountID,
+        Amount:      t.Amount,
+        Currency:    t.Currency,
+        ToAmount:    t.ToAmount,
+        ToCurrency:  t.ToCurrency,
+        Rate:        t.Rate,
+        Spread:      t.Spread,
+        QuoteID:     t.QuoteID,
+        ParentID:    t.ParentID,
+        Description: t.Description,
+        Type:        t.Type,
+        Status:      t.Status,
+        CreatedAt:   t.CreatedAt,
+        BookedAt:    t.BookedAt,
+    }
+}
+
+func cloneAuditLog(l *models.AuditLog) *models.AuditLog {
+    c := *l
+    return &c
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+    store    *Store
+    users    *UserRepository
+    accounts *AccountRepository
+    balances *BalanceRepository
+    txs      *TransactionRepository
+    user     *models.User
+    account  *models.Account
+}
+
+// newFixture returns a store holding one user with a USD account of 100.
+func newFixture(t *testing.T) *fixture {
+    t.Helper()
+
+    store := NewStore()
+    f := &fixture{
+        store:    store,
+        users:    NewUserRepository(store),
+        accounts: NewAccountRepository(store),
+        balances: NewBalanceRepository(store),
+        txs:      NewTransactionRepository(store),
+    }
+
+    ctx := context.Background()
+
+    f.user = &models.User{Username: "alice", Email: "alice@example.com", Role: models.RoleUser}
+    require.NoError(t, f.users.Create(ctx, f.user))
+
+    f.account = &models.Account{UserID: f.user.ID, Type: models.AccountTypeChecking, Currency: "USD"}
+    require.NoError(t, f.accounts.Create(ctx, f.account))
+
+    require.NoError(t, f.balances.CreateBalance(ctx, &models.Balance{
+        AccountID: f.account.ID,
+        UserID:    f.user.ID,
+        Currency:  "USD",
+        Amount:    100,
+    }))
+
+    return f
+}
+
+// within fails the test if fn has not returned after a second, so a deadlock
+// shows up as a failure instead of a hung test run.
+func within(t *testing.T, fn func()) {
+    t.Helper()
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        fn()
+    }()
+
+    select {
+        case <-done:
+        case <-time.After(time.Second):
+            t.Fatal("timed out, the store lock is probably held")
+    }
+}
+
+func TestWithinTransaction(t *testing.T) {
+    errFail := errors.New("fail")
+
+    tests := []struct {
+        name       string
+        fn         func(f *fixture) func(ctx context.Context) error
+        wantErr    error
+        wantAmount float64
+        wantTxs    int
+    }{
+        {
+            name: "commit keeps every write",
+            fn: func(f *fixture) func(ctx context.Context) error {
+                return func(ctx context.Context) error {
+                    if err := f.txs.Create(ctx, &models.Transaction{ToUserID: f.user.ID, Amount: 25, Currency: "USD", Type: models.TransactionTypeCredit}); err != nil {
+                        return err
+                    }
+                    return f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: 125})
+                }
+            },
+            wantAmount: 125,
+            wantTxs:    1,
+        },
+        {
+            name: "error undoes every write",
+            fn: func(f *fixture) func(ctx context.Context) error {
+                return func(ctx context.Context) error {
+                    if err := f.txs.Create(ctx, &models.Transaction{ToUserID: f.user.ID, Amount: 25, Currency: "USD", Type: models.TransactionTypeCredit}); err != nil {
+                        return err
+                    }
+                    if err := f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: 125}); err != nil {
+                        return err
+                    }
+                    return errFail
+                }
+            },
+            wantErr:    errFail,
+            wantAmount: 100,
+        },
+        {
+            name: "updates are undone in reverse order",
+            fn: func(f *fixture) func(ctx context.Context) error {
+                return func(ctx context.Context) error {
+                    for _, amount := range []float64{90, 80, 70} {
+                        if err := f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: amount}); err != nil {
+                            return err
+                        }
+                    }
+                    return errFail
+                }
+            },
+            wantErr:    errFail,
+            wantAmount: 100,
+        },
+        {
+            name: "nested transaction joins the outer one",
+            fn: func(f *fixture) func(ctx context.Context) error {
+                return func(ctx context.Context) error {
+                    err := f.store.WithinTransaction(ctx, func(ctx context.Context) error {
+                        return f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: 50})
+                    })
+                    if err != nil {
+                        return err
+                    }
+                    return errFail
+                }
+            },
+            wantErr:    errFail,
+            wantAmount: 100,
+        },
+        {
+            name: "repository error rolls back earlier writes",
+            fn: func(f *fixture) func(ctx context.Context) error {
+                return func(ctx context.Context) error {
+                    if err := f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: 10}); err != nil {
+                        return err
+                    }
+                    return f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID + 1, Amount: 10})
+                }
+            },
+            wantErr:    repository.ErrNotFound,
+            wantAmount: 100,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            f := newFixture(t)
+            ctx := context.Background()
+
+            var err error
+            within(t, func() {
+                err = f.store.WithinTransaction(ctx, tt.fn(f))
+            })
+
+            if tt.wantErr != nil {
+                assert.ErrorIs(t, err, tt.wantErr)
+            } else {
+                assert.NoError(t, err)
+            }
+
+            balance, err := f.balances.GetAccountBalance(ctx, f.account.ID)
+            require.NoError(t, err)
+            assert.Equal(t, tt.wantAmount, balance.Amount)
+
+            txs, err := f.txs.GetUserTransactions(ctx, f.user.ID, 10, 0)
+            require.NoError(t, err)
+            assert.Len(t, txs, tt.wantTxs)
+        })
+    }
+}
+
+func TestWithinTransactionPanicRollsBack(t *testing.T) {
+    f := newFixture(t)
+    ctx := context.Background()
+
+    within(t, func() {
+        assert.Panics(t, func() {
+            f.store.WithinTransaction(ctx, func(ctx context.Context) error {
+                if err := f.balances.UpdateBalance(ctx, &models.Balance{AccountID: f.account.ID, Amount: 0}); err != nil {
+                    return err
+                }
+                panic("boom")
+            })
+        })
+    })
+
+    // The lock is released, so this does not block
+    within(t, func() {
+        balance, err := f.balances.GetAccountBalance(ctx, f.account.ID)
+        require.NoError(t, err)
+        assert.Equal(t, 100.0, balance.Amount)
+    })
+}
+
+// TestCallOutsideTransactionWaits pins down the invariant documented on
+// repository.Transactor: inside fn, a call made without the transaction's ctx
+// waits for the transaction to end.
+func TestCallOutsideTransactionWaits(t *testing.T) {
+    f := newFixture(t)
+    ctx := context.Background()
+
+    read := make(chan float64, 1)
+
+    within(t, func() {
+        err := f.store.WithinTransaction(ctx, func(txCtx context.Context) error {
+            go func() {
+                balance, err := f.balances.GetAccountBalance(ctx, f.account.ID)
+                if err != nil {
+                    read <- -1
+                    return
+                }
+                read <- balance.Amount
+            }()
+
+            select {
+                case <-read:
+                    t.Error("a call without the transaction's ctx ran inside it")
+                case <-time.After(50 * time.Millisecond):
+            }
+
+            return f.balances.UpdateBalance(txCtx, &models.Balance{AccountID: f.account.ID, Amount: 60})
+        })
+        require.NoError(t, err)
+    })
+
+    select {
+        case amount := <-read:
+            assert.Equal(t, 60.0, amount)
+        case <-time.After(time.Second):
+            t.Fatal("the call did not run once the transaction ended")
+    }
+}
@@ -0,0 +1,276 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type TransactionRepository struct {
+    store *Store
+}
+
+func NewTransactionRepository(store *Store) *TransactionRepository {
+    return &TransactionRepository{store: store}
+}
+
+func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction) error {
+    state, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, userID := range []uint{tx.FromUserID, tx.ToUserID} {
+        if _, ok := r.store.users[userID]; userID != 0 && !ok {
+            return repository.ErrInvalidData
+        }
+    }
+
+    r.store.nextTxID++
+    tx.ID = r.store.nextTxID
+
+    r.store.transactions[tx.ID] = cloneTransaction(tx)
+
+    id := tx.ID
+    state.record(func() {
+        delete(r.store.transactions, id)
+    })
+
+    return nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    tx, ok := r.store.transactions[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneTransaction(tx), nil
+}
+
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, id uint, status models.TransactionStatus) error {
+    state, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    tx, ok := r.store.transactions[id]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    previous := tx.Status
+    tx.Status = status
+
+    state.record(func() {
+        tx.Status = previous
+    })
+
+    return nil
+}
+
+func (r *TransactionRepository) Complete(ctx context.Context, id uint, bookedAt time.Time) error {
+    state, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    tx, ok := r.store.transactions[id]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    previousStatus, previousBookedAt := tx.Status, tx.BookedAt
+    tx.Status = models.TransactionStatusCompleted
+    tx.BookedAt = &bookedAt
+
+    state.record(func() {
+        tx.Status, tx.BookedAt = previousStatus, previousBookedAt
+    })
+
+    return nil
+}
+
+func (r *TransactionRepository) SetAccounts(ctx context.Context, id uint, fromAccountID, toAccountID uint) error {
+    state, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    tx, ok := r.store.transactions[id]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    previousFrom, previousTo := tx.FromAccountID, tx.ToAccountID
+    tx.FromAccountID, tx.ToAccountID = fromAccountID, toAccountID
+
+    state.record(func() {
+        tx.FromAccountID, tx.ToAccountID = previousFrom, previousTo
+    })
+
+    return nil
+}
+
+// GetUserTransactions mirrors the SQL implementation: newest first, and a
+// limit of zero yields no rows.
+func (r *TransactionRepository) GetUserTransactions(ctx context.Context, userID uint, limit, offset int) ([]models.Transaction, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var matched []*models.Transaction
+    for _, tx := range r.store.transactions {
+        if tx.FromUserID == userID || tx.ToUserID == userID {
+            matched = append(matched, tx)
+        }
+    }
+
+    sort.Slice(matched, func(i, j int) bool {
+        if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+            return matched[i].ID > matched[j].ID
+        }
+        return matched[i].CreatedAt.After(matched[j].CreatedAt)
+    })
+
+    if offset >= len(matched) || limit <= 0 {
+        return nil, nil
+    }
+
+    matched = matched[offset:]
+    if limit < len(matched) {
+        matched = matched[:limit]
+    }
+
+    transactions := make([]models.Transaction, 0, len(matched))
+    for _, tx := range matched {
+        transactions = append(transactions, copyTransaction(tx))
+    }
+
+    return transactions, nil
+}
+
+func (r *TransactionRepository) GetUserTransactionsAfter(ctx context.Context, userID uint, afterID uint, limit int) ([]models.Transaction, error) {
+    return r.page(ctx, userID, afterID, limit, func(tx *models.Transaction) bool {
+        return true
+    })
+}
+
+func (r *TransactionRepository) GetUserTransactionsBookedBetween(ctx context.Context, userID uint, from, to time.Time, afterID uint, limit int) ([]models.Transaction, error) {
+    return r.page(ctx, userID, afterID, limit, func(tx *models.Transaction) bool {
+        return tx.BookedAt != nil && !tx.BookedAt.Before(from) && tx.BookedAt.Before(to)
+    })
+}
+
+func (r *TransactionRepository) SumUserTransactions(ctx context.Context, userID uint, txType models.TransactionType, currency string, since time.Time) (float64, int, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var total float64
+    var count int
+
+    for _, tx := range r.store.transactions {
+        if tx.Currency != currency || tx.CreatedAt.Before(since) ||
+            tx.Status == models.TransactionStatusFailed ||
+            tx.Status == models.TransactionStatusRejected || tx.Status == models.TransactionStatusExpired ||
+            tx.Type == models.TransactionTypeFee || tx.Type == models.TransactionTypeInterest {
+            continue
+        }
+
+        if txType != "" && tx.Type != txType {
+            continue
+        }
+
+        initiator := tx.FromUserID
+        if tx.Type == models.TransactionTypeCredit {
+            initiator = tx.ToUserID
+        }
+
+        if initiator == userID {
+            total += tx.Amount
+            count++
+        }
+    }
+
+    return total, count, nil
+}
+
+// page returns up to limit of the user's transactions with an ID above
+// afterID that satisfy match, in ID order.
+func (r *TransactionRepository) page(ctx context.Context, userID uint, afterID uint, limit int, match func(tx *models.Transaction) bool) ([]models.Transaction, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var matched []*models.Transaction
+    for _, tx := range r.store.transactions {
+        if tx.ID > afterID && (tx.FromUserID == userID || tx.ToUserID == userID) && match(tx) {
+            matched = append(matched, tx)
+        }
+    }
+
+    sort.Slice(matched, func(i, j int) bool {
+        return matched[i].ID < matched[j].ID
+    })
+
+    if limit < len(matched) {
+        matched = matched[:limit]
+    }
+
+    transactions := make([]models.Transaction, 0, len(matched))
+    for _, tx := range matched {
+        transactions = append(transactions, copyTransaction(tx))
+    }
+
+    return transactions, nil
+}
+
+// isPayment reports whether tx pays another user's account, as transfers and
+// conversions do.
+func isPayment(tx *models.Transaction) bool {
+    return tx.Type == models.TransactionTypeTransfer || tx.Type == models.TransactionTypeConversion
+}
+
+func (r *TransactionRepository) HasPaid(ctx context.Context, fromUserID, toUserID uint) (bool, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, tx := range r.store.transactions {
+        if isPayment(tx) && tx.FromUserID == fromUserID && tx.ToUserID == toUserID &&
+            tx.Status == models.TransactionStatusCompleted {
+            return true, nil
+        }
+    }
+
+    return false, nil
+}
+
+func (r *TransactionRepository) CountNewCounterparties(ctx context.Context, userID uint, since time.Time) (int, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    paidBefore := make(map[uint]bool)
+    paidSince := make(map[uint]bool)
+
+    for _, tx := range r.store.transactions {
+        if !isPayment(tx) || tx.FromUserID != userID || tx.ToUserID == userID {
+            continue
+        }
+
+        if tx.CreatedAt.Before(since) {
+            if tx.Status == models.TransactionStatusCompleted {
+                paidBefore[tx.ToUserID] = true
+            }
+            continue
+        }
+
+        if tx.Status != models.TransactionStatusFailed && tx.Status != models.TransactionStatusRejected &&
+            tx.Status != models.TransactionStatusExpired {
+            paidSince[tx.ToUserID] = true
+        }
+    }
+
+    count := 0
+    for toUserID := range paidSince {
+        if !paidBefore[toUserID] {
+            count++
+        }
+    }
+
+    return count, nil
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type TransferBatchRepository struct {
+    store *Store
+}
+
+func NewTransferBatchRepository(store *Store) *TransferBatchRepository {
+    return &TransferBatchRepository{store: store}
+}
+
+// cloneBatch copies the stored part of a batch, leaving out its items.
+func cloneBatch(b *models.TransferBatch) *models.TransferBatch {
+    c := *b
+    c.Items = nil
+    if b.CompletedAt != nil {
+        completedAt := *b.CompletedAt
+        c.CompletedAt = &completedAt
+    }
+    return &c
+}
+
+func (r *TransferBatchRepository) Create(ctx context.Context, batch *models.TransferBatch) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, existing := range r.store.batches {
+        if existing.ClientBatchID == batch.ClientBatchID {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    r.store.nextBatchID++
+    batch.ID = r.store.nextBatchID
+
+    r.store.batches[batch.ID] = cloneBatch(batch)
+
+    id := batch.ID
+    tx.record(func() {
+        delete(r.store.batches, id)
+    })
+
+    return nil
+}
+
+func (r *TransferBatchRepository) GetByClientID(ctx context.Context, clientBatchID string) (*models.TransferBatch, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for _, batch := range r.store.batches {
+        if batch.ClientBatchID == clientBatchID {
+            return cloneBatch(batch), nil
+        }
+    }
+
+    return nil, repository.ErrNotFound
+}
+
+func (r *TransferBatchRepository) Update(ctx context.Context, batch *models.TransferBatch) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.batches[batch.ID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    updated := cloneBatch(existing)
+    updated.Status = batch.Status
+    updated.SucceededCount = batch.SucceededCount
+    updated.FailedCount = batch.FailedCount
+    updated.CompletedAt = cloneBatch(batch).CompletedAt
+
+    r.store.batches[batch.ID] = updated
+
+    id := batch.ID
+    tx.record(func() {
+        r.store.batches[id] = existing
+    })
+
+    return nil
+}
+
+func (r *TransferBatchRepository) CreateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, ok := r.store.batches[item.BatchID]; !ok {
+        return repository.ErrInvalidData
+    }
+
+    for _, existing := range r.store.batchItems[item.BatchID] {
+        if existing.Index == item.Index {
+            return repository.ErrDuplicateKey
+        }
+    }
+
+    c := *item
+    r.store.batchItems[item.BatchID] = append(r.store.batchItems[item.BatchID], &c)
+
+    batchID := item.BatchID
+    tx.record(func() {
+        items := r.store.batchItems[batchID]
+        r.store.batchItems[batchID] = items[:len(items)-1]
+    })
+
+    return nil
+}
+
+func (r *TransferBatchRepository) UpdateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    for i, existing := range r.store.batchItems[item.BatchID] {
+        if existing.Index != item.Index {
+            continue
+        }
+
+        updated := *existing
+        updated.Status = item.Status
+        updated.TransactionID = item.TransactionID
+        updated.Error = item.Error
+
+        items := r.store.batchItems[item.BatchID]
+        items[i] = &updated
+
+        previous := existing
+        tx.record(func() {
+            items[i] = previous
+        })
+
+        return nil
+    }
+
+    return repository.ErrNotFound
+}
+
+func (r *TransferBatchRepository) GetItems(ctx context.Context, batchID uint) ([]*models.TransferBatchItem, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    var items []*models.TransferBatchItem
+    for _, item := range r.store.batchItems[batchID] {
+        c := *item
+        items = append(items, &c)
+    }
+
+    sort.Slice(items, func(i, j int) bool {
+        return items[i].Index < items[j].Index
+    })
+
+    return items, nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+    "context"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type UserRepository struct {
+    store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+    return &UserRepository{store: store}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    if _, exists := r.store.emails[user.Email]; exists {
+        return repository.ErrDuplicateKey
+    }
+
+    r.store.nextUserID++
+    user.ID = r.store.nextUserID
+
+    r.store.users[user.ID] = cloneUser(user)
+    r.store.emails[user.Email] = user.ID
+
+    tx.record(func() {
+        delete(r.store.users, user.ID)
+        delete(r.store.emails, user.Email)
+    })
+
+    return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    user, ok := r.store.users[id]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneUser(user), nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+    _, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    id, ok := r.store.emails[email]
+    if !ok {
+        return nil, repository.ErrNotFound
+    }
+
+    return cloneUser(r.store.users[id]), nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+    tx, unlock := r.store.lock(ctx)
+    defer unlock()
+
+    existing, ok := r.store.users[user.ID]
+    if !ok {
+        return repository.ErrNotFound
+    }
+
+    if owner, taken := r.store.emails[user.Email]; taken && owner != user.ID {
+        return repository.ErrDuplicateKey
+    }
+
+    previous := cloneUser(existing)
+
+    delete(r.store.emails, existing.Email)
+    r.store.users[user.ID] = cloneUser(user)
+    r.store.emails[user.Email] = user.ID
+
+    tx.record(func() {
+        delete(r.store.emails, user.Email)
+        r.store.users[previous.ID] = previous
+        r.store.emails[previous.Email] = previous.ID
+    })
+
+    return nil
+}
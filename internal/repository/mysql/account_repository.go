@@ -0,0 +1,164 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+const accountColumns = `id, user_id, type, currency, status, created_at, updated_at`
+
+type AccountRepository struct {
+    db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+    return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+    query := `
+        INSERT INTO accounts (user_id, type, currency, status, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        account.UserID,
+        account.Type,
+        account.Currency,
+        account.Status,
+        account.CreatedAt.UTC(),
+        account.UpdatedAt.UTC(),
+    )
+    if err != nil {
+        return mapError(err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+
+    account.ID = uint(id)
+
+    return nil
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+    query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = ?`
+
+    return scanAccount(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
+
+func (r *AccountRepository) GetDefault(ctx context.Context, userID uint, currency string) (*models.Account, error) {
+    query := `
+        SELECT ` + accountColumns + ` FROM accounts
+        WHERE user_id = ? AND currency = ? AND status <> ?
+        ORDER BY id
+        LIMIT 1
+    `
+
+    return scanAccount(conn(ctx, r.db).QueryRowContext(ctx, query, userID, currency, models.AccountStatusClosed))
+}
+
+func (r *AccountRepository) ListByUser(ctx context.Context, userID uint) ([]*models.Account, error) {
+    query := `SELECT ` + accountColumns + ` FROM accounts WHERE user_id = ? ORDER BY id`
+
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var accounts []*models.Account
+    for rows.Next() {
+        account, err := scanAccount(rows)
+        if err != nil {
+            return nil, err
+        }
+        accounts = append(accounts, account)
+    }
+
+    return accounts, rows.Err()
+}
+
+func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
+    query := `UPDATE accounts SET type = ?, status = ?, updated_at = ? WHERE id = ?`
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        account.Type,
+        account.Status,
+        account.UpdatedAt.UTC(),
+        account.ID,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *AccountRepository) Delete(ctx context.Context, id uint) error {
+    for _, table := range []string{"interest_accruals", "balance_snapshots", "balances"} {
+        if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM `+table+` WHERE account_id = ?`, id); err != nil {
+            return err
+        }
+    }
+
+    result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM accounts WHERE id = ?`, id)
+    if err != nil {
+        return mapError(err)
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *AccountRepository) HasTransactions(ctx context.Context, id uint) (bool, error) {
+    var exists bool
+
+    query := `SELECT EXISTS (SELECT 1 FROM transactions WHERE from_account_id = ? OR to_account_id = ?)`
+    if err := conn(ctx, r.db).QueryRowContext(ctx, query, id, id).Scan(&exists); err != nil {
+        return false, err
+    }
+
+    return exists, nil
+}
+
+func scanAccount(row scanner) (*models.Account, error) {
+    account := &models.Account{}
+
+    err := row.Scan(
+        &account.ID,
+        &account.UserID,
+        &account.Type,
+        &account.Currency,
+        &account.Status,
+        &account.CreatedAt,
+        &account.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return account, nil
+}
@@ -0,0 +1,136 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+const approvalColumns = `transaction_id, kind, status, initiated_by, initiated_by_id, reason, expires_at, decided_by, decided_by_id, decided_at, note, created_at`
+
+type ApprovalRepository struct {
+    db *sql.DB
+}
+
+func NewApprovalRepository(db *sql.DB) *ApprovalRepository {
+    return &ApprovalRepository{db: db}
+}
+
+func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approval) error {
+    query := `
+        INSERT INTO transaction_approvals (` + approvalColumns + `)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        approval.TransactionID,
+        approval.Kind,
+        approval.Status,
+        approval.InitiatedBy,
+        approval.InitiatedByID,
+        approval.Reason,
+        approval.ExpiresAt.UTC(),
+        approval.DecidedBy,
+        approval.DecidedByID,
+        nullTime(approval.DecidedAt),
+        approval.Note,
+        approval.CreatedAt.UTC(),
+    )
+
+    return mapError(err)
+}
+
+func (r *ApprovalRepository) GetByTransactionID(ctx context.Context, transactionID uint) (*models.Approval, error) {
+    query := `SELECT ` + approvalColumns + ` FROM transaction_approvals WHERE transaction_id = ?`
+
+    return scanApproval(conn(ctx, r.db).QueryRowContext(ctx, query, transactionID))
+}
+
+func (r *ApprovalRepository) ListPending(ctx context.Context) ([]*models.Approval, error) {
+    query := `
+        SELECT ` + approvalColumns + ` FROM transaction_approvals
+        WHERE status = ?
+        ORDER BY transaction_id
+    `
+
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, models.ApprovalPending)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var approvals []*models.Approval
+    for rows.Next() {
+        approval, err := scanApproval(rows)
+        if err != nil {
+            return nil, err
+        }
+        approvals = append(approvals, approval)
+    }
+
+    return approvals, rows.Err()
+}
+
+func (r *ApprovalRepository) Decide(ctx context.Context, approval *models.Approval) error {
+    query := `
+        UPDATE transaction_approvals
+        SET status = ?, decided_by = ?, decided_by_id = ?, decided_at = ?, note = ?
+        WHERE transaction_id = ? AND status = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        approval.Status,
+        approval.DecidedBy,
+        approval.DecidedByID,
+        nullTime(approval.DecidedAt),
+        approval.Note,
+        approval.TransactionID,
+        models.ApprovalPending,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func scanApproval(row scanner) (*models.Approval, error) {
+    approval := &models.Approval{}
+    var decidedAt sql.NullTime
+
+    err := row.Scan(
+        &approval.TransactionID,
+        &approval.Kind,
+        &approval.Status,
+        &approval.InitiatedBy,
+        &approval.InitiatedByID,
+        &approval.Reason,
+        &approval.ExpiresAt,
+        &approval.DecidedBy,
+        &approval.DecidedByID,
+        &decidedAt,
+        &approval.Note,
+        &approval.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    if decidedAt.Valid {
+        approval.DecidedAt = &decidedAt.Time
+    }
+
+    return approval, nil
+}
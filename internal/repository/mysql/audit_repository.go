@@ -17,14 +17,16 @@ func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
 func (r *AuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
     query := `
         INSERT INTO audit_logs (
-            entity_type, entity_id, action, changes, created_at
-        ) VALUES (?, ?, ?, ?, ?)
+            entity_type, entity_id, action, changes, actor, reason, created_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?)
     `
-    _, err := r.db.ExecContext(ctx, query,
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
         log.EntityType,
         log.EntityID,
         log.Action,
         log.Changes,
+        log.Actor,
+        log.Reason,
         log.CreatedAt,
     )
 
@@ -33,13 +35,13 @@ func (r *AuditLogRepository) Create(ctx context.Context, log *models.AuditLog) e
 
 func (r *AuditLogRepository) GetByEntityID(ctx context.Context, entityType string, entityID uint) ([]*models.AuditLog, error) {
     query := `
-        SELECT entity_type, entity_id, action, changes, created_at
+        SELECT id, entity_type, entity_id, action, changes, actor, reason, created_at
         FROM audit_logs 
         WHERE entity_type = ? AND entity_id = ?
         ORDER BY created_at DESC
     `
 
-    rows, err := r.db.QueryContext(ctx, query, entityType, entityID)
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, entityType, entityID)
 
     if err != nil {
         return nil, err
@@ -53,10 +55,13 @@ func (r *AuditLogRepository) GetByEntityID(ctx context.Context, entityType strin
         log := &models.AuditLog{}
 
         err := rows.Scan(
+            &log.ID,
             &log.EntityType,
             &log.EntityID,
             &log.Action,
             &log.Changes,
+            &log.Actor,
+            &log.Reason,
             &log.CreatedAt,
         )
 
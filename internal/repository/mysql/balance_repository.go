@@ -5,27 +5,47 @@ import (
     "database/sql"
     "financial-service/internal/models"
     "financial-service/internal/repository"
-    "github.com/rs/zerolog/log"
 )
 
 type BalanceRepository struct {
-    db *sql.DB
+    db          *sql.DB
+    lockingRead bool
 }
 
 func NewBalanceRepository(db *sql.DB) *BalanceRepository {
-    return &BalanceRepository{db: db}
+    return &BalanceRepository{db: db, lockingRead: isMySQL(db)}
 }
 
-func (r *BalanceRepository) GetBalance(ctx context.Context, userID uint) (*models.Balance, error) {
+func (r *BalanceRepository) GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error) {
+    // The default account is the user's first open one in the currency
+    return r.get(ctx, `account_id = (
+            SELECT MIN(id) FROM accounts WHERE user_id = ? AND currency = ? AND status <> ?
+        )`, userID, currency, models.AccountStatusClosed)
+}
+
+func (r *BalanceRepository) GetAccountBalance(ctx context.Context, accountID uint) (*models.Balance, error) {
+    return r.get(ctx, "account_id = ?", accountID)
+}
+
+func (r *BalanceRepository) get(ctx context.Context, where string, args ...interface{}) (*models.Balance, error) {
     balance := &models.Balance{}
     
     query := `
-        SELECT user_id, amount, last_updated_at
-        FROM balances WHERE user_id = ?
-    `
-    err := r.db.QueryRowContext(ctx, query, userID).Scan(
+        SELECT account_id, user_id, currency, amount, credit_limit, last_updated_at
+        FROM balances WHERE ` + where
+
+    // Lock the row when reading inside a transaction so the read-modify-write
+    // done by the worker pool cannot lose a concurrent update.
+    if r.lockingRead && inTransaction(ctx) {
+        query += " FOR UPDATE"
+    }
+
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+        &balance.AccountID,
         &balance.UserID,
+        &balance.Currency,
         &balance.Amount,
+        &balance.CreditLimit,
         &balance.LastUpdatedAt,
     )
 
@@ -37,21 +57,19 @@ func (r *BalanceRepository) GetBalance(ctx context.Context, userID uint) (*model
         return nil, err
     }
 
-    log.Printf("Balance: %+v", balance)
-
     return balance, nil
 }
 
 func (r *BalanceRepository) UpdateBalance(ctx context.Context, balance *models.Balance) error {
     query := `
         UPDATE balances 
-        SET amount = ?, last_updated_at = ?
-        WHERE user_id = ?
+        SET amount = ROUND(?, 4), last_updated_at = ?
+        WHERE account_id = ?
     `
-    result, err := r.db.ExecContext(ctx, query,
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
         balance.Amount,
         balance.LastUpdatedAt,
-        balance.UserID,
+        balance.AccountID,
     )
 
     if err != nil {
@@ -73,14 +91,94 @@ func (r *BalanceRepository) UpdateBalance(ctx context.Context, balance *models.B
 
 func (r *BalanceRepository) CreateBalance(ctx context.Context, balance *models.Balance) error {
     query := `
-        INSERT INTO balances (user_id, amount, last_updated_at)
-        VALUES (?, ?, ?)
+        INSERT INTO balances (account_id, user_id, currency, amount, credit_limit, last_updated_at)
+        VALUES (?, ?, ?, ROUND(?, 4), ROUND(?, 4), ?)
     `
-    _, err := r.db.ExecContext(ctx, query,
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        balance.AccountID,
         balance.UserID,
+        balance.Currency,
         balance.Amount,
+        balance.CreditLimit,
         balance.LastUpdatedAt,
     )
 
-    return err
-} 
\ No newline at end of file
+    return mapError(err)
+}
+
+func (r *BalanceRepository) SetCreditLimit(ctx context.Context, accountID uint, creditLimit float64) error {
+    query := `UPDATE balances SET credit_limit = ROUND(?, 4) WHERE account_id = ?`
+    result, err := conn(ctx, r.db).ExecContext(ctx, query, creditLimit, accountID)
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *BalanceRepository) GetUserBalances(ctx context.Context, userID uint) ([]*models.Balance, error) {
+    query := `
+        SELECT account_id, user_id, currency, amount, credit_limit, last_updated_at
+        FROM balances WHERE user_id = ?
+        ORDER BY currency, account_id
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+
+    if err != nil {
+        return nil, err
+    }
+
+    return scanBalances(rows)
+}
+
+func (r *BalanceRepository) ListBalances(ctx context.Context, afterAccountID uint, limit int) ([]*models.Balance, error) {
+    query := `
+        SELECT account_id, user_id, currency, amount, credit_limit, last_updated_at
+        FROM balances
+        WHERE account_id > ?
+        ORDER BY account_id
+        LIMIT ?
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, afterAccountID, limit)
+
+    if err != nil {
+        return nil, err
+    }
+
+    return scanBalances(rows)
+}
+
+func scanBalances(rows *sql.Rows) ([]*models.Balance, error) {
+    defer rows.Close()
+
+    var balances []*models.Balance
+
+    for rows.Next() {
+        balance := &models.Balance{}
+
+        err := rows.Scan(
+            &balance.AccountID,
+            &balance.UserID,
+            &balance.Currency,
+            &balance.Amount,
+            &balance.CreditLimit,
+            &balance.LastUpdatedAt,
+        )
+
+        if err != nil {
+            return nil, err
+        }
+
+        balances = append(balances, balance)
+    }
+
+    return balances, rows.Err()
+}
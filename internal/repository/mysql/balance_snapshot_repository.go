@@ -0,0 +1,64 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "time"
+)
+
+type BalanceSnapshotRepository struct {
+    db *sql.DB
+}
+
+func NewBalanceSnapshotRepository(db *sql.DB) *BalanceSnapshotRepository {
+    return &BalanceSnapshotRepository{db: db}
+}
+
+func (r *BalanceSnapshotRepository) Create(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+    query := `
+        INSERT INTO balance_snapshots (account_id, user_id, currency, as_of, amount, created_at)
+        VALUES (?, ?, ?, ?, ROUND(?, 4), ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        snapshot.AccountID,
+        snapshot.UserID,
+        snapshot.Currency,
+        snapshot.AsOf.UTC(),
+        snapshot.Amount,
+        snapshot.CreatedAt.UTC(),
+    )
+
+    return mapError(err)
+}
+
+func (r *BalanceSnapshotRepository) GetLatest(ctx context.Context, accountID uint, asOf time.Time) (*models.BalanceSnapshot, error) {
+    snapshot := &models.BalanceSnapshot{}
+
+    query := `
+        SELECT account_id, user_id, currency, as_of, amount, created_at
+        FROM balance_snapshots
+        WHERE account_id = ? AND as_of <= ?
+        ORDER BY as_of DESC
+        LIMIT 1
+    `
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, accountID, asOf.UTC()).Scan(
+        &snapshot.AccountID,
+        &snapshot.UserID,
+        &snapshot.Currency,
+        &snapshot.AsOf,
+        &snapshot.Amount,
+        &snapshot.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return snapshot, nil
+}
@@ -0,0 +1,178 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type FXRateRepository struct {
+    db *sql.DB
+}
+
+func NewFXRateRepository(db *sql.DB) *FXRateRepository {
+    return &FXRateRepository{db: db}
+}
+
+func (r *FXRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.FXRate, error) {
+    rate := &models.FXRate{}
+
+    query := `
+        SELECT base_currency, quote_currency, rate, updated_at
+        FROM fx_rates
+        WHERE base_currency = ? AND quote_currency = ?
+    `
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, baseCurrency, quoteCurrency).Scan(
+        &rate.BaseCurrency,
+        &rate.QuoteCurrency,
+        &rate.Rate,
+        &rate.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return rate, nil
+}
+
+func (r *FXRateRepository) SetRate(ctx context.Context, rate *models.FXRate) error {
+    upsert := `ON CONFLICT (base_currency, quote_currency) DO UPDATE SET rate = excluded.rate, updated_at = excluded.updated_at`
+    if isMySQL(r.db) {
+        upsert = `ON DUPLICATE KEY UPDATE rate = VALUES(rate), updated_at = VALUES(updated_at)`
+    }
+
+    query := `
+        INSERT INTO fx_rates (base_currency, quote_currency, rate, updated_at)
+        VALUES (?, ?, ?, ?)
+    ` + upsert
+
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        rate.BaseCurrency,
+        rate.QuoteCurrency,
+        rate.Rate,
+        rate.UpdatedAt.UTC(),
+    )
+
+    return err
+}
+
+func (r *FXRateRepository) ListRates(ctx context.Context) ([]*models.FXRate, error) {
+    query := `
+        SELECT base_currency, quote_currency, rate, updated_at
+        FROM fx_rates
+        ORDER BY base_currency, quote_currency
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var rates []*models.FXRate
+    for rows.Next() {
+        rate := &models.FXRate{}
+        if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.UpdatedAt); err != nil {
+            return nil, err
+        }
+        rates = append(rates, rate)
+    }
+
+    return rates, rows.Err()
+}
+
+type FXQuoteRepository struct {
+    db *sql.DB
+}
+
+func NewFXQuoteRepository(db *sql.DB) *FXQuoteRepository {
+    return &FXQuoteRepository{db: db}
+}
+
+func (r *FXQuoteRepository) Create(ctx context.Context, quote *models.FXQuote) error {
+    query := `
+        INSERT INTO fx_quotes
+        (user_id, from_currency, to_currency, from_amount, to_amount, rate, spread, created_at, expires_at)
+        VALUES (?, ?, ?, ROUND(?, 4), ROUND(?, 4), ?, ?, ?, ?)
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        quote.UserID,
+        quote.FromCurrency,
+        quote.ToCurrency,
+        quote.FromAmount,
+        quote.ToAmount,
+        quote.Rate,
+        quote.Spread,
+        quote.CreatedAt.UTC(),
+        quote.ExpiresAt.UTC(),
+    )
+    if err != nil {
+        return mapError(err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+
+    quote.ID = uint(id)
+
+    return nil
+}
+
+func (r *FXQuoteRepository) GetByID(ctx context.Context, id uint) (*models.FXQuote, error) {
+    quote := &models.FXQuote{}
+
+    query := `
+        SELECT id, user_id, from_currency, to_currency, from_amount, to_amount, rate, spread,
+            created_at, expires_at, COALESCE(transaction_id, 0)
+        FROM fx_quotes
+        WHERE id = ?
+    `
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+        &quote.ID,
+        &quote.UserID,
+        &quote.FromCurrency,
+        &quote.ToCurrency,
+        &quote.FromAmount,
+        &quote.ToAmount,
+        &quote.Rate,
+        &quote.Spread,
+        &quote.CreatedAt,
+        &quote.ExpiresAt,
+        &quote.TransactionID,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return quote, nil
+}
+
+func (r *FXQuoteRepository) MarkExecuted(ctx context.Context, id, transactionID uint) error {
+    query := `UPDATE fx_quotes SET transaction_id = ? WHERE id = ? AND transaction_id IS NULL`
+    result, err := conn(ctx, r.db).ExecContext(ctx, query, transactionID, id)
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
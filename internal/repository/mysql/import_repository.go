@@ -0,0 +1,207 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type ImportRepository struct {
+    db *sql.DB
+}
+
+func NewImportRepository(db *sql.DB) *ImportRepository {
+    return &ImportRepository{db: db}
+}
+
+func (r *ImportRepository) Create(ctx context.Context, imp *models.Import) error {
+    query := `
+        INSERT INTO imports
+        (file_hash, filename, status, row_count, executed_count, failed_count, created_at, completed_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        imp.FileHash,
+        imp.Filename,
+        imp.Status,
+        imp.RowCount,
+        imp.ExecutedCount,
+        imp.FailedCount,
+        imp.CreatedAt.UTC(),
+        nullTime(imp.CompletedAt),
+    )
+    if err != nil {
+        return mapError(err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+
+    imp.ID = uint(id)
+
+    return nil
+}
+
+func (r *ImportRepository) GetByID(ctx context.Context, id uint) (*models.Import, error) {
+    return r.get(ctx, "id = ?", id)
+}
+
+func (r *ImportRepository) GetByHash(ctx context.Context, fileHash string) (*models.Import, error) {
+    return r.get(ctx, "file_hash = ?", fileHash)
+}
+
+func (r *ImportRepository) get(ctx context.Context, where string, arg interface{}) (*models.Import, error) {
+    imp := &models.Import{}
+    var completedAt sql.NullTime
+
+    query := `
+        SELECT id, file_hash, filename, status, row_count, executed_count, failed_count, created_at, completed_at
+        FROM imports
+        WHERE ` + where
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, arg).Scan(
+        &imp.ID,
+        &imp.FileHash,
+        &imp.Filename,
+        &imp.Status,
+        &imp.RowCount,
+        &imp.ExecutedCount,
+        &imp.FailedCount,
+        &imp.CreatedAt,
+        &completedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    if completedAt.Valid {
+        imp.CompletedAt = &completedAt.Time
+    }
+
+    return imp, nil
+}
+
+func (r *ImportRepository) Update(ctx context.Context, imp *models.Import) error {
+    query := `
+        UPDATE imports
+        SET status = ?, executed_count = ?, failed_count = ?, completed_at = ?
+        WHERE id = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        imp.Status,
+        imp.ExecutedCount,
+        imp.FailedCount,
+        nullTime(imp.CompletedAt),
+        imp.ID,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *ImportRepository) CreateRow(ctx context.Context, row *models.ImportRow) error {
+    query := `
+        INSERT INTO import_rows
+        (import_id, line, type, from_user_id, to_user_id, amount, currency, reference, status, transaction_id, error)
+        VALUES (?, ?, ?, ?, ?, ROUND(?, 4), ?, ?, ?, NULLIF(?, 0), ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        row.ImportID,
+        row.Line,
+        row.Type,
+        row.FromUserID,
+        row.ToUserID,
+        row.Amount,
+        row.Currency,
+        row.Reference,
+        row.Status,
+        row.TransactionID,
+        row.Error,
+    )
+
+    return mapError(err)
+}
+
+func (r *ImportRepository) UpdateRow(ctx context.Context, row *models.ImportRow) error {
+    query := `
+        UPDATE import_rows
+        SET status = ?, transaction_id = NULLIF(?, 0), error = ?
+        WHERE import_id = ? AND line = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        row.Status,
+        row.TransactionID,
+        row.Error,
+        row.ImportID,
+        row.Line,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *ImportRepository) GetRows(ctx context.Context, importID uint) ([]*models.ImportRow, error) {
+    query := `
+        SELECT import_id, line, type, from_user_id, to_user_id, amount, currency, reference, status,
+            COALESCE(transaction_id, 0), error
+        FROM import_rows
+        WHERE import_id = ?
+        ORDER BY line
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, importID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var result []*models.ImportRow
+    for rows.Next() {
+        row := &models.ImportRow{}
+        err := rows.Scan(
+            &row.ImportID,
+            &row.Line,
+            &row.Type,
+            &row.FromUserID,
+            &row.ToUserID,
+            &row.Amount,
+            &row.Currency,
+            &row.Reference,
+            &row.Status,
+            &row.TransactionID,
+            &row.Error,
+        )
+        if err != nil {
+            return nil, err
+        }
+        result = append(result, row)
+    }
+
+    return result, rows.Err()
+}
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type InterestRepository struct {
+    db *sql.DB
+}
+
+func NewInterestRepository(db *sql.DB) *InterestRepository {
+    return &InterestRepository{db: db}
+}
+
+func (r *InterestRepository) CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error {
+    query := `
+        INSERT INTO interest_accruals
+        (account_id, user_id, currency, accrual_date, balance, rate, day_count, amount, created_at)
+        VALUES (?, ?, ?, ?, ROUND(?, 4), ?, ?, ROUND(?, 10), ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        accrual.AccountID,
+        accrual.UserID,
+        accrual.Currency,
+        accrual.Date.UTC(),
+        accrual.Balance,
+        accrual.Rate,
+        accrual.DayCount,
+        accrual.Amount,
+        accrual.CreatedAt.UTC(),
+    )
+
+    return mapError(err)
+}
+
+func (r *InterestRepository) GetUnposted(ctx context.Context, accountID uint, before time.Time) ([]*models.InterestAccrual, error) {
+    query := `
+        SELECT account_id, user_id, currency, accrual_date, balance, rate, day_count, amount, created_at
+        FROM interest_accruals
+        WHERE account_id = ? AND accrual_date < ? AND transaction_id IS NULL
+        ORDER BY accrual_date
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, accountID, before.UTC())
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var accruals []*models.InterestAccrual
+    for rows.Next() {
+        accrual := &models.InterestAccrual{}
+        err := rows.Scan(
+            &accrual.AccountID,
+            &accrual.UserID,
+            &accrual.Currency,
+            &accrual.Date,
+            &accrual.Balance,
+            &accrual.Rate,
+            &accrual.DayCount,
+            &accrual.Amount,
+            &accrual.CreatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        accruals = append(accruals, accrual)
+    }
+
+    return accruals, rows.Err()
+}
+
+func (r *InterestRepository) MarkPosted(ctx context.Context, accountID uint, before time.Time, transactionID uint) error {
+    query := `
+        UPDATE interest_accruals SET transaction_id = ?
+        WHERE account_id = ? AND accrual_date < ? AND transaction_id IS NULL
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query, transactionID, accountID, before.UTC())
+
+    return err
+}
+
+func (r *InterestRepository) GetLastRun(ctx context.Context) (time.Time, error) {
+    var last time.Time
+
+    query := `SELECT accrual_date FROM interest_runs ORDER BY accrual_date DESC LIMIT 1`
+    err := conn(ctx, r.db).QueryRowContext(ctx, query).Scan(&last)
+
+    if err == sql.ErrNoRows {
+        return time.Time{}, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return time.Time{}, err
+    }
+
+    return last, nil
+}
+
+func (r *InterestRepository) CreateRun(ctx context.Context, date time.Time) error {
+    query := `INSERT INTO interest_runs (accrual_date, completed_at) VALUES (?, ?)`
+    _, err := conn(ctx, r.db).ExecContext(ctx, query, date.UTC(), time.Now().UTC())
+
+    return mapError(err)
+}
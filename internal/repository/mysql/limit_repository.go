@@ -0,0 +1,104 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type LimitRepository struct {
+    db *sql.DB
+}
+
+func NewLimitRepository(db *sql.DB) *LimitRepository {
+    return &LimitRepository{db: db}
+}
+
+func (r *LimitRepository) GetUserLimits(ctx context.Context, userID uint) ([]*models.TransactionLimit, error) {
+    query := `
+        SELECT user_id, type, currency, per_transaction, daily_amount, daily_count,
+            monthly_amount, monthly_count, updated_at
+        FROM user_limits
+        WHERE user_id = ?
+        ORDER BY type, currency
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var limits []*models.TransactionLimit
+    for rows.Next() {
+        limit := &models.TransactionLimit{}
+        err := rows.Scan(
+            &limit.UserID,
+            &limit.Type,
+            &limit.Currency,
+            &limit.PerTransaction,
+            &limit.DailyAmount,
+            &limit.DailyCount,
+            &limit.MonthlyAmount,
+            &limit.MonthlyCount,
+            &limit.UpdatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        limits = append(limits, limit)
+    }
+
+    return limits, rows.Err()
+}
+
+func (r *LimitRepository) SetUserLimit(ctx context.Context, limit *models.TransactionLimit) error {
+    upsert := `ON CONFLICT (user_id, type, currency) DO UPDATE SET
+            per_transaction = excluded.per_transaction, daily_amount = excluded.daily_amount,
+            daily_count = excluded.daily_count, monthly_amount = excluded.monthly_amount,
+            monthly_count = excluded.monthly_count, updated_at = excluded.updated_at`
+    if isMySQL(r.db) {
+        upsert = `ON DUPLICATE KEY UPDATE
+            per_transaction = VALUES(per_transaction), daily_amount = VALUES(daily_amount),
+            daily_count = VALUES(daily_count), monthly_amount = VALUES(monthly_amount),
+            monthly_count = VALUES(monthly_count), updated_at = VALUES(updated_at)`
+    }
+
+    query := `
+        INSERT INTO user_limits
+        (user_id, type, currency, per_transaction, daily_amount, daily_count, monthly_amount, monthly_count, updated_at)
+        VALUES (?, ?, ?, ROUND(?, 4), ROUND(?, 4), ?, ROUND(?, 4), ?, ?)
+    ` + upsert
+
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        limit.UserID,
+        limit.Type,
+        limit.Currency,
+        limit.PerTransaction,
+        limit.DailyAmount,
+        limit.DailyCount,
+        limit.MonthlyAmount,
+        limit.MonthlyCount,
+        limit.UpdatedAt.UTC(),
+    )
+
+    return err
+}
+
+func (r *LimitRepository) DeleteUserLimit(ctx context.Context, userID uint, txType models.TransactionType, currency string) error {
+    query := `DELETE FROM user_limits WHERE user_id = ? AND type = ? AND currency = ?`
+    result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, txType, currency)
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
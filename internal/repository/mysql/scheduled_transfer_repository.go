@@ -0,0 +1,346 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+const scheduledTransferColumns = `id, from_user_id, to_user_id, amount, currency, frequency, start_at, end_date,
+        next_run_at, due_at, on_insufficient_funds, status, created_at, updated_at`
+
+const scheduledRunColumns = `schedule_id, occurrence, status, attempts, COALESCE(transaction_id, 0), error, updated_at`
+
+type ScheduledTransferRepository struct {
+    db *sql.DB
+}
+
+func NewScheduledTransferRepository(db *sql.DB) *ScheduledTransferRepository {
+    return &ScheduledTransferRepository{db: db}
+}
+
+type scanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanScheduledTransfer(row scanner) (*models.ScheduledTransfer, error) {
+    schedule := &models.ScheduledTransfer{}
+    var endDate sql.NullTime
+
+    err := row.Scan(
+        &schedule.ID,
+        &schedule.FromUserID,
+        &schedule.ToUserID,
+        &schedule.Amount,
+        &schedule.Currency,
+        &schedule.Frequency,
+        &schedule.StartAt,
+        &endDate,
+        &schedule.NextRunAt,
+        &schedule.DueAt,
+        &schedule.OnInsufficientFunds,
+        &schedule.Status,
+        &schedule.CreatedAt,
+        &schedule.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+
+    if endDate.Valid {
+        schedule.EndDate = &endDate.Time
+    }
+
+    return schedule, nil
+}
+
+func nullTime(t *time.Time) interface{} {
+    if t == nil {
+        return nil
+    }
+    return t.UTC()
+}
+
+func (r *ScheduledTransferRepository) Create(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    query := `
+        INSERT INTO scheduled_transfers
+        (from_user_id, to_user_id, amount, currency, frequency, start_at, end_date, next_run_at, due_at,
+        on_insufficient_funds, status, created_at, updated_at)
+        VALUES (?, ?, ROUND(?, 4), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        schedule.FromUserID,
+        schedule.ToUserID,
+        schedule.Amount,
+        schedule.Currency,
+        schedule.Frequency,
+        schedule.StartAt.UTC(),
+        nullTime(schedule.EndDate),
+        schedule.NextRunAt.UTC(),
+        schedule.DueAt.UTC(),
+        schedule.OnInsufficientFunds,
+        schedule.Status,
+        schedule.CreatedAt.UTC(),
+        schedule.UpdatedAt.UTC(),
+    )
+    if err != nil {
+        return mapError(err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+
+    schedule.ID = uint(id)
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) GetByID(ctx context.Context, id uint) (*models.ScheduledTransfer, error) {
+    query := `SELECT ` + scheduledTransferColumns + ` FROM scheduled_transfers WHERE id = ?`
+    schedule, err := scanScheduledTransfer(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return schedule, nil
+}
+
+func (r *ScheduledTransferRepository) Update(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    query := `
+        UPDATE scheduled_transfers
+        SET amount = ROUND(?, 4), end_date = ?, next_run_at = ?, due_at = ?, on_insufficient_funds = ?,
+            status = ?, updated_at = ?
+        WHERE id = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        schedule.Amount,
+        nullTime(schedule.EndDate),
+        schedule.NextRunAt.UTC(),
+        schedule.DueAt.UTC(),
+        schedule.OnInsufficientFunds,
+        schedule.Status,
+        schedule.UpdatedAt.UTC(),
+        schedule.ID,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ListByUser(ctx context.Context, userID uint) ([]*models.ScheduledTransfer, error) {
+    query := `
+        SELECT ` + scheduledTransferColumns + `
+        FROM scheduled_transfers
+        WHERE from_user_id = ? OR to_user_id = ?
+        ORDER BY id
+    `
+    return r.list(ctx, query, userID, userID)
+}
+
+func (r *ScheduledTransferRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+    query := `
+        SELECT ` + scheduledTransferColumns + `
+        FROM scheduled_transfers
+        WHERE status = ? AND due_at <= ?
+        ORDER BY due_at, id
+        LIMIT ?
+    `
+    return r.list(ctx, query, models.ScheduleStatusActive, now.UTC(), limit)
+}
+
+func (r *ScheduledTransferRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.ScheduledTransfer, error) {
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var schedules []*models.ScheduledTransfer
+    for rows.Next() {
+        schedule, err := scanScheduledTransfer(rows)
+        if err != nil {
+            return nil, err
+        }
+        schedules = append(schedules, schedule)
+    }
+
+    return schedules, rows.Err()
+}
+
+func (r *ScheduledTransferRepository) CreateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    query := `
+        INSERT INTO scheduled_transfer_runs
+        (schedule_id, occurrence, status, attempts, transaction_id, error, updated_at)
+        VALUES (?, ?, ?, ?, NULLIF(?, 0), ?, ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        run.ScheduleID,
+        run.Occurrence.UTC(),
+        run.Status,
+        run.Attempts,
+        run.TransactionID,
+        run.Error,
+        run.UpdatedAt.UTC(),
+    )
+
+    return mapError(err)
+}
+
+func (r *ScheduledTransferRepository) GetRun(ctx context.Context, scheduleID uint, occurrence time.Time) (*models.ScheduledTransferRun, error) {
+    run := &models.ScheduledTransferRun{}
+
+    query := `SELECT ` + scheduledRunColumns + ` FROM scheduled_transfer_runs WHERE schedule_id = ? AND occurrence = ?`
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, scheduleID, occurrence.UTC()).Scan(
+        &run.ScheduleID,
+        &run.Occurrence,
+        &run.Status,
+        &run.Attempts,
+        &run.TransactionID,
+        &run.Error,
+        &run.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return run, nil
+}
+
+func (r *ScheduledTransferRepository) GetRunByTransaction(ctx context.Context, transactionID uint) (*models.ScheduledTransferRun, error) {
+    run := &models.ScheduledTransferRun{}
+
+    query := `SELECT ` + scheduledRunColumns + ` FROM scheduled_transfer_runs WHERE transaction_id = ?`
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, transactionID).Scan(
+        &run.ScheduleID,
+        &run.Occurrence,
+        &run.Status,
+        &run.Attempts,
+        &run.TransactionID,
+        &run.Error,
+        &run.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    return run, nil
+}
+
+func (r *ScheduledTransferRepository) UpdateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    query := `
+        UPDATE scheduled_transfer_runs
+        SET status = ?, attempts = ?, transaction_id = NULLIF(?, 0), error = ?, updated_at = ?
+        WHERE schedule_id = ? AND occurrence = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        run.Status,
+        run.Attempts,
+        run.TransactionID,
+        run.Error,
+        run.UpdatedAt.UTC(),
+        run.ScheduleID,
+        run.Occurrence.UTC(),
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ClaimRetry(ctx context.Context, scheduleID uint, occurrence time.Time) error {
+    query := `
+        UPDATE scheduled_transfer_runs SET status = ?
+        WHERE schedule_id = ? AND occurrence = ? AND status = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        models.ScheduledRunPending,
+        scheduleID,
+        occurrence.UTC(),
+        models.ScheduledRunRetrying,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *ScheduledTransferRepository) ListRuns(ctx context.Context, scheduleID uint) ([]*models.ScheduledTransferRun, error) {
+    query := `
+        SELECT ` + scheduledRunColumns + `
+        FROM scheduled_transfer_runs
+        WHERE schedule_id = ?
+        ORDER BY occurrence
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, scheduleID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var runs []*models.ScheduledTransferRun
+    for rows.Next() {
+        run := &models.ScheduledTransferRun{}
+        err := rows.Scan(
+            &run.ScheduleID,
+            &run.Occurrence,
+            &run.Status,
+            &run.Attempts,
+            &run.TransactionID,
+            &run.Error,
+            &run.UpdatedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+        runs = append(runs, run)
+    }
+
+    return runs, rows.Err()
+}
@@ -6,8 +6,16 @@ import (
     "financial-service/internal/models"
     "financial-service/internal/repository"
     "fmt"
+    "time"
 )
 
+// transactionColumns lists the columns scanned into a models.Transaction, with
+// the optional ones defaulted to their zero value.
+const transactionColumns = `id, COALESCE(from_user_id, 0), COALESCE(to_user_id, 0),
+        COALESCE(from_account_id, 0), COALESCE(to_account_id, 0), amount, currency,
+        COALESCE(to_amount, 0), COALESCE(to_currency, ''), COALESCE(fx_rate, 0), COALESCE(fx_spread, 0),
+        COALESCE(quote_id, 0), COALESCE(parent_id, 0), description, type, status, created_at, booked_at`
+
 type TransactionRepository struct {
     db *sql.DB
 }
@@ -19,18 +27,30 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction) error {
     query := `
         INSERT INTO transactions 
-        (from_user_id, to_user_id, amount, type, status, created_at)
+        (from_user_id, to_user_id, from_account_id, to_account_id, amount, currency, to_amount, to_currency,
+        fx_rate, fx_spread, quote_id, parent_id, description, type, status, created_at)
         VALUES 
-        (NULLIF(?, 0), NULLIF(?, 0), ?, ?, ?, ?)
+        (NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), ROUND(?, 4), ?, ROUND(NULLIF(?, 0), 4), NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, 0),
+        NULLIF(?, 0), NULLIF(?, 0), ?, ?, ?, ?)
     `
     
-    result, err := r.db.ExecContext(ctx, query,
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
         tx.FromUserID,
         tx.ToUserID,
+        tx.FromAccountID,
+        tx.ToAccountID,
         tx.Amount,
+        tx.Currency,
+        tx.ToAmount,
+        tx.ToCurrency,
+        tx.Rate,
+        tx.Spread,
+        tx.QuoteID,
+        tx.ParentID,
+        tx.Description,
         tx.Type,
         tx.Status,
-        tx.CreatedAt,
+        tx.CreatedAt.UTC(),
     )
     if err != nil {
         return fmt.Errorf("failed to create transaction: %w", err)
@@ -49,17 +69,28 @@ func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transacti
 func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
     tx := &models.Transaction{}
     query := `
-        SELECT id, from_user_id, to_user_id, amount, type, status, created_at
+        SELECT ` + transactionColumns + `
         FROM transactions WHERE id = ?
     `
-    err := r.db.QueryRowContext(ctx, query, id).Scan(
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
         &tx.ID,
         &tx.FromUserID,
         &tx.ToUserID,
+        &tx.FromAccountID,
+        &tx.ToAccountID,
         &tx.Amount,
+        &tx.Currency,
+        &tx.ToAmount,
+        &tx.ToCurrency,
+        &tx.Rate,
+        &tx.Spread,
+        &tx.QuoteID,
+        &tx.ParentID,
+        &tx.Description,
         &tx.Type,
         &tx.Status,
         &tx.CreatedAt,
+        &tx.BookedAt,
     )
     if err == sql.ErrNoRows {
         return nil, repository.ErrNotFound
@@ -73,7 +104,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.T
 
 func (r *TransactionRepository) UpdateStatus(ctx context.Context, id uint, status models.TransactionStatus) error {
     query := `UPDATE transactions SET status = ? WHERE id = ?`
-    result, err := r.db.ExecContext(ctx, query, status, id)
+    result, err := conn(ctx, r.db).ExecContext(ctx, query, status, id)
     if err != nil {
         return err
     }
@@ -88,15 +119,39 @@ func (r *TransactionRepository) UpdateStatus(ctx context.Context, id uint, statu
     return nil
 }
 
+func (r *TransactionRepository) Complete(ctx context.Context, id uint, bookedAt time.Time) error {
+    query := `UPDATE transactions SET status = ?, booked_at = ? WHERE id = ?`
+    result, err := conn(ctx, r.db).ExecContext(ctx, query, models.TransactionStatusCompleted, bookedAt.UTC(), id)
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+    return nil
+}
+
+func (r *TransactionRepository) SetAccounts(ctx context.Context, id uint, fromAccountID, toAccountID uint) error {
+    query := `UPDATE transactions SET from_account_id = NULLIF(?, 0), to_account_id = NULLIF(?, 0) WHERE id = ?`
+    _, err := conn(ctx, r.db).ExecContext(ctx, query, fromAccountID, toAccountID, id)
+
+    return err
+}
+
 func (r *TransactionRepository) GetUserTransactions(ctx context.Context, userID uint, limit, offset int) ([]models.Transaction, error) {
     query := `
-        SELECT id, from_user_id, to_user_id, amount, type, status, created_at
+        SELECT ` + transactionColumns + `
         FROM transactions 
         WHERE from_user_id = ? OR to_user_id = ?
         ORDER BY created_at DESC
         LIMIT ? OFFSET ?
     `
-    rows, err := r.db.QueryContext(ctx, query, userID, userID, limit, offset)
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, userID, limit, offset)
     if err != nil {
         return nil, err
     }
@@ -104,20 +159,187 @@ func (r *TransactionRepository) GetUserTransactions(ctx context.Context, userID
 
     var transactions []models.Transaction
     for rows.Next() {
-        var tx models.Transaction
+        transactions = append(transactions, models.Transaction{})
+        tx := &transactions[len(transactions)-1]
         err := rows.Scan(
             &tx.ID,
             &tx.FromUserID,
             &tx.ToUserID,
+            &tx.FromAccountID,
+            &tx.ToAccountID,
             &tx.Amount,
+            &tx.Currency,
+            &tx.ToAmount,
+            &tx.ToCurrency,
+            &tx.Rate,
+            &tx.Spread,
+            &tx.QuoteID,
+            &tx.ParentID,
+            &tx.Description,
             &tx.Type,
             &tx.Status,
             &tx.CreatedAt,
+            &tx.BookedAt,
         )
         if err != nil {
             return nil, err
         }
-        transactions = append(transactions, tx)
     }
     return transactions, nil
-} 
\ No newline at end of file
+}
+
+func (r *TransactionRepository) GetUserTransactionsAfter(ctx context.Context, userID uint, afterID uint, limit int) ([]models.Transaction, error) {
+    query := `
+        SELECT ` + transactionColumns + `
+        FROM transactions 
+        WHERE (from_user_id = ? OR to_user_id = ?) AND id > ?
+        ORDER BY id
+        LIMIT ?
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, userID, afterID, limit)
+    if err != nil {
+        return nil, err
+    }
+
+    return scanTransactions(rows)
+}
+
+func (r *TransactionRepository) GetUserTransactionsBookedBetween(ctx context.Context, userID uint, from, to time.Time, afterID uint, limit int) ([]models.Transaction, error) {
+    query := `
+        SELECT ` + transactionColumns + `
+        FROM transactions 
+        WHERE (from_user_id = ? OR to_user_id = ?) AND booked_at >= ? AND booked_at < ? AND id > ?
+        ORDER BY id
+        LIMIT ?
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, userID, from.UTC(), to.UTC(), afterID, limit)
+    if err != nil {
+        return nil, err
+    }
+
+    return scanTransactions(rows)
+}
+
+func (r *TransactionRepository) SumUserTransactions(ctx context.Context, userID uint, txType models.TransactionType, currency string, since time.Time) (float64, int, error) {
+    query := `
+        SELECT COALESCE(SUM(amount), 0), COUNT(*)
+        FROM transactions
+        WHERE currency = ? AND created_at >= ? AND status NOT IN (?, ?, ?) AND type NOT IN (?, ?)
+            AND (? = '' OR type = ?)
+            AND ((type = ? AND to_user_id = ?) OR (type <> ? AND from_user_id = ?))
+    `
+    var total float64
+    var count int
+
+    err := conn(ctx, r.db).QueryRowContext(ctx, query,
+        currency,
+        since.UTC(),
+        models.TransactionStatusFailed,
+        models.TransactionStatusRejected,
+        models.TransactionStatusExpired,
+        models.TransactionTypeFee,
+        models.TransactionTypeInterest,
+        txType,
+        txType,
+        models.TransactionTypeCredit,
+        userID,
+        models.TransactionTypeCredit,
+        userID,
+    ).Scan(&total, &count)
+    if err != nil {
+        return 0, 0, err
+    }
+
+    return total, count, nil
+}
+
+func (r *TransactionRepository) HasPaid(ctx context.Context, fromUserID, toUserID uint) (bool, error) {
+    var exists bool
+
+    query := `
+        SELECT EXISTS (
+            SELECT 1 FROM transactions
+            WHERE from_user_id = ? AND to_user_id = ? AND type IN (?, ?) AND status = ?
+        )
+    `
+    err := conn(ctx, r.db).QueryRowContext(ctx, query,
+        fromUserID,
+        toUserID,
+        models.TransactionTypeTransfer,
+        models.TransactionTypeConversion,
+        models.TransactionStatusCompleted,
+    ).Scan(&exists)
+    if err != nil {
+        return false, err
+    }
+
+    return exists, nil
+}
+
+func (r *TransactionRepository) CountNewCounterparties(ctx context.Context, userID uint, since time.Time) (int, error) {
+    query := `
+        SELECT COUNT(DISTINCT t.to_user_id)
+        FROM transactions t
+        WHERE t.from_user_id = ? AND t.to_user_id <> t.from_user_id AND t.type IN (?, ?)
+            AND t.created_at >= ? AND t.status NOT IN (?, ?, ?)
+            AND NOT EXISTS (
+                SELECT 1 FROM transactions p
+                WHERE p.from_user_id = t.from_user_id AND p.to_user_id = t.to_user_id
+                    AND p.type IN (?, ?) AND p.status = ? AND p.created_at < ?
+            )
+    `
+    var count int
+
+    err := conn(ctx, r.db).QueryRowContext(ctx, query,
+        userID,
+        models.TransactionTypeTransfer,
+        models.TransactionTypeConversion,
+        since.UTC(),
+        models.TransactionStatusFailed,
+        models.TransactionStatusRejected,
+        models.TransactionStatusExpired,
+        models.TransactionTypeTransfer,
+        models.TransactionTypeConversion,
+        models.TransactionStatusCompleted,
+        since.UTC(),
+    ).Scan(&count)
+    if err != nil {
+        return 0, err
+    }
+
+    return count, nil
+}
+
+func scanTransactions(rows *sql.Rows) ([]models.Transaction, error) {
+    defer rows.Close()
+
+    var transactions []models.Transaction
+    for rows.Next() {
+        transactions = append(transactions, models.Transaction{})
+        tx := &transactions[len(transactions)-1]
+        err := rows.Scan(
+            &tx.ID,
+            &tx.FromUserID,
+            &tx.ToUserID,
+            &tx.FromAccountID,
+            &tx.ToAccountID,
+            &tx.Amount,
+            &tx.Currency,
+            &tx.ToAmount,
+            &tx.ToCurrency,
+            &tx.Rate,
+            &tx.Spread,
+            &tx.QuoteID,
+            &tx.ParentID,
+            &tx.Description,
+            &tx.Type,
+            &tx.Status,
+            &tx.CreatedAt,
+            &tx.BookedAt,
+        )
+        if err != nil {
+            return nil, err
+        }
+    }
+    return transactions, rows.Err()
+}
@@ -0,0 +1,85 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "financial-service/internal/repository"
+    driver "github.com/go-sql-driver/mysql"
+)
+
+type txKey struct{}
+
+// executor is satisfied by both *sql.DB and *sql.Tx.
+type executor interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type Transactor struct {
+    db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+    return &Transactor{db: db}
+}
+
+// WithinTransaction runs fn inside a database transaction. Repositories called
+// with the ctx handed to fn use that transaction; nested calls join it.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+    if inTransaction(ctx) {
+        return fn(ctx)
+    }
+
+    tx, err := t.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+
+    defer func() {
+        if p := recover(); p != nil {
+            tx.Rollback()
+            panic(p)
+        }
+    }()
+
+    if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+        tx.Rollback()
+        return err
+    }
+
+    return tx.Commit()
+}
+
+func inTransaction(ctx context.Context) bool {
+    _, ok := ctx.Value(txKey{}).(*sql.Tx)
+    return ok
+}
+
+// conn returns the transaction carried by ctx, falling back to db.
+func conn(ctx context.Context, db *sql.DB) executor {
+    if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+        return tx
+    }
+
+    return db
+}
+
+// isMySQL reports whether db is backed by the MySQL driver. The queries in
+// this package are portable to SQLite except for MySQL-only clauses, which are
+// gated on this.
+func isMySQL(db *sql.DB) bool {
+    _, ok := db.Driver().(*driver.MySQLDriver)
+    return ok
+}
+
+// mapError translates driver errors into the repository error set.
+func mapError(err error) error {
+    var mysqlErr *driver.MySQLError
+    if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+        return repository.ErrDuplicateKey
+    }
+
+    return err
+}
@@ -0,0 +1,196 @@
+package mysql
+
+import (
+    "context"
+    "database/sql"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+type TransferBatchRepository struct {
+    db *sql.DB
+}
+
+func NewTransferBatchRepository(db *sql.DB) *TransferBatchRepository {
+    return &TransferBatchRepository{db: db}
+}
+
+func (r *TransferBatchRepository) Create(ctx context.Context, batch *models.TransferBatch) error {
+    query := `
+        INSERT INTO transfer_batches
+        (client_batch_id, mode, status, item_count, succeeded_count, failed_count, created_at, completed_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        batch.ClientBatchID,
+        batch.Mode,
+        batch.Status,
+        batch.ItemCount,
+        batch.SucceededCount,
+        batch.FailedCount,
+        batch.CreatedAt.UTC(),
+        nullTime(batch.CompletedAt),
+    )
+    if err != nil {
+        return mapError(err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+
+    batch.ID = uint(id)
+
+    return nil
+}
+
+func (r *TransferBatchRepository) GetByClientID(ctx context.Context, clientBatchID string) (*models.TransferBatch, error) {
+    batch := &models.TransferBatch{}
+    var completedAt sql.NullTime
+
+    query := `
+        SELECT id, client_batch_id, mode, status, item_count, succeeded_count, failed_count, created_at, completed_at
+        FROM transfer_batches
+        WHERE client_batch_id = ?
+    `
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, clientBatchID).Scan(
+        &batch.ID,
+        &batch.ClientBatchID,
+        &batch.Mode,
+        &batch.Status,
+        &batch.ItemCount,
+        &batch.SucceededCount,
+        &batch.FailedCount,
+        &batch.CreatedAt,
+        &completedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, repository.ErrNotFound
+    }
+
+    if err != nil {
+        return nil, err
+    }
+
+    if completedAt.Valid {
+        batch.CompletedAt = &completedAt.Time
+    }
+
+    return batch, nil
+}
+
+func (r *TransferBatchRepository) Update(ctx context.Context, batch *models.TransferBatch) error {
+    query := `
+        UPDATE transfer_batches
+        SET status = ?, succeeded_count = ?, failed_count = ?, completed_at = ?
+        WHERE id = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        batch.Status,
+        batch.SucceededCount,
+        batch.FailedCount,
+        nullTime(batch.CompletedAt),
+        batch.ID,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *TransferBatchRepository) CreateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    query := `
+        INSERT INTO transfer_batch_items
+        (batch_id, item_index, from_user_id, to_user_id, amount, currency, status, transaction_id, error)
+        VALUES (?, ?, ?, ?, ROUND(?, 4), ?, ?, NULLIF(?, 0), ?)
+    `
+    _, err := conn(ctx, r.db).ExecContext(ctx, query,
+        item.BatchID,
+        item.Index,
+        item.FromUserID,
+        item.ToUserID,
+        item.Amount,
+        item.Currency,
+        item.Status,
+        item.TransactionID,
+        item.Error,
+    )
+
+    return mapError(err)
+}
+
+func (r *TransferBatchRepository) UpdateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    query := `
+        UPDATE transfer_batch_items
+        SET status = ?, transaction_id = NULLIF(?, 0), error = ?
+        WHERE batch_id = ? AND item_index = ?
+    `
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
+        item.Status,
+        item.TransactionID,
+        item.Error,
+        item.BatchID,
+        item.Index,
+    )
+    if err != nil {
+        return err
+    }
+
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return repository.ErrNotFound
+    }
+
+    return nil
+}
+
+func (r *TransferBatchRepository) GetItems(ctx context.Context, batchID uint) ([]*models.TransferBatchItem, error) {
+    query := `
+        SELECT batch_id, item_index, from_user_id, to_user_id, amount, currency, status,
+            COALESCE(transaction_id, 0), error
+        FROM transfer_batch_items
+        WHERE batch_id = ?
+        ORDER BY item_index
+    `
+    rows, err := conn(ctx, r.db).QueryContext(ctx, query, batchID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var items []*models.TransferBatchItem
+    for rows.Next() {
+        item := &models.TransferBatchItem{}
+        err := rows.Scan(
+            &item.BatchID,
+            &item.Index,
+            &item.FromUserID,
+            &item.ToUserID,
+            &item.Amount,
+            &item.Currency,
+            &item.Status,
+            &item.TransactionID,
+            &item.Error,
+        )
+        if err != nil {
+            return nil, err
+        }
+        items = append(items, item)
+    }
+
+    return items, rows.Err()
+}
@@ -18,19 +18,21 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
     query := `
-        INSERT INTO users (username, email, password_hash, role, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?, ?)
+        INSERT INTO users (username, email, password_hash, role, tier, password_changed_at, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     `
-    result, err := r.db.ExecContext(ctx, query,
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
         user.Username,
         user.Email,
         user.PasswordHash,
         user.Role,
+        user.Tier,
+        nullTime(user.PasswordChangedAt),
         user.CreatedAt,
         user.UpdatedAt,
     )
     if err != nil {
-        return err
+        return mapError(err)
     }
 
     id, err := result.LastInsertId()
@@ -44,16 +46,19 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
     user := &models.User{}
+    var passwordChangedAt sql.NullTime
     query := `
-        SELECT id, username, email, password_hash, role, created_at, updated_at
+        SELECT id, username, email, password_hash, role, tier, password_changed_at, created_at, updated_at
         FROM users WHERE id = ?
     `
-    err := r.db.QueryRowContext(ctx, query, id).Scan(
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
         &user.ID,
         &user.Username,
         &user.Email,
         &user.PasswordHash,
         &user.Role,
+        &user.Tier,
+        &passwordChangedAt,
         &user.CreatedAt,
         &user.UpdatedAt,
     )
@@ -66,23 +71,30 @@ func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, er
         return nil, err
     }
 
+    if passwordChangedAt.Valid {
+        user.PasswordChangedAt = &passwordChangedAt.Time
+    }
+
     return user, nil
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
     user := &models.User{}
+    var passwordChangedAt sql.NullTime
 
     query := `
-        SELECT id, username, email, password_hash, role, created_at, updated_at
+        SELECT id, username, email, password_hash, role, tier, password_changed_at, created_at, updated_at
         FROM users WHERE email = ?
     `
 
-    err := r.db.QueryRowContext(ctx, query, email).Scan(
+    err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
         &user.ID,
         &user.Username,
         &user.Email,
         &user.PasswordHash,
         &user.Role,
+        &user.Tier,
+        &passwordChangedAt,
         &user.CreatedAt,
         &user.UpdatedAt,
     )
@@ -95,20 +107,26 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
         return nil, err
     }
 
+    if passwordChangedAt.Valid {
+        user.PasswordChangedAt = &passwordChangedAt.Time
+    }
+
     return user, nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
     query := `
         UPDATE users 
-        SET username = ?, email = ?, password_hash = ?, role = ?, updated_at = ?
+        SET username = ?, email = ?, password_hash = ?, role = ?, tier = ?, password_changed_at = ?, updated_at = ?
         WHERE id = ?
     `
-    result, err := r.db.ExecContext(ctx, query,
+    result, err := conn(ctx, r.db).ExecContext(ctx, query,
         user.Username,
         user.Email,
         user.PasswordHash,
         user.Role,
+        user.Tier,
+        nullTime(user.PasswordChangedAt),
         user.UpdatedAt,
         user.ID,
     )
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+    "errors"
+    "financial-service/internal/repository"
+    driver "github.com/mattn/go-sqlite3"
+)
+
+// mapError translates SQLite constraint violations into the repository error
+// set the MySQL backend reports.
+func mapError(err error) error {
+    var sqliteErr driver.Error
+    if !errors.As(err, &sqliteErr) {
+        return err
+    }
+
+    switch sqliteErr.ExtendedCode {
+        case driver.ErrConstraintUnique, driver.ErrConstraintPrimaryKey:
+            return repository.ErrDuplicateKey
+        case driver.ErrConstraintForeignKey:
+            return repository.ErrInvalidData
+    }
+
+    return err
+}
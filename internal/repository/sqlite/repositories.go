@@ -0,0 +1,172 @@
+// Package sqlite runs the SQL repositories against an SQLite database. The
+// queries in the mysql package are written to be portable, so this package
+// reuses them and only adapts what the two drivers report differently.
+package sqlite
+
+import (
+    "context"
+    "database/sql"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository/mysql"
+)
+
+type UserRepository struct {
+    *mysql.UserRepository
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+    return &UserRepository{mysql.NewUserRepository(db)}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+    return mapError(r.UserRepository.Create(ctx, user))
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+    return mapError(r.UserRepository.Update(ctx, user))
+}
+
+type BalanceRepository struct {
+    *mysql.BalanceRepository
+}
+
+func NewBalanceRepository(db *sql.DB) *BalanceRepository {
+    return &BalanceRepository{mysql.NewBalanceRepository(db)}
+}
+
+func (r *BalanceRepository) CreateBalance(ctx context.Context, balance *models.Balance) error {
+    return mapError(r.BalanceRepository.CreateBalance(ctx, balance))
+}
+
+func NewTransactionRepository(db *sql.DB) *mysql.TransactionRepository {
+    return mysql.NewTransactionRepository(db)
+}
+
+func NewAuditLogRepository(db *sql.DB) *mysql.AuditLogRepository {
+    return mysql.NewAuditLogRepository(db)
+}
+
+func NewTransactor(db *sql.DB) *mysql.Transactor {
+    return mysql.NewTransactor(db)
+}
+
+type BalanceSnapshotRepository struct {
+    *mysql.BalanceSnapshotRepository
+}
+
+func NewBalanceSnapshotRepository(db *sql.DB) *BalanceSnapshotRepository {
+    return &BalanceSnapshotRepository{mysql.NewBalanceSnapshotRepository(db)}
+}
+
+func (r *BalanceSnapshotRepository) Create(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+    return mapError(r.BalanceSnapshotRepository.Create(ctx, snapshot))
+}
+
+func NewFXRateRepository(db *sql.DB) *mysql.FXRateRepository {
+    return mysql.NewFXRateRepository(db)
+}
+
+type FXQuoteRepository struct {
+    *mysql.FXQuoteRepository
+}
+
+func NewFXQuoteRepository(db *sql.DB) *FXQuoteRepository {
+    return &FXQuoteRepository{mysql.NewFXQuoteRepository(db)}
+}
+
+func (r *FXQuoteRepository) Create(ctx context.Context, quote *models.FXQuote) error {
+    return mapError(r.FXQuoteRepository.Create(ctx, quote))
+}
+
+func NewLimitRepository(db *sql.DB) *mysql.LimitRepository {
+    return mysql.NewLimitRepository(db)
+}
+
+type InterestRepository struct {
+    *mysql.InterestRepository
+}
+
+func NewInterestRepository(db *sql.DB) *InterestRepository {
+    return &InterestRepository{mysql.NewInterestRepository(db)}
+}
+
+func (r *InterestRepository) CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error {
+    return mapError(r.InterestRepository.CreateAccrual(ctx, accrual))
+}
+
+func (r *InterestRepository) CreateRun(ctx context.Context, date time.Time) error {
+    return mapError(r.InterestRepository.CreateRun(ctx, date))
+}
+
+type ScheduledTransferRepository struct {
+    *mysql.ScheduledTransferRepository
+}
+
+func NewScheduledTransferRepository(db *sql.DB) *ScheduledTransferRepository {
+    return &ScheduledTransferRepository{mysql.NewScheduledTransferRepository(db)}
+}
+
+func (r *ScheduledTransferRepository) Create(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    return mapError(r.ScheduledTransferRepository.Create(ctx, schedule))
+}
+
+func (r *ScheduledTransferRepository) CreateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    return mapError(r.ScheduledTransferRepository.CreateRun(ctx, run))
+}
+
+type TransferBatchRepository struct {
+    *mysql.TransferBatchRepository
+}
+
+func NewTransferBatchRepository(db *sql.DB) *TransferBatchRepository {
+    return &TransferBatchRepository{mysql.NewTransferBatchRepository(db)}
+}
+
+func (r *TransferBatchRepository) Create(ctx context.Context, batch *models.TransferBatch) error {
+    return mapError(r.TransferBatchRepository.Create(ctx, batch))
+}
+
+func (r *TransferBatchRepository) CreateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    return mapError(r.TransferBatchRepository.CreateItem(ctx, item))
+}
+
+type ImportRepository struct {
+    *mysql.ImportRepository
+}
+
+func NewImportRepository(db *sql.DB) *ImportRepository {
+    return &ImportRepository{mysql.NewImportRepository(db)}
+}
+
+func (r *ImportRepository) Create(ctx context.Context, imp *models.Import) error {
+    return mapError(r.ImportRepository.Create(ctx, imp))
+}
+
+func (r *ImportRepository) CreateRow(ctx context.Context, row *models.ImportRow) error {
+    return mapError(r.ImportRepository.CreateRow(ctx, row))
+}
+
+type AccountRepository struct {
+    *mysql.AccountRepository
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+    return &AccountRepository{mysql.NewAccountRepository(db)}
+}
+
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+    return mapError(r.AccountRepository.Create(ctx, account))
+}
+
+type ApprovalRepository struct {
+    *mysql.ApprovalRepository
+}
+
+func NewApprovalRepository(db *sql.DB) *ApprovalRepository {
+    return &ApprovalRepository{mysql.NewApprovalRepository(db)}
+}
+
+func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approval) error {
+    return mapError(r.ApprovalRepository.Create(ctx, approval))
+}
@@ -0,0 +1,429 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+var (
+    ErrAccountNotFound      = errors.New("account not found")
+    ErrAccountNotEmpty      = errors.New("account still holds money")
+    ErrAccountUsed          = errors.New("accounts with transactions cannot be deleted")
+    ErrInvalidAccountStatus = errors.New("account status does not allow this")
+    ErrReasonRequired       = errors.New("a reason is required")
+)
+
+// AccountService opens and manages the accounts users hold their money in.
+type AccountService struct {
+    accountRepo repository.AccountRepository
+    balanceRepo repository.BalanceRepository
+    userRepo    repository.UserRepository
+    transactor  repository.Transactor
+    auditLogger *AuditLogger
+    events      *BalanceEvents
+}
+
+func NewAccountService(
+    accountRepo repository.AccountRepository,
+    balanceRepo repository.BalanceRepository,
+    userRepo repository.UserRepository,
+    transactor repository.Transactor,
+) *AccountService {
+    return &AccountService{
+        accountRepo: accountRepo,
+        balanceRepo: balanceRepo,
+        userRepo:    userRepo,
+        transactor:  transactor,
+    }
+}
+
+func (s *AccountService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// SetBalanceEvents publishes the user and currency of accounts that are
+// deleted or change status, since that can change which account is their
+// default and so the balance cached for them.
+func (s *AccountService) SetBalanceEvents(events *BalanceEvents) {
+    s.events = events
+}
+
+func (s *AccountService) audit(ctx context.Context, id uint, action string, changes map[string]interface{}) {
+    if s.auditLogger == nil {
+        return
+    }
+
+    if err := s.auditLogger.LogAction(ctx, "account", id, action, changes); err != nil {
+        log.Error().Err(err).Msg("Failed to log audit")
+    }
+}
+
+// openAccount saves a new active account together with its empty balance.
+// Callers that need both or neither run it inside a storage transaction.
+func openAccount(ctx context.Context, accountRepo repository.AccountRepository, balanceRepo repository.BalanceRepository, account *models.Account) (*models.Balance, error) {
+    now := time.Now()
+
+    account.Status = models.AccountStatusActive
+    account.CreatedAt = now
+    account.UpdatedAt = now
+
+    if err := accountRepo.Create(ctx, account); err != nil {
+        if err == repository.ErrInvalidData {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, account.UserID)
+        }
+        return nil, fmt.Errorf("failed to create account: %w", err)
+    }
+
+    balance := &models.Balance{
+        AccountID:     account.ID,
+        UserID:        account.UserID,
+        Currency:      account.Currency,
+        LastUpdatedAt: now,
+    }
+
+    if err := balanceRepo.CreateBalance(ctx, balance); err != nil {
+        return nil, fmt.Errorf("failed to create balance: %w", err)
+    }
+
+    return balance, nil
+}
+
+// findAccount loads the account with the given ID, which must belong to
+// userID and hold currency, or the user's default account in currency when
+// id is zero. A missing default account is returned as nil.
+func findAccount(ctx context.Context, accountRepo repository.AccountRepository, id, userID uint, currency string) (*models.Account, error) {
+    if id != 0 {
+        account, err := accountRepo.GetByID(ctx, id)
+        if err != nil {
+            if err == repository.ErrNotFound {
+                return nil, fmt.Errorf("%w: %d", ErrAccountNotFound, id)
+            }
+            return nil, fmt.Errorf("failed to get account: %w", err)
+        }
+
+        if err := account.CheckOwner(userID, currency); err != nil {
+            return nil, err
+        }
+
+        return account, nil
+    }
+
+    account, err := accountRepo.GetDefault(ctx, userID, currency)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, nil
+        }
+        return nil, fmt.Errorf("failed to get account: %w", err)
+    }
+
+    return account, nil
+}
+
+// checkAccountStatus refuses tx if the status of the account it pays from
+// or into forbids it; either may be nil. Interest and fees are booked to
+// frozen accounts regardless, fees having been allowed with the transaction
+// they are charged on.
+func checkAccountStatus(tx *models.Transaction, from, to *models.Account, rejectFrozenCredits bool) error {
+    if tx.Type == models.TransactionTypeInterest || tx.Type == models.TransactionTypeFee {
+        for _, account := range []*models.Account{from, to} {
+            if account != nil && account.Status == models.AccountStatusClosed {
+                return fmt.Errorf("account %d: %w", account.ID, models.ErrAccountClosed)
+            }
+        }
+        return nil
+    }
+
+    if from != nil {
+        if err := from.CheckDebit(); err != nil {
+            return fmt.Errorf("source: %w", err)
+        }
+    }
+
+    if to != nil {
+        if err := to.CheckCredit(rejectFrozenCredits); err != nil {
+            return fmt.Errorf("destination: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// Create opens an account of the given type for the user. The user's first
+// account in a currency becomes their default account in it.
+func (s *AccountService) Create(ctx context.Context, userID uint, accountType models.AccountType, currency string) (*models.Account, error) {
+    account := &models.Account{
+        UserID:   userID,
+        Type:     accountType,
+        Currency: currency,
+    }
+
+    if err := account.Validate(); err != nil {
+        return nil, err
+    }
+
+    if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        balance, err := openAccount(ctx, s.accountRepo, s.balanceRepo, account)
+        account.Balance = balance
+        return err
+    })
+
+    if err != nil {
+        account.ID = 0
+        return nil, err
+    }
+
+    s.audit(ctx, account.ID, "create", map[string]interface{}{
+        "user_id":  account.UserID,
+        "type":     account.Type,
+        "currency": account.Currency,
+    })
+
+    return account, nil
+}
+
+// Get returns the account with its balance.
+func (s *AccountService) Get(ctx context.Context, id uint) (*models.Account, error) {
+    account, err := s.accountRepo.GetByID(ctx, id)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrAccountNotFound, id)
+        }
+        return nil, fmt.Errorf("failed to get account: %w", err)
+    }
+
+    balance, err := s.balanceRepo.GetAccountBalance(ctx, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    account.Balance = balance
+
+    return account, nil
+}
+
+// ListByUser returns the user's accounts, oldest first, with their balances.
+func (s *AccountService) ListByUser(ctx context.Context, userID uint) ([]*models.Account, error) {
+    accounts, err := s.accountRepo.ListByUser(ctx, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list accounts: %w", err)
+    }
+
+    balances, err := s.balanceRepo.GetUserBalances(ctx, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list balances: %w", err)
+    }
+
+    byAccount := make(map[uint]*models.Balance, len(balances))
+    for _, balance := range balances {
+        byAccount[balance.AccountID] = balance
+    }
+
+    for _, account := range accounts {
+        account.Balance = byAccount[account.ID]
+    }
+
+    return accounts, nil
+}
+
+// AccountUpdate holds the changes to an account; nil fields are left as they
+// are.
+type AccountUpdate struct {
+    Type *models.AccountType `json:"type"`
+}
+
+func (s *AccountService) Update(ctx context.Context, id uint, update AccountUpdate) (*models.Account, error) {
+    account, err := s.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    changes := map[string]interface{}{}
+
+    if update.Type != nil && *update.Type != account.Type {
+        changes["from_type"] = account.Type
+        changes["to_type"] = *update.Type
+        account.Type = *update.Type
+    }
+
+    if len(changes) == 0 {
+        return account, nil
+    }
+
+    if account.Status == models.AccountStatusClosed {
+        return nil, fmt.Errorf("account %d: %w", id, models.ErrAccountClosed)
+    }
+
+    if err := account.Validate(); err != nil {
+        return nil, err
+    }
+
+    account.UpdatedAt = time.Now()
+
+    if err := s.accountRepo.Update(ctx, account); err != nil {
+        return nil, fmt.Errorf("failed to update account: %w", err)
+    }
+
+    s.audit(ctx, id, "update", changes)
+
+    return account, nil
+}
+
+// Delete removes an account opened by mistake. Only accounts that hold
+// nothing and were never used can be deleted.
+func (s *AccountService) Delete(ctx context.Context, id uint) error {
+    var account *models.Account
+
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        var err error
+        if account, err = s.Get(ctx, id); err != nil {
+            return err
+        }
+
+        if account.Balance.Amount != 0 {
+            return fmt.Errorf("%w: account %d holds %v %s", ErrAccountNotEmpty, id, account.Balance.Amount, account.Currency)
+        }
+
+        used, err := s.accountRepo.HasTransactions(ctx, id)
+        if err != nil {
+            return fmt.Errorf("failed to check account transactions: %w", err)
+        }
+        if used {
+            return ErrAccountUsed
+        }
+
+        if err := s.accountRepo.Delete(ctx, id); err != nil {
+            return fmt.Errorf("failed to delete account: %w", err)
+        }
+
+        return nil
+    })
+
+    if err != nil {
+        return err
+    }
+
+    s.events.Publish(account.Currency, account.UserID)
+
+    s.audit(ctx, id, "delete", map[string]interface{}{
+        "user_id":  account.UserID,
+        "type":     account.Type,
+        "currency": account.Currency,
+    })
+
+    return nil
+}
+
+// Freeze stops money leaving the account, and entering it too if credits to
+// frozen accounts are refused, until it is unfrozen. The reason is taken
+// from the context.
+func (s *AccountService) Freeze(ctx context.Context, id uint) (*models.Account, error) {
+    return s.setStatus(ctx, id, "freeze", models.AccountStatusActive, models.AccountStatusFrozen)
+}
+
+// Unfreeze makes a frozen account active again.
+func (s *AccountService) Unfreeze(ctx context.Context, id uint) (*models.Account, error) {
+    return s.setStatus(ctx, id, "unfreeze", models.AccountStatusFrozen, models.AccountStatusActive)
+}
+
+// Close permanently closes an account holding nothing. Unlike Delete, the
+// account and its history are kept. The reason is taken from the context.
+func (s *AccountService) Close(ctx context.Context, id uint) (*models.Account, error) {
+    if ReasonFromContext(ctx) == "" {
+        return nil, fmt.Errorf("%w to close an account", ErrReasonRequired)
+    }
+
+    var account *models.Account
+    var previous models.AccountStatus
+
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        var err error
+        if account, err = s.Get(ctx, id); err != nil {
+            return err
+        }
+
+        if account.Status == models.AccountStatusClosed {
+            return fmt.Errorf("account %d: %w", id, models.ErrAccountClosed)
+        }
+
+        if account.Balance.Amount != 0 {
+            return fmt.Errorf("%w: account %d holds %v %s", ErrAccountNotEmpty, id, account.Balance.Amount, account.Currency)
+        }
+
+        previous = account.Status
+
+        return s.saveStatus(ctx, account, models.AccountStatusClosed)
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    s.events.Publish(account.Currency, account.UserID)
+
+    s.audit(ctx, id, "close", map[string]interface{}{
+        "from_status": previous,
+        "to_status":   account.Status,
+        "user_id":     account.UserID,
+        "currency":    account.Currency,
+    })
+
+    return account, nil
+}
+
+// setStatus moves the account from status from to status to.
+func (s *AccountService) setStatus(ctx context.Context, id uint, action string, from, to models.AccountStatus) (*models.Account, error) {
+    if ReasonFromContext(ctx) == "" {
+        return nil, fmt.Errorf("%w to %s an account", ErrReasonRequired, action)
+    }
+
+    var account *models.Account
+
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        var err error
+        if account, err = s.Get(ctx, id); err != nil {
+            return err
+        }
+
+        if account.Status != from {
+            return fmt.Errorf("cannot %s account %d, it is %s: %w", action, id, account.Status, ErrInvalidAccountStatus)
+        }
+
+        return s.saveStatus(ctx, account, to)
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    s.events.Publish(account.Currency, account.UserID)
+
+    s.audit(ctx, id, action, map[string]interface{}{
+        "from_status": from,
+        "to_status":   to,
+    })
+
+    return account, nil
+}
+
+func (s *AccountService) saveStatus(ctx context.Context, account *models.Account, status models.AccountStatus) error {
+    account.Status = status
+    account.UpdatedAt = time.Now()
+
+    if err := s.accountRepo.Update(ctx, account); err != nil {
+        return fmt.Errorf("failed to update account: %w", err)
+    }
+
+    return nil
+}
@@ -30,6 +30,8 @@ func (l *AuditLogger) LogAction(ctx context.Context, entityType string, entityID
         EntityID:   entityID,
         Action:     action,
         Changes:    string(changesJSON),
+        Actor:      ActorFromContext(ctx),
+        Reason:     ReasonFromContext(ctx),
         CreatedAt:  time.Now(),
     }
 
@@ -0,0 +1,106 @@
+package services
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "strconv"
+    "strings"
+    "time"
+    "financial-service/internal/models"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// tokenHeader is the JOSE header of every token issued: HS256 JWTs.
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type tokenClaims struct {
+    Subject   string `json:"sub"`
+    IssuedAt  int64  `json:"iat"`
+    ExpiresAt int64  `json:"exp"`
+}
+
+// SetTokenSecret makes LoginUser issue JWTs signed with secret that are
+// valid for ttl. Without a secret, logins fail.
+func (s *UserService) SetTokenSecret(secret string, ttl time.Duration) {
+    s.tokenSecret = []byte(secret)
+    s.tokenTTL = ttl
+}
+
+func (s *UserService) issueToken(user *models.User) (string, error) {
+    if len(s.tokenSecret) == 0 {
+        return "", errors.New("token signing is not configured")
+    }
+
+    now := time.Now()
+    claims, err := json.Marshal(tokenClaims{
+        Subject:   strconv.FormatUint(uint64(user.ID), 10),
+        IssuedAt:  now.Unix(),
+        ExpiresAt: now.Add(s.tokenTTL).Unix(),
+    })
+    if err != nil {
+        return "", err
+    }
+
+    unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+    return unsigned + "." + s.sign(unsigned), nil
+}
+
+func (s *UserService) sign(unsigned string) string {
+    mac := hmac.New(sha256.New, s.tokenSecret)
+    mac.Write([]byte(unsigned))
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UserFromToken verifies a token issued by LoginUser and returns the user it
+// was issued to. Tokens issued before the user last changed their password
+// are no longer valid.
+func (s *UserService) UserFromToken(ctx context.Context, token string) (*models.User, error) {
+    if len(s.tokenSecret) == 0 {
+        return nil, ErrInvalidToken
+    }
+
+    parts := strings.Split(token, ".")
+    if len(parts) != 3 || parts[0] != tokenHeader {
+        return nil, ErrInvalidToken
+    }
+
+    if !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[0]+"."+parts[1]))) {
+        return nil, ErrInvalidToken
+    }
+
+    payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+
+    var claims tokenClaims
+    if err := json.Unmarshal(payload, &claims); err != nil {
+        return nil, ErrInvalidToken
+    }
+
+    if time.Now().Unix() >= claims.ExpiresAt {
+        return nil, ErrInvalidToken
+    }
+
+    userID, err := strconv.ParseUint(claims.Subject, 10, 32)
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+
+    user, err := s.GetUser(ctx, uint(userID))
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+
+    if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() {
+        return nil, ErrInvalidToken
+    }
+
+    return user, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+    "container/list"
+    "sync"
+    "sync/atomic"
+    "time"
+    "financial-service/internal/models"
+)
+
+// BalanceCache is a bounded LRU cache of balances whose entries also expire
+// after a TTL.
+type BalanceCache struct {
+    mu         sync.Mutex
+    capacity   int
+    ttl        time.Duration
+    entries    map[balanceKey]*list.Element
+    order      *list.List
+    generation uint64
+    stats      *CacheStats
+}
+
+type balanceKey struct {
+    userID   uint
+    currency string
+}
+
+type cacheEntry struct {
+    key       balanceKey
+    balance   *models.Balance
+    expiresAt time.Time
+}
+
+type CacheStats struct {
+    Hits          int64 `json:"hits"`
+    Misses        int64 `json:"misses"`
+    Evictions     int64 `json:"evictions"`
+    Invalidations int64 `json:"invalidations"`
+}
+
+func NewBalanceCache(capacity int, ttl time.Duration) *BalanceCache {
+    return &BalanceCache{
+        capacity: capacity,
+        ttl:      ttl,
+        entries:  make(map[balanceKey]*list.Element),
+        order:    list.New(),
+        stats:    &CacheStats{},
+    }
+}
+
+func (c *BalanceCache) get(key balanceKey) *models.Balance {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    elem, ok := c.entries[key]
+    if !ok {
+        atomic.AddInt64(&c.stats.Misses, 1)
+        return nil
+    }
+
+    entry := elem.Value.(*cacheEntry)
+    if time.Now().After(entry.expiresAt) {
+        c.remove(elem)
+        atomic.AddInt64(&c.stats.Misses, 1)
+        return nil
+    }
+
+    c.order.MoveToFront(elem)
+    atomic.AddInt64(&c.stats.Hits, 1)
+
+    return copyBalance(entry.balance)
+}
+
+func (c *BalanceCache) set(balance *models.Balance) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.store(balance)
+}
+
+// setIfCurrent stores a balance loaded while the cache was at generation,
+// unless an invalidation happened in the meantime and the value may be stale.
+func (c *BalanceCache) setIfCurrent(balance *models.Balance, generation uint64) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.generation != generation {
+        return
+    }
+
+    c.store(balance)
+}
+
+func (c *BalanceCache) currentGeneration() uint64 {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    return c.generation
+}
+
+func (c *BalanceCache) invalidate(key balanceKey) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.generation++
+    atomic.AddInt64(&c.stats.Invalidations, 1)
+
+    if elem, ok := c.entries[key]; ok {
+        c.remove(elem)
+    }
+}
+
+func (c *BalanceCache) Stats() CacheStats {
+    return CacheStats{
+        Hits:          atomic.LoadInt64(&c.stats.Hits),
+        Misses:        atomic.LoadInt64(&c.stats.Misses),
+        Evictions:     atomic.LoadInt64(&c.stats.Evictions),
+        Invalidations: atomic.LoadInt64(&c.stats.Invalidations),
+    }
+}
+
+func (c *BalanceCache) store(balance *models.Balance) {
+    if c.capacity <= 0 {
+        return
+    }
+
+    key := balanceKey{balance.UserID, balance.Currency}
+
+    entry := &cacheEntry{
+        key:       key,
+        balance:   copyBalance(balance),
+        expiresAt: time.Now().Add(c.ttl),
+    }
+
+    if elem, ok := c.entries[key]; ok {
+        elem.Value = entry
+        c.order.MoveToFront(elem)
+        return
+    }
+
+    c.entries[key] = c.order.PushFront(entry)
+
+    for c.order.Len() > c.capacity {
+        c.remove(c.order.Back())
+        atomic.AddInt64(&c.stats.Evictions, 1)
+    }
+}
+
+func (c *BalanceCache) remove(elem *list.Element) {
+    entry := c.order.Remove(elem).(*cacheEntry)
+    delete(c.entries, entry.key)
+}
+
+func copyBalance(b *models.Balance) *models.Balance {
+    return &models.Balance{
+        AccountID:     b.AccountID,
+        UserID:        b.UserID,
+        Currency:      b.Currency,
+        Amount:        b.Amount,
+        CreditLimit:   b.CreditLimit,
+        LastUpdatedAt: b.LastUpdatedAt,
+    }
+}
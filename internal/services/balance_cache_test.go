@@ -0,0 +1,240 @@
+package services
+
+import (
+    "context"
+    "sync"
+    "sync/atomic"
+    "testing"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository/memory"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestBalanceCache(t *testing.T) {
+    balance := func(userID uint, amount float64) *models.Balance {
+        return &models.Balance{UserID: userID, Currency: "USD", Amount: amount}
+    }
+
+    tests := []struct {
+        name     string
+        capacity int
+        ttl      time.Duration
+        run      func(c *BalanceCache)
+        // cached maps the users still cached to their amounts
+        cached   map[uint]float64
+        stats    CacheStats
+    }{
+        {
+            name:     "least recently used is evicted",
+            capacity: 2,
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                c.set(balance(1, 10))
+                c.set(balance(2, 20))
+                c.get(balanceKey{1, "USD"})
+                c.set(balance(3, 30))
+            },
+            cached: map[uint]float64{1: 10, 3: 30},
+            stats:  CacheStats{Hits: 1, Evictions: 1},
+        },
+        {
+            name:     "updating an entry does not evict",
+            capacity: 2,
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                c.set(balance(1, 10))
+                c.set(balance(2, 20))
+                c.set(balance(1, 15))
+            },
+            cached: map[uint]float64{1: 15, 2: 20},
+        },
+        {
+            name:     "zero capacity caches nothing",
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                c.set(balance(1, 10))
+            },
+            cached: map[uint]float64{},
+        },
+        {
+            name:     "expired entries are dropped",
+            capacity: 2,
+            ttl:      time.Millisecond,
+            run: func(c *BalanceCache) {
+                c.set(balance(1, 10))
+                time.Sleep(5 * time.Millisecond)
+            },
+            cached: map[uint]float64{},
+        },
+        {
+            name:     "invalidation drops only its key",
+            capacity: 2,
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                c.set(balance(1, 10))
+                c.set(balance(2, 20))
+                c.invalidate(balanceKey{1, "USD"})
+            },
+            cached: map[uint]float64{2: 20},
+            stats:  CacheStats{Invalidations: 1},
+        },
+        {
+            name:     "fill loaded before an invalidation is dropped",
+            capacity: 2,
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                generation := c.currentGeneration()
+                c.invalidate(balanceKey{2, "USD"})
+                c.setIfCurrent(balance(1, 10), generation)
+            },
+            cached: map[uint]float64{},
+            stats:  CacheStats{Invalidations: 1},
+        },
+        {
+            name:     "fill loaded after an invalidation is kept",
+            capacity: 2,
+            ttl:      time.Minute,
+            run: func(c *BalanceCache) {
+                c.invalidate(balanceKey{1, "USD"})
+                c.setIfCurrent(balance(1, 10), c.currentGeneration())
+            },
+            cached: map[uint]float64{1: 10},
+            stats:  CacheStats{Invalidations: 1},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            c := NewBalanceCache(tt.capacity, tt.ttl)
+            tt.run(c)
+            stats := c.Stats()
+
+            for userID := uint(1); userID <= 3; userID++ {
+                cached := c.get(balanceKey{userID, "USD"})
+                amount, ok := tt.cached[userID]
+                if !ok {
+                    assert.Nil(t, cached, "user %d", userID)
+                } else if assert.NotNil(t, cached, "user %d", userID) {
+                    assert.Equal(t, amount, cached.Amount)
+                }
+            }
+
+            // Lookups made above are not counted
+            assert.Equal(t, tt.stats.Hits, stats.Hits)
+            assert.Equal(t, tt.stats.Evictions, stats.Evictions)
+            assert.Equal(t, tt.stats.Invalidations, stats.Invalidations)
+        })
+    }
+}
+
+// gatedBalances counts balance reads and holds each one, after reading, until
+// the gate is opened.
+type gatedBalances struct {
+    *memory.BalanceRepository
+    reads int32
+    read  chan struct{}
+    gate  chan struct{}
+}
+
+func (r *gatedBalances) GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error) {
+    balance, err := r.BalanceRepository.GetBalance(ctx, userID, currency)
+    atomic.AddInt32(&r.reads, 1)
+
+    select {
+        case r.read <- struct{}{}:
+        default:
+    }
+    <-r.gate
+
+    return balance, err
+}
+
+// newGatedBalanceService registers a user holding 10 USD and returns a
+// balance service reading through a gate, with the events dropping its cache.
+func newGatedBalanceService(t *testing.T) (*BalanceService, *gatedBalances, *BalanceEvents, *models.Balance) {
+    t.Helper()
+
+    ctx := context.Background()
+    store := memory.NewStore()
+    users := memory.NewUserRepository(store)
+    accounts := memory.NewAccountRepository(store)
+    balances := &gatedBalances{
+        BalanceRepository: memory.NewBalanceRepository(store),
+        read:              make(chan struct{}, 1),
+        gate:              make(chan struct{}),
+    }
+
+    user, err := NewUserService(users, accounts, balances, "USD").RegisterUser(ctx, "alice", "alice@example.com", "Passw0rd!23")
+    require.NoError(t, err)
+
+    stored, err := balances.BalanceRepository.GetBalance(ctx, user.ID, "USD")
+    require.NoError(t, err)
+    stored.Amount = 10
+    require.NoError(t, balances.UpdateBalance(ctx, stored))
+
+    events := NewBalanceEvents()
+    service := NewBalanceService(balances, accounts, memory.NewTransactionRepository(store), 10, time.Minute)
+    service.SetBalanceEvents(events)
+
+    return service, balances, events, stored
+}
+
+func TestBalanceServiceDropsStaleFill(t *testing.T) {
+    ctx := context.Background()
+    service, balances, events, stored := newGatedBalanceService(t)
+
+    loaded := make(chan *models.Balance)
+    go func() {
+        balance, err := service.GetBalance(ctx, stored.UserID, "USD")
+        assert.NoError(t, err)
+        loaded <- balance
+    }()
+
+    // The balance changes after the read but before it is cached
+    <-balances.read
+    stored.Amount = 20
+    require.NoError(t, balances.UpdateBalance(ctx, stored))
+    events.Publish("USD", stored.UserID)
+    close(balances.gate)
+
+    assert.Equal(t, 10.0, (<-loaded).Amount)
+
+    balance, err := service.GetBalance(ctx, stored.UserID, "USD")
+    require.NoError(t, err)
+    assert.Equal(t, 20.0, balance.Amount)
+    assert.Equal(t, int32(2), atomic.LoadInt32(&balances.reads))
+
+    // Which is then cached
+    balance, err = service.GetBalance(ctx, stored.UserID, "USD")
+    require.NoError(t, err)
+    assert.Equal(t, 20.0, balance.Amount)
+    assert.Equal(t, int32(2), atomic.LoadInt32(&balances.reads))
+}
+
+func TestBalanceServiceCollapsesConcurrentMisses(t *testing.T) {
+    ctx := context.Background()
+    service, balances, _, stored := newGatedBalanceService(t)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 5; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+
+            balance, err := service.GetBalance(ctx, stored.UserID, "USD")
+            if assert.NoError(t, err) {
+                assert.Equal(t, 10.0, balance.Amount)
+            }
+        }()
+    }
+
+    // Give the other lookups time to join the one read in flight
+    <-balances.read
+    time.Sleep(50 * time.Millisecond)
+    close(balances.gate)
+    wg.Wait()
+
+    assert.Equal(t, int32(1), atomic.LoadInt32(&balances.reads))
+}
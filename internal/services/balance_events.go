@@ -0,0 +1,75 @@
+package services
+
+import "sync"
+
+// BalanceEvents fans out notifications that a user's stored balance in a
+// currency changed.
+// Listeners run synchronously on the publishing goroutine and must not block.
+// Events are in-process only; other processes sharing the database (such as
+// finctl) are not seen, which is why cached balances also expire.
+type BalanceEvents struct {
+    mu                 sync.RWMutex
+    listeners          []func(userID uint, currency string)
+    overdraftListeners []func(event OverdraftEvent)
+}
+
+// OverdraftEvent reports a balance that went below zero (Overdrawn) or came
+// back to zero or above.
+type OverdraftEvent struct {
+    AccountID   uint    `json:"account_id"`
+    UserID      uint    `json:"user_id"`
+    Currency    string  `json:"currency"`
+    Amount      float64 `json:"amount"`
+    CreditLimit float64 `json:"credit_limit"`
+    Overdrawn   bool    `json:"overdrawn"`
+}
+
+func NewBalanceEvents() *BalanceEvents {
+    return &BalanceEvents{}
+}
+
+func (e *BalanceEvents) Subscribe(listener func(userID uint, currency string)) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    e.listeners = append(e.listeners, listener)
+}
+
+func (e *BalanceEvents) Publish(currency string, userIDs ...uint) {
+    if e == nil {
+        return
+    }
+
+    e.mu.RLock()
+    defer e.mu.RUnlock()
+
+    for _, userID := range userIDs {
+        for _, listener := range e.listeners {
+            listener(userID, currency)
+        }
+    }
+}
+
+// SubscribeOverdraft registers a listener for balances entering or leaving
+// overdraft.
+func (e *BalanceEvents) SubscribeOverdraft(listener func(event OverdraftEvent)) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    e.overdraftListeners = append(e.overdraftListeners, listener)
+}
+
+func (e *BalanceEvents) PublishOverdraft(events ...OverdraftEvent) {
+    if e == nil {
+        return
+    }
+
+    e.mu.RLock()
+    defer e.mu.RUnlock()
+
+    for _, event := range events {
+        for _, listener := range e.overdraftListeners {
+            listener(event)
+        }
+    }
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// BalanceHistoryService answers point-in-time balance questions. A balance
+// at time T is the latest end-of-day snapshot taken at or before T plus a
+// replay of the transactions booked between the snapshot and T. Booking, not
+// creation, time counts: a transaction held for approval changes no balance
+// until it is approved, which may be after a snapshot was taken.
+type BalanceHistoryService struct {
+    balanceRepo  repository.BalanceRepository
+    txRepo       repository.TransactionRepository
+    snapshotRepo repository.BalanceSnapshotRepository
+}
+
+type BalanceHistoryEntry struct {
+    TransactionID uint                   `json:"transaction_id"`
+    Type          models.TransactionType `json:"type"`
+    Amount        float64                `json:"amount"`
+    Balance       float64                `json:"balance"`
+    CreatedAt     time.Time              `json:"created_at"`
+    BookedAt      time.Time              `json:"booked_at"`
+}
+
+type BalanceHistory struct {
+    AccountID      uint                  `json:"account_id"`
+    UserID         uint                  `json:"user_id"`
+    Currency       string                `json:"currency"`
+    From           time.Time             `json:"from"`
+    To             time.Time             `json:"to"`
+    OpeningBalance float64               `json:"opening_balance"`
+    ClosingBalance float64               `json:"closing_balance"`
+    Entries        []BalanceHistoryEntry `json:"entries"`
+}
+
+func NewBalanceHistoryService(
+    balanceRepo repository.BalanceRepository,
+    txRepo repository.TransactionRepository,
+    snapshotRepo repository.BalanceSnapshotRepository,
+) *BalanceHistoryService {
+    return &BalanceHistoryService{
+        balanceRepo:  balanceRepo,
+        txRepo:       txRepo,
+        snapshotRepo: snapshotRepo,
+    }
+}
+
+// GetBalanceAsOf returns the user's balance in currency, held in their
+// default account, as it stood at asOf.
+func (s *BalanceHistoryService) GetBalanceAsOf(ctx context.Context, userID uint, currency string, asOf time.Time) (*models.Balance, error) {
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        return nil, err
+    }
+
+    amount, err := s.balanceAt(ctx, balance, asOf)
+    if err != nil {
+        return nil, err
+    }
+
+    return &models.Balance{
+        AccountID:     balance.AccountID,
+        UserID:        userID,
+        Currency:      currency,
+        Amount:        amount,
+        LastUpdatedAt: asOf,
+    }, nil
+}
+
+// GetHistory returns the running balance of the user's default account in
+// currency after each transaction booked in [from, to).
+func (s *BalanceHistoryService) GetHistory(ctx context.Context, userID uint, currency string, from, to time.Time) (*BalanceHistory, error) {
+    if !from.Before(to) {
+        return nil, errors.New("from must be before to")
+    }
+
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        return nil, err
+    }
+
+    opening, err := s.balanceAt(ctx, balance, from)
+    if err != nil {
+        return nil, err
+    }
+
+    history := &BalanceHistory{
+        AccountID:      balance.AccountID,
+        UserID:         userID,
+        Currency:       currency,
+        From:           from,
+        To:             to,
+        OpeningBalance: opening,
+        Entries:        []BalanceHistoryEntry{},
+    }
+
+    running := opening
+
+    err = s.replay(ctx, balance, from, to, func(tx *models.Transaction, change float64) {
+        running = models.RoundAmount(running+change, currency)
+        history.Entries = append(history.Entries, BalanceHistoryEntry{
+            TransactionID: tx.ID,
+            Type:          tx.Type,
+            Amount:        change,
+            Balance:       running,
+            CreatedAt:     tx.CreatedAt,
+            BookedAt:      *tx.BookedAt,
+        })
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    history.ClosingBalance = running
+
+    return history, nil
+}
+
+// TakeSnapshots records the balance of every account as of asOf. Accounts
+// that already have a snapshot for asOf are skipped, so the job can safely
+// rerun.
+func (s *BalanceHistoryService) TakeSnapshots(ctx context.Context, asOf time.Time) (int, error) {
+    var afterAccountID uint
+    var taken int
+
+    for {
+        balances, err := s.balanceRepo.ListBalances(ctx, afterAccountID, reconcilePageSize)
+        if err != nil {
+            return taken, fmt.Errorf("failed to list balances: %w", err)
+        }
+
+        for _, balance := range balances {
+            afterAccountID = balance.AccountID
+
+            amount, err := s.balanceAt(ctx, balance, asOf)
+            if err != nil {
+                return taken, fmt.Errorf("failed to compute balance of account %d: %w", balance.AccountID, err)
+            }
+
+            snapshot := &models.BalanceSnapshot{
+                AccountID: balance.AccountID,
+                UserID:    balance.UserID,
+                Currency:  balance.Currency,
+                AsOf:      asOf,
+                Amount:    amount,
+                CreatedAt: time.Now(),
+            }
+
+            if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
+                if errors.Is(err, repository.ErrDuplicateKey) {
+                    continue
+                }
+                return taken, fmt.Errorf("failed to save snapshot of account %d: %w", balance.AccountID, err)
+            }
+
+            taken++
+        }
+
+        if len(balances) < reconcilePageSize {
+            return taken, nil
+        }
+    }
+}
+
+// SnapshotJob returns a periodic job that snapshots balances as of the most
+// recent UTC midnight. Running it more often than daily only fills gaps.
+func (s *BalanceHistoryService) SnapshotJob(interval time.Duration) *PeriodicJob {
+    return NewPeriodicJob("balance_snapshots", interval, func(ctx context.Context) error {
+        asOf := time.Now().UTC().Truncate(24 * time.Hour)
+
+        taken, err := s.TakeSnapshots(ctx, asOf)
+        if err != nil {
+            return err
+        }
+
+        if taken > 0 {
+            log.Info().Int("snapshots", taken).Time("as_of", asOf).Msg("Balance snapshots taken")
+        }
+
+        return nil
+    })
+}
+
+// balanceAt returns the amount the account of balance held at asOf.
+func (s *BalanceHistoryService) balanceAt(ctx context.Context, balance *models.Balance, asOf time.Time) (float64, error) {
+    var start time.Time
+    var amount float64
+
+    snapshot, err := s.snapshotRepo.GetLatest(ctx, balance.AccountID, asOf)
+    if err != nil && !errors.Is(err, repository.ErrNotFound) {
+        return 0, err
+    }
+
+    if snapshot != nil {
+        start, amount = snapshot.AsOf, snapshot.Amount
+    }
+
+    err = s.replay(ctx, balance, start, asOf, func(tx *models.Transaction, change float64) {
+        amount += change
+    })
+
+    if err != nil {
+        return 0, err
+    }
+
+    return models.RoundAmount(amount, balance.Currency), nil
+}
+
+// replay calls fn, in ID order, for each transaction booked to the account of
+// balance in [from, to) with the signed change it made to the account.
+func (s *BalanceHistoryService) replay(ctx context.Context, balance *models.Balance, from, to time.Time, fn func(tx *models.Transaction, change float64)) error {
+    var afterID uint
+
+    for {
+        transactions, err := s.txRepo.GetUserTransactionsBookedBetween(ctx, balance.UserID, from, to, afterID, recalculatePageSize)
+        if err != nil {
+            return err
+        }
+
+        for i := range transactions {
+            tx := &transactions[i]
+            afterID = tx.ID
+
+            if tx.Status != models.TransactionStatusCompleted || !affectsBalance(tx, balance.AccountID) {
+                continue
+            }
+
+            fn(tx, balanceChange(tx, balance.AccountID))
+        }
+
+        if len(transactions) < recalculatePageSize {
+            return nil
+        }
+    }
+}
+
+// balanceChange is the signed amount tx moved in or out of the account.
+func balanceChange(tx *models.Transaction, accountID uint) float64 {
+    var change float64
+
+    if tx.ToAccountID == accountID {
+        change += tx.CreditAmount()
+    }
+
+    if tx.FromAccountID == accountID {
+        change -= tx.Amount
+    }
+
+    return change
+}
+
+// affectsBalance reports whether tx was booked to the account on either side.
+func affectsBalance(tx *models.Transaction, accountID uint) bool {
+    return tx.ToAccountID == accountID || tx.FromAccountID == accountID
+}
@@ -2,74 +2,156 @@ package services
 
 import (
     "context"
-    "sync"
+    "errors"
+    "fmt"
     "time"
     "financial-service/internal/models"
     "financial-service/internal/repository"
     "github.com/rs/zerolog/log"
+    "golang.org/x/sync/singleflight"
 )
 
+// recalculatePageSize is how many transactions are read per query when
+// deriving a balance from the ledger.
+const recalculatePageSize = 500
+
 type BalanceService struct {
     balanceRepo repository.BalanceRepository
+    accountRepo repository.AccountRepository
     txRepo      repository.TransactionRepository
     cache       *BalanceCache
+    loads       singleflight.Group
+    auditLogger *AuditLogger
 }
 
-type BalanceCache struct {
-    balances map[uint]*models.Balance
-    mu       sync.RWMutex
-}
-
-func NewBalanceService(balanceRepo repository.BalanceRepository, txRepo repository.TransactionRepository) *BalanceService {
+func NewBalanceService(
+    balanceRepo repository.BalanceRepository,
+    accountRepo repository.AccountRepository,
+    txRepo repository.TransactionRepository,
+    cacheSize int,
+    cacheTTL time.Duration,
+) *BalanceService {
     return &BalanceService{
         balanceRepo: balanceRepo,
+        accountRepo: accountRepo,
         txRepo:      txRepo,
-        cache: &BalanceCache{
-            balances: make(map[uint]*models.Balance),
-        },
+        cache:       NewBalanceCache(cacheSize, cacheTTL),
     }
 }
 
-func (s *BalanceService) GetBalance(ctx context.Context, userID uint) (*models.Balance, error) {
-    balance, err := s.balanceRepo.GetBalance(ctx, userID)
+func (s *BalanceService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// SetBalanceEvents drops cached balances whenever events reports a change.
+func (s *BalanceService) SetBalanceEvents(events *BalanceEvents) {
+    events.Subscribe(func(userID uint, currency string) {
+        s.cache.invalidate(balanceKey{userID, currency})
+        s.loads.Forget(loadKey(userID, currency))
+    })
+}
+
+// GetBalance serves from the cache, collapsing concurrent misses for the same
+// user and currency into a single repository read.
+func (s *BalanceService) GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error) {
+    if balance := s.cache.get(balanceKey{userID, currency}); balance != nil {
+        return balance, nil
+    }
+
+    generation := s.cache.currentGeneration()
+
+    result, err, _ := s.loads.Do(loadKey(userID, currency), func() (interface{}, error) {
+        balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+        if err != nil {
+            return nil, err
+        }
 
-    log.Printf("Balance: %+v", balance)
+        s.cache.setIfCurrent(balance, generation)
+
+        return balance, nil
+    })
 
     if err != nil {
         return nil, err
     }
 
-    s.cache.set(userID, balance)
+    return copyBalance(result.(*models.Balance)), nil
+}
 
-    return balance, nil
+func loadKey(userID uint, currency string) string {
+    return fmt.Sprintf("%d/%s", userID, currency)
 }
 
-func (s *BalanceService) RecalculateBalance(ctx context.Context, userID uint) error {
-    transactions, err := s.txRepo.GetUserTransactions(ctx, userID, 0, 0)
+// GetUserBalances lists every currency balance the user holds.
+func (s *BalanceService) GetUserBalances(ctx context.Context, userID uint) ([]*models.Balance, error) {
+    return s.balanceRepo.GetUserBalances(ctx, userID)
+}
+
+func (s *BalanceService) CacheStats() CacheStats {
+    return s.cache.Stats()
+}
 
+// DeriveBalance computes the balance of a user's default account in currency
+// from its completed transactions.
+func (s *BalanceService) DeriveBalance(ctx context.Context, userID uint, currency string) (float64, error) {
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
     if err != nil {
-        return err
+        return 0, err
     }
 
+    return deriveBalance(ctx, s.txRepo, balance)
+}
+
+// deriveBalance streams through every transaction of the account's owner in
+// ID order and sums the completed ones booked to the account of balance.
+func deriveBalance(ctx context.Context, txRepo repository.TransactionRepository, balance *models.Balance) (float64, error) {
     var totalBalance float64
+    var afterID uint
+
+    for {
+        transactions, err := txRepo.GetUserTransactionsAfter(ctx, balance.UserID, afterID, recalculatePageSize)
 
-    for _, tx := range transactions {
-        if tx.Status != models.TransactionStatusCompleted {
-            continue
+        if err != nil {
+            return 0, err
         }
 
-        if tx.ToUserID == userID {
-            totalBalance += tx.Amount
+        for i := range transactions {
+            tx := &transactions[i]
+            afterID = tx.ID
+
+            if tx.Status != models.TransactionStatusCompleted {
+                continue
+            }
+
+            totalBalance += balanceChange(tx, balance.AccountID)
         }
 
-        if tx.FromUserID == userID {
-            totalBalance -= tx.Amount
+        if len(transactions) < recalculatePageSize {
+            return models.RoundAmount(totalBalance, balance.Currency), nil
         }
     }
+}
+
+func (s *BalanceService) RecalculateBalance(ctx context.Context, userID uint, currency string) error {
+    previous, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        return err
+    }
+
+    totalBalance, err := deriveBalance(ctx, s.txRepo, previous)
+
+    if err != nil {
+        return err
+    }
+
+    previousAmount := previous.Amount
 
     balance := &models.Balance{
+        AccountID:     previous.AccountID,
         UserID:        userID,
+        Currency:      currency,
         Amount:        totalBalance,
+        CreditLimit:   previous.CreditLimit,
         LastUpdatedAt: time.Now(),
     }
 
@@ -77,21 +159,81 @@ func (s *BalanceService) RecalculateBalance(ctx context.Context, userID uint) er
         return err
     }
 
-    s.cache.set(userID, balance)
+    s.cache.set(balance)
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "currency":    currency,
+            "from_amount": previousAmount,
+            "to_amount":   totalBalance,
+        }
+        if err := s.auditLogger.LogAction(ctx, "balance", userID, "recalculate", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
 
     return nil
 }
 
-func (c *BalanceCache) get(userID uint) *models.Balance {
-    c.mu.RLock()
-    defer c.mu.RUnlock()
+// SetCreditLimit lets the user's default account in currency go negative
+// down to -creditLimit, opening the account if the user does not hold the
+// currency yet. Lowering the limit does not touch an existing overdraft; it only
+// refuses further debits.
+func (s *BalanceService) SetCreditLimit(ctx context.Context, userID uint, currency string, creditLimit float64) (*models.Balance, error) {
+    if err := models.ValidateCurrency(currency); err != nil {
+        return nil, err
+    }
+
+    if creditLimit < 0 {
+        return nil, errors.New("credit limit cannot be negative")
+    }
 
-    return c.balances[userID]
-}
+    if creditLimit != models.RoundAmount(creditLimit, currency) {
+        return nil, fmt.Errorf("credit limit has more decimal places than %s allows", currency)
+    }
+
+    var previousLimit float64
+
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    switch {
+        case err == nil:
+            previousLimit = balance.CreditLimit
+            if err := s.balanceRepo.SetCreditLimit(ctx, balance.AccountID, creditLimit); err != nil {
+                return nil, fmt.Errorf("failed to set credit limit: %w", err)
+            }
+            balance.CreditLimit = creditLimit
+
+        case err == repository.ErrNotFound:
+            account := &models.Account{
+                UserID:   userID,
+                Type:     models.AccountTypeChecking,
+                Currency: currency,
+            }
+            if balance, err = openAccount(ctx, s.accountRepo, s.balanceRepo, account); err != nil {
+                return nil, err
+            }
+            if err := s.balanceRepo.SetCreditLimit(ctx, balance.AccountID, creditLimit); err != nil {
+                return nil, fmt.Errorf("failed to set credit limit: %w", err)
+            }
+            balance.CreditLimit = creditLimit
+
+        default:
+            return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    s.cache.invalidate(balanceKey{userID, currency})
+    s.loads.Forget(loadKey(userID, currency))
 
-func (c *BalanceCache) set(userID uint, balance *models.Balance) {
-    c.mu.Lock()
-    defer c.mu.Unlock()
-    
-    c.balances[userID] = balance
-} 
\ No newline at end of file
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "currency":   currency,
+            "from_limit": previousLimit,
+            "to_limit":   creditLimit,
+        }
+        if err := s.auditLogger.LogAction(ctx, "balance", userID, "set_credit_limit", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return balance, nil
+}
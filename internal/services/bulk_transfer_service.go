@@ -0,0 +1,249 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// BulkTransferService accepts batches of transfers and executes them in the
+// background, recording the result of every transfer on the batch.
+type BulkTransferService struct {
+    batchRepo   repository.TransferBatchRepository
+    txService   *TransactionService
+    transactor  repository.Transactor
+    auditLogger *AuditLogger
+    maxItems    int
+}
+
+func NewBulkTransferService(
+    batchRepo repository.TransferBatchRepository,
+    txService *TransactionService,
+    transactor repository.Transactor,
+    maxItems int,
+) *BulkTransferService {
+    return &BulkTransferService{
+        batchRepo:  batchRepo,
+        txService:  txService,
+        transactor: transactor,
+        maxItems:   maxItems,
+    }
+}
+
+func (s *BulkTransferService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// Submit saves the batch and starts executing it. If a batch with the same
+// client batch ID was submitted before, that batch is returned instead and
+// created is false; nothing is executed again.
+func (s *BulkTransferService) Submit(ctx context.Context, batch *models.TransferBatch) (result *models.TransferBatch, created bool, err error) {
+    if err := batch.Validate(s.maxItems); err != nil {
+        return nil, false, err
+    }
+
+    if existing, err := s.Get(ctx, batch.ClientBatchID); err == nil {
+        return existing, false, nil
+    } else if !errors.Is(err, repository.ErrNotFound) {
+        return nil, false, err
+    }
+
+    batch.Status = models.BatchStatusProcessing
+    batch.ItemCount = len(batch.Items)
+    batch.SucceededCount = 0
+    batch.FailedCount = 0
+    batch.CreatedAt = time.Now()
+    batch.CompletedAt = nil
+
+    err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.batchRepo.Create(ctx, batch); err != nil {
+            return err
+        }
+
+        for i, item := range batch.Items {
+            item.BatchID = batch.ID
+            item.Index = i
+            item.Status = models.BatchItemPending
+            item.TransactionID = 0
+            item.Error = ""
+
+            if err := s.batchRepo.CreateItem(ctx, item); err != nil {
+                return fmt.Errorf("failed to save transfer %d: %w", i, err)
+            }
+        }
+
+        return nil
+    })
+
+    if errors.Is(err, repository.ErrDuplicateKey) {
+        // Submitted concurrently under the same client batch ID
+        existing, err := s.Get(ctx, batch.ClientBatchID)
+        return existing, false, err
+    }
+
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to save batch: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "client_batch_id": batch.ClientBatchID,
+            "mode":            batch.Mode,
+            "item_count":      batch.ItemCount,
+        }
+        if err := s.auditLogger.LogAction(ctx, "transfer_batch", batch.ID, "submit", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    // The batch is returned as submitted; processing works on a copy and
+    // outlives the request that submitted it.
+    go s.process(context.WithoutCancel(ctx), copyBatch(batch))
+
+    return batch, true, nil
+}
+
+func copyBatch(batch *models.TransferBatch) *models.TransferBatch {
+    c := *batch
+    c.Items = make([]*models.TransferBatchItem, len(batch.Items))
+    for i, item := range batch.Items {
+        itemCopy := *item
+        c.Items[i] = &itemCopy
+    }
+    return &c
+}
+
+// Get returns the batch with the given client batch ID and its items, or an
+// error wrapping repository.ErrNotFound.
+func (s *BulkTransferService) Get(ctx context.Context, clientBatchID string) (*models.TransferBatch, error) {
+    batch, err := s.batchRepo.GetByClientID(ctx, clientBatchID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("batch %q: %w", clientBatchID, err)
+        }
+        return nil, fmt.Errorf("failed to get batch: %w", err)
+    }
+
+    if batch.Items, err = s.batchRepo.GetItems(ctx, batch.ID); err != nil {
+        return nil, fmt.Errorf("failed to get batch items: %w", err)
+    }
+
+    return batch, nil
+}
+
+func (s *BulkTransferService) process(ctx context.Context, batch *models.TransferBatch) {
+    switch batch.Mode {
+        case models.BatchModeAllOrNothing:
+            s.processAll(ctx, batch)
+        default:
+            s.processEach(ctx, batch)
+    }
+
+    for _, item := range batch.Items {
+        if item.Status == models.BatchItemCompleted {
+            batch.SucceededCount++
+        } else {
+            batch.FailedCount++
+        }
+    }
+
+    switch {
+        case batch.FailedCount == 0:
+            batch.Status = models.BatchStatusCompleted
+        case batch.SucceededCount == 0:
+            batch.Status = models.BatchStatusFailed
+        default:
+            batch.Status = models.BatchStatusPartiallyCompleted
+    }
+
+    completedAt := time.Now()
+    batch.CompletedAt = &completedAt
+
+    if err := s.batchRepo.Update(ctx, batch); err != nil {
+        log.Error().Err(err).Str("client_batch_id", batch.ClientBatchID).Msg("Failed to save batch result")
+        return
+    }
+
+    log.Info().Str("client_batch_id", batch.ClientBatchID).Str("status", string(batch.Status)).
+        Int("succeeded", batch.SucceededCount).Int("failed", batch.FailedCount).Msg("Transfer batch processed")
+}
+
+// processAll applies the whole batch through TransactionService.TransferAll.
+// When it fails, the transfer at fault is marked failed and the others not
+// executed.
+func (s *BulkTransferService) processAll(ctx context.Context, batch *models.TransferBatch) {
+    txs := make([]*models.Transaction, len(batch.Items))
+    for i, item := range batch.Items {
+        txs[i] = &models.Transaction{
+            FromUserID: item.FromUserID,
+            ToUserID:   item.ToUserID,
+            Amount:     item.Amount,
+            Currency:   item.Currency,
+        }
+    }
+
+    err := s.txService.TransferAll(ctx, txs)
+
+    var itemErr *batchItemError
+    errors.As(err, &itemErr)
+
+    for i, item := range batch.Items {
+        switch {
+            case err == nil:
+                item.Status = models.BatchItemCompleted
+                item.TransactionID = txs[i].ID
+            case itemErr != nil && itemErr.index == i:
+                item.Status = models.BatchItemFailed
+                item.Error = itemErr.err.Error()
+            case itemErr != nil:
+                item.Status = models.BatchItemNotExecuted
+                item.Error = fmt.Sprintf("batch rolled back: transfer %d failed", itemErr.index)
+            default:
+                item.Status = models.BatchItemNotExecuted
+                item.Error = err.Error()
+        }
+
+        s.saveItem(ctx, item)
+    }
+}
+
+// processEach makes every transfer on its own, as many at a time as the
+// worker pool has workers.
+func (s *BulkTransferService) processEach(ctx context.Context, batch *models.TransferBatch) {
+    var wg sync.WaitGroup
+    slots := make(chan struct{}, s.txService.workerPool.numWorkers)
+
+    for _, item := range batch.Items {
+        wg.Add(1)
+        slots <- struct{}{}
+
+        go func(item *models.TransferBatchItem) {
+            defer wg.Done()
+            defer func() { <-slots }()
+
+            tx, err := s.txService.Transfer(ctx, item.FromUserID, item.ToUserID, item.Amount, item.Currency)
+            if err != nil {
+                item.Status = models.BatchItemFailed
+                item.Error = err.Error()
+            } else {
+                item.Status = models.BatchItemCompleted
+                item.TransactionID = tx.ID
+            }
+
+            s.saveItem(ctx, item)
+        }(item)
+    }
+
+    wg.Wait()
+}
+
+func (s *BulkTransferService) saveItem(ctx context.Context, item *models.TransferBatchItem) {
+    if err := s.batchRepo.UpdateItem(ctx, item); err != nil {
+        log.Error().Err(err).Uint("batch_id", item.BatchID).Int("index", item.Index).Msg("Failed to save batch item result")
+    }
+}
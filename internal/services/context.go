@@ -0,0 +1,50 @@
+package services
+
+import (
+    "context"
+    "financial-service/internal/models"
+)
+
+type contextKey string
+
+const (
+    actorKey   contextKey = "actor"
+    actorIDKey contextKey = "actor_id"
+    reasonKey  contextKey = "reason"
+)
+
+// WithActor attributes audit entries written with the returned context to
+// actor, e.g. the operator running an admin command.
+func WithActor(ctx context.Context, actor string) context.Context {
+    return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+    actor, _ := ctx.Value(actorKey).(string)
+    return actor
+}
+
+// WithActorUser attributes audit entries to an identified user, such as an
+// authenticated admin, and identifies them by user ID, which is what
+// approvals are keyed on.
+func WithActorUser(ctx context.Context, user *models.User) context.Context {
+    return context.WithValue(WithActor(ctx, user.Username), actorIDKey, user.ID)
+}
+
+// ActorIDFromContext returns the user ID of the user acting, or zero if the
+// actor is not an identified user.
+func ActorIDFromContext(ctx context.Context) uint {
+    id, _ := ctx.Value(actorIDKey).(uint)
+    return id
+}
+
+// WithReason records why an operation was performed; the reason is stored
+// alongside the audit entries it produces.
+func WithReason(ctx context.Context, reason string) context.Context {
+    return context.WithValue(ctx, reasonKey, reason)
+}
+
+func ReasonFromContext(ctx context.Context) string {
+    reason, _ := ctx.Value(reasonKey).(string)
+    return reason
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "math"
+    "os"
+    "financial-service/internal/models"
+)
+
+// FeeSchedule decides the fees charged on a transaction. Every rule matching
+// the transaction type, the payer's tier and the currency adds one fee line.
+type FeeSchedule struct {
+    Rules []FeeRule `json:"rules"`
+}
+
+// FeeRule charges a flat amount, a percentage of the transaction amount, or
+// the flat amount and percentage of the first tier the amount falls into.
+// Empty Type, Tier and Currency match anything. Amounts are in the
+// transaction's currency; the result is clamped to [Min, Max] when those are
+// set.
+type FeeRule struct {
+    Name     string                 `json:"name"`
+    Type     models.TransactionType `json:"type"`
+    Tier     string                 `json:"tier"`
+    Currency string                 `json:"currency"`
+    Kind     string                 `json:"kind"`
+    Flat     float64                `json:"flat"`
+    Percent  float64                `json:"percent"`
+    Tiers    []FeeTier              `json:"tiers"`
+    Min      float64                `json:"min"`
+    Max      float64                `json:"max"`
+}
+
+// FeeTier applies to amounts up to and including UpTo; a zero UpTo has no
+// upper bound and must come last.
+type FeeTier struct {
+    UpTo    float64 `json:"up_to"`
+    Flat    float64 `json:"flat"`
+    Percent float64 `json:"percent"`
+}
+
+const (
+    FeeKindFlat       = "flat"
+    FeeKindPercentage = "percentage"
+    FeeKindTiered     = "tiered"
+)
+
+// LoadFeeSchedule reads a JSON fee schedule from path.
+func LoadFeeSchedule(path string) (*FeeSchedule, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read fee schedule: %w", err)
+    }
+
+    schedule := &FeeSchedule{}
+    if err := json.Unmarshal(data, schedule); err != nil {
+        return nil, fmt.Errorf("failed to parse fee schedule: %w", err)
+    }
+
+    if err := schedule.Validate(); err != nil {
+        return nil, err
+    }
+
+    return schedule, nil
+}
+
+func (s *FeeSchedule) Validate() error {
+    for i := range s.Rules {
+        rule := &s.Rules[i]
+
+        if rule.Name == "" {
+            return fmt.Errorf("fee rule %d has no name", i)
+        }
+
+        if rule.Currency != "" {
+            if err := models.ValidateCurrency(rule.Currency); err != nil {
+                return fmt.Errorf("fee rule %q: %w", rule.Name, err)
+            }
+        }
+
+        if rule.Max > 0 && rule.Min > rule.Max {
+            return fmt.Errorf("fee rule %q: min is above max", rule.Name)
+        }
+
+        switch rule.Kind {
+            case FeeKindFlat, FeeKindPercentage:
+            case FeeKindTiered:
+                if len(rule.Tiers) == 0 {
+                    return fmt.Errorf("fee rule %q has no tiers", rule.Name)
+                }
+                for j, tier := range rule.Tiers {
+                    if tier.UpTo == 0 && j != len(rule.Tiers)-1 {
+                        return fmt.Errorf("fee rule %q: only the last tier can be unbounded", rule.Name)
+                    }
+                    if j > 0 && tier.UpTo != 0 && tier.UpTo <= rule.Tiers[j-1].UpTo {
+                        return fmt.Errorf("fee rule %q: tiers must be in increasing order", rule.Name)
+                    }
+                }
+            default:
+                return fmt.Errorf("fee rule %q has unknown kind %q", rule.Name, rule.Kind)
+        }
+    }
+
+    return nil
+}
+
+// Calculate returns the non-zero fees for a transaction of txType moving
+// amount in currency, paid by a user in tier.
+func (s *FeeSchedule) Calculate(txType models.TransactionType, tier, currency string, amount float64) []models.FeeLine {
+    if s == nil {
+        return nil
+    }
+
+    var lines []models.FeeLine
+
+    for i := range s.Rules {
+        rule := &s.Rules[i]
+
+        if !rule.matches(txType, tier, currency) {
+            continue
+        }
+
+        fee := models.RoundAmount(rule.fee(amount), currency)
+        if fee <= 0 {
+            continue
+        }
+
+        lines = append(lines, models.FeeLine{
+            Rule:     rule.Name,
+            Amount:   fee,
+            Currency: currency,
+        })
+    }
+
+    return lines
+}
+
+func (r *FeeRule) matches(txType models.TransactionType, tier, currency string) bool {
+    return (r.Type == "" || r.Type == txType) &&
+        (r.Tier == "" || r.Tier == tier) &&
+        (r.Currency == "" || r.Currency == currency)
+}
+
+func (r *FeeRule) fee(amount float64) float64 {
+    var fee float64
+
+    switch r.Kind {
+        case FeeKindFlat:
+            fee = r.Flat
+        case FeeKindPercentage:
+            fee = amount * r.Percent / 100
+        case FeeKindTiered:
+            for _, tier := range r.Tiers {
+                if tier.UpTo == 0 || amount <= tier.UpTo {
+                    fee = tier.Flat + amount*tier.Percent/100
+                    break
+                }
+            }
+    }
+
+    if r.Min > 0 {
+        fee = math.Max(fee, r.Min)
+    }
+
+    if r.Max > 0 {
+        fee = math.Min(fee, r.Max)
+    }
+
+    return fee
+}
+
+// totalFees sums the amounts of lines.
+func totalFees(lines []models.FeeLine) float64 {
+    var total float64
+    for _, line := range lines {
+        total += line.Amount
+    }
+    return total
+}
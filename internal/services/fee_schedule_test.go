@@ -0,0 +1,198 @@
+package services
+
+import (
+    "testing"
+    "financial-service/internal/models"
+    "github.com/stretchr/testify/assert"
+)
+
+func TestFeeScheduleCalculate(t *testing.T) {
+    schedule := &FeeSchedule{Rules: []FeeRule{
+        {Name: "transfer", Type: models.TransactionTypeTransfer, Kind: FeeKindPercentage, Percent: 1, Min: 0.5, Max: 20},
+        {Name: "premium debit", Type: models.TransactionTypeDebit, Tier: "premium", Kind: FeeKindFlat, Flat: 0},
+        {Name: "debit", Type: models.TransactionTypeDebit, Tier: "standard", Kind: FeeKindFlat, Flat: 1.5},
+        {Name: "fx", Currency: "EUR", Kind: FeeKindTiered, Tiers: []FeeTier{
+            {UpTo: 100, Flat: 1},
+            {UpTo: 1000, Percent: 0.5},
+            {Flat: 2, Percent: 0.25},
+        }},
+        {Name: "yen", Type: models.TransactionTypeTransfer, Currency: "JPY", Kind: FeeKindPercentage, Percent: 0.3},
+    }}
+
+    tests := []struct {
+        name     string
+        txType   models.TransactionType
+        tier     string
+        currency string
+        amount   float64
+        want     []models.FeeLine
+    }{
+        {
+            name:     "percentage",
+            txType:   models.TransactionTypeTransfer,
+            tier:     "standard",
+            currency: "USD",
+            amount:   200,
+            want:     []models.FeeLine{{Rule: "transfer", Amount: 2, Currency: "USD"}},
+        },
+        {
+            name:     "percentage below the minimum",
+            txType:   models.TransactionTypeTransfer,
+            tier:     "standard",
+            currency: "USD",
+            amount:   10,
+            want:     []models.FeeLine{{Rule: "transfer", Amount: 0.5, Currency: "USD"}},
+        },
+        {
+            name:     "percentage above the maximum",
+            txType:   models.TransactionTypeTransfer,
+            tier:     "standard",
+            currency: "USD",
+            amount:   5000,
+            want:     []models.FeeLine{{Rule: "transfer", Amount: 20, Currency: "USD"}},
+        },
+        {
+            name:     "rule for another tier does not apply",
+            txType:   models.TransactionTypeDebit,
+            tier:     "standard",
+            currency: "USD",
+            amount:   50,
+            want:     []models.FeeLine{{Rule: "debit", Amount: 1.5, Currency: "USD"}},
+        },
+        {
+            name:     "zero fees are left out",
+            txType:   models.TransactionTypeDebit,
+            tier:     "premium",
+            currency: "USD",
+            amount:   50,
+        },
+        {
+            name:     "no rule matches",
+            txType:   models.TransactionTypeCredit,
+            tier:     "standard",
+            currency: "USD",
+            amount:   50,
+        },
+        {
+            name:     "first tier includes its bound",
+            txType:   models.TransactionTypeCredit,
+            tier:     "standard",
+            currency: "EUR",
+            amount:   100,
+            want:     []models.FeeLine{{Rule: "fx", Amount: 1, Currency: "EUR"}},
+        },
+        {
+            name:     "middle tier",
+            txType:   models.TransactionTypeCredit,
+            tier:     "standard",
+            currency: "EUR",
+            amount:   100.01,
+            want:     []models.FeeLine{{Rule: "fx", Amount: 0.5, Currency: "EUR"}},
+        },
+        {
+            name:     "unbounded last tier",
+            txType:   models.TransactionTypeCredit,
+            tier:     "standard",
+            currency: "EUR",
+            amount:   2000,
+            want:     []models.FeeLine{{Rule: "fx", Amount: 7, Currency: "EUR"}},
+        },
+        {
+            name:     "every matching rule adds a line",
+            txType:   models.TransactionTypeTransfer,
+            tier:     "standard",
+            currency: "EUR",
+            amount:   50,
+            want: []models.FeeLine{
+                {Rule: "transfer", Amount: 0.5, Currency: "EUR"},
+                {Rule: "fx", Amount: 1, Currency: "EUR"},
+            },
+        },
+        {
+            name:     "rounded to the currency's minor units",
+            txType:   models.TransactionTypeTransfer,
+            tier:     "standard",
+            currency: "JPY",
+            amount:   1234,
+            want: []models.FeeLine{
+                {Rule: "transfer", Amount: 12, Currency: "JPY"},
+                {Rule: "yen", Amount: 4, Currency: "JPY"},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := schedule.Calculate(tt.txType, tt.tier, tt.currency, tt.amount)
+            assert.Equal(t, tt.want, got)
+        })
+    }
+}
+
+func TestFeeScheduleCalculateWithoutSchedule(t *testing.T) {
+    var schedule *FeeSchedule
+    assert.Nil(t, schedule.Calculate(models.TransactionTypeTransfer, "standard", "USD", 100))
+}
+
+func TestFeeScheduleValidate(t *testing.T) {
+    tests := []struct {
+        name    string
+        rule    FeeRule
+        wantErr string
+    }{
+        {
+            name: "flat",
+            rule: FeeRule{Name: "flat", Kind: FeeKindFlat, Flat: 1},
+        },
+        {
+            name: "tiered",
+            rule: FeeRule{Name: "tiered", Kind: FeeKindTiered, Tiers: []FeeTier{{UpTo: 10, Flat: 1}, {Percent: 1}}},
+        },
+        {
+            name:    "no name",
+            rule:    FeeRule{Kind: FeeKindFlat},
+            wantErr: "has no name",
+        },
+        {
+            name:    "unknown currency",
+            rule:    FeeRule{Name: "fee", Kind: FeeKindFlat, Currency: "XXX"},
+            wantErr: `fee rule "fee"`,
+        },
+        {
+            name:    "min above max",
+            rule:    FeeRule{Name: "fee", Kind: FeeKindPercentage, Min: 5, Max: 1},
+            wantErr: "min is above max",
+        },
+        {
+            name:    "unknown kind",
+            rule:    FeeRule{Name: "fee", Kind: "sliding"},
+            wantErr: `unknown kind "sliding"`,
+        },
+        {
+            name:    "tiered without tiers",
+            rule:    FeeRule{Name: "fee", Kind: FeeKindTiered},
+            wantErr: "has no tiers",
+        },
+        {
+            name:    "unbounded tier before the last",
+            rule:    FeeRule{Name: "fee", Kind: FeeKindTiered, Tiers: []FeeTier{{Flat: 1}, {UpTo: 10, Flat: 2}}},
+            wantErr: "only the last tier can be unbounded",
+        },
+        {
+            name:    "tiers out of order",
+            rule:    FeeRule{Name: "fee", Kind: FeeKindTiered, Tiers: []FeeTier{{UpTo: 10}, {UpTo: 10}}},
+            wantErr: "increasing order",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := (&FeeSchedule{Rules: []FeeRule{tt.rule}}).Validate()
+            if tt.wantErr == "" {
+                assert.NoError(t, err)
+            } else {
+                assert.ErrorContains(t, err, tt.wantErr)
+            }
+        })
+    }
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+// LoadFraudRules reads fraud rules from a JSON file holding {"rules": [...]}.
+func LoadFraudRules(path string) ([]models.FraudRuleConfig, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read fraud rules: %w", err)
+    }
+
+    var file struct {
+        Rules []models.FraudRuleConfig `json:"rules"`
+    }
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse fraud rules: %w", err)
+    }
+
+    for i := range file.Rules {
+        if err := file.Rules[i].Validate(); err != nil {
+            return nil, fmt.Errorf("rule %d: %w", i, err)
+        }
+    }
+
+    return file.Rules, nil
+}
+
+// FraudCheck is what fraud rules look at: a transaction about to be
+// submitted and the user who initiated it, the payer or, for credits, the
+// user credited.
+type FraudCheck struct {
+    Transaction *models.Transaction
+    User        *models.User
+    Now         time.Time
+}
+
+// FraudRule is one check of the fraud screening. Any implementation can be
+// added to a FraudEngine; the rules in the configuration are built by
+// NewFraudRule.
+type FraudRule interface {
+    Name() string
+    // Match reports whether the check hits the rule.
+    Match(ctx context.Context, check *FraudCheck) (bool, error)
+}
+
+type fraudEntry struct {
+    rule    FraudRule
+    outcome models.FraudOutcome
+}
+
+// FraudEngine screens transactions against its rules. A transaction hitting
+// no rule is allowed; otherwise the strictest outcome of the rules it hits
+// applies.
+type FraudEngine struct {
+    rules []fraudEntry
+}
+
+func NewFraudEngine() *FraudEngine {
+    return &FraudEngine{}
+}
+
+// NewFraudEngineFromConfig builds an engine holding the configured rules.
+func NewFraudEngineFromConfig(configs []models.FraudRuleConfig, txRepo repository.TransactionRepository) (*FraudEngine, error) {
+    engine := NewFraudEngine()
+
+    for i := range configs {
+        rule, err := NewFraudRule(configs[i], txRepo)
+        if err != nil {
+            return nil, fmt.Errorf("rule %s: %w", configs[i].Name, err)
+        }
+        engine.Add(rule, configs[i].Outcome)
+    }
+
+    return engine, nil
+}
+
+// Add screens transactions against rule, applying outcome when it is hit.
+func (e *FraudEngine) Add(rule FraudRule, outcome models.FraudOutcome) {
+    e.rules = append(e.rules, fraudEntry{rule, outcome})
+}
+
+func (e *FraudEngine) Screen(ctx context.Context, check *FraudCheck) (*models.FraudAssessment, error) {
+    assessment := &models.FraudAssessment{Outcome: models.FraudAllow}
+
+    for _, entry := range e.rules {
+        hit, err := entry.rule.Match(ctx, check)
+        if err != nil {
+            return nil, fmt.Errorf("fraud rule %s: %w", entry.rule.Name(), err)
+        }
+
+        if !hit {
+            continue
+        }
+
+        assessment.Rules = append(assessment.Rules, entry.rule.Name())
+        if entry.outcome.Stricter(assessment.Outcome) {
+            assessment.Outcome = entry.outcome
+        }
+    }
+
+    return assessment, nil
+}
+
+// NewFraudRule builds the rule a configuration defines.
+func NewFraudRule(config models.FraudRuleConfig, txRepo repository.TransactionRepository) (FraudRule, error) {
+    if err := config.Validate(); err != nil {
+        return nil, err
+    }
+
+    window, _ := config.WindowDuration()
+    sincePasswordChange, _ := config.PasswordChangeDuration()
+
+    base := baseRule{config: config, sincePasswordChange: sincePasswordChange}
+
+    switch config.Kind {
+        case models.FraudRuleAmount:
+            return &amountRule{base}, nil
+        case models.FraudRuleVelocity:
+            return &velocityRule{base, window, txRepo}, nil
+        default:
+            return &newCounterpartyRule{base, window, txRepo}, nil
+    }
+}
+
+// baseRule holds the filters every configured rule applies before its own
+// check.
+type baseRule struct {
+    config              models.FraudRuleConfig
+    sincePasswordChange time.Duration
+}
+
+func (r *baseRule) Name() string {
+    return r.config.Name
+}
+
+// applies reports whether the rule looks at the check at all.
+func (r *baseRule) applies(check *FraudCheck) bool {
+    tx := check.Transaction
+
+    if r.config.Type != "" && r.config.Type != tx.Type {
+        return false
+    }
+
+    if r.config.Currency != "" && r.config.Currency != tx.Currency {
+        return false
+    }
+
+    if r.sincePasswordChange > 0 {
+        changedAt := check.User.PasswordChangedAt
+        if changedAt == nil || check.Now.Sub(*changedAt) > r.sincePasswordChange {
+            return false
+        }
+    }
+
+    return true
+}
+
+// amountRule is hit by transactions above an amount.
+type amountRule struct {
+    baseRule
+}
+
+func (r *amountRule) Match(ctx context.Context, check *FraudCheck) (bool, error) {
+    return r.applies(check) && check.Transaction.Amount > r.config.Amount, nil
+}
+
+// velocityRule is hit when the user's transactions within the window,
+// counting this one, exceed the rule's count or total.
+type velocityRule struct {
+    baseRule
+    window time.Duration
+    txRepo repository.TransactionRepository
+}
+
+func (r *velocityRule) Match(ctx context.Context, check *FraudCheck) (bool, error) {
+    if !r.applies(check) {
+        return false, nil
+    }
+
+    tx := check.Transaction
+
+    total, count, err := r.txRepo.SumUserTransactions(ctx, check.User.ID, r.config.Type, tx.Currency, check.Now.Add(-r.window))
+    if err != nil {
+        return false, err
+    }
+
+    if r.config.Count > 0 && count+1 > r.config.Count {
+        return true, nil
+    }
+
+    return r.config.Amount > 0 && total+tx.Amount > r.config.Amount, nil
+}
+
+// newCounterpartyRule is hit by payments above the rule's amount to users
+// the payer never paid before. With a count, it is only hit once the payer
+// starts paying more than that many new users within the window.
+type newCounterpartyRule struct {
+    baseRule
+    window time.Duration
+    txRepo repository.TransactionRepository
+}
+
+func (r *newCounterpartyRule) Match(ctx context.Context, check *FraudCheck) (bool, error) {
+    tx := check.Transaction
+
+    if !r.applies(check) || tx.FromUserID == 0 || tx.ToUserID == 0 || tx.FromUserID == tx.ToUserID {
+        return false, nil
+    }
+
+    if tx.Amount <= r.config.Amount {
+        return false, nil
+    }
+
+    paid, err := r.txRepo.HasPaid(ctx, tx.FromUserID, tx.ToUserID)
+    if err != nil || paid {
+        return false, err
+    }
+
+    if r.config.Count == 0 {
+        return true, nil
+    }
+
+    count, err := r.txRepo.CountNewCounterparties(ctx, tx.FromUserID, check.Now.Add(-r.window))
+    if err != nil {
+        return false, err
+    }
+
+    return count+1 > r.config.Count, nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+    "context"
+    "testing"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository/memory"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestFraudEngineScreen(t *testing.T) {
+    large := models.FraudRuleConfig{Name: "large", Kind: models.FraudRuleAmount, Amount: 1000, Outcome: models.FraudReview}
+    huge := models.FraudRuleConfig{Name: "huge", Kind: models.FraudRuleAmount, Amount: 5000, Outcome: models.FraudBlock}
+    burst := models.FraudRuleConfig{Name: "burst", Kind: models.FraudRuleVelocity, Type: models.TransactionTypeTransfer, Count: 2, Window: "1h", Outcome: models.FraudReview}
+    drain := models.FraudRuleConfig{Name: "drain", Kind: models.FraudRuleVelocity, Amount: 500, Window: "24h", Outcome: models.FraudBlock}
+    stranger := models.FraudRuleConfig{Name: "stranger", Kind: models.FraudRuleNewCounterparty, Amount: 100, Outcome: models.FraudReview}
+    fanOut := models.FraudRuleConfig{Name: "fan out", Kind: models.FraudRuleNewCounterparty, Count: 2, Window: "24h", Outcome: models.FraudBlock}
+    takeover := models.FraudRuleConfig{Name: "takeover", Kind: models.FraudRuleAmount, Amount: 50, AfterPasswordChange: "1h", Outcome: models.FraudReview}
+    euro := models.FraudRuleConfig{Name: "euro", Kind: models.FraudRuleAmount, Currency: "EUR", Amount: 10, Outcome: models.FraudBlock}
+
+    // payment is a transfer alice made to the user at index to of the
+    // users bob, carol, dave and erin, age ago
+    type payment struct {
+        to     int
+        amount float64
+        age    time.Duration
+        status models.TransactionStatus
+    }
+
+    tests := []struct {
+        name            string
+        rules           []models.FraudRuleConfig
+        history         []payment
+        passwordChanged time.Duration
+        // to is the index of the payee as in payment
+        to        int
+        txType    models.TransactionType
+        amount    float64
+        currency  string
+        want      models.FraudOutcome
+        wantRules []string
+    }{
+        {
+            name:   "no rules",
+            amount: 1e6,
+            want:   models.FraudAllow,
+        },
+        {
+            name:   "at the amount threshold",
+            rules:  []models.FraudRuleConfig{large},
+            amount: 1000,
+            want:   models.FraudAllow,
+        },
+        {
+            name:      "above the amount threshold",
+            rules:     []models.FraudRuleConfig{large},
+            amount:    1000.01,
+            want:      models.FraudReview,
+            wantRules: []string{"large"},
+        },
+        {
+            name:      "strictest outcome of every rule hit",
+            rules:     []models.FraudRuleConfig{large, huge},
+            amount:    6000,
+            want:      models.FraudBlock,
+            wantRules: []string{"large", "huge"},
+        },
+        {
+            name:    "velocity count within the window",
+            rules:   []models.FraudRuleConfig{burst},
+            history: []payment{{0, 1, 30 * time.Minute, ""}, {0, 1, 2 * time.Hour, ""}},
+            amount:  1,
+            want:    models.FraudAllow,
+        },
+        {
+            name:      "velocity count exceeded",
+            rules:     []models.FraudRuleConfig{burst},
+            history:   []payment{{0, 1, 30 * time.Minute, ""}, {0, 1, 40 * time.Minute, ""}},
+            amount:    1,
+            want:      models.FraudReview,
+            wantRules: []string{"burst"},
+        },
+        {
+            name:    "velocity ignores other types",
+            rules:   []models.FraudRuleConfig{burst},
+            history: []payment{{0, 1, 30 * time.Minute, ""}, {0, 1, 40 * time.Minute, ""}},
+            txType:  models.TransactionTypeDebit,
+            amount:  1,
+            want:    models.FraudAllow,
+        },
+        {
+            name:      "velocity total exceeded",
+            rules:     []models.FraudRuleConfig{drain},
+            history:   []payment{{0, 300, time.Hour, ""}},
+            amount:    200.01,
+            want:      models.FraudBlock,
+            wantRules: []string{"drain"},
+        },
+        {
+            name:    "velocity total ignores failed transactions",
+            rules:   []models.FraudRuleConfig{drain},
+            history: []payment{{0, 300, time.Hour, models.TransactionStatusFailed}},
+            amount:  500,
+            want:    models.FraudAllow,
+        },
+        {
+            name:      "payment to a new counterparty",
+            rules:     []models.FraudRuleConfig{stranger},
+            history:   []payment{{0, 5, 48 * time.Hour, ""}},
+            to:        1,
+            amount:    150,
+            want:      models.FraudReview,
+            wantRules: []string{"stranger"},
+        },
+        {
+            name:    "payment to a known counterparty",
+            rules:   []models.FraudRuleConfig{stranger},
+            history: []payment{{0, 5, 48 * time.Hour, ""}},
+            amount:  150,
+            want:    models.FraudAllow,
+        },
+        {
+            name:   "small payment to a new counterparty",
+            rules:  []models.FraudRuleConfig{stranger},
+            to:     1,
+            amount: 100,
+            want:   models.FraudAllow,
+        },
+        {
+            name:      "counterparty only paid in a failed payment is new",
+            rules:     []models.FraudRuleConfig{stranger},
+            history:   []payment{{1, 5, 48 * time.Hour, models.TransactionStatusFailed}},
+            to:        1,
+            amount:    150,
+            want:      models.FraudReview,
+            wantRules: []string{"stranger"},
+        },
+        {
+            name:   "credits have no counterparty",
+            rules:  []models.FraudRuleConfig{stranger},
+            txType: models.TransactionTypeCredit,
+            amount: 150,
+            want:   models.FraudAllow,
+        },
+        {
+            name:    "new counterparties within the count",
+            rules:   []models.FraudRuleConfig{fanOut},
+            history: []payment{{0, 1, time.Hour, ""}},
+            to:      1,
+            amount:  1,
+            want:    models.FraudAllow,
+        },
+        {
+            name:      "new counterparties above the count",
+            rules:     []models.FraudRuleConfig{fanOut},
+            history:   []payment{{0, 1, time.Hour, ""}, {1, 1, time.Hour, ""}},
+            to:        2,
+            amount:    1,
+            want:      models.FraudBlock,
+            wantRules: []string{"fan out"},
+        },
+        {
+            name:    "counterparties paid before the window are not new",
+            rules:   []models.FraudRuleConfig{fanOut},
+            history: []payment{{0, 1, 48 * time.Hour, ""}, {1, 1, 48 * time.Hour, ""}, {0, 1, time.Hour, ""}, {1, 1, time.Hour, ""}},
+            to:      2,
+            amount:  1,
+            want:    models.FraudAllow,
+        },
+        {
+            name:            "soon after a password change",
+            rules:           []models.FraudRuleConfig{takeover},
+            passwordChanged: 10 * time.Minute,
+            amount:          60,
+            want:            models.FraudReview,
+            wantRules:       []string{"takeover"},
+        },
+        {
+            name:            "long after a password change",
+            rules:           []models.FraudRuleConfig{takeover},
+            passwordChanged: 2 * time.Hour,
+            amount:          60,
+            want:            models.FraudAllow,
+        },
+        {
+            name:   "password never changed",
+            rules:  []models.FraudRuleConfig{takeover},
+            amount: 60,
+            want:   models.FraudAllow,
+        },
+        {
+            name:   "rule for another currency",
+            rules:  []models.FraudRuleConfig{euro},
+            amount: 60,
+            want:   models.FraudAllow,
+        },
+        {
+            name:      "rule for the currency",
+            rules:     []models.FraudRuleConfig{euro},
+            amount:    60,
+            currency:  "EUR",
+            want:      models.FraudBlock,
+            wantRules: []string{"euro"},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ctx := context.Background()
+            store := memory.NewStore()
+            users := memory.NewUserRepository(store)
+            txRepo := memory.NewTransactionRepository(store)
+            now := time.Now()
+
+            alice := &models.User{Username: "alice", Email: "alice@example.com"}
+            if tt.passwordChanged > 0 {
+                changedAt := now.Add(-tt.passwordChanged)
+                alice.PasswordChangedAt = &changedAt
+            }
+            require.NoError(t, users.Create(ctx, alice))
+
+            var payees []*models.User
+            for _, name := range []string{"bob", "carol", "dave", "erin"} {
+                payee := &models.User{Username: name, Email: name + "@example.com"}
+                require.NoError(t, users.Create(ctx, payee))
+                payees = append(payees, payee)
+            }
+
+            for _, p := range tt.history {
+                status := p.status
+                if status == "" {
+                    status = models.TransactionStatusCompleted
+                }
+                require.NoError(t, txRepo.Create(ctx, &models.Transaction{
+                    FromUserID: alice.ID,
+                    ToUserID:   payees[p.to].ID,
+                    Amount:     p.amount,
+                    Currency:   "USD",
+                    Type:       models.TransactionTypeTransfer,
+                    Status:     status,
+                    CreatedAt:  now.Add(-p.age),
+                }))
+            }
+
+            engine, err := NewFraudEngineFromConfig(tt.rules, txRepo)
+            require.NoError(t, err)
+
+            tx := &models.Transaction{
+                FromUserID: alice.ID,
+                ToUserID:   payees[tt.to].ID,
+                Amount:     tt.amount,
+                Currency:   tt.currency,
+                Type:       tt.txType,
+            }
+            if tx.Type == "" {
+                tx.Type = models.TransactionTypeTransfer
+            }
+            if tx.Currency == "" {
+                tx.Currency = "USD"
+            }
+            switch tx.Type {
+                case models.TransactionTypeCredit:
+                    tx.FromUserID, tx.ToUserID = 0, alice.ID
+                case models.TransactionTypeDebit:
+                    tx.ToUserID = 0
+            }
+
+            assessment, err := engine.Screen(ctx, &FraudCheck{Transaction: tx, User: alice, Now: now})
+            require.NoError(t, err)
+            assert.Equal(t, tt.want, assessment.Outcome)
+            assert.Equal(t, tt.wantRules, assessment.Rules)
+        })
+    }
+}
+
+func TestFraudRuleConfigValidate(t *testing.T) {
+    tests := []struct {
+        name    string
+        config  models.FraudRuleConfig
+        wantErr string
+    }{
+        {
+            name:   "amount rule",
+            config: models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleAmount, Amount: 10, Outcome: models.FraudBlock},
+        },
+        {
+            name:    "no name",
+            config:  models.FraudRuleConfig{Kind: models.FraudRuleAmount, Outcome: models.FraudBlock},
+            wantErr: "name is required",
+        },
+        {
+            name:    "unknown kind",
+            config:  models.FraudRuleConfig{Name: "r", Kind: "geo", Outcome: models.FraudBlock},
+            wantErr: "unknown rule kind",
+        },
+        {
+            name:    "allow outcome",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleAmount, Outcome: models.FraudAllow},
+            wantErr: "outcome must be",
+        },
+        {
+            name:    "negative amount",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleAmount, Amount: -1, Outcome: models.FraudBlock},
+            wantErr: "cannot be negative",
+        },
+        {
+            name:    "invalid window",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleVelocity, Count: 1, Window: "a day", Outcome: models.FraudBlock},
+            wantErr: "invalid window",
+        },
+        {
+            name:    "velocity without a window",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleVelocity, Count: 1, Outcome: models.FraudBlock},
+            wantErr: "need a window",
+        },
+        {
+            name:    "velocity without a count or amount",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleVelocity, Window: "1h", Outcome: models.FraudBlock},
+            wantErr: "need a count or an amount",
+        },
+        {
+            name:    "counting new counterparties without a window",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleNewCounterparty, Count: 2, Outcome: models.FraudBlock},
+            wantErr: "needs a window",
+        },
+        {
+            name:    "invalid password change window",
+            config:  models.FraudRuleConfig{Name: "r", Kind: models.FraudRuleAmount, AfterPasswordChange: "-1h", Outcome: models.FraudBlock},
+            wantErr: "invalid after_password_change",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := tt.config.Validate()
+            if tt.wantErr == "" {
+                assert.NoError(t, err)
+            } else {
+                assert.ErrorContains(t, err, tt.wantErr)
+            }
+        })
+    }
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+    "financial-service/internal/models"
+    "github.com/rs/zerolog/log"
+)
+
+// SetFraudEngine screens new transactions with engine before they are
+// submitted. Blocked transactions are refused; flagged ones are held for
+// review, or refused if approvals are not configured.
+func (s *TransactionService) SetFraudEngine(engine *FraudEngine) {
+    s.fraud = engine
+}
+
+// sanctionsWatchlistRule names possible sanctions matches among the rules a
+// transaction hit.
+const sanctionsWatchlistRule = "sanctions watchlist"
+
+// screen runs sanctions and fraud screening on tx, initiated by user.
+// Blocked transactions are refused and recorded on the audit log.
+func (s *TransactionService) screen(ctx context.Context, tx *models.Transaction, user *models.User) (*models.FraudAssessment, error) {
+    sanctions, err := s.screenParties(ctx, tx, user)
+    if err != nil {
+        return nil, err
+    }
+
+    assessment := &models.FraudAssessment{Outcome: models.FraudAllow}
+    if s.fraud != nil {
+        assessment, err = s.fraud.Screen(ctx, &FraudCheck{
+            Transaction: tx,
+            User:        user,
+            Now:         time.Now(),
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    // A possible sanctions match is reviewed like a fraud rule hit
+    if sanctions == models.FraudReview {
+        assessment.Rules = append(assessment.Rules, sanctionsWatchlistRule)
+        if sanctions.Stricter(assessment.Outcome) {
+            assessment.Outcome = sanctions
+        }
+    }
+
+    if assessment.Outcome == models.FraudBlock {
+        return nil, s.refuse(ctx, tx, user, assessment)
+    }
+
+    return assessment, nil
+}
+
+// refuse records that fraud screening refused tx and returns the error to
+// refuse it with.
+func (s *TransactionService) refuse(ctx context.Context, tx *models.Transaction, user *models.User, assessment *models.FraudAssessment) error {
+    rules := strings.Join(assessment.Rules, ", ")
+
+    log.Warn().Uint("user_id", user.ID).Str("type", string(tx.Type)).Float64("amount", tx.Amount).
+        Str("currency", tx.Currency).Str("outcome", string(assessment.Outcome)).Str("rules", rules).
+        Msg("Transaction refused by fraud screening")
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "amount":    tx.Amount,
+            "currency":  tx.Currency,
+            "from_user": tx.FromUserID,
+            "to_user":   tx.ToUserID,
+            "type":      tx.Type,
+            "outcome":   assessment.Outcome,
+            "rules":     assessment.Rules,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user", user.ID, "fraud_block", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return fmt.Errorf("%w: %s", models.ErrFraudBlocked, rules)
+}
+
+// holdReason screens tx, initiated by user, for fraud and checks whether it
+// needs approval. It returns the kind of hold tx needs and why, or an empty
+// reason if it can be applied now.
+func (s *TransactionService) holdReason(ctx context.Context, tx *models.Transaction, user *models.User, manual bool) (models.ApprovalKind, string, error) {
+    assessment, err := s.screen(ctx, tx, user)
+    if err != nil {
+        return "", "", err
+    }
+
+    if assessment.Outcome == models.FraudReview {
+        if s.approvals == nil {
+            return "", "", s.refuse(ctx, tx, user, assessment)
+        }
+        return models.ApprovalKindReview, "flagged by: " + strings.Join(assessment.Rules, ", "), nil
+    }
+
+    return models.ApprovalKindApproval, s.approvalReason(tx, manual), nil
+}
+
+// screenUnheld screens a transaction that cannot be held, refusing it if it
+// is flagged for review as well as if it is blocked.
+func (s *TransactionService) screenUnheld(ctx context.Context, tx *models.Transaction, user *models.User) error {
+    assessment, err := s.screen(ctx, tx, user)
+    if err != nil {
+        return err
+    }
+
+    if assessment.Outcome == models.FraudReview {
+        return s.refuse(ctx, tx, user, assessment)
+    }
+
+    return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+// FXService prices currency conversions. A quote fixes the rate offered to a
+// user for a short time; TransactionService.ConvertTransfer executes it.
+type FXService struct {
+    rates     RateProvider
+    quoteRepo repository.FXQuoteRepository
+    userRepo  repository.UserRepository
+    spread    float64
+    quoteTTL  time.Duration
+}
+
+// NewFXService creates a service quoting the provider's mid rate less spread,
+// a fraction such as 0.005 for 0.5%. Quotes are valid for quoteTTL.
+func NewFXService(
+    rates RateProvider,
+    quoteRepo repository.FXQuoteRepository,
+    userRepo repository.UserRepository,
+    spread float64,
+    quoteTTL time.Duration,
+) *FXService {
+    return &FXService{
+        rates:     rates,
+        quoteRepo: quoteRepo,
+        userRepo:  userRepo,
+        spread:    spread,
+        quoteTTL:  quoteTTL,
+    }
+}
+
+// CreateQuote prices converting amount of fromCurrency into toCurrency for
+// the user.
+func (s *FXService) CreateQuote(ctx context.Context, userID uint, fromCurrency, toCurrency string, amount float64) (*models.FXQuote, error) {
+    if err := models.ValidateAmount(amount, fromCurrency); err != nil {
+        return nil, err
+    }
+
+    if err := models.ValidateCurrency(toCurrency); err != nil {
+        return nil, err
+    }
+
+    if fromCurrency == toCurrency {
+        return nil, errors.New("a quote needs two different currencies")
+    }
+
+    if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    mid, err := s.rates.Rate(ctx, fromCurrency, toCurrency)
+    if err != nil {
+        return nil, err
+    }
+
+    rate := mid * (1 - s.spread)
+    toAmount := models.RoundAmount(amount*rate, toCurrency)
+
+    if toAmount <= 0 {
+        return nil, errors.New("amount is too small to convert")
+    }
+
+    now := time.Now()
+
+    quote := &models.FXQuote{
+        UserID:       userID,
+        FromCurrency: fromCurrency,
+        ToCurrency:   toCurrency,
+        FromAmount:   amount,
+        ToAmount:     toAmount,
+        Rate:         rate,
+        Spread:       s.spread,
+        CreatedAt:    now,
+        ExpiresAt:    now.Add(s.quoteTTL),
+    }
+
+    if err := s.quoteRepo.Create(ctx, quote); err != nil {
+        return nil, fmt.Errorf("failed to save quote: %w", err)
+    }
+
+    return quote, nil
+}
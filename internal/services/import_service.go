@@ -0,0 +1,498 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/csv"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// ImportService executes files of credits and transfers. Every row is
+// validated against users and balances before any is executed, and a file
+// is only ever executed once: uploading it again returns the first import.
+type ImportService struct {
+    importRepo      repository.ImportRepository
+    userRepo        repository.UserRepository
+    balanceRepo     repository.BalanceRepository
+    txService       *TransactionService
+    transactor      repository.Transactor
+    auditLogger     *AuditLogger
+    defaultCurrency string
+    maxRows         int
+}
+
+func NewImportService(
+    importRepo repository.ImportRepository,
+    userRepo repository.UserRepository,
+    balanceRepo repository.BalanceRepository,
+    txService *TransactionService,
+    transactor repository.Transactor,
+    defaultCurrency string,
+    maxRows int,
+) *ImportService {
+    return &ImportService{
+        importRepo:      importRepo,
+        userRepo:        userRepo,
+        balanceRepo:     balanceRepo,
+        txService:       txService,
+        transactor:      transactor,
+        defaultCurrency: defaultCurrency,
+        maxRows:         maxRows,
+    }
+}
+
+func (s *ImportService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// ImportCSV imports a CSV file with a header row. The type and amount
+// columns are required; currency, from_user_id, to_user_id (or user_id for
+// credits) and reference are optional. See Import for dryRun and created.
+func (s *ImportService) ImportCSV(ctx context.Context, filename string, data []byte, dryRun bool) (result *models.Import, created bool, err error) {
+    rows, err := s.parseCSV(data)
+    if err != nil {
+        return nil, false, err
+    }
+
+    return s.Import(ctx, filename, data, rows, dryRun)
+}
+
+// Import validates the rows parsed from data and executes the valid ones in
+// order. A file whose content was imported before is not executed again; the
+// earlier import is returned and created is false. With dryRun the rows are
+// only validated and nothing is stored.
+func (s *ImportService) Import(ctx context.Context, filename string, data []byte, rows []*models.ImportRow, dryRun bool) (result *models.Import, created bool, err error) {
+    sum := sha256.Sum256(data)
+
+    imp := &models.Import{
+        FileHash:  hex.EncodeToString(sum[:]),
+        Filename:  filename,
+        Status:    models.ImportStatusProcessing,
+        RowCount:  len(rows),
+        CreatedAt: time.Now(),
+        Rows:      rows,
+    }
+
+    if !dryRun {
+        if existing, err := s.getByHash(ctx, imp.FileHash); err == nil {
+            return existing, false, nil
+        } else if !errors.Is(err, repository.ErrNotFound) {
+            return nil, false, err
+        }
+    }
+
+    if err := s.validate(ctx, rows); err != nil {
+        return nil, false, err
+    }
+
+    if dryRun {
+        imp.Status = models.ImportStatusValidated
+        for _, row := range rows {
+            if row.Status == models.ImportRowInvalid {
+                imp.FailedCount++
+            } else {
+                row.Status = models.ImportRowValid
+            }
+        }
+        return imp, true, nil
+    }
+
+    err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.importRepo.Create(ctx, imp); err != nil {
+            return err
+        }
+
+        for _, row := range rows {
+            row.ImportID = imp.ID
+            if err := s.importRepo.CreateRow(ctx, row); err != nil {
+                return fmt.Errorf("failed to save line %d: %w", row.Line, err)
+            }
+        }
+
+        return nil
+    })
+
+    if errors.Is(err, repository.ErrDuplicateKey) {
+        // The same file was uploaded concurrently
+        existing, err := s.getByHash(ctx, imp.FileHash)
+        return existing, false, err
+    }
+
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to save import: %w", err)
+    }
+
+    // Once claimed the file is executed to the end even if the caller goes
+    // away, so that the stored outcome is complete.
+    s.execute(context.WithoutCancel(ctx), imp)
+
+    return imp, true, nil
+}
+
+// Get returns an import with its rows, or an error wrapping
+// repository.ErrNotFound.
+func (s *ImportService) Get(ctx context.Context, id uint) (*models.Import, error) {
+    imp, err := s.importRepo.GetByID(ctx, id)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("import %d: %w", id, err)
+        }
+        return nil, fmt.Errorf("failed to get import: %w", err)
+    }
+
+    return s.withRows(ctx, imp)
+}
+
+func (s *ImportService) getByHash(ctx context.Context, fileHash string) (*models.Import, error) {
+    imp, err := s.importRepo.GetByHash(ctx, fileHash)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, err
+        }
+        return nil, fmt.Errorf("failed to get import: %w", err)
+    }
+
+    return s.withRows(ctx, imp)
+}
+
+func (s *ImportService) withRows(ctx context.Context, imp *models.Import) (*models.Import, error) {
+    rows, err := s.importRepo.GetRows(ctx, imp.ID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get import rows: %w", err)
+    }
+
+    imp.Rows = rows
+    return imp, nil
+}
+
+// parseCSV reads the rows of a CSV file. A missing or unknown header fails
+// the whole file; a malformed value only marks its row invalid.
+func (s *ImportService) parseCSV(data []byte) ([]*models.ImportRow, error) {
+    reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+    reader.FieldsPerRecord = -1
+    reader.TrimLeadingSpace = true
+
+    header, err := reader.Read()
+    if err == io.EOF {
+        return nil, errors.New("file is empty")
+    }
+    if err != nil {
+        return nil, fmt.Errorf("invalid CSV: %w", err)
+    }
+
+    columns := make(map[string]int)
+    for i, name := range header {
+        name = strings.ToLower(strings.TrimSpace(name))
+        switch name {
+            case "type", "amount", "currency", "from_user_id", "to_user_id", "user_id", "reference":
+            default:
+                return nil, fmt.Errorf("unknown column %q", name)
+        }
+        if _, ok := columns[name]; ok {
+            return nil, fmt.Errorf("duplicate column %q", name)
+        }
+        columns[name] = i
+    }
+
+    for _, name := range []string{"type", "amount"} {
+        if _, ok := columns[name]; !ok {
+            return nil, fmt.Errorf("missing column %q", name)
+        }
+    }
+
+    var rows []*models.ImportRow
+    for {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, fmt.Errorf("invalid CSV: %w", err)
+        }
+
+        line, _ := reader.FieldPos(0)
+
+        if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+            continue
+        }
+
+        if s.maxRows > 0 && len(rows) == s.maxRows {
+            return nil, fmt.Errorf("file has more than %d rows", s.maxRows)
+        }
+
+        rows = append(rows, parseCSVRow(line, columns, record, s.defaultCurrency))
+    }
+
+    if len(rows) == 0 {
+        return nil, errors.New("file has no rows")
+    }
+
+    return rows, nil
+}
+
+func parseCSVRow(line int, columns map[string]int, record []string, defaultCurrency string) *models.ImportRow {
+    field := func(name string) string {
+        i, ok := columns[name]
+        if !ok || i >= len(record) {
+            return ""
+        }
+        return strings.TrimSpace(record[i])
+    }
+
+    row := &models.ImportRow{
+        Line:      line,
+        Type:      models.TransactionType(strings.ToLower(field("type"))),
+        Currency:  strings.ToUpper(field("currency")),
+        Reference: field("reference"),
+        Status:    models.ImportRowPending,
+    }
+
+    if row.Currency == "" {
+        row.Currency = defaultCurrency
+    }
+
+    var errs []string
+    userID := func(name string) uint {
+        value := field(name)
+        if value == "" {
+            return 0
+        }
+        id, err := strconv.ParseUint(value, 10, 32)
+        if err != nil || id == 0 {
+            errs = append(errs, fmt.Sprintf("invalid %s %q", name, value))
+            return 0
+        }
+        return uint(id)
+    }
+
+    row.FromUserID = userID("from_user_id")
+    row.ToUserID = userID("to_user_id")
+    if id := userID("user_id"); id != 0 {
+        if row.ToUserID != 0 && row.ToUserID != id {
+            errs = append(errs, "user_id and to_user_id differ")
+        }
+        row.ToUserID = id
+    }
+
+    if amount, err := strconv.ParseFloat(field("amount"), 64); err != nil {
+        errs = append(errs, fmt.Sprintf("invalid amount %q", field("amount")))
+    } else {
+        row.Amount = amount
+    }
+
+    if len(row.Reference) > 255 {
+        errs = append(errs, "reference is longer than 255 characters")
+    }
+
+    if len(errs) > 0 {
+        row.Status = models.ImportRowInvalid
+        row.Error = strings.Join(errs, "; ")
+    }
+
+    return row
+}
+
+// validate checks every pending row before anything is executed. Balances
+// are simulated row by row in file order, so a row may spend what an
+// earlier row credits, and a row that would overdraw is refused.
+func (s *ImportService) validate(ctx context.Context, rows []*models.ImportRow) error {
+    users := make(map[uint]*models.User)
+    getUser := func(id uint) (*models.User, error) {
+        if user, ok := users[id]; ok {
+            return user, nil
+        }
+        user, err := s.userRepo.GetByID(ctx, id)
+        if err != nil {
+            if err != repository.ErrNotFound {
+                return nil, fmt.Errorf("failed to get user: %w", err)
+            }
+            user = nil
+        }
+        users[id] = user
+        return user, nil
+    }
+
+    available := make(map[balanceKey]float64)
+    getAvailable := func(userID uint, currency string) (float64, error) {
+        key := balanceKey{userID, currency}
+        if amount, ok := available[key]; ok {
+            return amount, nil
+        }
+        balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+        if err != nil {
+            if err != repository.ErrNotFound {
+                return 0, fmt.Errorf("failed to get balance: %w", err)
+            }
+            balance = &models.Balance{UserID: userID, Currency: currency}
+        }
+        available[key] = balance.Available()
+        return available[key], nil
+    }
+
+    for _, row := range rows {
+        if row.Status != models.ImportRowPending {
+            continue
+        }
+
+        invalid := func(format string, args ...interface{}) {
+            row.Status = models.ImportRowInvalid
+            row.Error = fmt.Sprintf(format, args...)
+        }
+
+        switch row.Type {
+            case models.TransactionTypeCredit:
+                if row.ToUserID == 0 {
+                    invalid("credit needs a user_id")
+                    continue
+                }
+                if row.FromUserID != 0 {
+                    invalid("credit cannot have a from_user_id")
+                    continue
+                }
+            case models.TransactionTypeTransfer:
+                if row.FromUserID == 0 || row.ToUserID == 0 {
+                    invalid("transfer needs a from_user_id and a to_user_id")
+                    continue
+                }
+                if row.FromUserID == row.ToUserID {
+                    invalid("cannot transfer to the same user")
+                    continue
+                }
+            default:
+                invalid("unsupported type %q", row.Type)
+                continue
+        }
+
+        if err := models.ValidateAmount(row.Amount, row.Currency); err != nil {
+            invalid("%v", err)
+            continue
+        }
+
+        toUser, err := getUser(row.ToUserID)
+        if err != nil {
+            return err
+        }
+        if toUser == nil {
+            invalid("user not found: %d", row.ToUserID)
+            continue
+        }
+
+        if row.Type == models.TransactionTypeCredit {
+            toAvailable, err := getAvailable(row.ToUserID, row.Currency)
+            if err != nil {
+                return err
+            }
+            fees := totalFees(s.txService.feeSchedule.Calculate(row.Type, toUser.Tier, row.Currency, row.Amount))
+            available[balanceKey{row.ToUserID, row.Currency}] = toAvailable + row.Amount - fees
+            continue
+        }
+
+        fromUser, err := getUser(row.FromUserID)
+        if err != nil {
+            return err
+        }
+        if fromUser == nil {
+            invalid("user not found: %d", row.FromUserID)
+            continue
+        }
+
+        fromAvailable, err := getAvailable(row.FromUserID, row.Currency)
+        if err != nil {
+            return err
+        }
+        toAvailable, err := getAvailable(row.ToUserID, row.Currency)
+        if err != nil {
+            return err
+        }
+
+        fees := totalFees(s.txService.feeSchedule.Calculate(row.Type, fromUser.Tier, row.Currency, row.Amount))
+        if fromAvailable < row.Amount+fees {
+            invalid("%v", models.ErrInsufficientFunds)
+            continue
+        }
+
+        available[balanceKey{row.FromUserID, row.Currency}] = fromAvailable - row.Amount - fees
+        available[balanceKey{row.ToUserID, row.Currency}] = toAvailable + row.Amount
+    }
+
+    return nil
+}
+
+// execute runs the valid rows one by one in file order through the
+// transaction service and records the outcome of each.
+func (s *ImportService) execute(ctx context.Context, imp *models.Import) {
+    for _, row := range imp.Rows {
+        if row.Status != models.ImportRowPending {
+            imp.FailedCount++
+            continue
+        }
+
+        var tx *models.Transaction
+        var err error
+        if row.Type == models.TransactionTypeCredit {
+            tx, err = s.txService.Credit(ctx, row.ToUserID, row.Amount, row.Currency)
+        } else {
+            tx, err = s.txService.Transfer(ctx, row.FromUserID, row.ToUserID, row.Amount, row.Currency)
+        }
+
+        if err != nil {
+            row.Status = models.ImportRowFailed
+            row.Error = err.Error()
+            imp.FailedCount++
+        } else {
+            row.Status = models.ImportRowCompleted
+            if tx.Status == models.TransactionStatusAwaitingApproval {
+                row.Status = models.ImportRowAwaitingApproval
+            }
+            row.TransactionID = tx.ID
+            imp.ExecutedCount++
+        }
+
+        if err := s.importRepo.UpdateRow(ctx, row); err != nil {
+            log.Error().Err(err).Uint("import_id", imp.ID).Int("line", row.Line).Msg("Failed to save import row result")
+        }
+    }
+
+    switch {
+        case imp.FailedCount == 0:
+            imp.Status = models.ImportStatusCompleted
+        case imp.ExecutedCount == 0:
+            imp.Status = models.ImportStatusFailed
+        default:
+            imp.Status = models.ImportStatusCompletedWithErrors
+    }
+
+    completedAt := time.Now()
+    imp.CompletedAt = &completedAt
+
+    if err := s.importRepo.Update(ctx, imp); err != nil {
+        log.Error().Err(err).Uint("import_id", imp.ID).Msg("Failed to save import result")
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "filename":       imp.Filename,
+            "file_hash":      imp.FileHash,
+            "row_count":      imp.RowCount,
+            "executed_count": imp.ExecutedCount,
+            "failed_count":   imp.FailedCount,
+            "status":         imp.Status,
+        }
+        if err := s.auditLogger.LogAction(ctx, "import", imp.ID, "execute", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    log.Info().Uint("import_id", imp.ID).Str("status", string(imp.Status)).
+        Int("executed", imp.ExecutedCount).Int("failed", imp.FailedCount).Msg("Import processed")
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+    "testing"
+    "financial-service/internal/models"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestImportServiceParseCSV(t *testing.T) {
+    tests := []struct {
+        name    string
+        data    string
+        want    []models.ImportRow
+        wantErr string
+    }{
+        {
+            name: "credit and transfer",
+            data: "type,amount,currency,from_user_id,to_user_id,reference\n" +
+                "credit,10.50,eur,,2,payroll\n" +
+                "TRANSFER,5,,2,3,\n",
+            want: []models.ImportRow{
+                {Line: 2, Type: models.TransactionTypeCredit, ToUserID: 2, Amount: 10.5, Currency: "EUR", Reference: "payroll", Status: models.ImportRowPending},
+                {Line: 3, Type: models.TransactionTypeTransfer, FromUserID: 2, ToUserID: 3, Amount: 5, Currency: "USD", Status: models.ImportRowPending},
+            },
+        },
+        {
+            name: "byte order mark, blank lines and user_id",
+            data: "\xef\xbb\xbfType, Amount, User_ID\n\ncredit, 1, 4\n\n",
+            want: []models.ImportRow{
+                {Line: 3, Type: models.TransactionTypeCredit, ToUserID: 4, Amount: 1, Currency: "USD", Status: models.ImportRowPending},
+            },
+        },
+        {
+            name: "invalid rows are kept with their errors",
+            data: "type,amount,to_user_id,user_id\n" +
+                "credit,abc,2,\n" +
+                "credit,1,2,3\n" +
+                "credit,1,-1,\n",
+            want: []models.ImportRow{
+                {Line: 2, Type: models.TransactionTypeCredit, ToUserID: 2, Currency: "USD", Status: models.ImportRowInvalid, Error: `invalid amount "abc"`},
+                {Line: 3, Type: models.TransactionTypeCredit, ToUserID: 3, Amount: 1, Currency: "USD", Status: models.ImportRowInvalid, Error: "user_id and to_user_id differ"},
+                {Line: 4, Type: models.TransactionTypeCredit, Amount: 1, Currency: "USD", Status: models.ImportRowInvalid, Error: `invalid to_user_id "-1"`},
+            },
+        },
+        {
+            name:    "empty file",
+            data:    "",
+            wantErr: "file is empty",
+        },
+        {
+            name:    "header only",
+            data:    "type,amount\n",
+            wantErr: "file has no rows",
+        },
+        {
+            name:    "unknown column",
+            data:    "type,amount,memo\ncredit,1,x\n",
+            wantErr: `unknown column "memo"`,
+        },
+        {
+            name:    "duplicate column",
+            data:    "type,amount,Amount\ncredit,1,1\n",
+            wantErr: `duplicate column "amount"`,
+        },
+        {
+            name:    "missing column",
+            data:    "type,user_id\ncredit,1\n",
+            wantErr: `missing column "amount"`,
+        },
+        {
+            name:    "too many rows",
+            data:    "type,amount,user_id\ncredit,1,2\ncredit,1,2\ncredit,1,2\ncredit,1,2\n",
+            wantErr: "more than 3 rows",
+        },
+        {
+            name:    "malformed CSV",
+            data:    "type,amount\n\"credit,1\n",
+            wantErr: "invalid CSV",
+        },
+    }
+
+    service := &ImportService{defaultCurrency: "USD", maxRows: 3}
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rows, err := service.parseCSV([]byte(tt.data))
+
+            if tt.wantErr != "" {
+                assert.ErrorContains(t, err, tt.wantErr)
+                return
+            }
+
+            require.NoError(t, err)
+            require.Len(t, rows, len(tt.want))
+            for i := range rows {
+                assert.Equal(t, tt.want[i], *rows[i])
+            }
+        })
+    }
+}
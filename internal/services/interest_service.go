@@ -0,0 +1,357 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// Day-count conventions deciding the fraction of a year one day of interest
+// is worth.
+const (
+    DayCountActual365 = "ACT/365"
+    DayCountActual360 = "ACT/360"
+    DayCount30360     = "30/360"
+)
+
+// InterestRate holds the annual rates, as fractions such as 0.02 for 2%,
+// paid on positive balances and charged on overdrafts in Currency. An empty
+// Currency applies to currencies without a rate of their own.
+type InterestRate struct {
+    Currency      string  `json:"currency"`
+    CreditRate    float64 `json:"credit_rate"`
+    OverdraftRate float64 `json:"overdraft_rate"`
+    DayCount      string  `json:"day_count"`
+}
+
+// LoadInterestRates reads interest rates from a JSON file holding
+// {"rates": [...]}.
+func LoadInterestRates(path string) ([]InterestRate, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read interest rates: %w", err)
+    }
+
+    var file struct {
+        Rates []InterestRate `json:"rates"`
+    }
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse interest rates: %w", err)
+    }
+
+    seen := make(map[string]bool)
+
+    for i := range file.Rates {
+        rate := &file.Rates[i]
+
+        if rate.Currency != "" {
+            if err := models.ValidateCurrency(rate.Currency); err != nil {
+                return nil, fmt.Errorf("interest rate %d: %w", i, err)
+            }
+        }
+
+        if seen[rate.Currency] {
+            return nil, fmt.Errorf("interest rate %d: currency %q is listed twice", i, rate.Currency)
+        }
+        seen[rate.Currency] = true
+
+        if rate.CreditRate < 0 || rate.OverdraftRate < 0 {
+            return nil, fmt.Errorf("interest rate %d: rates cannot be negative", i)
+        }
+
+        switch rate.DayCount {
+            case "":
+                rate.DayCount = DayCountActual365
+            case DayCountActual365, DayCountActual360, DayCount30360:
+            default:
+                return nil, fmt.Errorf("interest rate %d: unknown day count %q", i, rate.DayCount)
+        }
+    }
+
+    return file.Rates, nil
+}
+
+// dayFraction is the part of a year the day starting at day is worth under
+// the convention.
+func dayFraction(convention string, day time.Time) float64 {
+    switch convention {
+        case DayCountActual360:
+            return 1.0 / 360
+        case DayCount30360:
+            // 30/360 (bond basis): every month counts as 30 days. The 31st
+            // counts as the 30th, so the 30th of a long month accrues
+            // nothing and the 31st a day, while the last day of February
+            // makes up the rest of the month.
+            return float64(days30360(day, day.AddDate(0, 0, 1))) / 360
+        default:
+            return 1.0 / 365
+    }
+}
+
+func days30360(from, to time.Time) int {
+    d1, d2 := from.Day(), to.Day()
+    if d1 == 31 {
+        d1 = 30
+    }
+    if d2 == 31 && d1 == 30 {
+        d2 = 30
+    }
+
+    return 360*(to.Year()-from.Year()) + 30*(int(to.Month())-int(from.Month())) + d2 - d1
+}
+
+// InterestService accrues interest daily on closing balances and pays out
+// what has accrued once a month through TransactionService.
+type InterestService struct {
+    rates        []InterestRate
+    balanceRepo  repository.BalanceRepository
+    interestRepo repository.InterestRepository
+    history      *BalanceHistoryService
+    txService    *TransactionService
+}
+
+func NewInterestService(
+    rates []InterestRate,
+    balanceRepo repository.BalanceRepository,
+    interestRepo repository.InterestRepository,
+    history *BalanceHistoryService,
+    txService *TransactionService,
+) *InterestService {
+    return &InterestService{
+        rates:        rates,
+        balanceRepo:  balanceRepo,
+        interestRepo: interestRepo,
+        history:      history,
+        txService:    txService,
+    }
+}
+
+func (s *InterestService) rateFor(currency string) *InterestRate {
+    var fallback *InterestRate
+
+    for i := range s.rates {
+        switch s.rates[i].Currency {
+            case currency:
+                return &s.rates[i]
+            case "":
+                fallback = &s.rates[i]
+        }
+    }
+
+    return fallback
+}
+
+// AccruedInterest is the interest accrued on a balance and not yet paid out.
+type AccruedInterest struct {
+    UserID   uint       `json:"user_id"`
+    Currency string     `json:"currency"`
+    Amount   float64    `json:"amount"`
+    Days     int        `json:"days"`
+    Since    *time.Time `json:"since,omitempty"`
+}
+
+// GetAccrued sums the user's unpaid accruals in currency.
+func (s *InterestService) GetAccrued(ctx context.Context, userID uint, currency string) (*AccruedInterest, error) {
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        return nil, err
+    }
+
+    accruals, err := s.interestRepo.GetUnposted(ctx, balance.AccountID, time.Now())
+    if err != nil {
+        return nil, fmt.Errorf("failed to get accruals: %w", err)
+    }
+
+    accrued := &AccruedInterest{
+        UserID:   userID,
+        Currency: currency,
+        Amount:   models.RoundAmount(sumAccruals(accruals), currency),
+        Days:     len(accruals),
+    }
+
+    if len(accruals) > 0 {
+        accrued.Since = &accruals[0].Date
+    }
+
+    return accrued, nil
+}
+
+func sumAccruals(accruals []*models.InterestAccrual) float64 {
+    var total float64
+    for _, accrual := range accruals {
+        total += accrual.Amount
+    }
+    return total
+}
+
+// Run accrues every day that has ended since the last run and, whenever a
+// month is complete, pays out its interest. The first run accrues only the
+// day before now. Days are recorded once done, so an interrupted run is
+// picked up where it stopped.
+func (s *InterestService) Run(ctx context.Context, now time.Time) error {
+    today := now.UTC().Truncate(24 * time.Hour)
+
+    day := today.AddDate(0, 0, -1)
+
+    last, err := s.interestRepo.GetLastRun(ctx)
+    switch {
+        case err == nil:
+            day = last.UTC().AddDate(0, 0, 1)
+        case !errors.Is(err, repository.ErrNotFound):
+            return fmt.Errorf("failed to get last interest run: %w", err)
+    }
+
+    for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+        accrued, err := s.accrueDay(ctx, day)
+        if err != nil {
+            return fmt.Errorf("failed to accrue interest for %s: %w", day.Format("2006-01-02"), err)
+        }
+
+        next := day.AddDate(0, 0, 1)
+
+        posted := 0
+        if next.Day() == 1 {
+            if posted, err = s.post(ctx, next, day.Format("2006-01")); err != nil {
+                return fmt.Errorf("failed to post interest for %s: %w", day.Format("2006-01"), err)
+            }
+        }
+
+        if err := s.interestRepo.CreateRun(ctx, day); err != nil && !errors.Is(err, repository.ErrDuplicateKey) {
+            return fmt.Errorf("failed to record interest run: %w", err)
+        }
+
+        log.Info().Str("date", day.Format("2006-01-02")).Int("accrued", accrued).Int("posted", posted).Msg("Interest accrued")
+    }
+
+    return nil
+}
+
+// accrueDay records the interest on every account's closing balance for day.
+// Accounts already accrued for the day are skipped.
+func (s *InterestService) accrueDay(ctx context.Context, day time.Time) (int, error) {
+    var afterAccountID uint
+    var accrued int
+
+    for {
+        balances, err := s.balanceRepo.ListBalances(ctx, afterAccountID, reconcilePageSize)
+        if err != nil {
+            return accrued, fmt.Errorf("failed to list balances: %w", err)
+        }
+
+        for _, balance := range balances {
+            afterAccountID = balance.AccountID
+
+            rate := s.rateFor(balance.Currency)
+            if rate == nil {
+                continue
+            }
+
+            closing, err := s.history.balanceAt(ctx, balance, day.AddDate(0, 0, 1))
+            if err != nil {
+                return accrued, fmt.Errorf("failed to get closing balance for account %d: %w", balance.AccountID, err)
+            }
+
+            annual := rate.CreditRate
+            if closing < 0 {
+                annual = rate.OverdraftRate
+            }
+
+            if closing == 0 || annual == 0 {
+                continue
+            }
+
+            accrual := &models.InterestAccrual{
+                AccountID: balance.AccountID,
+                UserID:    balance.UserID,
+                Currency:  balance.Currency,
+                Date:      day,
+                Balance:   closing,
+                Rate:      annual,
+                DayCount:  rate.DayCount,
+                Amount:    closing * annual * dayFraction(rate.DayCount, day),
+                CreatedAt: time.Now(),
+            }
+
+            if err := s.interestRepo.CreateAccrual(ctx, accrual); err != nil {
+                if errors.Is(err, repository.ErrDuplicateKey) {
+                    continue
+                }
+                return accrued, fmt.Errorf("failed to save accrual for account %d: %w", balance.AccountID, err)
+            }
+
+            accrued++
+        }
+
+        if len(balances) < reconcilePageSize {
+            return accrued, nil
+        }
+    }
+}
+
+// post pays out, per account, the accruals dated before before. Totals that
+// round to zero stay accrued and roll into the next month.
+func (s *InterestService) post(ctx context.Context, before time.Time, period string) (int, error) {
+    var afterAccountID uint
+    var posted int
+
+    for {
+        balances, err := s.balanceRepo.ListBalances(ctx, afterAccountID, reconcilePageSize)
+        if err != nil {
+            return posted, fmt.Errorf("failed to list balances: %w", err)
+        }
+
+        for _, balance := range balances {
+            afterAccountID = balance.AccountID
+
+            accruals, err := s.interestRepo.GetUnposted(ctx, balance.AccountID, before)
+            if err != nil {
+                return posted, fmt.Errorf("failed to get accruals for account %d: %w", balance.AccountID, err)
+            }
+
+            amount := models.RoundAmount(sumAccruals(accruals), balance.Currency)
+            if amount == 0 {
+                continue
+            }
+
+            // The accruals are marked in the transaction that pays them, so
+            // a failure can neither pay them twice nor lose them
+            markPosted := func(ctx context.Context, tx *models.Transaction) error {
+                if err := s.interestRepo.MarkPosted(ctx, balance.AccountID, before, tx.ID); err != nil {
+                    return fmt.Errorf("failed to mark accruals posted: %w", err)
+                }
+                return nil
+            }
+
+            _, err = s.txService.PostInterest(ctx, balance.UserID, balance.AccountID, amount, balance.Currency, "interest "+period, markPosted)
+            if errors.Is(err, models.ErrAccountClosed) {
+                // Interest still accrued when the account was closed is
+                // forfeited
+                log.Warn().Uint("account_id", balance.AccountID).Float64("amount", amount).Msg("Interest not posted to closed account")
+                continue
+            }
+            if err != nil {
+                return posted, fmt.Errorf("failed to post interest for account %d: %w", balance.AccountID, err)
+            }
+
+            posted++
+        }
+
+        if len(balances) < reconcilePageSize {
+            return posted, nil
+        }
+    }
+}
+
+// Job returns a periodic job running the accrual. Running it more often than
+// daily only finds no complete day to accrue.
+func (s *InterestService) Job(interval time.Duration) *PeriodicJob {
+    return NewPeriodicJob("interest_accrual", interval, func(ctx context.Context) error {
+        return s.Run(WithActor(ctx, "interest_accrual"), time.Now())
+    })
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "financial-service/internal/repository/memory"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+type interestFixture struct {
+    balances  *memory.BalanceRepository
+    snapshots *memory.BalanceSnapshotRepository
+    interest  *memory.InterestRepository
+    history   *BalanceHistoryService
+    txs       *TransactionService
+    balance   *models.Balance
+}
+
+// newInterestFixture registers a user whose USD balance was amount from
+// since on.
+func newInterestFixture(t *testing.T, amount float64, since time.Time) *interestFixture {
+    t.Helper()
+
+    ctx := context.Background()
+    store := memory.NewStore()
+    users := memory.NewUserRepository(store)
+    accounts := memory.NewAccountRepository(store)
+    txRepo := memory.NewTransactionRepository(store)
+
+    f := &interestFixture{
+        balances:  memory.NewBalanceRepository(store),
+        snapshots: memory.NewBalanceSnapshotRepository(store),
+        interest:  memory.NewInterestRepository(store),
+    }
+
+    f.history = NewBalanceHistoryService(f.balances, txRepo, f.snapshots)
+
+    f.txs = NewTransactionService(txRepo, f.balances, users, 1)
+    t.Cleanup(f.txs.Cleanup)
+    f.txs.SetTransactor(store)
+    f.txs.SetAccounts(accounts)
+
+    user, err := NewUserService(users, accounts, f.balances, "USD").RegisterUser(ctx, "alice", "alice@example.com", "Passw0rd!23")
+    require.NoError(t, err)
+
+    f.balance, err = f.balances.GetBalance(ctx, user.ID, "USD")
+    require.NoError(t, err)
+
+    require.NoError(t, f.snapshots.Create(ctx, &models.BalanceSnapshot{
+        AccountID: f.balance.AccountID,
+        UserID:    user.ID,
+        Currency:  "USD",
+        AsOf:      since,
+        Amount:    amount,
+        CreatedAt: since,
+    }))
+
+    return f
+}
+
+func (f *interestFixture) service(rates []InterestRate, interest repository.InterestRepository) *InterestService {
+    return NewInterestService(rates, f.balances, interest, f.history, f.txs)
+}
+
+// paid returns the interest paid so far, which is all the account holds.
+func (f *interestFixture) paid(t *testing.T) float64 {
+    t.Helper()
+
+    balance, err := f.balances.GetAccountBalance(context.Background(), f.balance.AccountID)
+    require.NoError(t, err)
+
+    return balance.Amount
+}
+
+func (f *interestFixture) unposted(t *testing.T) []*models.InterestAccrual {
+    t.Helper()
+
+    accruals, err := f.interest.GetUnposted(context.Background(), f.balance.AccountID, time.Now())
+    require.NoError(t, err)
+
+    return accruals
+}
+
+// unmarkableInterest fails to mark accruals posted.
+type unmarkableInterest struct {
+    *memory.InterestRepository
+}
+
+func (r unmarkableInterest) MarkPosted(ctx context.Context, accountID uint, before time.Time, transactionID uint) error {
+    return errors.New("mark failed")
+}
+
+func TestInterestServicePostIsAtomic(t *testing.T) {
+    ctx := context.Background()
+    jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+    rates := []InterestRate{{CreditRate: 0.0365, DayCount: DayCountActual365}}
+
+    f := newInterestFixture(t, 1000, jan1)
+    require.NoError(t, f.interest.CreateRun(ctx, time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)))
+
+    // The accruals cannot be marked, so the interest is not paid either
+    err := f.service(rates, unmarkableInterest{f.interest}).Run(ctx, time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC))
+    require.Error(t, err)
+    assert.Equal(t, 0.0, f.paid(t))
+    assert.Len(t, f.unposted(t), 2)
+
+    // The next run pays them once
+    require.NoError(t, f.service(rates, f.interest).Run(ctx, time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)))
+    assert.Equal(t, 0.2, f.paid(t))
+    assert.Empty(t, f.unposted(t))
+}
+
+func TestDayFraction(t *testing.T) {
+    date := func(year int, month time.Month, day int) time.Time {
+        return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+    }
+
+    tests := []struct {
+        name       string
+        convention string
+        day        time.Time
+        want       float64
+    }{
+        {name: "actual/365", convention: DayCountActual365, day: date(2024, 1, 31), want: 1.0 / 365},
+        {name: "actual/360", convention: DayCountActual360, day: date(2024, 1, 31), want: 1.0 / 360},
+        {name: "30/360 ordinary day", convention: DayCount30360, day: date(2024, 1, 15), want: 1.0 / 360},
+        {name: "30/360 30th of a long month", convention: DayCount30360, day: date(2024, 1, 30), want: 0},
+        {name: "30/360 31st", convention: DayCount30360, day: date(2024, 1, 31), want: 1.0 / 360},
+        {name: "30/360 30th of a short month", convention: DayCount30360, day: date(2024, 4, 30), want: 1.0 / 360},
+        {name: "30/360 end of February", convention: DayCount30360, day: date(2023, 2, 28), want: 3.0 / 360},
+        {name: "30/360 28 February of a leap year", convention: DayCount30360, day: date(2024, 2, 28), want: 1.0 / 360},
+        {name: "30/360 end of February of a leap year", convention: DayCount30360, day: date(2024, 2, 29), want: 2.0 / 360},
+        {name: "30/360 end of the year", convention: DayCount30360, day: date(2024, 12, 31), want: 1.0 / 360},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            assert.InDelta(t, tt.want, dayFraction(tt.convention, tt.day), 1e-12)
+        })
+    }
+}
+
+func TestDayFraction30360Month(t *testing.T) {
+    for _, month := range []time.Time{
+        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+        time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+        time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+    } {
+        var total float64
+        for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+            total += dayFraction(DayCount30360, day)
+        }
+
+        assert.InDelta(t, 30.0/360, total, 1e-12, month.Format("2006-01"))
+    }
+}
+
+func TestInterestServiceRun(t *testing.T) {
+    date := func(month time.Month, day int) time.Time {
+        return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+    }
+
+    // Each rate pays 0.10 a day, or a counted day, on 1000
+    act365 := []InterestRate{{CreditRate: 0.0365, OverdraftRate: 0.0365, DayCount: DayCountActual365}}
+    act360 := []InterestRate{{CreditRate: 0.036, DayCount: DayCountActual360}}
+    thirty360 := []InterestRate{{CreditRate: 0.036, DayCount: DayCount30360}}
+
+    tests := []struct {
+        name    string
+        rates   []InterestRate
+        balance float64
+        lastRun time.Time
+        now     time.Time
+        // unposted is the number of accruals left to pay and accrued their
+        // total
+        unposted int
+        accrued  float64
+        paid     float64
+    }{
+        {
+            name:     "first run accrues only yesterday",
+            rates:    act365,
+            balance:  1000,
+            now:      date(1, 16).Add(9 * time.Hour),
+            unposted: 1,
+            accrued:  0.1,
+        },
+        {
+            name:     "actual/360",
+            rates:    act360,
+            balance:  1000,
+            lastRun:  date(1, 9),
+            now:      date(1, 15),
+            unposted: 5,
+            accrued:  0.5,
+        },
+        {
+            name:     "30/360 over the end of a long month",
+            rates:    thirty360,
+            balance:  1000,
+            lastRun:  date(1, 28),
+            now:      date(1, 31),
+            unposted: 2,
+            accrued:  0.1,
+        },
+        {
+            name:     "overdrafts accrue at the overdraft rate",
+            rates:    act365,
+            balance:  -1000,
+            lastRun:  date(1, 9),
+            now:      date(1, 12),
+            unposted: 2,
+            accrued:  -0.2,
+        },
+        {
+            name:    "currency without a rate accrues nothing",
+            rates:   []InterestRate{{Currency: "EUR", CreditRate: 0.0365, DayCount: DayCountActual365}},
+            balance: 1000,
+            lastRun: date(1, 9),
+            now:     date(1, 12),
+        },
+        {
+            name:    "zero balance accrues nothing",
+            rates:   act365,
+            lastRun: date(1, 9),
+            now:     date(1, 12),
+        },
+        {
+            name:     "month start pays the month",
+            rates:    act365,
+            balance:  1000,
+            lastRun:  date(1, 29),
+            now:      date(2, 2),
+            unposted: 1,
+            accrued:  0.1,
+            paid:     0.2,
+        },
+        {
+            name:     "30/360 pays a whole month as 30 days",
+            rates:    thirty360,
+            balance:  1000,
+            lastRun:  date(1, 31),
+            now:      date(3, 1),
+            paid:     3,
+        },
+        {
+            name:     "catch-up after missed runs pays each month once",
+            rates:    act365,
+            balance:  1000,
+            lastRun:  date(1, 30),
+            now:      date(3, 2),
+            unposted: 1,
+            accrued:  0.1,
+            paid:     3,
+        },
+        {
+            name:     "total rounding to zero rolls into the next month",
+            rates:    act365,
+            balance:  1,
+            lastRun:  date(1, 1).AddDate(0, 0, -1),
+            now:      date(2, 1),
+            unposted: 31,
+            accrued:  0.0031,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ctx := context.Background()
+
+            f := newInterestFixture(t, tt.balance, date(1, 1).AddDate(0, -1, 0))
+            if !tt.lastRun.IsZero() {
+                require.NoError(t, f.interest.CreateRun(ctx, tt.lastRun))
+            }
+
+            service := f.service(tt.rates, f.interest)
+            require.NoError(t, service.Run(ctx, tt.now))
+
+            // Another run the same day finds nothing left to do
+            require.NoError(t, service.Run(ctx, tt.now))
+
+            accruals := f.unposted(t)
+            assert.Len(t, accruals, tt.unposted)
+            assert.InDelta(t, tt.accrued, sumAccruals(accruals), 1e-9)
+            assert.InDelta(t, tt.paid, f.paid(t), 1e-9)
+        })
+    }
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+const (
+    dailyLimitWindow   = 24 * time.Hour
+    monthlyLimitWindow = 30 * 24 * time.Hour
+)
+
+// LoadLimits reads the default transaction limits from a JSON file holding
+// {"limits": [...]}.
+func LoadLimits(path string) ([]models.TransactionLimit, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read limits: %w", err)
+    }
+
+    var file struct {
+        Limits []models.TransactionLimit `json:"limits"`
+    }
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse limits: %w", err)
+    }
+
+    for i := range file.Limits {
+        limit := &file.Limits[i]
+        if limit.UserID != 0 {
+            return nil, fmt.Errorf("limit %d: default limits cannot name a user", i)
+        }
+        if err := limit.Validate(); err != nil {
+            return nil, fmt.Errorf("limit %d: %w", i, err)
+        }
+    }
+
+    return file.Limits, nil
+}
+
+// LimitService enforces transaction limits: the defaults every user gets and
+// the overrides an admin has set for a user.
+type LimitService struct {
+    defaults    []models.TransactionLimit
+    limitRepo   repository.LimitRepository
+    txRepo      repository.TransactionRepository
+    userRepo    repository.UserRepository
+    auditLogger *AuditLogger
+}
+
+func NewLimitService(
+    defaults []models.TransactionLimit,
+    limitRepo repository.LimitRepository,
+    txRepo repository.TransactionRepository,
+    userRepo repository.UserRepository,
+) *LimitService {
+    return &LimitService{
+        defaults:  defaults,
+        limitRepo: limitRepo,
+        txRepo:    txRepo,
+        userRepo:  userRepo,
+    }
+}
+
+func (s *LimitService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// GetLimits returns the limits in force for a user: their overrides followed
+// by the defaults they do not override.
+func (s *LimitService) GetLimits(ctx context.Context, userID uint) ([]models.TransactionLimit, error) {
+    overrides, err := s.limitRepo.GetUserLimits(ctx, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user limits: %w", err)
+    }
+
+    limits := make([]models.TransactionLimit, 0, len(overrides)+len(s.defaults))
+    for _, limit := range overrides {
+        limits = append(limits, *limit)
+    }
+
+    for _, limit := range s.defaults {
+        overridden := false
+        for _, override := range overrides {
+            if override.Type == limit.Type && override.Currency == limit.Currency {
+                overridden = true
+                break
+            }
+        }
+        if !overridden {
+            limits = append(limits, limit)
+        }
+    }
+
+    return limits, nil
+}
+
+// SetUserLimit overrides the default limits of limit's type and currency for
+// its user.
+func (s *LimitService) SetUserLimit(ctx context.Context, limit *models.TransactionLimit) error {
+    if err := limit.Validate(); err != nil {
+        return err
+    }
+
+    if _, err := s.userRepo.GetByID(ctx, limit.UserID); err != nil {
+        if err == repository.ErrNotFound {
+            return fmt.Errorf("%w: %d", ErrUserNotFound, limit.UserID)
+        }
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+
+    limit.UpdatedAt = time.Now()
+
+    if err := s.limitRepo.SetUserLimit(ctx, limit); err != nil {
+        return fmt.Errorf("failed to save limit: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "type":            limit.Type,
+            "currency":        limit.Currency,
+            "per_transaction": limit.PerTransaction,
+            "daily_amount":    limit.DailyAmount,
+            "daily_count":     limit.DailyCount,
+            "monthly_amount":  limit.MonthlyAmount,
+            "monthly_count":   limit.MonthlyCount,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user_limit", limit.UserID, "set", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return nil
+}
+
+// RemoveUserLimit drops an override so the user falls back to the defaults.
+func (s *LimitService) RemoveUserLimit(ctx context.Context, userID uint, txType models.TransactionType, currency string) error {
+    if err := s.limitRepo.DeleteUserLimit(ctx, userID, txType, currency); err != nil {
+        if err == repository.ErrNotFound {
+            return fmt.Errorf("user %d has no %q limit override for %q", userID, txType, currency)
+        }
+        return fmt.Errorf("failed to remove limit: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "type":     txType,
+            "currency": currency,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user_limit", userID, "remove", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return nil
+}
+
+// Check returns a *models.LimitExceededError if tx would break one of the
+// limits of the user initiating it. A nil service enforces nothing.
+func (s *LimitService) Check(ctx context.Context, tx *models.Transaction) error {
+    if s == nil {
+        return nil
+    }
+
+    userID := tx.FromUserID
+    if tx.Type == models.TransactionTypeCredit {
+        userID = tx.ToUserID
+    }
+
+    limits, err := s.GetLimits(ctx, userID)
+    if err != nil {
+        return err
+    }
+
+    now := time.Now()
+
+    for i := range limits {
+        limit := &limits[i]
+
+        if (limit.Type != "" && limit.Type != tx.Type) || (limit.Currency != "" && limit.Currency != tx.Currency) {
+            continue
+        }
+
+        exceeded := func(name string, max, used float64) error {
+            return &models.LimitExceededError{
+                Limit:    name,
+                Type:     limit.Type,
+                Currency: tx.Currency,
+                Max:      max,
+                Used:     used,
+            }
+        }
+
+        if limit.PerTransaction > 0 && tx.Amount > limit.PerTransaction {
+            return exceeded(models.LimitPerTransaction, limit.PerTransaction, 0)
+        }
+
+        windows := []struct {
+            period      time.Duration
+            amount      float64
+            count       int
+            amountLimit string
+            countLimit  string
+        }{
+            {dailyLimitWindow, limit.DailyAmount, limit.DailyCount, models.LimitDailyAmount, models.LimitDailyCount},
+            {monthlyLimitWindow, limit.MonthlyAmount, limit.MonthlyCount, models.LimitMonthlyAmount, models.LimitMonthlyCount},
+        }
+
+        for _, window := range windows {
+            if window.amount <= 0 && window.count <= 0 {
+                continue
+            }
+
+            since := now.Add(-window.period)
+
+            total, count, err := s.txRepo.SumUserTransactions(ctx, userID, limit.Type, tx.Currency, since)
+            if err != nil {
+                return fmt.Errorf("failed to sum transactions: %w", err)
+            }
+
+            // A held transaction checked again on approval is stored already
+            // and counted in the sum
+            if tx.ID != 0 && !tx.CreatedAt.Before(since) {
+                total -= tx.Amount
+                count--
+            }
+
+            if window.amount > 0 && models.RoundAmount(total+tx.Amount, tx.Currency) > window.amount {
+                return exceeded(window.amountLimit, window.amount, models.RoundAmount(total, tx.Currency))
+            }
+
+            if window.count > 0 && count+1 > window.count {
+                return exceeded(window.countLimit, float64(window.count), float64(count))
+            }
+        }
+    }
+
+    return nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository/memory"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestLimitServiceCheck(t *testing.T) {
+    defaults := []models.TransactionLimit{
+        {Type: models.TransactionTypeTransfer, Currency: "USD", PerTransaction: 500, DailyAmount: 1000, DailyCount: 3, MonthlyAmount: 2000},
+        {Type: models.TransactionTypeCredit, PerTransaction: 100},
+    }
+
+    type past struct {
+        txType models.TransactionType
+        amount float64
+        age    time.Duration
+        status models.TransactionStatus
+    }
+
+    tests := []struct {
+        name     string
+        override *models.TransactionLimit
+        history  []past
+        tx       *models.Transaction
+        // stored stores tx before checking it, as a held transaction is
+        stored   bool
+        want     string
+        used     float64
+    }{
+        {
+            name: "within every limit",
+            tx:   &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500, Currency: "USD"},
+        },
+        {
+            name: "above the per-transaction limit",
+            tx:   &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500.01, Currency: "USD"},
+            want: models.LimitPerTransaction,
+        },
+        {
+            name:    "daily amount reached",
+            history: []past{{models.TransactionTypeTransfer, 400, time.Hour, ""}, {models.TransactionTypeTransfer, 400, 2 * time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 300, Currency: "USD"},
+            want:    models.LimitDailyAmount,
+            used:    800,
+        },
+        {
+            name:    "daily amount reached exactly",
+            history: []past{{models.TransactionTypeTransfer, 400, time.Hour, ""}, {models.TransactionTypeTransfer, 400, 2 * time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 200, Currency: "USD"},
+        },
+        {
+            name: "daily count reached",
+            history: []past{
+                {models.TransactionTypeTransfer, 1, time.Hour, ""},
+                {models.TransactionTypeTransfer, 1, time.Hour, ""},
+                {models.TransactionTypeTransfer, 1, time.Hour, ""},
+            },
+            tx:   &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 1, Currency: "USD"},
+            want: models.LimitDailyCount,
+            used: 3,
+        },
+        {
+            name:    "older transactions only count monthly",
+            history: []past{{models.TransactionTypeTransfer, 500, 48 * time.Hour, ""}, {models.TransactionTypeTransfer, 500, 72 * time.Hour, ""}, {models.TransactionTypeTransfer, 500, 96 * time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500, Currency: "USD"},
+        },
+        {
+            name: "monthly amount reached",
+            history: []past{
+                {models.TransactionTypeTransfer, 500, 48 * time.Hour, ""},
+                {models.TransactionTypeTransfer, 500, 72 * time.Hour, ""},
+                {models.TransactionTypeTransfer, 500, 96 * time.Hour, ""},
+                {models.TransactionTypeTransfer, 400, 120 * time.Hour, ""},
+            },
+            tx:   &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 200, Currency: "USD"},
+            want: models.LimitMonthlyAmount,
+            used: 1900,
+        },
+        {
+            name:    "failed and rejected transactions do not count",
+            history: []past{{models.TransactionTypeTransfer, 900, time.Hour, models.TransactionStatusFailed}, {models.TransactionTypeTransfer, 900, time.Hour, models.TransactionStatusRejected}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500, Currency: "USD"},
+        },
+        {
+            name:    "other types do not count",
+            history: []past{{models.TransactionTypeDebit, 900, time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500, Currency: "USD"},
+        },
+        {
+            name: "limit for another currency does not apply",
+            tx:   &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 5000, Currency: "EUR"},
+        },
+        {
+            name: "limit without a currency applies to every currency",
+            tx:   &models.Transaction{Type: models.TransactionTypeCredit, Amount: 150, Currency: "EUR"},
+            want: models.LimitPerTransaction,
+        },
+        {
+            name:     "override replaces the default",
+            override: &models.TransactionLimit{Type: models.TransactionTypeTransfer, Currency: "USD", PerTransaction: 5000},
+            tx:       &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 3000, Currency: "USD"},
+        },
+        {
+            name:     "override of another currency keeps the default",
+            override: &models.TransactionLimit{Type: models.TransactionTypeTransfer, Currency: "EUR", PerTransaction: 5000},
+            tx:       &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 3000, Currency: "USD"},
+            want:     models.LimitPerTransaction,
+        },
+        {
+            name:    "held transaction is not counted twice on approval",
+            history: []past{{models.TransactionTypeTransfer, 400, time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 500, Currency: "USD"},
+            stored:  true,
+        },
+        {
+            name:    "held transaction still counts the others on approval",
+            history: []past{{models.TransactionTypeTransfer, 400, time.Hour, ""}, {models.TransactionTypeTransfer, 400, time.Hour, ""}},
+            tx:      &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 300, Currency: "USD"},
+            stored:  true,
+            want:    models.LimitDailyAmount,
+            used:    800,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ctx := context.Background()
+            store := memory.NewStore()
+            users := memory.NewUserRepository(store)
+            txRepo := memory.NewTransactionRepository(store)
+            limitRepo := memory.NewLimitRepository(store)
+
+            user := &models.User{Username: "alice", Email: "alice@example.com"}
+            require.NoError(t, users.Create(ctx, user))
+            other := &models.User{Username: "bob", Email: "bob@example.com"}
+            require.NoError(t, users.Create(ctx, other))
+
+            for _, p := range tt.history {
+                status := p.status
+                if status == "" {
+                    status = models.TransactionStatusCompleted
+                }
+                require.NoError(t, txRepo.Create(ctx, &models.Transaction{
+                    FromUserID: user.ID,
+                    ToUserID:   other.ID,
+                    Amount:     p.amount,
+                    Currency:   "USD",
+                    Type:       p.txType,
+                    Status:     status,
+                    CreatedAt:  time.Now().Add(-p.age),
+                }))
+            }
+
+            service := NewLimitService(defaults, limitRepo, txRepo, users)
+
+            if tt.override != nil {
+                tt.override.UserID = user.ID
+                require.NoError(t, service.SetUserLimit(ctx, tt.override))
+            }
+
+            tx := tt.tx
+            if tx.Type == models.TransactionTypeCredit {
+                tx.ToUserID = user.ID
+            } else {
+                tx.FromUserID = user.ID
+                tx.ToUserID = other.ID
+            }
+            tx.CreatedAt = time.Now()
+            if tt.stored {
+                tx.Status = models.TransactionStatusAwaitingApproval
+                require.NoError(t, txRepo.Create(ctx, tx))
+            }
+
+            err := service.Check(ctx, tx)
+
+            if tt.want == "" {
+                assert.NoError(t, err)
+                return
+            }
+
+            var exceeded *models.LimitExceededError
+            require.True(t, errors.As(err, &exceeded), "want a limit error, got %v", err)
+            assert.ErrorIs(t, err, models.ErrLimitExceeded)
+            assert.Equal(t, tt.want, exceeded.Limit)
+            assert.Equal(t, tt.used, exceeded.Used)
+        })
+    }
+}
+
+func TestLimitServiceCheckWithoutService(t *testing.T) {
+    var service *LimitService
+    assert.NoError(t, service.Check(context.Background(), &models.Transaction{Amount: 1e9}))
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
     "context"
+    "time"
     "financial-service/internal/models"
     "github.com/stretchr/testify/mock"
 )
@@ -40,8 +41,16 @@ type MockBalanceRepository struct {
     mock.Mock
 }
 
-func (m *MockBalanceRepository) GetBalance(ctx context.Context, userID uint) (*models.Balance, error) {
-    args := m.Called(ctx, userID)
+func (m *MockBalanceRepository) GetBalance(ctx context.Context, userID uint, currency string) (*models.Balance, error) {
+    args := m.Called(ctx, userID, currency)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) GetAccountBalance(ctx context.Context, accountID uint) (*models.Balance, error) {
+    args := m.Called(ctx, accountID)
     if args.Get(0) == nil {
         return nil, args.Error(1)
     }
@@ -58,6 +67,27 @@ func (m *MockBalanceRepository) CreateBalance(ctx context.Context, balance *mode
     return args.Error(0)
 }
 
+func (m *MockBalanceRepository) SetCreditLimit(ctx context.Context, accountID uint, creditLimit float64) error {
+    args := m.Called(ctx, accountID, creditLimit)
+    return args.Error(0)
+}
+
+func (m *MockBalanceRepository) GetUserBalances(ctx context.Context, userID uint) ([]*models.Balance, error) {
+    args := m.Called(ctx, userID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.Balance), args.Error(1)
+}
+
+func (m *MockBalanceRepository) ListBalances(ctx context.Context, afterAccountID uint, limit int) ([]*models.Balance, error) {
+    args := m.Called(ctx, afterAccountID, limit)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.Balance), args.Error(1)
+}
+
 type MockTransactionRepository struct {
     mock.Mock
 }
@@ -80,6 +110,16 @@ func (m *MockTransactionRepository) UpdateStatus(ctx context.Context, id uint, s
     return args.Error(0)
 }
 
+func (m *MockTransactionRepository) Complete(ctx context.Context, id uint, bookedAt time.Time) error {
+    args := m.Called(ctx, id, bookedAt)
+    return args.Error(0)
+}
+
+func (m *MockTransactionRepository) SetAccounts(ctx context.Context, id, fromAccountID, toAccountID uint) error {
+    args := m.Called(ctx, id, fromAccountID, toAccountID)
+    return args.Error(0)
+}
+
 func (m *MockTransactionRepository) GetUserTransactions(ctx context.Context, userID uint, limit, offset int) ([]models.Transaction, error) {
     args := m.Called(ctx, userID, limit, offset)
     if args.Get(0) == nil {
@@ -88,6 +128,398 @@ func (m *MockTransactionRepository) GetUserTransactions(ctx context.Context, use
     return args.Get(0).([]models.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetUserTransactionsAfter(ctx context.Context, userID uint, afterID uint, limit int) ([]models.Transaction, error) {
+    args := m.Called(ctx, userID, afterID, limit)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetUserTransactionsBookedBetween(ctx context.Context, userID uint, from, to time.Time, afterID uint, limit int) ([]models.Transaction, error) {
+    args := m.Called(ctx, userID, from, to, afterID, limit)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SumUserTransactions(ctx context.Context, userID uint, txType models.TransactionType, currency string, since time.Time) (float64, int, error) {
+    args := m.Called(ctx, userID, txType, currency, since)
+    return args.Get(0).(float64), args.Int(1), args.Error(2)
+}
+
+func (m *MockTransactionRepository) HasPaid(ctx context.Context, fromUserID, toUserID uint) (bool, error) {
+    args := m.Called(ctx, fromUserID, toUserID)
+    return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) CountNewCounterparties(ctx context.Context, userID uint, since time.Time) (int, error) {
+    args := m.Called(ctx, userID, since)
+    return args.Int(0), args.Error(1)
+}
+
+type MockBalanceSnapshotRepository struct {
+    mock.Mock
+}
+
+func (m *MockBalanceSnapshotRepository) Create(ctx context.Context, snapshot *models.BalanceSnapshot) error {
+    args := m.Called(ctx, snapshot)
+    return args.Error(0)
+}
+
+func (m *MockBalanceSnapshotRepository) GetLatest(ctx context.Context, accountID uint, asOf time.Time) (*models.BalanceSnapshot, error) {
+    args := m.Called(ctx, accountID, asOf)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.BalanceSnapshot), args.Error(1)
+}
+
+type MockFXRateRepository struct {
+    mock.Mock
+}
+
+func (m *MockFXRateRepository) GetRate(ctx context.Context, baseCurrency, quoteCurrency string) (*models.FXRate, error) {
+    args := m.Called(ctx, baseCurrency, quoteCurrency)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepository) SetRate(ctx context.Context, rate *models.FXRate) error {
+    args := m.Called(ctx, rate)
+    return args.Error(0)
+}
+
+func (m *MockFXRateRepository) ListRates(ctx context.Context) ([]*models.FXRate, error) {
+    args := m.Called(ctx)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.FXRate), args.Error(1)
+}
+
+type MockFXQuoteRepository struct {
+    mock.Mock
+}
+
+func (m *MockFXQuoteRepository) Create(ctx context.Context, quote *models.FXQuote) error {
+    args := m.Called(ctx, quote)
+    return args.Error(0)
+}
+
+func (m *MockFXQuoteRepository) GetByID(ctx context.Context, id uint) (*models.FXQuote, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.FXQuote), args.Error(1)
+}
+
+func (m *MockFXQuoteRepository) MarkExecuted(ctx context.Context, id, transactionID uint) error {
+    args := m.Called(ctx, id, transactionID)
+    return args.Error(0)
+}
+
+type MockLimitRepository struct {
+    mock.Mock
+}
+
+func (m *MockLimitRepository) GetUserLimits(ctx context.Context, userID uint) ([]*models.TransactionLimit, error) {
+    args := m.Called(ctx, userID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.TransactionLimit), args.Error(1)
+}
+
+func (m *MockLimitRepository) SetUserLimit(ctx context.Context, limit *models.TransactionLimit) error {
+    args := m.Called(ctx, limit)
+    return args.Error(0)
+}
+
+func (m *MockLimitRepository) DeleteUserLimit(ctx context.Context, userID uint, txType models.TransactionType, currency string) error {
+    args := m.Called(ctx, userID, txType, currency)
+    return args.Error(0)
+}
+
+type MockInterestRepository struct {
+    mock.Mock
+}
+
+func (m *MockInterestRepository) CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error {
+    args := m.Called(ctx, accrual)
+    return args.Error(0)
+}
+
+func (m *MockInterestRepository) GetUnposted(ctx context.Context, accountID uint, before time.Time) ([]*models.InterestAccrual, error) {
+    args := m.Called(ctx, accountID, before)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.InterestAccrual), args.Error(1)
+}
+
+func (m *MockInterestRepository) MarkPosted(ctx context.Context, accountID uint, before time.Time, transactionID uint) error {
+    args := m.Called(ctx, accountID, before, transactionID)
+    return args.Error(0)
+}
+
+func (m *MockInterestRepository) GetLastRun(ctx context.Context) (time.Time, error) {
+    args := m.Called(ctx)
+    return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockInterestRepository) CreateRun(ctx context.Context, date time.Time) error {
+    args := m.Called(ctx, date)
+    return args.Error(0)
+}
+
+type MockScheduledTransferRepository struct {
+    mock.Mock
+}
+
+func (m *MockScheduledTransferRepository) Create(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    args := m.Called(ctx, schedule)
+    return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) GetByID(ctx context.Context, id uint) (*models.ScheduledTransfer, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) Update(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    args := m.Called(ctx, schedule)
+    return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) ListByUser(ctx context.Context, userID uint) ([]*models.ScheduledTransfer, error) {
+    args := m.Called(ctx, userID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.ScheduledTransfer, error) {
+    args := m.Called(ctx, now, limit)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) CreateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    args := m.Called(ctx, run)
+    return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) GetRun(ctx context.Context, scheduleID uint, occurrence time.Time) (*models.ScheduledTransferRun, error) {
+    args := m.Called(ctx, scheduleID, occurrence)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.ScheduledTransferRun), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) GetRunByTransaction(ctx context.Context, transactionID uint) (*models.ScheduledTransferRun, error) {
+    args := m.Called(ctx, transactionID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.ScheduledTransferRun), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) UpdateRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+    args := m.Called(ctx, run)
+    return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) ClaimRetry(ctx context.Context, scheduleID uint, occurrence time.Time) error {
+    args := m.Called(ctx, scheduleID, occurrence)
+    return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) ListRuns(ctx context.Context, scheduleID uint) ([]*models.ScheduledTransferRun, error) {
+    args := m.Called(ctx, scheduleID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.ScheduledTransferRun), args.Error(1)
+}
+
+type MockTransferBatchRepository struct {
+    mock.Mock
+}
+
+func (m *MockTransferBatchRepository) Create(ctx context.Context, batch *models.TransferBatch) error {
+    args := m.Called(ctx, batch)
+    return args.Error(0)
+}
+
+func (m *MockTransferBatchRepository) GetByClientID(ctx context.Context, clientBatchID string) (*models.TransferBatch, error) {
+    args := m.Called(ctx, clientBatchID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.TransferBatch), args.Error(1)
+}
+
+func (m *MockTransferBatchRepository) Update(ctx context.Context, batch *models.TransferBatch) error {
+    args := m.Called(ctx, batch)
+    return args.Error(0)
+}
+
+func (m *MockTransferBatchRepository) CreateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    args := m.Called(ctx, item)
+    return args.Error(0)
+}
+
+func (m *MockTransferBatchRepository) UpdateItem(ctx context.Context, item *models.TransferBatchItem) error {
+    args := m.Called(ctx, item)
+    return args.Error(0)
+}
+
+func (m *MockTransferBatchRepository) GetItems(ctx context.Context, batchID uint) ([]*models.TransferBatchItem, error) {
+    args := m.Called(ctx, batchID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.TransferBatchItem), args.Error(1)
+}
+
+type MockImportRepository struct {
+    mock.Mock
+}
+
+func (m *MockImportRepository) Create(ctx context.Context, imp *models.Import) error {
+    args := m.Called(ctx, imp)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) GetByID(ctx context.Context, id uint) (*models.Import, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Import), args.Error(1)
+}
+
+func (m *MockImportRepository) GetByHash(ctx context.Context, fileHash string) (*models.Import, error) {
+    args := m.Called(ctx, fileHash)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Import), args.Error(1)
+}
+
+func (m *MockImportRepository) Update(ctx context.Context, imp *models.Import) error {
+    args := m.Called(ctx, imp)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) CreateRow(ctx context.Context, row *models.ImportRow) error {
+    args := m.Called(ctx, row)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) UpdateRow(ctx context.Context, row *models.ImportRow) error {
+    args := m.Called(ctx, row)
+    return args.Error(0)
+}
+
+func (m *MockImportRepository) GetRows(ctx context.Context, importID uint) ([]*models.ImportRow, error) {
+    args := m.Called(ctx, importID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.ImportRow), args.Error(1)
+}
+
+type MockAccountRepository struct {
+    mock.Mock
+}
+
+func (m *MockAccountRepository) Create(ctx context.Context, account *models.Account) error {
+    args := m.Called(ctx, account)
+    return args.Error(0)
+}
+
+func (m *MockAccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+    args := m.Called(ctx, id)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) GetDefault(ctx context.Context, userID uint, currency string) (*models.Account, error) {
+    args := m.Called(ctx, userID, currency)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) ListByUser(ctx context.Context, userID uint) ([]*models.Account, error) {
+    args := m.Called(ctx, userID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) Update(ctx context.Context, account *models.Account) error {
+    args := m.Called(ctx, account)
+    return args.Error(0)
+}
+
+func (m *MockAccountRepository) Delete(ctx context.Context, id uint) error {
+    args := m.Called(ctx, id)
+    return args.Error(0)
+}
+
+func (m *MockAccountRepository) HasTransactions(ctx context.Context, id uint) (bool, error) {
+    args := m.Called(ctx, id)
+    return args.Bool(0), args.Error(1)
+}
+
+type MockApprovalRepository struct {
+    mock.Mock
+}
+
+func (m *MockApprovalRepository) Create(ctx context.Context, approval *models.Approval) error {
+    args := m.Called(ctx, approval)
+    return args.Error(0)
+}
+
+func (m *MockApprovalRepository) GetByTransactionID(ctx context.Context, transactionID uint) (*models.Approval, error) {
+    args := m.Called(ctx, transactionID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.Approval), args.Error(1)
+}
+
+func (m *MockApprovalRepository) ListPending(ctx context.Context) ([]*models.Approval, error) {
+    args := m.Called(ctx)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).([]*models.Approval), args.Error(1)
+}
+
+func (m *MockApprovalRepository) Decide(ctx context.Context, approval *models.Approval) error {
+    args := m.Called(ctx, approval)
+    return args.Error(0)
+}
+
 type MockAuditLogRepository struct {
     mock.Mock
 }
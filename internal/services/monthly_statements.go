@@ -0,0 +1,165 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "time"
+    "github.com/rs/zerolog/log"
+)
+
+// ErrStatementNotReady is returned for a monthly statement of a month that
+// has not ended yet.
+var ErrStatementNotReady = errors.New("statement is not available until the month ends")
+
+// monthStart returns the first instant of t's month in UTC.
+func monthStart(t time.Time) time.Time {
+    t = t.UTC()
+    return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *StatementService) pdfPath(userID uint, currency string, month time.Time) string {
+    return filepath.Join(s.pdfDir, month.Format("2006-01"), fmt.Sprintf("%d-%s.pdf", userID, currency))
+}
+
+// MonthlyPDF returns the user's PDF statement in currency for the month
+// containing month. Statements the monthly job has not produced yet are
+// rendered and kept. Only months that have ended have a statement.
+func (s *StatementService) MonthlyPDF(ctx context.Context, userID uint, currency string, month time.Time) ([]byte, error) {
+    month = monthStart(month)
+
+    if !month.Before(monthStart(time.Now())) {
+        return nil, fmt.Errorf("%w: %s", ErrStatementNotReady, month.Format("2006-01"))
+    }
+
+    if s.pdfDir != "" {
+        data, err := os.ReadFile(s.pdfPath(userID, currency, month))
+        if err == nil {
+            return data, nil
+        }
+        if !errors.Is(err, os.ErrNotExist) {
+            return nil, fmt.Errorf("failed to read statement: %w", err)
+        }
+    }
+
+    return s.renderMonthly(ctx, userID, currency, month)
+}
+
+// renderMonthly renders a monthly PDF statement and, when a directory is
+// configured, keeps it there.
+func (s *StatementService) renderMonthly(ctx context.Context, userID uint, currency string, month time.Time) ([]byte, error) {
+    statement, err := s.Generate(ctx, userID, currency, month, month.AddDate(0, 1, 0))
+    if err != nil {
+        return nil, err
+    }
+
+    var buf bytes.Buffer
+    if err := statement.WritePDF(&buf); err != nil {
+        return nil, fmt.Errorf("failed to render statement: %w", err)
+    }
+
+    if s.pdfDir == "" {
+        return buf.Bytes(), nil
+    }
+
+    if err := writeFileAtomic(s.pdfPath(userID, currency, month), buf.Bytes()); err != nil {
+        return nil, fmt.Errorf("failed to save statement: %w", err)
+    }
+
+    return buf.Bytes(), nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers never see a partial statement.
+func writeFileAtomic(path string, data []byte) error {
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        return err
+    }
+
+    f, err := os.CreateTemp(filepath.Dir(path), ".statement-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(f.Name())
+
+    if _, err := f.Write(data); err != nil {
+        f.Close()
+        return err
+    }
+
+    if err := f.Close(); err != nil {
+        return err
+    }
+
+    return os.Rename(f.Name(), path)
+}
+
+// GenerateMonthly renders the PDF statement of every default account for the
+// month containing month, skipping those already kept, and returns how many
+// it rendered.
+func (s *StatementService) GenerateMonthly(ctx context.Context, month time.Time) (int, error) {
+    if s.pdfDir == "" {
+        return 0, errors.New("no statement directory is configured")
+    }
+
+    month = monthStart(month)
+
+    var afterAccountID uint
+    var rendered int
+
+    for {
+        balances, err := s.historyService.balanceRepo.ListBalances(ctx, afterAccountID, reconcilePageSize)
+        if err != nil {
+            return rendered, fmt.Errorf("failed to list balances: %w", err)
+        }
+
+        for _, balance := range balances {
+            afterAccountID = balance.AccountID
+
+            // Statements cover the default account in each currency
+            ledger, err := s.historyService.balanceRepo.GetBalance(ctx, balance.UserID, balance.Currency)
+            if err != nil {
+                return rendered, fmt.Errorf("failed to get balance for user %d: %w", balance.UserID, err)
+            }
+            if ledger.AccountID != balance.AccountID {
+                continue
+            }
+
+            if _, err := os.Stat(s.pdfPath(balance.UserID, balance.Currency, month)); err == nil {
+                continue
+            }
+
+            if _, err := s.renderMonthly(ctx, balance.UserID, balance.Currency, month); err != nil {
+                return rendered, fmt.Errorf("failed to render statement for user %d: %w", balance.UserID, err)
+            }
+
+            rendered++
+        }
+
+        if len(balances) < reconcilePageSize {
+            return rendered, nil
+        }
+    }
+}
+
+// MonthlyJob returns a periodic job that renders the statements for the
+// previous month. Running it more often than monthly only fills gaps.
+func (s *StatementService) MonthlyJob(interval time.Duration) *PeriodicJob {
+    return NewPeriodicJob("monthly_statements", interval, func(ctx context.Context) error {
+        month := monthStart(time.Now()).AddDate(0, -1, 0)
+
+        rendered, err := s.GenerateMonthly(ctx, month)
+        if err != nil {
+            return err
+        }
+
+        if rendered > 0 {
+            log.Info().Int("statements", rendered).Str("month", month.Format("2006-01")).Msg("Monthly statements rendered")
+        }
+
+        return nil
+    })
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "financial-service/internal/iso20022"
+    "financial-service/internal/models"
+)
+
+// PaymentInitiationService imports ISO 20022 pain.001 messages through the
+// import pipeline and answers them with a pain.002 status report. Accounts
+// are identified by the user ID in their Othr/Id element.
+type PaymentInitiationService struct {
+    importService *ImportService
+}
+
+func NewPaymentInitiationService(importService *ImportService) *PaymentInitiationService {
+    return &PaymentInitiationService{importService: importService}
+}
+
+// ImportPain001 executes the credit transfers of a pain.001 message and
+// reports on each of them. As with every import, a message whose content was
+// imported before is not executed again; its original outcome is reported
+// and created is false. With dryRun the transfers are only validated.
+func (s *PaymentInitiationService) ImportPain001(ctx context.Context, filename string, data []byte, dryRun bool) (report *iso20022.Pain002, imp *models.Import, created bool, err error) {
+    doc, err := iso20022.ParsePain001(data)
+    if err != nil {
+        return nil, nil, false, err
+    }
+
+    if filename == "" {
+        filename = doc.Initiation.GroupHeader.MessageID
+    }
+
+    imp, created, err = s.importService.Import(ctx, filename, data, pain001Rows(doc), dryRun)
+    if err != nil {
+        return nil, nil, false, err
+    }
+
+    if len(imp.Rows) != doc.Transfers() {
+        return nil, nil, false, fmt.Errorf("import %d has %d rows for %d transfers", imp.ID, len(imp.Rows), doc.Transfers())
+    }
+
+    return statusReport(doc, imp), imp, created, nil
+}
+
+// pain001Rows maps every credit transfer to an import row numbered from 1
+// in message order.
+func pain001Rows(doc *iso20022.Pain001) []*models.ImportRow {
+    var rows []*models.ImportRow
+
+    for _, payment := range doc.Initiation.Payments {
+        fromUserID, fromErr := accountUserID(payment.DebtorAccount)
+
+        for _, transfer := range payment.Transfers {
+            row := &models.ImportRow{
+                Line:       len(rows) + 1,
+                Type:       models.TransactionTypeTransfer,
+                FromUserID: fromUserID,
+                Currency:   strings.ToUpper(transfer.Amount.Currency),
+                Reference:  transfer.EndToEndID,
+                Status:     models.ImportRowPending,
+            }
+            rows = append(rows, row)
+
+            var toErr, amountErr error
+            row.ToUserID, toErr = accountUserID(transfer.CreditorAccount)
+            row.Amount, amountErr = transfer.Amount.Float()
+
+            switch {
+                case payment.PaymentMethod != "TRF":
+                    row.Error = fmt.Sprintf("unsupported payment method %q", payment.PaymentMethod)
+                case fromErr != nil:
+                    row.Error = "debtor " + fromErr.Error()
+                case toErr != nil:
+                    row.Error = "creditor " + toErr.Error()
+                case amountErr != nil:
+                    row.Error = amountErr.Error()
+                default:
+                    continue
+            }
+            row.Status = models.ImportRowInvalid
+        }
+    }
+
+    return rows
+}
+
+// accountUserID resolves an account to the user holding it.
+func accountUserID(account iso20022.Account) (uint, error) {
+    if account.Other == "" {
+        if account.IBAN != "" {
+            return 0, fmt.Errorf("account %s: IBAN accounts are not held here", account.IBAN)
+        }
+        return 0, errors.New("account is missing")
+    }
+
+    id, err := strconv.ParseUint(strings.TrimSpace(account.Other), 10, 32)
+    if err != nil || id == 0 {
+        return 0, fmt.Errorf("account %q is not held here", account.Other)
+    }
+
+    return uint(id), nil
+}
+
+func statusReport(doc *iso20022.Pain001, imp *models.Import) *iso20022.Pain002 {
+    header := doc.Initiation.GroupHeader
+
+    messageID := fmt.Sprintf("PSR-%d", imp.ID)
+    if imp.ID == 0 {
+        messageID = "PSR-DRYRUN-" + imp.FileHash[:16]
+    }
+
+    report := &iso20022.Pain002{
+        Report: iso20022.PaymentStatusReport{
+            GroupHeader: iso20022.ReportGroupHeader{
+                MessageID:        messageID,
+                CreationDateTime: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+            },
+            OriginalGroup: iso20022.OriginalGroupStatus{
+                OriginalMessageID:            header.MessageID,
+                OriginalMessageNameID:        doc.Version(),
+                OriginalNumberOfTransactions: header.NumberOfTransactions,
+                OriginalControlSum:           header.ControlSum,
+            },
+        },
+    }
+
+    var groupStatuses []string
+    rows := imp.Rows
+
+    for _, payment := range doc.Initiation.Payments {
+        paymentStatus := iso20022.OriginalPaymentStatus{
+            OriginalPaymentInformationID: payment.PaymentInformationID,
+        }
+
+        var statuses []string
+        for _, transfer := range payment.Transfers {
+            row := rows[0]
+            rows = rows[1:]
+
+            status := iso20022.TransactionStatus{
+                OriginalInstructionID: transfer.InstructionID,
+                OriginalEndToEndID:    transfer.EndToEndID,
+            }
+
+            switch row.Status {
+                case models.ImportRowCompleted:
+                    status.Status = iso20022.StatusAcceptedSettlementCompleted
+                    status.StatusID = strconv.FormatUint(uint64(row.TransactionID), 10)
+                case models.ImportRowAwaitingApproval:
+                    status.Status = iso20022.StatusPending
+                    status.StatusID = strconv.FormatUint(uint64(row.TransactionID), 10)
+                case models.ImportRowValid, models.ImportRowPending:
+                    status.Status = iso20022.StatusAcceptedTechnicalValidation
+                default:
+                    status.Status = iso20022.StatusRejected
+                    status.Reason = iso20022.NewStatusReason(rejectionReason(row.Error), row.Error)
+            }
+
+            statuses = append(statuses, status.Status)
+            paymentStatus.Transactions = append(paymentStatus.Transactions, status)
+        }
+
+        paymentStatus.PaymentInformationStatus = iso20022.CombinedStatus(statuses)
+        groupStatuses = append(groupStatuses, statuses...)
+        report.Report.OriginalPayments = append(report.Report.OriginalPayments, paymentStatus)
+    }
+
+    report.Report.OriginalGroup.GroupStatus = iso20022.CombinedStatus(groupStatuses)
+
+    return report
+}
+
+// rejectionReason picks the status reason code for the error a transfer was
+// rejected with. Errors are only kept as text on the import row, so they are
+// matched by message.
+func rejectionReason(message string) string {
+    switch {
+        case strings.Contains(message, models.ErrInsufficientFunds.Error()):
+            return iso20022.ReasonInsufficientFunds
+        case strings.Contains(message, models.ErrLimitExceeded.Error()):
+            return iso20022.ReasonNotAllowedAmount
+        case strings.Contains(message, models.ErrUnknownCurrency.Error()):
+            return iso20022.ReasonNotAllowedCurrency
+        case strings.Contains(message, "account"), strings.Contains(message, "user not found"):
+            return iso20022.ReasonIncorrectAccountNumber
+        case strings.Contains(message, "amount"):
+            return iso20022.ReasonInvalidAmount
+        default:
+            return iso20022.ReasonNarrative
+    }
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+    "context"
+    "sync"
+    "time"
+    "github.com/rs/zerolog/log"
+)
+
+// PeriodicJob runs a function on a fixed interval in the background until
+// stopped. Failures are logged and the next run proceeds as scheduled.
+type PeriodicJob struct {
+    name     string
+    interval time.Duration
+    run      func(ctx context.Context) error
+    ctx      context.Context
+    cancel   context.CancelFunc
+    wg       sync.WaitGroup
+}
+
+func NewPeriodicJob(name string, interval time.Duration, run func(ctx context.Context) error) *PeriodicJob {
+    ctx, cancel := context.WithCancel(context.Background())
+
+    return &PeriodicJob{
+        name:     name,
+        interval: interval,
+        run:      run,
+        ctx:      ctx,
+        cancel:   cancel,
+    }
+}
+
+func (j *PeriodicJob) Start() {
+    j.wg.Add(1)
+
+    go func() {
+        defer j.wg.Done()
+
+        ticker := time.NewTicker(j.interval)
+        defer ticker.Stop()
+
+        for {
+            select {
+                case <-j.ctx.Done():
+                    return
+                case <-ticker.C:
+                    if err := j.run(j.ctx); err != nil {
+                        log.Error().Err(err).Str("job", j.name).Msg("Periodic job failed")
+                    }
+            }
+        }
+    }()
+}
+
+// Stop cancels a run in progress and waits for the job to exit.
+func (j *PeriodicJob) Stop() {
+    j.cancel()
+    j.wg.Wait()
+}
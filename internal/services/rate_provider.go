@@ -0,0 +1,149 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+)
+
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// RateProvider supplies mid-market exchange rates.
+type RateProvider interface {
+    // Rate returns the price of one unit of base in quote.
+    Rate(ctx context.Context, base, quote string) (float64, error)
+}
+
+// StoredRateProvider reads rates maintained in the fx_rates table. A pair
+// missing in one direction is served from its inverse. Rates older than
+// maxAge are refused; a zero maxAge accepts any age.
+type StoredRateProvider struct {
+    repo   repository.FXRateRepository
+    maxAge time.Duration
+}
+
+func NewStoredRateProvider(repo repository.FXRateRepository, maxAge time.Duration) *StoredRateProvider {
+    return &StoredRateProvider{
+        repo:   repo,
+        maxAge: maxAge,
+    }
+}
+
+func (p *StoredRateProvider) Rate(ctx context.Context, base, quote string) (float64, error) {
+    lookup := func(base, quote string) (*models.FXRate, error) {
+        rate, err := p.repo.GetRate(ctx, base, quote)
+        if errors.Is(err, repository.ErrNotFound) {
+            return nil, nil
+        }
+        return rate, err
+    }
+
+    rate, err := lookup(base, quote)
+    if err != nil {
+        return 0, err
+    }
+
+    inverse := false
+    if rate == nil {
+        if rate, err = lookup(quote, base); err != nil {
+            return 0, err
+        }
+        inverse = true
+    }
+
+    if rate == nil || rate.Rate <= 0 {
+        return 0, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, base, quote)
+    }
+
+    if p.maxAge > 0 && time.Since(rate.UpdatedAt) > p.maxAge {
+        return 0, fmt.Errorf("%w: %s/%s is stale", ErrRateUnavailable, base, quote)
+    }
+
+    if inverse {
+        return 1 / rate.Rate, nil
+    }
+
+    return rate.Rate, nil
+}
+
+// FileRateProvider serves rates from a JSON file holding an array of
+// models.FXRate. The file is re-read whenever its modification time changes,
+// so rates can be updated without a restart.
+type FileRateProvider struct {
+    path    string
+    mu      sync.Mutex
+    modTime time.Time
+    rates   map[[2]string]float64
+}
+
+func NewFileRateProvider(path string) (*FileRateProvider, error) {
+    p := &FileRateProvider{path: path}
+
+    if err := p.reload(); err != nil {
+        return nil, err
+    }
+
+    return p, nil
+}
+
+func (p *FileRateProvider) Rate(ctx context.Context, base, quote string) (float64, error) {
+    if err := p.reload(); err != nil {
+        return 0, err
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if rate, ok := p.rates[[2]string{base, quote}]; ok {
+        return rate, nil
+    }
+
+    if rate, ok := p.rates[[2]string{quote, base}]; ok {
+        return 1 / rate, nil
+    }
+
+    return 0, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, base, quote)
+}
+
+func (p *FileRateProvider) reload() error {
+    info, err := os.Stat(p.path)
+    if err != nil {
+        return fmt.Errorf("failed to read rate file: %w", err)
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.rates != nil && info.ModTime().Equal(p.modTime) {
+        return nil
+    }
+
+    data, err := os.ReadFile(p.path)
+    if err != nil {
+        return fmt.Errorf("failed to read rate file: %w", err)
+    }
+
+    var list []models.FXRate
+    if err := json.Unmarshal(data, &list); err != nil {
+        return fmt.Errorf("failed to parse rate file: %w", err)
+    }
+
+    rates := make(map[[2]string]float64, len(list))
+    for _, rate := range list {
+        if rate.Rate <= 0 {
+            return fmt.Errorf("invalid rate for %s/%s in rate file", rate.BaseCurrency, rate.QuoteCurrency)
+        }
+        rates[[2]string{rate.BaseCurrency, rate.QuoteCurrency}] = rate.Rate
+    }
+
+    p.rates = rates
+    p.modTime = info.ModTime()
+
+    return nil
+}
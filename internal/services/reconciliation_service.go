@@ -0,0 +1,312 @@
+package services
+
+import (
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// reconcilePageSize is how many balances are listed per query when
+// reconciling every user.
+const reconcilePageSize = 200
+
+// ReconciliationService compares stored balances with the balance derived
+// from each user's ledger. It never changes a balance unless asked to repair.
+type ReconciliationService struct {
+    balanceRepo repository.BalanceRepository
+    txRepo      repository.TransactionRepository
+    transactor  repository.Transactor
+    events      *BalanceEvents
+    auditLogger *AuditLogger
+}
+
+type ReconciliationOptions struct {
+    // UserIDs limits the run to the given users; empty means every balance.
+    UserIDs []uint
+    // Repair overwrites drifted balances with the derived amount.
+    Repair bool
+}
+
+type BalanceDrift struct {
+    AccountID uint    `json:"account_id"`
+    UserID    uint    `json:"user_id"`
+    Currency  string  `json:"currency"`
+    Stored    float64 `json:"stored"`
+    Derived   float64 `json:"derived"`
+    Drift     float64 `json:"drift"`
+    Repaired  bool    `json:"repaired"`
+}
+
+type ReconciliationReport struct {
+    StartedAt    time.Time      `json:"started_at"`
+    FinishedAt   time.Time      `json:"finished_at"`
+    BalancesChecked int         `json:"balances_checked"`
+    Repaired     int            `json:"repaired"`
+    Drifts       []BalanceDrift `json:"drifts"`
+}
+
+func NewReconciliationService(
+    balanceRepo repository.BalanceRepository,
+    txRepo repository.TransactionRepository,
+    transactor repository.Transactor,
+) *ReconciliationService {
+    return &ReconciliationService{
+        balanceRepo: balanceRepo,
+        txRepo:      txRepo,
+        transactor:  transactor,
+    }
+}
+
+func (s *ReconciliationService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+// SetBalanceEvents publishes repaired balances so caches drop them.
+func (s *ReconciliationService) SetBalanceEvents(events *BalanceEvents) {
+    s.events = events
+}
+
+// Run reconciles the selected users and reports every balance that differs
+// from its ledger.
+func (s *ReconciliationService) Run(ctx context.Context, opts ReconciliationOptions) (*ReconciliationReport, error) {
+    report := &ReconciliationReport{
+        StartedAt: time.Now(),
+        Drifts:    []BalanceDrift{},
+    }
+
+    check := func(balance *models.Balance) error {
+        drift, err := s.reconcileAccount(ctx, balance.AccountID, opts.Repair)
+        if err != nil {
+            return fmt.Errorf("failed to reconcile account %d of user %d: %w", balance.AccountID, balance.UserID, err)
+        }
+
+        report.BalancesChecked++
+
+        if drift != nil {
+            report.Drifts = append(report.Drifts, *drift)
+            if drift.Repaired {
+                report.Repaired++
+            }
+        }
+
+        return nil
+    }
+
+    if len(opts.UserIDs) > 0 {
+        for _, userID := range opts.UserIDs {
+            balances, err := s.balanceRepo.GetUserBalances(ctx, userID)
+            if err != nil {
+                return nil, fmt.Errorf("failed to list balances of user %d: %w", userID, err)
+            }
+
+            if len(balances) == 0 {
+                return nil, fmt.Errorf("balance not found for user %d", userID)
+            }
+
+            for _, balance := range balances {
+                if err := check(balance); err != nil {
+                    return nil, err
+                }
+            }
+        }
+    } else {
+        var afterAccountID uint
+
+        for {
+            balances, err := s.balanceRepo.ListBalances(ctx, afterAccountID, reconcilePageSize)
+            if err != nil {
+                return nil, fmt.Errorf("failed to list balances: %w", err)
+            }
+
+            for _, balance := range balances {
+                afterAccountID = balance.AccountID
+                if err := check(balance); err != nil {
+                    return nil, err
+                }
+            }
+
+            if len(balances) < reconcilePageSize {
+                break
+            }
+        }
+    }
+
+    report.FinishedAt = time.Now()
+
+    return report, nil
+}
+
+// reconcileAccount reads the stored balance and the ledger in one
+// transaction so a transfer completing mid-check cannot show up as drift.
+func (s *ReconciliationService) reconcileAccount(ctx context.Context, accountID uint, repair bool) (*BalanceDrift, error) {
+    var drift *BalanceDrift
+    var userID uint
+    var currency string
+
+    err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        balance, err := s.balanceRepo.GetAccountBalance(ctx, accountID)
+        if err != nil {
+            if errors.Is(err, repository.ErrNotFound) {
+                return fmt.Errorf("balance not found for account %d", accountID)
+            }
+            return err
+        }
+
+        userID, currency = balance.UserID, balance.Currency
+
+        derived, err := deriveBalance(ctx, s.txRepo, balance)
+        if err != nil {
+            return err
+        }
+
+        difference := models.RoundAmount(balance.Amount-derived, currency)
+        if difference == 0 {
+            return nil
+        }
+
+        drift = &BalanceDrift{
+            AccountID: accountID,
+            UserID:    userID,
+            Currency:  currency,
+            Stored:    balance.Amount,
+            Derived:   derived,
+            Drift:     difference,
+        }
+
+        if !repair {
+            return nil
+        }
+
+        repaired := &models.Balance{
+            AccountID:     accountID,
+            UserID:        userID,
+            Currency:      currency,
+            Amount:        derived,
+            LastUpdatedAt: time.Now(),
+        }
+
+        if err := s.balanceRepo.UpdateBalance(ctx, repaired); err != nil {
+            return fmt.Errorf("failed to repair balance: %w", err)
+        }
+
+        if s.auditLogger != nil {
+            changes := map[string]interface{}{
+                "account_id":  accountID,
+                "currency":    currency,
+                "from_amount": balance.Amount,
+                "to_amount":   derived,
+                "drift":       difference,
+            }
+            // The audit entry is part of the repair: if it cannot be written
+            // the balance is left untouched.
+            if err := s.auditLogger.LogAction(ctx, "balance", userID, "reconcile_repair", changes); err != nil {
+                return fmt.Errorf("failed to audit repair: %w", err)
+            }
+        }
+
+        drift.Repaired = true
+
+        return nil
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    if drift != nil && drift.Repaired {
+        s.events.Publish(currency, userID)
+    }
+
+    return drift, nil
+}
+
+func (r *ReconciliationReport) WriteJSON(w io.Writer) error {
+    enc := json.NewEncoder(w)
+    enc.SetIndent("", "  ")
+    return enc.Encode(r)
+}
+
+// WriteCSV writes one row per drifted balance.
+func (r *ReconciliationReport) WriteCSV(w io.Writer) error {
+    cw := csv.NewWriter(w)
+
+    if err := cw.Write([]string{"account_id", "user_id", "currency", "stored", "derived", "drift", "repaired"}); err != nil {
+        return err
+    }
+
+    for _, d := range r.Drifts {
+        units := models.MinorUnits(d.Currency)
+        record := []string{
+            strconv.FormatUint(uint64(d.AccountID), 10),
+            strconv.FormatUint(uint64(d.UserID), 10),
+            d.Currency,
+            strconv.FormatFloat(d.Stored, 'f', units, 64),
+            strconv.FormatFloat(d.Derived, 'f', units, 64),
+            strconv.FormatFloat(d.Drift, 'f', units, 64),
+            strconv.FormatBool(d.Repaired),
+        }
+        if err := cw.Write(record); err != nil {
+            return err
+        }
+    }
+
+    cw.Flush()
+
+    return cw.Error()
+}
+
+// Write renders the report as "json" or "csv".
+func (r *ReconciliationReport) Write(w io.Writer, format string) error {
+    switch format {
+        case "json":
+            return r.WriteJSON(w)
+        case "csv":
+            return r.WriteCSV(w)
+        default:
+            return fmt.Errorf("unsupported report format: %q", format)
+    }
+}
+
+// ReportOnlyJob returns a periodic job that reconciles every balance without
+// repairing and, when reportDir is set, saves each report there as JSON.
+func (s *ReconciliationService) ReportOnlyJob(interval time.Duration, reportDir string) *PeriodicJob {
+    return NewPeriodicJob("reconciliation", interval, func(ctx context.Context) error {
+        report, err := s.Run(ctx, ReconciliationOptions{})
+        if err != nil {
+            return err
+        }
+
+        log.Info().
+            Int("balances_checked", report.BalancesChecked).
+            Int("drifts", len(report.Drifts)).
+            Msg("Balance reconciliation finished")
+
+        if reportDir == "" {
+            return nil
+        }
+
+        name := fmt.Sprintf("reconciliation-%s.json", report.StartedAt.UTC().Format("20060102T150405Z"))
+
+        f, err := os.Create(filepath.Join(reportDir, name))
+        if err != nil {
+            return err
+        }
+
+        if err := report.WriteJSON(f); err != nil {
+            f.Close()
+            return err
+        }
+
+        return f.Close()
+    })
+}
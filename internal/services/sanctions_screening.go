@@ -0,0 +1,127 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "financial-service/internal/models"
+    "github.com/rs/zerolog/log"
+)
+
+// SetWatchlist screens users against the sanctions watchlist when they
+// register. Users are screened by username, the only name the service
+// holds; strong matches are refused and possible ones recorded.
+func (s *UserService) SetWatchlist(watchlist *Watchlist) {
+    s.watchlist = watchlist
+}
+
+// screenRegistration screens a user about to register. A block is recorded
+// on the audit log here, as the user is never created; any other decision is
+// returned for Register to record once the user exists.
+func (s *UserService) screenRegistration(ctx context.Context, user *models.User) (*models.ScreeningResult, error) {
+    if s.watchlist == nil {
+        return nil, nil
+    }
+
+    result := s.watchlist.Screen(user.Username)
+
+    if result.Outcome != models.FraudAllow {
+        log.Warn().Str("username", user.Username).Str("outcome", string(result.Outcome)).
+            Interface("matches", result.Matches).Msg("Registration matched the sanctions watchlist")
+    }
+
+    if result.Outcome == models.FraudBlock {
+        // The user is never created, so the decision is recorded without one
+        auditScreening(ctx, s.auditLogger, 0, result, map[string]interface{}{
+            "email":  user.Email,
+            "action": "register",
+        })
+        return nil, fmt.Errorf("%w: %s", models.ErrSanctionsMatch, user.Username)
+    }
+
+    return result, nil
+}
+
+// auditScreening records a screening decision on the audit log of userID.
+func auditScreening(ctx context.Context, auditLogger *AuditLogger, userID uint, result *models.ScreeningResult, details map[string]interface{}) {
+    if auditLogger == nil {
+        return
+    }
+
+    changes := map[string]interface{}{
+        "name":    result.Name,
+        "outcome": result.Outcome,
+        "matches": result.Matches,
+    }
+    for k, v := range details {
+        changes[k] = v
+    }
+
+    if err := auditLogger.LogAction(ctx, "user", userID, "sanctions_screen", changes); err != nil {
+        log.Error().Err(err).Msg("Failed to log audit")
+    }
+}
+
+// SetWatchlist screens both parties to new transactions against the
+// sanctions watchlist before they are submitted. Strong matches are refused;
+// possible ones are held for review like transactions flagged by fraud
+// screening.
+func (s *TransactionService) SetWatchlist(watchlist *Watchlist) {
+    s.watchlist = watchlist
+}
+
+// screenParties screens user, who initiated tx, and the counterparty to tx
+// against the watchlist, recording the decision for each party on its audit
+// log, matched or not. It returns the strictest outcome, refusing tx if it is a block.
+func (s *TransactionService) screenParties(ctx context.Context, tx *models.Transaction, user *models.User) (models.FraudOutcome, error) {
+    if s.watchlist == nil {
+        return models.FraudAllow, nil
+    }
+
+    parties := []*models.User{user}
+
+    counterpartyID := tx.ToUserID
+    if counterpartyID == user.ID {
+        counterpartyID = tx.FromUserID
+    }
+    if counterpartyID != 0 && counterpartyID != user.ID {
+        counterparty, err := s.userRepo.GetByID(ctx, counterpartyID)
+        if err != nil {
+            return "", fmt.Errorf("failed to get counterparty: %w", err)
+        }
+        parties = append(parties, counterparty)
+    }
+
+    outcome := models.FraudAllow
+    var blocked string
+
+    for _, party := range parties {
+        result := s.watchlist.Screen(party.Username)
+
+        if result.Outcome != models.FraudAllow {
+            log.Warn().Uint("user_id", party.ID).Str("type", string(tx.Type)).Float64("amount", tx.Amount).
+                Str("currency", tx.Currency).Str("outcome", string(result.Outcome)).
+                Msg("Transaction party matched the sanctions watchlist")
+        }
+
+        auditScreening(ctx, s.auditLogger, party.ID, result, map[string]interface{}{
+            "amount":    tx.Amount,
+            "currency":  tx.Currency,
+            "from_user": tx.FromUserID,
+            "to_user":   tx.ToUserID,
+            "type":      tx.Type,
+        })
+
+        if result.Outcome == models.FraudBlock && blocked == "" {
+            blocked = party.Username
+        }
+        if result.Outcome.Stricter(outcome) {
+            outcome = result.Outcome
+        }
+    }
+
+    if outcome == models.FraudBlock {
+        return outcome, fmt.Errorf("%w: %s", models.ErrSanctionsMatch, blocked)
+    }
+
+    return outcome, nil
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// schedulerPageSize is how many due schedules the scheduler loads at a time.
+const schedulerPageSize = 100
+
+// ScheduledTransferService manages standing orders and executes their
+// occurrences through TransactionService.Transfer.
+type ScheduledTransferService struct {
+    repo          repository.ScheduledTransferRepository
+    userRepo      repository.UserRepository
+    txService     *TransactionService
+    auditLogger   *AuditLogger
+    maxRetries    int
+    retryInterval time.Duration
+}
+
+// NewScheduledTransferService retries occurrences that find insufficient
+// funds up to maxRetries times, retryInterval apart, for schedules that ask
+// for it.
+func NewScheduledTransferService(
+    repo repository.ScheduledTransferRepository,
+    userRepo repository.UserRepository,
+    txService *TransactionService,
+    maxRetries int,
+    retryInterval time.Duration,
+) *ScheduledTransferService {
+    return &ScheduledTransferService{
+        repo:          repo,
+        userRepo:      userRepo,
+        txService:     txService,
+        maxRetries:    maxRetries,
+        retryInterval: retryInterval,
+    }
+}
+
+func (s *ScheduledTransferService) SetAuditLogger(logger *AuditLogger) {
+    s.auditLogger = logger
+}
+
+func (s *ScheduledTransferService) audit(ctx context.Context, id uint, action string, changes map[string]interface{}) {
+    if s.auditLogger == nil {
+        return
+    }
+
+    if err := s.auditLogger.LogAction(ctx, "scheduled_transfer", id, action, changes); err != nil {
+        log.Error().Err(err).Msg("Failed to log audit")
+    }
+}
+
+// Create saves a new active schedule. A zero StartAt starts it now and an
+// empty OnInsufficientFunds retries.
+func (s *ScheduledTransferService) Create(ctx context.Context, schedule *models.ScheduledTransfer) error {
+    now := time.Now().UTC().Truncate(time.Second)
+
+    if schedule.StartAt.IsZero() {
+        schedule.StartAt = now
+    }
+    schedule.StartAt = schedule.StartAt.UTC().Truncate(time.Second)
+
+    if schedule.OnInsufficientFunds == "" {
+        schedule.OnInsufficientFunds = models.OnInsufficientFundsRetry
+    }
+
+    if err := schedule.Validate(); err != nil {
+        return err
+    }
+
+    for _, userID := range []uint{schedule.FromUserID, schedule.ToUserID} {
+        if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+            if err == repository.ErrNotFound {
+                return fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+            }
+            return fmt.Errorf("failed to get user: %w", err)
+        }
+    }
+
+    schedule.NextRunAt = schedule.StartAt
+    schedule.DueAt = schedule.StartAt
+    schedule.Status = models.ScheduleStatusActive
+    schedule.CreatedAt = now
+    schedule.UpdatedAt = now
+
+    if err := s.repo.Create(ctx, schedule); err != nil {
+        return fmt.Errorf("failed to create scheduled transfer: %w", err)
+    }
+
+    s.audit(ctx, schedule.ID, "create", map[string]interface{}{
+        "from_user":             schedule.FromUserID,
+        "to_user":               schedule.ToUserID,
+        "amount":                schedule.Amount,
+        "currency":              schedule.Currency,
+        "frequency":             schedule.Frequency,
+        "start_at":              schedule.StartAt,
+        "end_date":              schedule.EndDate,
+        "on_insufficient_funds": schedule.OnInsufficientFunds,
+    })
+
+    return nil
+}
+
+func (s *ScheduledTransferService) Get(ctx context.Context, id uint) (*models.ScheduledTransfer, error) {
+    schedule, err := s.repo.GetByID(ctx, id)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("scheduled transfer not found: %d", id)
+        }
+        return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+    }
+
+    return schedule, nil
+}
+
+// ListByUser returns the schedules paying from or into the user.
+func (s *ScheduledTransferService) ListByUser(ctx context.Context, userID uint) ([]*models.ScheduledTransfer, error) {
+    schedules, err := s.repo.ListByUser(ctx, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list scheduled transfers: %w", err)
+    }
+
+    return schedules, nil
+}
+
+// ScheduledTransferUpdate holds the changes to a schedule; nil fields are
+// left as they are.
+type ScheduledTransferUpdate struct {
+    Amount              *float64   `json:"amount"`
+    EndDate             *time.Time `json:"end_date"`
+    OnInsufficientFunds *string    `json:"on_insufficient_funds"`
+}
+
+// Update changes an active schedule. Moving the end date before the next
+// occurrence completes it.
+func (s *ScheduledTransferService) Update(ctx context.Context, id uint, update ScheduledTransferUpdate) (*models.ScheduledTransfer, error) {
+    schedule, err := s.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if schedule.Status != models.ScheduleStatusActive {
+        return nil, fmt.Errorf("scheduled transfer %d is %s", id, schedule.Status)
+    }
+
+    changes := map[string]interface{}{}
+
+    if update.Amount != nil {
+        schedule.Amount = *update.Amount
+        changes["amount"] = *update.Amount
+    }
+
+    if update.EndDate != nil {
+        endDate := update.EndDate.UTC()
+        schedule.EndDate = &endDate
+        changes["end_date"] = endDate
+    }
+
+    if update.OnInsufficientFunds != nil {
+        schedule.OnInsufficientFunds = *update.OnInsufficientFunds
+        changes["on_insufficient_funds"] = *update.OnInsufficientFunds
+    }
+
+    if err := schedule.Validate(); err != nil {
+        return nil, err
+    }
+
+    if schedule.Ends(schedule.NextRunAt) {
+        schedule.Status = models.ScheduleStatusCompleted
+        changes["status"] = schedule.Status
+    }
+
+    schedule.UpdatedAt = time.Now()
+
+    if err := s.repo.Update(ctx, schedule); err != nil {
+        return nil, fmt.Errorf("failed to update scheduled transfer: %w", err)
+    }
+
+    s.audit(ctx, id, "update", changes)
+
+    return schedule, nil
+}
+
+// Cancel stops an active schedule. Occurrences already executed stand.
+func (s *ScheduledTransferService) Cancel(ctx context.Context, id uint) (*models.ScheduledTransfer, error) {
+    schedule, err := s.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if schedule.Status != models.ScheduleStatusActive {
+        return nil, fmt.Errorf("scheduled transfer %d is %s", id, schedule.Status)
+    }
+
+    schedule.Status = models.ScheduleStatusCancelled
+    schedule.UpdatedAt = time.Now()
+
+    if err := s.repo.Update(ctx, schedule); err != nil {
+        return nil, fmt.Errorf("failed to cancel scheduled transfer: %w", err)
+    }
+
+    s.audit(ctx, id, "cancel", map[string]interface{}{
+        "status": schedule.Status,
+    })
+
+    return schedule, nil
+}
+
+// ListRuns returns the occurrences of a schedule executed so far.
+func (s *ScheduledTransferService) ListRuns(ctx context.Context, id uint) ([]*models.ScheduledTransferRun, error) {
+    if _, err := s.Get(ctx, id); err != nil {
+        return nil, err
+    }
+
+    runs, err := s.repo.ListRuns(ctx, id)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list runs: %w", err)
+    }
+
+    return runs, nil
+}
+
+// Run executes every occurrence due at now, catching up one occurrence at a
+// time on schedules that fell behind. A schedule that fails to execute is
+// logged and left for the next run.
+func (s *ScheduledTransferService) Run(ctx context.Context, now time.Time) error {
+    for {
+        due, err := s.repo.ListDue(ctx, now, schedulerPageSize)
+        if err != nil {
+            return fmt.Errorf("failed to list due scheduled transfers: %w", err)
+        }
+
+        progressed := 0
+
+        for _, schedule := range due {
+            if err := s.execute(ctx, schedule, now); err != nil {
+                log.Error().Err(err).Uint("schedule_id", schedule.ID).Msg("Failed to execute scheduled transfer")
+                continue
+            }
+            progressed++
+        }
+
+        if len(due) < schedulerPageSize || progressed == 0 {
+            return nil
+        }
+    }
+}
+
+// execute runs the schedule's next occurrence, unless a run for it already
+// exists, and moves the schedule on. The run is recorded before the transfer
+// is made, so an occurrence is never transferred twice, even if the
+// scheduler stops halfway. The transfer is made on behalf of the payer, so
+// that one held for approval is the payer's to have approved; its run waits
+// for the decision.
+func (s *ScheduledTransferService) execute(ctx context.Context, schedule *models.ScheduledTransfer, now time.Time) error {
+    occurrence := schedule.NextRunAt
+
+    payer, err := s.userRepo.GetByID(ctx, schedule.FromUserID)
+    if err != nil {
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+
+    run, err := s.claim(ctx, schedule.ID, occurrence, now)
+    if err != nil {
+        return err
+    }
+
+    if run == nil {
+        // Already executed by a run that did not get to move the schedule on
+        return s.advance(ctx, schedule, now)
+    }
+
+    tx, err := s.txService.Transfer(WithActorUser(ctx, payer), schedule.FromUserID, schedule.ToUserID, schedule.Amount, schedule.Currency)
+
+    run.UpdatedAt = now
+    run.Error = ""
+
+    switch {
+        case err == nil && tx.Status == models.TransactionStatusAwaitingApproval:
+            run.Status = models.ScheduledRunAwaitingApproval
+            run.TransactionID = tx.ID
+        case err == nil:
+            run.Status = models.ScheduledRunCompleted
+            run.TransactionID = tx.ID
+        case errors.Is(err, models.ErrInsufficientFunds):
+            run.Error = err.Error()
+            if retryAt, ok := s.retryAt(schedule, run, now); ok {
+                return s.retry(ctx, schedule, run, retryAt, now)
+            }
+            run.Status = models.ScheduledRunSkipped
+        default:
+            run.Error = err.Error()
+            run.Status = models.ScheduledRunFailed
+    }
+
+    if err := s.repo.UpdateRun(ctx, run); err != nil {
+        return fmt.Errorf("failed to record run: %w", err)
+    }
+
+    log.Info().Uint("schedule_id", schedule.ID).Time("occurrence", occurrence).
+        Str("status", string(run.Status)).Str("error", run.Error).Msg("Scheduled transfer executed")
+
+    return s.advance(ctx, schedule, now)
+}
+
+// Resolve records how the held transaction of a run awaiting approval
+// ended. It is the TransactionService's DecisionListener; transactions not
+// made by a scheduled run are ignored.
+func (s *ScheduledTransferService) Resolve(ctx context.Context, transactionID uint, status models.TransactionStatus, detail string) error {
+    run, err := s.repo.GetRunByTransaction(ctx, transactionID)
+    if errors.Is(err, repository.ErrNotFound) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get run: %w", err)
+    }
+
+    if run.Status != models.ScheduledRunAwaitingApproval {
+        return nil
+    }
+
+    run.UpdatedAt = time.Now()
+
+    switch status {
+        case models.TransactionStatusCompleted:
+            run.Status = models.ScheduledRunCompleted
+        case models.TransactionStatusRejected:
+            run.Status = models.ScheduledRunFailed
+            run.Error = "transfer rejected: " + detail
+        case models.TransactionStatusExpired:
+            run.Status = models.ScheduledRunFailed
+            run.Error = "transfer not approved in time"
+        default:
+            run.Status = models.ScheduledRunFailed
+            run.Error = detail
+    }
+
+    if err := s.repo.UpdateRun(ctx, run); err != nil {
+        return fmt.Errorf("failed to record run: %w", err)
+    }
+
+    log.Info().Uint("schedule_id", run.ScheduleID).Time("occurrence", run.Occurrence).
+        Str("status", string(run.Status)).Str("error", run.Error).Msg("Scheduled transfer decided")
+
+    return nil
+}
+
+// claim records a pending run for the occurrence, or takes over its run if
+// it is waiting to be retried. It returns nil if the occurrence has been
+// dealt with already.
+func (s *ScheduledTransferService) claim(ctx context.Context, scheduleID uint, occurrence, now time.Time) (*models.ScheduledTransferRun, error) {
+    run := &models.ScheduledTransferRun{
+        ScheduleID: scheduleID,
+        Occurrence: occurrence,
+        Status:     models.ScheduledRunPending,
+        Attempts:   1,
+        UpdatedAt:  now,
+    }
+
+    err := s.repo.CreateRun(ctx, run)
+    if err == nil {
+        return run, nil
+    }
+
+    if !errors.Is(err, repository.ErrDuplicateKey) {
+        return nil, fmt.Errorf("failed to record run: %w", err)
+    }
+
+    if err := s.repo.ClaimRetry(ctx, scheduleID, occurrence); err != nil {
+        if errors.Is(err, repository.ErrNotFound) {
+            return nil, nil
+        }
+        return nil, fmt.Errorf("failed to claim run: %w", err)
+    }
+
+    run, err = s.repo.GetRun(ctx, scheduleID, occurrence)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get run: %w", err)
+    }
+
+    run.Attempts++
+
+    return run, nil
+}
+
+// retryAt returns when to retry an occurrence that found insufficient funds,
+// or false if the schedule skips it instead: it does not ask for retries, the
+// retries are used up, or the next occurrence would be due first.
+func (s *ScheduledTransferService) retryAt(schedule *models.ScheduledTransfer, run *models.ScheduledTransferRun, now time.Time) (time.Time, bool) {
+    if schedule.OnInsufficientFunds != models.OnInsufficientFundsRetry || run.Attempts > s.maxRetries {
+        return time.Time{}, false
+    }
+
+    retryAt := now.Add(s.retryInterval)
+
+    if next := schedule.Next(run.Occurrence); !schedule.Ends(next) && !retryAt.Before(next) {
+        return time.Time{}, false
+    }
+
+    return retryAt, true
+}
+
+func (s *ScheduledTransferService) retry(ctx context.Context, schedule *models.ScheduledTransfer, run *models.ScheduledTransferRun, retryAt, now time.Time) error {
+    run.Status = models.ScheduledRunRetrying
+
+    if err := s.repo.UpdateRun(ctx, run); err != nil {
+        return fmt.Errorf("failed to record run: %w", err)
+    }
+
+    schedule.DueAt = retryAt
+    schedule.UpdatedAt = now
+
+    if err := s.repo.Update(ctx, schedule); err != nil {
+        return fmt.Errorf("failed to update scheduled transfer: %w", err)
+    }
+
+    log.Warn().Uint("schedule_id", schedule.ID).Time("occurrence", run.Occurrence).Int("attempts", run.Attempts).
+        Time("retry_at", retryAt).Msg("Scheduled transfer found insufficient funds, will retry")
+
+    return nil
+}
+
+// advance moves the schedule to its next occurrence, completing it when
+// there is none.
+func (s *ScheduledTransferService) advance(ctx context.Context, schedule *models.ScheduledTransfer, now time.Time) error {
+    next := schedule.Next(schedule.NextRunAt)
+
+    if schedule.Ends(next) {
+        schedule.Status = models.ScheduleStatusCompleted
+    } else {
+        schedule.NextRunAt = next
+        schedule.DueAt = next
+    }
+
+    schedule.UpdatedAt = now
+
+    if err := s.repo.Update(ctx, schedule); err != nil {
+        return fmt.Errorf("failed to update scheduled transfer: %w", err)
+    }
+
+    return nil
+}
+
+// Job returns a periodic job executing due occurrences. The interval bounds
+// how late after its time an occurrence runs.
+func (s *ScheduledTransferService) Job(interval time.Duration) *PeriodicJob {
+    return NewPeriodicJob("scheduled_transfers", interval, func(ctx context.Context) error {
+        return s.Run(WithActor(ctx, "scheduler"), time.Now())
+    })
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "financial-service/internal/models"
+    "github.com/go-pdf/fpdf"
+)
+
+// Layout of PDF statements, in millimetres on A4 paper
+const (
+    pdfMargin    = 15.0
+    pdfRowHeight = 6.0
+)
+
+// pdfColumn is a column of the transaction table.
+type pdfColumn struct {
+    title string
+    width float64
+    align string
+}
+
+var pdfColumns = []pdfColumn{
+    {"Date", 24, "L"},
+    {"Reference", 18, "L"},
+    {"Type", 20, "L"},
+    {"Counterparty", 24, "L"},
+    {"Description", 46, "L"},
+    {"Amount", 24, "R"},
+    {"Balance", 24, "R"},
+}
+
+// WritePDF renders the statement as a paginated PDF: every page carries the
+// account details and, after the summary on the first page, the transaction
+// table continues across pages with its column headings repeated.
+func (st *Statement) WritePDF(w io.Writer) error {
+    pdf := fpdf.New("P", "mm", "A4", "")
+    pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+    pdf.SetAutoPageBreak(false, pdfMargin)
+    // Date the file by the statement and write its resources in order, so
+    // that the same statement always renders to the same bytes
+    pdf.SetCreationDate(st.GeneratedAt)
+    pdf.SetModificationDate(st.GeneratedAt)
+    pdf.SetCatalogSort(true)
+    pdf.SetTitle(fmt.Sprintf("Statement for account %d", st.UserID), true)
+    pdf.AliasNbPages("")
+
+    // Core fonts are Latin-1; translate UTF-8 text into their encoding
+    tr := pdf.UnicodeTranslatorFromDescriptor("")
+    units := models.MinorUnits(st.Currency)
+    amount := func(v float64) string {
+        return strconv.FormatFloat(v, 'f', units, 64)
+    }
+
+    // The period is [From, To); show the last day it includes
+    period := fmt.Sprintf("%s to %s", st.From.UTC().Format("2 January 2006"),
+        st.To.UTC().Add(-1).Format("2 January 2006"))
+
+    pdf.SetHeaderFunc(func() {
+        pdf.SetFont("Helvetica", "B", 16)
+        pdf.CellFormat(0, 8, "Account statement", "", 1, "L", false, 0, "")
+
+        pdf.SetFont("Helvetica", "", 10)
+        pdf.CellFormat(0, 5, tr(fmt.Sprintf("%s (account %d)", st.Username, st.UserID)), "", 1, "L", false, 0, "")
+        pdf.CellFormat(0, 5, fmt.Sprintf("%s, %s", st.Currency, period), "", 1, "L", false, 0, "")
+
+        pdf.Ln(3)
+        pdf.Line(pdfMargin, pdf.GetY(), 210-pdfMargin, pdf.GetY())
+        pdf.Ln(4)
+    })
+
+    pdf.SetFooterFunc(func() {
+        pdf.SetY(-pdfMargin)
+        pdf.SetFont("Helvetica", "", 8)
+        pdf.CellFormat(0, 5, fmt.Sprintf("Generated %s", st.GeneratedAt.UTC().Format("2006-01-02 15:04 MST")),
+            "", 0, "L", false, 0, "")
+        pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+    })
+
+    pdf.AddPage()
+
+    // Summary
+    summary := [][2]string{
+        {"Opening balance", amount(st.OpeningBalance)},
+        {"Total credits", amount(st.TotalCredits)},
+        {"Total debits", amount(st.TotalDebits)},
+        {"Closing balance", amount(st.ClosingBalance)},
+        {"Transactions", strconv.Itoa(len(st.Lines))},
+    }
+
+    pdf.SetFont("Helvetica", "B", 11)
+    pdf.CellFormat(0, 7, "Summary", "", 1, "L", false, 0, "")
+    pdf.SetFont("Helvetica", "", 10)
+    for _, row := range summary {
+        pdf.CellFormat(50, pdfRowHeight, row[0], "", 0, "L", false, 0, "")
+        pdf.CellFormat(40, pdfRowHeight, row[1], "", 1, "R", false, 0, "")
+    }
+    pdf.Ln(6)
+
+    tableHeader := func() {
+        pdf.SetFont("Helvetica", "B", 9)
+        pdf.SetFillColor(230, 230, 230)
+        for _, column := range pdfColumns {
+            pdf.CellFormat(column.width, pdfRowHeight, column.title, "B", 0, column.align, true, 0, "")
+        }
+        pdf.Ln(-1)
+        pdf.SetFont("Helvetica", "", 9)
+    }
+
+    _, pageHeight := pdf.GetPageSize()
+    row := func(values ...string) {
+        // Leave room for the footer
+        if pdf.GetY()+pdfRowHeight > pageHeight-2*pdfMargin {
+            pdf.AddPage()
+            tableHeader()
+        }
+        for i, column := range pdfColumns {
+            pdf.CellFormat(column.width, pdfRowHeight, fitText(pdf, tr(values[i]), column.width-2), "", 0, column.align, false, 0, "")
+        }
+        pdf.Ln(-1)
+    }
+
+    tableHeader()
+    row(st.From.UTC().Format("2006-01-02"), "", "", "", "Opening balance", "", amount(st.OpeningBalance))
+
+    for _, line := range st.Lines {
+        counterparty := ""
+        if line.CounterpartyID != 0 {
+            counterparty = strconv.FormatUint(uint64(line.CounterpartyID), 10)
+        }
+
+        row(
+            line.BookedAt.UTC().Format("2006-01-02"),
+            strconv.FormatUint(uint64(line.TransactionID), 10),
+            string(line.Type),
+            counterparty,
+            line.Description,
+            amount(line.Amount),
+            amount(line.Balance),
+        )
+    }
+
+    row(st.To.UTC().Add(-1).Format("2006-01-02"), "", "", "", "Closing balance", "", amount(st.ClosingBalance))
+
+    return pdf.Output(w)
+}
+
+// fitText shortens text with an ellipsis until it fits in width.
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+    if pdf.GetStringWidth(text) <= width {
+        return text
+    }
+
+    for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+        text = text[:len(text)-1]
+    }
+
+    return text + "..."
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "strconv"
+    "time"
+    "financial-service/internal/iso20022"
+    "financial-service/internal/models"
+    "financial-service/internal/ofx"
+    "financial-service/internal/repository"
+)
+
+// StatementService produces account statements: the balance at the start of
+// a period, every completed transaction in it with the running balance, and
+// the balance at its end.
+type StatementService struct {
+    userRepo       repository.UserRepository
+    historyService *BalanceHistoryService
+    ofxBankID      string
+    pdfDir         string
+}
+
+func NewStatementService(userRepo repository.UserRepository, historyService *BalanceHistoryService) *StatementService {
+    return &StatementService{
+        userRepo:       userRepo,
+        historyService: historyService,
+    }
+}
+
+// SetOFXBankID sets the BANKID that OFX statements identify accounts with.
+func (s *StatementService) SetOFXBankID(bankID string) {
+    s.ofxBankID = bankID
+}
+
+// SetPDFDir sets the directory monthly PDF statements are kept in, one
+// subdirectory per month.
+func (s *StatementService) SetPDFDir(dir string) {
+    s.pdfDir = dir
+}
+
+// StatementLine is one transaction on a statement. Amount is signed: what
+// the transaction paid into or out of the balance. Lines are dated by when
+// they were booked.
+type StatementLine struct {
+    TransactionID  uint                   `json:"transaction_id"`
+    Type           models.TransactionType `json:"type"`
+    CounterpartyID uint                   `json:"counterparty_id,omitempty"`
+    Description    string                 `json:"description,omitempty"`
+    Amount         float64                `json:"amount"`
+    Balance        float64                `json:"balance"`
+    CreatedAt      time.Time              `json:"created_at"`
+    BookedAt       time.Time              `json:"booked_at"`
+}
+
+type Statement struct {
+    UserID         uint            `json:"user_id"`
+    Username       string          `json:"username"`
+    Currency       string          `json:"currency"`
+    From           time.Time       `json:"from"`
+    To             time.Time       `json:"to"`
+    OpeningBalance float64         `json:"opening_balance"`
+    ClosingBalance float64         `json:"closing_balance"`
+    TotalCredits   float64         `json:"total_credits"`
+    TotalDebits    float64         `json:"total_debits"`
+    Lines          []StatementLine `json:"lines"`
+    GeneratedAt    time.Time       `json:"generated_at"`
+
+    // ledger is the stored balance when the statement was generated, which
+    // OFX reports as the ledger balance.
+    ledger    *models.Balance
+    ofxBankID string
+}
+
+// Generate builds the user's statement in currency for [from, to).
+func (s *StatementService) Generate(ctx context.Context, userID uint, currency string, from, to time.Time) (*Statement, error) {
+    if !from.Before(to) {
+        return nil, errors.New("from must be before to")
+    }
+
+    user, err := s.userRepo.GetByID(ctx, userID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    ledger, err := s.historyService.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("user %d has no %s balance: %w", userID, currency, err)
+        }
+        return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    opening, err := s.historyService.balanceAt(ctx, ledger, from)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get opening balance: %w", err)
+    }
+
+    statement := &Statement{
+        UserID:         userID,
+        Username:       user.Username,
+        Currency:       currency,
+        From:           from,
+        To:             to,
+        OpeningBalance: opening,
+        Lines:          []StatementLine{},
+        GeneratedAt:    time.Now(),
+        ledger:         ledger,
+        ofxBankID:      s.ofxBankID,
+    }
+
+    running := opening
+
+    err = s.historyService.replay(ctx, ledger, from, to, func(tx *models.Transaction, change float64) {
+        running = models.RoundAmount(running+change, currency)
+
+        counterparty := tx.ToUserID
+        if counterparty == userID {
+            counterparty = tx.FromUserID
+        }
+
+        if change >= 0 {
+            statement.TotalCredits += change
+        } else {
+            statement.TotalDebits -= change
+        }
+
+        statement.Lines = append(statement.Lines, StatementLine{
+            TransactionID:  tx.ID,
+            Type:           tx.Type,
+            CounterpartyID: counterparty,
+            Description:    tx.Description,
+            Amount:         change,
+            Balance:        running,
+            CreatedAt:      tx.CreatedAt,
+            BookedAt:       *tx.BookedAt,
+        })
+    })
+
+    if err != nil {
+        return nil, fmt.Errorf("failed to read transactions: %w", err)
+    }
+
+    statement.ClosingBalance = running
+    statement.TotalCredits = models.RoundAmount(statement.TotalCredits, currency)
+    statement.TotalDebits = models.RoundAmount(statement.TotalDebits, currency)
+
+    return statement, nil
+}
+
+// Write renders the statement as "json", "csv", "camt.053", "ofx" or "pdf".
+func (st *Statement) Write(w io.Writer, format string) error {
+    switch format {
+        case "json":
+            return st.WriteJSON(w)
+        case "csv":
+            return st.WriteCSV(w)
+        case "camt.053":
+            return st.WriteCamt053(w)
+        case "ofx":
+            return st.WriteOFX(w)
+        case "pdf":
+            return st.WritePDF(w)
+        default:
+            return fmt.Errorf("unsupported statement format: %q", format)
+    }
+}
+
+// StatementContentType returns the media type of a statement written in
+// format, or "" if Write does not support the format.
+func StatementContentType(format string) string {
+    switch format {
+        case "json":
+            return "application/json"
+        case "csv":
+            return "text/csv"
+        case "camt.053":
+            return "application/xml"
+        case "ofx":
+            return "application/x-ofx"
+        case "pdf":
+            return "application/pdf"
+        default:
+            return ""
+    }
+}
+
+func (st *Statement) WriteJSON(w io.Writer) error {
+    enc := json.NewEncoder(w)
+    enc.SetIndent("", "  ")
+    return enc.Encode(st)
+}
+
+// WriteCSV writes one row per transaction between an opening and a closing
+// balance row.
+func (st *Statement) WriteCSV(w io.Writer) error {
+    cw := csv.NewWriter(w)
+    amount := func(v float64) string {
+        return strconv.FormatFloat(v, 'f', models.MinorUnits(st.Currency), 64)
+    }
+
+    records := [][]string{
+        {"date", "transaction_id", "type", "counterparty_id", "description", "amount", "balance", "currency"},
+        {st.From.UTC().Format(time.RFC3339), "", "opening_balance", "", "", "", amount(st.OpeningBalance), st.Currency},
+    }
+
+    for _, line := range st.Lines {
+        counterparty := ""
+        if line.CounterpartyID != 0 {
+            counterparty = strconv.FormatUint(uint64(line.CounterpartyID), 10)
+        }
+
+        records = append(records, []string{
+            line.BookedAt.UTC().Format(time.RFC3339),
+            strconv.FormatUint(uint64(line.TransactionID), 10),
+            string(line.Type),
+            counterparty,
+            line.Description,
+            amount(line.Amount),
+            amount(line.Balance),
+            st.Currency,
+        })
+    }
+
+    records = append(records, []string{st.To.UTC().Format(time.RFC3339), "", "closing_balance", "", "", "", amount(st.ClosingBalance), st.Currency})
+
+    if err := cw.WriteAll(records); err != nil {
+        return err
+    }
+
+    return cw.Error()
+}
+
+// WriteCamt053 writes the statement as an ISO 20022 camt.053 message. The
+// account is identified by the user ID, as in pain.001 imports.
+func (st *Statement) WriteCamt053(w io.Writer) error {
+    units := models.MinorUnits(st.Currency)
+    amount := func(v float64) (iso20022.Amount, string) {
+        indicator := iso20022.Credit
+        if v < 0 {
+            indicator = iso20022.Debit
+        }
+        return iso20022.Amount{
+            Value:    strconv.FormatFloat(math.Abs(v), 'f', units, 64),
+            Currency: st.Currency,
+        }, indicator
+    }
+    dateTime := func(t time.Time) string {
+        return t.UTC().Format("2006-01-02T15:04:05Z")
+    }
+    balance := func(kind string, v float64, t time.Time) iso20022.CashBalance {
+        amt, indicator := amount(v)
+        return iso20022.CashBalance{Type: kind, Amount: amt, CreditDebit: indicator, DateTime: dateTime(t)}
+    }
+
+    id := fmt.Sprintf("STMT-%d-%s-%s", st.UserID, st.Currency, st.From.UTC().Format("20060102"))
+    created := dateTime(st.GeneratedAt)
+
+    statement := iso20022.AccountStatement{
+        ID:               id,
+        CreationDateTime: created,
+        FromDateTime:     dateTime(st.From),
+        ToDateTime:       dateTime(st.To),
+        Account: iso20022.StatementAccount{
+            ID:        strconv.FormatUint(uint64(st.UserID), 10),
+            Currency:  st.Currency,
+            OwnerName: st.Username,
+        },
+        Balances: []iso20022.CashBalance{
+            balance(iso20022.BalanceOpeningBooked, st.OpeningBalance, st.From),
+            balance(iso20022.BalanceClosingBooked, st.ClosingBalance, st.To),
+        },
+        Summary: &iso20022.TransactionsSummary{},
+    }
+
+    for _, line := range st.Lines {
+        amt, indicator := amount(line.Amount)
+
+        if indicator == iso20022.Credit {
+            statement.Summary.Credits.Count++
+        } else {
+            statement.Summary.Debits.Count++
+        }
+
+        entry := iso20022.ReportEntry{
+            Reference:         strconv.FormatUint(uint64(line.TransactionID), 10),
+            Amount:            amt,
+            CreditDebit:       indicator,
+            Status:            iso20022.EntryBooked,
+            BookingDateTime:   dateTime(line.BookedAt),
+            ValueDateTime:     dateTime(line.CreatedAt),
+            ServicerReference: strconv.FormatUint(uint64(line.TransactionID), 10),
+            TransactionCode:   string(line.Type),
+        }
+
+        if line.Description != "" {
+            entry.Details = &iso20022.EntryDetails{Remittance: line.Description}
+        }
+
+        if line.CounterpartyID != 0 {
+            entry.AdditionalInfo = fmt.Sprintf("counterparty account %d", line.CounterpartyID)
+        }
+
+        statement.Entries = append(statement.Entries, entry)
+    }
+
+    statement.Summary.Credits.Sum = strconv.FormatFloat(st.TotalCredits, 'f', units, 64)
+    statement.Summary.Debits.Sum = strconv.FormatFloat(st.TotalDebits, 'f', units, 64)
+
+    doc := &iso20022.Camt053{
+        Statement: iso20022.BankToCustomerStatement{
+            GroupHeader: iso20022.ReportGroupHeader{
+                MessageID:        id,
+                CreationDateTime: created,
+            },
+            Statements: []iso20022.AccountStatement{statement},
+        },
+    }
+
+    body, err := doc.Marshal()
+    if err != nil {
+        return err
+    }
+
+    _, err = w.Write(append(body, '\n'))
+    return err
+}
+
+// WriteOFX writes the statement as an OFX 2.2 bank statement. Each
+// transaction's ID is its FITID, and the ledger balance is the stored balance
+// at the time the statement was generated rather than the closing balance.
+func (st *Statement) WriteOFX(w io.Writer) error {
+    units := models.MinorUnits(st.Currency)
+    amount := func(v float64) string {
+        return strconv.FormatFloat(v, 'f', units, 64)
+    }
+
+    statement := ofx.StatementResponse{
+        Currency: st.Currency,
+        Account: ofx.BankAccount{
+            BankID:      st.ofxBankID,
+            AccountID:   strconv.FormatUint(uint64(st.UserID), 10),
+            AccountType: "CHECKING",
+        },
+        Transactions: ofx.TransactionList{
+            Start: ofx.Time(st.From),
+            End:   ofx.Time(st.To),
+        },
+    }
+
+    for _, line := range st.Lines {
+        entry := ofx.Transaction{
+            Type:   ofxType(line),
+            Posted: ofx.Time(line.BookedAt),
+            Amount: amount(line.Amount),
+            FITID:  strconv.FormatUint(uint64(line.TransactionID), 10),
+            Memo:   line.Description,
+        }
+
+        if line.CounterpartyID != 0 {
+            entry.Name = ofx.Name(fmt.Sprintf("Account %d", line.CounterpartyID))
+        }
+
+        statement.Transactions.Transactions = append(statement.Transactions.Transactions, entry)
+    }
+
+    if st.ledger != nil {
+        statement.LedgerBalance = ofx.Balance{Amount: amount(st.ledger.Amount), AsOf: ofx.Time(st.GeneratedAt)}
+        statement.AvailableBalance = &ofx.Balance{Amount: amount(st.ledger.Available()), AsOf: ofx.Time(st.GeneratedAt)}
+    } else {
+        statement.LedgerBalance = ofx.Balance{Amount: amount(st.ClosingBalance), AsOf: ofx.Time(st.To)}
+    }
+
+    doc := &ofx.Document{
+        SignOn: ofx.SignOn{
+            Status:     ofx.OK,
+            ServerTime: ofx.Time(st.GeneratedAt),
+            Language:   "ENG",
+        },
+        Bank: ofx.StatementSet{
+            TransactionUID: "0",
+            Status:         ofx.OK,
+            Statement:      statement,
+        },
+    }
+
+    body, err := doc.Marshal()
+    if err != nil {
+        return err
+    }
+
+    _, err = w.Write(append(body, '\n'))
+    return err
+}
+
+// ofxType maps a statement line to an OFX transaction type.
+func ofxType(line StatementLine) string {
+    switch line.Type {
+        case models.TransactionTypeTransfer:
+            return ofx.TypeTransfer
+        case models.TransactionTypeFee:
+            return ofx.TypeFee
+        case models.TransactionTypeInterest:
+            return ofx.TypeInterest
+    }
+
+    if line.Amount < 0 {
+        return ofx.TypeDebit
+    }
+    return ofx.TypeCredit
+}
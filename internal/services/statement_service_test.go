@@ -0,0 +1,152 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "flag"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+    "unicode/utf8"
+    "financial-service/internal/models"
+    "financial-service/internal/repository/memory"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with the golden file testdata/name, or rewrites the
+// file with -update.
+func golden(t *testing.T, name string, got []byte) {
+    t.Helper()
+
+    path := filepath.Join("testdata", name)
+    if *update {
+        require.NoError(t, os.WriteFile(path, got, 0o644))
+    }
+
+    want, err := os.ReadFile(path)
+    require.NoError(t, err)
+
+    // Only text is worth showing a diff of
+    if !utf8.Valid(want) {
+        assert.True(t, bytes.Equal(want, got), "output differs from %s, rerun with -update if that is intended", path)
+        return
+    }
+    assert.Equal(t, string(want), string(got), "output differs from %s, rerun with -update if that is intended", path)
+}
+
+// testStatement is a March statement with one line of each kind, and text
+// that needs quoting or translating.
+func testStatement() *Statement {
+    at := func(day, hour int) time.Time {
+        return time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)
+    }
+
+    return &Statement{
+        UserID:         7,
+        Username:       "zoë",
+        Currency:       "USD",
+        From:           at(1, 0),
+        To:             at(1, 0).AddDate(0, 1, 0),
+        OpeningBalance: 100,
+        ClosingBalance: 118.87,
+        TotalCredits:   50.12,
+        TotalDebits:    31.25,
+        Lines: []StatementLine{
+            {TransactionID: 11, Type: models.TransactionTypeCredit, Description: "Salary", Amount: 50, Balance: 150, CreatedAt: at(4, 9), BookedAt: at(4, 9)},
+            {TransactionID: 12, Type: models.TransactionTypeTransfer, CounterpartyID: 9, Description: `Rent, "March"`, Amount: -30.25, Balance: 119.75, CreatedAt: at(5, 10), BookedAt: at(6, 8)},
+            {TransactionID: 13, Type: models.TransactionTypeFee, Description: "Transfer fee", Amount: -1, Balance: 118.75, CreatedAt: at(5, 10), BookedAt: at(6, 8)},
+            {TransactionID: 14, Type: models.TransactionTypeInterest, Description: "Interest for February", Amount: 0.12, Balance: 118.87, CreatedAt: at(31, 23), BookedAt: at(31, 23)},
+        },
+        GeneratedAt: time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC),
+        ledger:      &models.Balance{AccountID: 3, UserID: 7, Currency: "USD", Amount: 120, CreditLimit: 50},
+        ofxBankID:   "FINSVC",
+    }
+}
+
+func TestStatementWrite(t *testing.T) {
+    tests := []struct {
+        name   string
+        format string
+        // edit, if set, changes the statement before it is written
+        edit   func(st *Statement)
+        golden string
+    }{
+        {name: "csv", format: "csv", golden: "statement.csv"},
+        {name: "camt.053", format: "camt.053", golden: "statement_camt053.xml"},
+        {name: "ofx", format: "ofx", golden: "statement.ofx"},
+        {
+            name:   "ofx without the stored balance",
+            format: "ofx",
+            edit: func(st *Statement) {
+                st.ledger = nil
+            },
+            golden: "statement_closing.ofx",
+        },
+        {name: "pdf", format: "pdf", golden: "statement.pdf"},
+        {
+            name:   "pdf over several pages",
+            format: "pdf",
+            edit: func(st *Statement) {
+                line := st.Lines[0]
+                for i := 0; i < 60; i++ {
+                    line.TransactionID++
+                    line.Balance += line.Amount
+                    st.Lines = append(st.Lines, line)
+                }
+                st.ClosingBalance = line.Balance
+            },
+            golden: "statement_pages.pdf",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            st := testStatement()
+            if tt.edit != nil {
+                tt.edit(st)
+            }
+
+            var buf bytes.Buffer
+            require.NoError(t, st.Write(&buf, tt.format))
+            golden(t, tt.golden, buf.Bytes())
+        })
+    }
+}
+
+func TestMonthlyPDF(t *testing.T) {
+    ctx := context.Background()
+    store := memory.NewStore()
+    users := memory.NewUserRepository(store)
+    balances := memory.NewBalanceRepository(store)
+
+    user, err := NewUserService(users, memory.NewAccountRepository(store), balances, "USD").RegisterUser(ctx, "alice", "alice@example.com", "Passw0rd!23")
+    require.NoError(t, err)
+
+    history := NewBalanceHistoryService(balances, memory.NewTransactionRepository(store), memory.NewBalanceSnapshotRepository(store))
+    service := NewStatementService(users, history)
+    service.SetPDFDir(t.TempDir())
+
+    // Months that have not ended have no statement yet
+    now := time.Now()
+    for _, month := range []time.Time{now, monthStart(now), monthStart(now).AddDate(0, 1, 0)} {
+        _, err := service.MonthlyPDF(ctx, user.ID, "USD", month)
+        assert.ErrorIs(t, err, ErrStatementNotReady, month.Format(time.RFC3339))
+    }
+
+    // An ended month is rendered once and then served as kept
+    lastMonth := monthStart(now).AddDate(0, 0, -1)
+    data, err := service.MonthlyPDF(ctx, user.ID, "USD", lastMonth)
+    require.NoError(t, err)
+    assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+
+    path := service.pdfPath(user.ID, "USD", monthStart(lastMonth))
+    require.NoError(t, os.WriteFile(path, []byte("kept"), 0o644))
+
+    data, err = service.MonthlyPDF(ctx, user.ID, "USD", lastMonth)
+    require.NoError(t, err)
+    assert.Equal(t, "kept", string(data))
+}
@@ -0,0 +1,548 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "time"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "github.com/rs/zerolog/log"
+)
+
+// LoadApprovalThresholds reads the approval thresholds from a JSON file
+// holding {"thresholds": [...]}.
+func LoadApprovalThresholds(path string) ([]models.ApprovalThreshold, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read approval thresholds: %w", err)
+    }
+
+    var file struct {
+        Thresholds []models.ApprovalThreshold `json:"thresholds"`
+    }
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("failed to parse approval thresholds: %w", err)
+    }
+
+    for i := range file.Thresholds {
+        if err := file.Thresholds[i].Validate(); err != nil {
+            return nil, fmt.Errorf("threshold %d: %w", i, err)
+        }
+    }
+
+    return file.Thresholds, nil
+}
+
+// ApprovalPolicy decides which transactions are held until a second person
+// approves them (maker-checker).
+type ApprovalPolicy struct {
+    // Thresholds hold credits, debits and transfers above them.
+    Thresholds []models.ApprovalThreshold
+    // ManualCredits holds every credit, credits being made by hand.
+    ManualCredits bool
+    // TTL is how long a held transaction waits for a decision before it
+    // expires.
+    TTL time.Duration
+}
+
+// reason returns why tx must be approved, or "" if it need not be.
+func (p *ApprovalPolicy) reason(tx *models.Transaction, manual bool) string {
+    if manual && p.ManualCredits {
+        return "manual credit"
+    }
+
+    for i := range p.Thresholds {
+        if p.Thresholds[i].Matches(tx) {
+            return p.Thresholds[i].String()
+        }
+    }
+
+    return ""
+}
+
+// SetApprovals holds the transactions policy selects for approval instead of
+// applying them straight away.
+func (s *TransactionService) SetApprovals(policy *ApprovalPolicy, approvalRepo repository.ApprovalRepository) {
+    s.approvals = policy
+    s.approvalRepo = approvalRepo
+}
+
+// DecisionListener learns how a held transaction ended: completed or failed
+// once approved, rejected or expired. detail is why it failed or was
+// rejected. It runs in the storage transaction recording the outcome, so
+// returning an error undoes that.
+type DecisionListener func(ctx context.Context, transactionID uint, status models.TransactionStatus, detail string) error
+
+// SetDecisionListener tells listener how every held transaction ends.
+func (s *TransactionService) SetDecisionListener(listener DecisionListener) {
+    s.decided = listener
+}
+
+func (s *TransactionService) notifyDecided(ctx context.Context, transactionID uint, status models.TransactionStatus, detail string) error {
+    if s.decided == nil {
+        return nil
+    }
+
+    return s.decided(ctx, transactionID, status, detail)
+}
+
+// approvalReason returns why tx must be approved before it is applied, or ""
+// if it can be applied now.
+func (s *TransactionService) approvalReason(tx *models.Transaction, manual bool) string {
+    if s.approvals == nil {
+        return ""
+    }
+
+    return s.approvals.reason(tx, manual)
+}
+
+func (s *TransactionService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    if s.transactor == nil {
+        return fn(ctx)
+    }
+
+    return s.transactor.WithinTransaction(ctx, fn)
+}
+
+func (s *TransactionService) auditApproval(ctx context.Context, id uint, action string, changes map[string]interface{}) {
+    if s.auditLogger == nil {
+        return
+    }
+
+    if err := s.auditLogger.LogAction(ctx, "transaction", id, action, changes); err != nil {
+        log.Error().Err(err).Msg("Failed to log audit")
+    }
+}
+
+// hold stores tx as awaiting approval or review, initiated by the actor in
+// ctx, and returns it without applying it. The actor must be an identified
+// user, or nobody could tell whether its approver is someone else.
+func (s *TransactionService) hold(ctx context.Context, tx *models.Transaction, kind models.ApprovalKind, reason string) (*models.Transaction, error) {
+    if ActorIDFromContext(ctx) == 0 {
+        return nil, models.ErrInitiatorUnknown
+    }
+
+    now := time.Now()
+
+    tx.Status = models.TransactionStatusAwaitingApproval
+
+    approval := &models.Approval{
+        Kind:          kind,
+        Status:        models.ApprovalPending,
+        InitiatedBy:   ActorFromContext(ctx),
+        InitiatedByID: ActorIDFromContext(ctx),
+        Reason:        reason,
+        ExpiresAt:     now.Add(s.approvals.TTL),
+        CreatedAt:     now,
+    }
+
+    err := s.withinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.txRepo.Create(ctx, tx); err != nil {
+            return fmt.Errorf("failed to create transaction: %w", err)
+        }
+
+        approval.TransactionID = tx.ID
+
+        if err := s.approvalRepo.Create(ctx, approval); err != nil {
+            return fmt.Errorf("failed to create approval: %w", err)
+        }
+
+        return nil
+    })
+
+    if err != nil {
+        return nil, err
+    }
+
+    action := "request_approval"
+    if kind == models.ApprovalKindReview {
+        action = "request_review"
+    }
+
+    s.auditApproval(ctx, tx.ID, action, map[string]interface{}{
+        "amount":          tx.Amount,
+        "currency":        tx.Currency,
+        "from_user":       tx.FromUserID,
+        "to_user":         tx.ToUserID,
+        "type":            tx.Type,
+        "status":          tx.Status,
+        "kind":            kind,
+        "initiated_by":    approval.InitiatedBy,
+        "initiated_by_id": approval.InitiatedByID,
+        "hold_reason":     reason,
+        "expires_at":      approval.ExpiresAt,
+    })
+
+    return tx, nil
+}
+
+// ListAwaitingApproval returns the undecided approvals of the given kind, or
+// of any kind if it is empty, with their transactions, oldest first.
+func (s *TransactionService) ListAwaitingApproval(ctx context.Context, kind models.ApprovalKind) ([]*models.Approval, error) {
+    if s.approvalRepo == nil {
+        return nil, errors.New("approvals are not configured")
+    }
+
+    approvals, err := s.approvalRepo.ListPending(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list approvals: %w", err)
+    }
+
+    var result []*models.Approval
+    for _, approval := range approvals {
+        if kind != "" && approval.Kind != kind {
+            continue
+        }
+
+        tx, err := s.txRepo.GetByID(ctx, approval.TransactionID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to get transaction %d: %w", approval.TransactionID, err)
+        }
+        approval.Transaction = tx
+        result = append(result, approval)
+    }
+
+    return result, nil
+}
+
+// pendingApproval loads the undecided approval of a transaction the admin
+// in ctx may decide, that is one they did not initiate. Admins are told apart
+// by user ID, whatever name they act under, so an approval without an
+// identified initiator is never decided. An approval found past its expiry
+// is expired instead.
+func (s *TransactionService) pendingApproval(ctx context.Context, id uint) (*models.Approval, *models.Transaction, error) {
+    if s.approvalRepo == nil {
+        return nil, nil, errors.New("approvals are not configured")
+    }
+
+    actorID := ActorIDFromContext(ctx)
+    if actorID == 0 {
+        return nil, nil, models.ErrApproverUnknown
+    }
+
+    actor, err := s.userRepo.GetByID(ctx, actorID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, nil, models.ErrApproverUnknown
+        }
+        return nil, nil, fmt.Errorf("failed to get approver: %w", err)
+    }
+
+    if actor.Role != models.RoleAdmin {
+        return nil, nil, models.ErrApproverUnknown
+    }
+
+    approval, err := s.approvalRepo.GetByTransactionID(ctx, id)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, nil, fmt.Errorf("transaction %d: %w", id, models.ErrNotAwaitingApproval)
+        }
+        return nil, nil, fmt.Errorf("failed to get approval: %w", err)
+    }
+
+    if approval.Status != models.ApprovalPending {
+        return nil, nil, fmt.Errorf("transaction %d was already %s: %w", id, approval.Status, models.ErrNotAwaitingApproval)
+    }
+
+    if approval.InitiatedByID == 0 {
+        return nil, nil, fmt.Errorf("transaction %d: %w", id, models.ErrInitiatorUnknown)
+    }
+
+    if actorID == approval.InitiatedByID {
+        return nil, nil, models.ErrSelfApproval
+    }
+
+    now := time.Now()
+
+    if now.After(approval.ExpiresAt) {
+        if err := s.expire(ctx, approval, now); err != nil {
+            return nil, nil, err
+        }
+        return nil, nil, fmt.Errorf("transaction %d expired at %s: %w", id, approval.ExpiresAt.Format(time.RFC3339), models.ErrNotAwaitingApproval)
+    }
+
+    tx, err := s.txRepo.GetByID(ctx, id)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to get transaction: %w", err)
+    }
+
+    return approval, tx, nil
+}
+
+// decide records the actor in ctx's decision on approval and moves its
+// transaction to status, failing if someone else decided it first.
+func (s *TransactionService) decide(ctx context.Context, approval *models.Approval, decision models.ApprovalStatus, status models.TransactionStatus) error {
+    now := time.Now()
+
+    approval.Status = decision
+    approval.DecidedBy = ActorFromContext(ctx)
+    approval.DecidedByID = ActorIDFromContext(ctx)
+    approval.DecidedAt = &now
+    approval.Note = ReasonFromContext(ctx)
+
+    return s.withinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.approvalRepo.Decide(ctx, approval); err != nil {
+            if err == repository.ErrNotFound {
+                return fmt.Errorf("transaction %d: %w", approval.TransactionID, models.ErrNotAwaitingApproval)
+            }
+            return fmt.Errorf("failed to save approval: %w", err)
+        }
+
+        if err := s.txRepo.UpdateStatus(ctx, approval.TransactionID, status); err != nil {
+            return fmt.Errorf("failed to update transaction status: %w", err)
+        }
+
+        // An approved transaction only ends once it is applied
+        if status == models.TransactionStatusPending {
+            return nil
+        }
+
+        return s.notifyDecided(ctx, approval.TransactionID, status, approval.Note)
+    })
+}
+
+// checkFunds refuses tx if the balance it is paid from cannot cover it and
+// its fees. Fees on credits are taken from the credited funds.
+func (s *TransactionService) checkFunds(ctx context.Context, tx *models.Transaction) error {
+    if tx.Type == models.TransactionTypeCredit {
+        return nil
+    }
+
+    var balance *models.Balance
+    var err error
+    if tx.FromAccountID != 0 {
+        balance, err = s.balanceRepo.GetAccountBalance(ctx, tx.FromAccountID)
+    } else {
+        balance, err = s.balanceRepo.GetBalance(ctx, tx.FromUserID, tx.Currency)
+    }
+
+    if err == repository.ErrNotFound {
+        return models.ErrInsufficientFunds
+    }
+    if err != nil {
+        return fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    if balance.Available() < tx.Amount+totalFees(tx.Fees) {
+        return models.ErrInsufficientFunds
+    }
+
+    return nil
+}
+
+// Approve applies a transaction held for approval. The approver, taken from
+// the context, must not be the admin who initiated it. As the transaction
+// may have waited for days, accounts, limits, funds and sanctions are
+// checked again first; fraud rules are not, the approver having reviewed it.
+func (s *TransactionService) Approve(ctx context.Context, id uint) (*models.Transaction, error) {
+    approval, tx, err := s.pendingApproval(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    payerID := tx.FromUserID
+    if tx.Type == models.TransactionTypeCredit {
+        payerID = tx.ToUserID
+    }
+
+    payer, err := s.userRepo.GetByID(ctx, payerID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    s.chargeFees(tx, payer)
+
+    // Refuse before deciding so the approval can be retried once the
+    // transaction can go through again
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.checkFunds(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if _, err := s.screenParties(ctx, tx, payer); err != nil {
+        return nil, err
+    }
+
+    if err := s.decide(ctx, approval, models.ApprovalApproved, models.TransactionStatusPending); err != nil {
+        return nil, err
+    }
+
+    tx.Status = models.TransactionStatusPending
+
+    changes := map[string]interface{}{
+        "amount":          tx.Amount,
+        "currency":        tx.Currency,
+        "from_user":       tx.FromUserID,
+        "to_user":         tx.ToUserID,
+        "fees":            totalFees(tx.Fees),
+        "type":            tx.Type,
+        "initiated_by":    approval.InitiatedBy,
+        "initiated_by_id": approval.InitiatedByID,
+        "approved_by":     approval.DecidedBy,
+        "approved_by_id":  approval.DecidedByID,
+        "hold_reason":     approval.Reason,
+    }
+
+    resultChan := make(chan error, 1)
+    err = s.workerPool.Submit(&Task{
+        Transaction: tx,
+        Then: func(ctx context.Context) error {
+            return s.notifyDecided(ctx, tx.ID, models.TransactionStatusCompleted, "")
+        },
+        ResultChan: resultChan,
+    })
+    if err == nil {
+        err = <-resultChan
+    }
+
+    if err != nil {
+        updateErr := s.withinTransaction(ctx, func(ctx context.Context) error {
+            if err := s.txRepo.UpdateStatus(ctx, tx.ID, models.TransactionStatusFailed); err != nil {
+                return err
+            }
+            return s.notifyDecided(ctx, tx.ID, models.TransactionStatusFailed, err.Error())
+        })
+        if updateErr != nil {
+            log.Error().Err(updateErr).Uint("transaction_id", tx.ID).Msg("Failed to mark approved transaction failed")
+        }
+
+        changes["status"] = models.TransactionStatusFailed
+        changes["error"] = err.Error()
+        s.auditApproval(ctx, tx.ID, "approve", changes)
+
+        return nil, fmt.Errorf("failed to process transaction: %w", err)
+    }
+
+    tx.Status = models.TransactionStatusCompleted
+
+    changes["status"] = tx.Status
+    s.auditApproval(ctx, tx.ID, "approve", changes)
+
+    return tx, nil
+}
+
+// Reject refuses a transaction held for approval, which is then never
+// applied. The rejecter, taken from the context, must not be the person who
+// initiated it and must give a reason.
+func (s *TransactionService) Reject(ctx context.Context, id uint) (*models.Transaction, error) {
+    if ReasonFromContext(ctx) == "" {
+        return nil, errors.New("a reason is required to reject a transaction")
+    }
+
+    approval, tx, err := s.pendingApproval(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.decide(ctx, approval, models.ApprovalRejected, models.TransactionStatusRejected); err != nil {
+        return nil, err
+    }
+
+    tx.Status = models.TransactionStatusRejected
+
+    s.auditApproval(ctx, tx.ID, "reject", map[string]interface{}{
+        "amount":          tx.Amount,
+        "currency":        tx.Currency,
+        "from_user":       tx.FromUserID,
+        "to_user":         tx.ToUserID,
+        "type":            tx.Type,
+        "status":          tx.Status,
+        "initiated_by":    approval.InitiatedBy,
+        "initiated_by_id": approval.InitiatedByID,
+        "rejected_by":     approval.DecidedBy,
+        "rejected_by_id":  approval.DecidedByID,
+        "hold_reason":     approval.Reason,
+    })
+
+    return tx, nil
+}
+
+// expire marks an approval that was not decided in time as expired.
+func (s *TransactionService) expire(ctx context.Context, approval *models.Approval, now time.Time) error {
+    approval.Status = models.ApprovalExpired
+    approval.DecidedAt = &now
+
+    err := s.withinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.approvalRepo.Decide(ctx, approval); err != nil {
+            return err
+        }
+
+        if err := s.txRepo.UpdateStatus(ctx, approval.TransactionID, models.TransactionStatusExpired); err != nil {
+            return fmt.Errorf("failed to update transaction status: %w", err)
+        }
+
+        return s.notifyDecided(ctx, approval.TransactionID, models.TransactionStatusExpired, "")
+    })
+
+    if err == repository.ErrNotFound {
+        // Decided in the meantime
+        return nil
+    }
+
+    if err != nil {
+        return fmt.Errorf("failed to expire approval of transaction %d: %w", approval.TransactionID, err)
+    }
+
+    s.auditApproval(ctx, approval.TransactionID, "expire", map[string]interface{}{
+        "status":       models.TransactionStatusExpired,
+        "initiated_by": approval.InitiatedBy,
+        "hold_reason":  approval.Reason,
+        "expires_at":   approval.ExpiresAt,
+    })
+
+    return nil
+}
+
+// ExpireApprovals expires every approval not decided by now and returns how
+// many it expired.
+func (s *TransactionService) ExpireApprovals(ctx context.Context, now time.Time) (int, error) {
+    if s.approvalRepo == nil {
+        return 0, nil
+    }
+
+    approvals, err := s.approvalRepo.ListPending(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to list approvals: %w", err)
+    }
+
+    expired := 0
+    for _, approval := range approvals {
+        if !now.After(approval.ExpiresAt) {
+            continue
+        }
+
+        if err := s.expire(ctx, approval, now); err != nil {
+            return expired, err
+        }
+        expired++
+    }
+
+    return expired, nil
+}
+
+// ApprovalExpiryJob returns a periodic job that expires approvals nobody
+// decided in time.
+func (s *TransactionService) ApprovalExpiryJob(interval time.Duration) *PeriodicJob {
+    return NewPeriodicJob("approval_expiry", interval, func(ctx context.Context) error {
+        expired, err := s.ExpireApprovals(ctx, time.Now())
+        if err != nil {
+            return err
+        }
+
+        if expired > 0 {
+            log.Info().Int("approvals", expired).Msg("Approvals expired")
+        }
+
+        return nil
+    })
+}
@@ -14,8 +14,26 @@ type TransactionService struct {
     txRepo      repository.TransactionRepository
     balanceRepo repository.BalanceRepository
     userRepo    repository.UserRepository
+    accountRepo repository.AccountRepository
+    // rejectFrozenCredits makes frozen accounts refuse credits as well as
+    // debits.
+    rejectFrozenCredits bool
+    quoteRepo   repository.FXQuoteRepository
+    feeSchedule *FeeSchedule
+    limits      *LimitService
     workerPool  *WorkerPool
     auditLogger *AuditLogger
+    transactor  repository.Transactor
+    // approvals selects the transactions held until a second person
+    // approves them.
+    approvals    *ApprovalPolicy
+    approvalRepo repository.ApprovalRepository
+    // decided learns how each held transaction ended.
+    decided DecisionListener
+    // fraud screens transactions before they are submitted.
+    fraud *FraudEngine
+    // watchlist screens the parties to transactions against sanctions.
+    watchlist *Watchlist
 }
 
 func NewTransactionService(
@@ -40,12 +58,89 @@ func (s *TransactionService) SetAuditLogger(logger *AuditLogger) {
     s.auditLogger = logger
 }
 
-func (s *TransactionService) Credit(ctx context.Context, userID uint, amount float64) (*models.Transaction, error) {
+// SetTransactor makes balance updates for each transaction atomic.
+func (s *TransactionService) SetTransactor(transactor repository.Transactor) {
+    s.transactor = transactor
+    s.workerPool.SetTransactor(transactor)
+}
+
+// SetAccounts books transactions to users' accounts and enables
+// TransferBetweenAccounts.
+func (s *TransactionService) SetAccounts(accountRepo repository.AccountRepository) {
+    s.accountRepo = accountRepo
+    s.workerPool.SetAccounts(accountRepo)
+}
+
+// SetRejectFrozenCredits makes frozen accounts refuse credits as well as
+// debits.
+func (s *TransactionService) SetRejectFrozenCredits(reject bool) {
+    s.rejectFrozenCredits = reject
+    s.workerPool.SetRejectFrozenCredits(reject)
+}
+
+// checkAccounts refuses tx early if an account it would be booked to is
+// frozen or closed. The worker checks again when applying it.
+func (s *TransactionService) checkAccounts(ctx context.Context, tx *models.Transaction) error {
+    if s.accountRepo == nil {
+        return nil
+    }
+
+    var from, to *models.Account
+    var err error
+
+    if tx.FromUserID != 0 {
+        if from, err = findAccount(ctx, s.accountRepo, tx.FromAccountID, tx.FromUserID, tx.Currency); err != nil {
+            return err
+        }
+    }
+
+    if tx.ToUserID != 0 {
+        if to, err = findAccount(ctx, s.accountRepo, tx.ToAccountID, tx.ToUserID, tx.CreditCurrency()); err != nil {
+            return err
+        }
+    }
+
+    return checkAccountStatus(tx, from, to, s.rejectFrozenCredits)
+}
+
+// SetFXQuotes enables ConvertTransfer, which executes quotes from quoteRepo.
+func (s *TransactionService) SetFXQuotes(quoteRepo repository.FXQuoteRepository) {
+    s.quoteRepo = quoteRepo
+    s.workerPool.SetFXQuotes(quoteRepo)
+}
+
+// SetFeeSchedule charges the fees of schedule on new transactions and posts
+// them to the house account houseUserID.
+func (s *TransactionService) SetFeeSchedule(schedule *FeeSchedule, houseUserID uint) {
+    s.feeSchedule = schedule
+    s.workerPool.SetFeeAccount(houseUserID)
+}
+
+// SetLimits refuses new transactions that would break a user's limits.
+func (s *TransactionService) SetLimits(limits *LimitService) {
+    s.limits = limits
+    s.workerPool.SetLimits(limits)
+}
+
+// chargeFees attaches the fees payer owes on tx.
+func (s *TransactionService) chargeFees(tx *models.Transaction, payer *models.User) {
+    tx.Fees = s.feeSchedule.Calculate(tx.Type, payer.Tier, tx.Currency, tx.Amount)
+}
+
+// SetBalanceEvents publishes balance changes made by the worker pool.
+func (s *TransactionService) SetBalanceEvents(events *BalanceEvents) {
+    s.workerPool.SetBalanceEvents(events)
+}
+
+// Credit pays money into a user's balance from outside the service. Credits
+// are only ever made by hand, by an operator or from an imported file, so
+// they are held for approval whenever manual credits need one.
+func (s *TransactionService) Credit(ctx context.Context, userID uint, amount float64, currency string) (*models.Transaction, error) {
     // Validate user exists
-    _, err := s.userRepo.GetByID(ctx, userID)
+    user, err := s.userRepo.GetByID(ctx, userID)
     if err != nil {
         if err == repository.ErrNotFound {
-            return nil, fmt.Errorf("user not found: %d", userID)
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
         }
         return nil, fmt.Errorf("failed to get user: %w", err)
     }
@@ -54,11 +149,35 @@ func (s *TransactionService) Credit(ctx context.Context, userID uint, amount flo
         FromUserID: 0,
         ToUserID:   userID,
         Amount:     amount,
+        Currency:   currency,
         Type:       models.TransactionTypeCredit,
         Status:     models.TransactionStatusPending,
         CreatedAt:  time.Now(),
     }
 
+    if err := tx.Validate(); err != nil {
+        return nil, err
+    }
+
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    // Fees on a credit are taken from the credited funds
+    s.chargeFees(tx, user)
+
+    kind, reason, err := s.holdReason(ctx, tx, user, true)
+    if err != nil {
+        return nil, err
+    }
+    if reason != "" {
+        return s.hold(ctx, tx, kind, reason)
+    }
+
     if err := s.txRepo.Create(ctx, tx); err != nil {
         return nil, fmt.Errorf("failed to create transaction: %w", err)
     }
@@ -82,7 +201,9 @@ func (s *TransactionService) Credit(ctx context.Context, userID uint, amount flo
     if s.auditLogger != nil {
         changes := map[string]interface{}{
             "amount":    amount,
+            "currency":  currency,
             "user_id":   userID,
+            "fees":      totalFees(tx.Fees),
             "type":      "credit",
             "status":    "completed",
         }
@@ -94,33 +215,59 @@ func (s *TransactionService) Credit(ctx context.Context, userID uint, amount flo
     return tx, nil
 }
 
-func (s *TransactionService) Debit(ctx context.Context, userID uint, amount float64) (*models.Transaction, error) {
+func (s *TransactionService) Debit(ctx context.Context, userID uint, amount float64, currency string) (*models.Transaction, error) {
     // Validate user exists
-    _, err := s.userRepo.GetByID(ctx, userID)
+    user, err := s.userRepo.GetByID(ctx, userID)
     if err != nil {
         if err == repository.ErrNotFound {
-            return nil, fmt.Errorf("user not found: %d", userID)
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
         }
         return nil, fmt.Errorf("failed to get user: %w", err)
     }
 
-    // Validate balance
-    balance, err := s.balanceRepo.GetBalance(ctx, userID)
-    if err != nil {
-        return nil, fmt.Errorf("failed to get balance: %w", err)
-    }
-    if balance.Amount < amount {
-        return nil, errors.New("insufficient funds")
-    }
-
     tx := &models.Transaction{
         FromUserID: userID,
         Amount:    amount,
+        Currency:  currency,
         Type:      models.TransactionTypeDebit,
         Status:    models.TransactionStatusPending,
         CreatedAt: time.Now(),
     }
 
+    if err := tx.Validate(); err != nil {
+        return nil, err
+    }
+
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    s.chargeFees(tx, user)
+
+    // Validate balance, which may draw on the credit limit
+    balance, err := s.balanceRepo.GetBalance(ctx, userID, currency)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, models.ErrInsufficientFunds
+        }
+        return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+    if balance.Available() < amount+totalFees(tx.Fees) {
+        return nil, models.ErrInsufficientFunds
+    }
+
+    kind, reason, err := s.holdReason(ctx, tx, user, false)
+    if err != nil {
+        return nil, err
+    }
+    if reason != "" {
+        return s.hold(ctx, tx, kind, reason)
+    }
+
     if err := s.txRepo.Create(ctx, tx); err != nil {
         return nil, fmt.Errorf("failed to create transaction: %w", err)
     }
@@ -144,7 +291,9 @@ func (s *TransactionService) Debit(ctx context.Context, userID uint, amount floa
     if s.auditLogger != nil {
         changes := map[string]interface{}{
             "amount":    amount,
+            "currency":  currency,
             "user_id":   userID,
+            "fees":      totalFees(tx.Fees),
             "type":      "debit",
             "status":    "completed",
         }
@@ -156,17 +305,17 @@ func (s *TransactionService) Debit(ctx context.Context, userID uint, amount floa
     return tx, nil
 }
 
-func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID uint, amount float64) (*models.Transaction, error) {
+func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID uint, amount float64, currency string) (*models.Transaction, error) {
     // Validate amount
-    if amount <= 0 {
-        return nil, errors.New("amount must be positive")
+    if err := models.ValidateAmount(amount, currency); err != nil {
+        return nil, err
     }
 
     // Validate users exist
-    _, err := s.userRepo.GetByID(ctx, fromUserID)
+    fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
     if err != nil {
         if err == repository.ErrNotFound {
-            return nil, fmt.Errorf("from user not found: %d", fromUserID)
+            return nil, fmt.Errorf("from %w: %d", ErrUserNotFound, fromUserID)
         }
         return nil, fmt.Errorf("failed to get from user: %w", err)
     }
@@ -174,37 +323,57 @@ func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID
     _, err = s.userRepo.GetByID(ctx, toUserID)
     if err != nil {
         if err == repository.ErrNotFound {
-            return nil, fmt.Errorf("to user not found: %d", toUserID)
+            return nil, fmt.Errorf("to %w: %d", ErrUserNotFound, toUserID)
         }
         return nil, fmt.Errorf("failed to get to user: %w", err)
     }
 
-    // Validate balance
-    balance, err := s.balanceRepo.GetBalance(ctx, fromUserID)
+    // Validate balance, which may draw on the credit limit
+    balance, err := s.balanceRepo.GetBalance(ctx, fromUserID, currency)
     if err != nil {
         if err != repository.ErrNotFound {
             return nil, fmt.Errorf("failed to get balance: %w", err)
         }
         // If balance not found, treat as zero
         balance = &models.Balance{
-            UserID: fromUserID,
-            Amount: 0,
+            UserID:   fromUserID,
+            Currency: currency,
+            Amount:   0,
         }
     }
 
-    if balance.Amount < amount {
-        return nil, errors.New("insufficient funds")
-    }
-
     tx := &models.Transaction{
         FromUserID: fromUserID,
         ToUserID:   toUserID,
         Amount:     amount,
+        Currency:   currency,
         Type:       models.TransactionTypeTransfer,
         Status:     models.TransactionStatusPending,
         CreatedAt:  time.Now(),
     }
 
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    s.chargeFees(tx, fromUser)
+
+    if balance.Available() < amount+totalFees(tx.Fees) {
+        return nil, models.ErrInsufficientFunds
+    }
+
+    kind, reason, err := s.holdReason(ctx, tx, fromUser, false)
+    if err != nil {
+        return nil, err
+    }
+    if reason != "" {
+        return s.hold(ctx, tx, kind, reason)
+    }
+
     if err := s.txRepo.Create(ctx, tx); err != nil {
         return nil, fmt.Errorf("failed to create transaction: %w", err)
     }
@@ -228,8 +397,10 @@ func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID
     if s.auditLogger != nil {
         changes := map[string]interface{}{
             "amount":      amount,
+            "currency":    currency,
             "from_user":   fromUserID,
             "to_user":     toUserID,
+            "fees":        totalFees(tx.Fees),
             "type":        "transfer",
             "status":      "completed",
         }
@@ -241,6 +412,426 @@ func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID
     return tx, nil
 }
 
+// TransferBetweenAccounts moves amount from one account to another in the
+// same currency. The accounts may belong to the same user.
+func (s *TransactionService) TransferBetweenAccounts(ctx context.Context, fromAccountID, toAccountID uint, amount float64) (*models.Transaction, error) {
+    if s.accountRepo == nil {
+        return nil, errors.New("accounts are not configured")
+    }
+
+    if fromAccountID == toAccountID {
+        return nil, errors.New("cannot transfer to the same account")
+    }
+
+    fromAccount, err := s.accountRepo.GetByID(ctx, fromAccountID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("from %w: %d", ErrAccountNotFound, fromAccountID)
+        }
+        return nil, fmt.Errorf("failed to get from account: %w", err)
+    }
+
+    toAccount, err := s.accountRepo.GetByID(ctx, toAccountID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("to %w: %d", ErrAccountNotFound, toAccountID)
+        }
+        return nil, fmt.Errorf("failed to get to account: %w", err)
+    }
+
+    if fromAccount.Currency != toAccount.Currency {
+        return nil, models.ErrCrossCurrency
+    }
+
+    currency := fromAccount.Currency
+
+    if err := models.ValidateAmount(amount, currency); err != nil {
+        return nil, err
+    }
+
+    fromUser, err := s.userRepo.GetByID(ctx, fromAccount.UserID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get from user: %w", err)
+    }
+
+    // Validate balance, which may draw on the credit limit
+    balance, err := s.balanceRepo.GetAccountBalance(ctx, fromAccountID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    tx := &models.Transaction{
+        FromUserID:    fromAccount.UserID,
+        ToUserID:      toAccount.UserID,
+        FromAccountID: fromAccountID,
+        ToAccountID:   toAccountID,
+        Amount:        amount,
+        Currency:      currency,
+        Type:          models.TransactionTypeTransfer,
+        Status:        models.TransactionStatusPending,
+        CreatedAt:     time.Now(),
+    }
+
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    s.chargeFees(tx, fromUser)
+
+    if balance.Available() < amount+totalFees(tx.Fees) {
+        return nil, models.ErrInsufficientFunds
+    }
+
+    kind, reason, err := s.holdReason(ctx, tx, fromUser, false)
+    if err != nil {
+        return nil, err
+    }
+    if reason != "" {
+        return s.hold(ctx, tx, kind, reason)
+    }
+
+    if err := s.txRepo.Create(ctx, tx); err != nil {
+        return nil, fmt.Errorf("failed to create transaction: %w", err)
+    }
+
+    resultChan := make(chan error, 1)
+    err = s.workerPool.Submit(&Task{
+        Transaction: tx,
+        ResultChan:  resultChan,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to submit transaction: %w", err)
+    }
+
+    if err := <-resultChan; err != nil {
+        return nil, fmt.Errorf("failed to process transaction: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "amount":       amount,
+            "currency":     currency,
+            "from_user":    fromAccount.UserID,
+            "to_user":      toAccount.UserID,
+            "from_account": fromAccountID,
+            "to_account":   toAccountID,
+            "fees":         totalFees(tx.Fees),
+            "type":         "transfer",
+            "status":       "completed",
+        }
+        if err := s.auditLogger.LogAction(ctx, "transaction", tx.ID, "transfer", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return tx, nil
+}
+
+// ConvertTransfer executes an FX quote: the quoted amount is debited from
+// fromUserID in the quote's source currency and the converted amount credited
+// to toUserID, who may be the same user, in its target currency.
+func (s *TransactionService) ConvertTransfer(ctx context.Context, fromUserID, toUserID, quoteID uint) (*models.Transaction, error) {
+    if s.quoteRepo == nil {
+        return nil, errors.New("currency conversion is not available")
+    }
+
+    quote, err := s.quoteRepo.GetByID(ctx, quoteID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", models.ErrQuoteNotFound, quoteID)
+        }
+        return nil, fmt.Errorf("failed to get quote: %w", err)
+    }
+
+    fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("from %w: %d", ErrUserNotFound, fromUserID)
+        }
+        return nil, fmt.Errorf("failed to get from user: %w", err)
+    }
+
+    if quote.UserID != fromUserID {
+        return nil, fmt.Errorf("quote %d was not issued to user %d", quoteID, fromUserID)
+    }
+
+    if quote.TransactionID != 0 {
+        return nil, models.ErrQuoteUsed
+    }
+
+    if quote.Expired(time.Now()) {
+        return nil, models.ErrQuoteExpired
+    }
+
+    _, err = s.userRepo.GetByID(ctx, toUserID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("to %w: %d", ErrUserNotFound, toUserID)
+        }
+        return nil, fmt.Errorf("failed to get to user: %w", err)
+    }
+
+    // Validate balance, which may draw on the credit limit
+    balance, err := s.balanceRepo.GetBalance(ctx, fromUserID, quote.FromCurrency)
+    if err != nil && err != repository.ErrNotFound {
+        return nil, fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    tx := &models.Transaction{
+        FromUserID: fromUserID,
+        ToUserID:   toUserID,
+        Amount:     quote.FromAmount,
+        Currency:   quote.FromCurrency,
+        ToAmount:   quote.ToAmount,
+        ToCurrency: quote.ToCurrency,
+        Rate:       quote.Rate,
+        Spread:     quote.Spread,
+        QuoteID:    quote.ID,
+        Type:       models.TransactionTypeConversion,
+        Status:     models.TransactionStatusPending,
+        CreatedAt:  time.Now(),
+    }
+
+    if err := tx.Validate(); err != nil {
+        return nil, err
+    }
+
+    if err := s.checkAccounts(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    if err := s.limits.Check(ctx, tx); err != nil {
+        return nil, err
+    }
+
+    s.chargeFees(tx, fromUser)
+
+    if balance == nil || balance.Available() < quote.FromAmount+totalFees(tx.Fees) {
+        return nil, models.ErrInsufficientFunds
+    }
+
+    // Quotes cannot wait for a review, so conversions flagged for one are
+    // refused
+    if err := s.screenUnheld(ctx, tx, fromUser); err != nil {
+        return nil, err
+    }
+
+    if err := s.txRepo.Create(ctx, tx); err != nil {
+        return nil, fmt.Errorf("failed to create transaction: %w", err)
+    }
+
+    // Process transaction
+    resultChan := make(chan error, 1)
+    err = s.workerPool.Submit(&Task{
+        Transaction: tx,
+        ResultChan:  resultChan,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to submit transaction: %w", err)
+    }
+
+    // Wait for processing
+    if err := <-resultChan; err != nil {
+        return nil, fmt.Errorf("failed to process transaction: %w", err)
+    }
+
+    // Log the audit
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "quote_id":    quote.ID,
+            "from_user":   fromUserID,
+            "to_user":     toUserID,
+            "amount":      quote.FromAmount,
+            "currency":    quote.FromCurrency,
+            "to_amount":   quote.ToAmount,
+            "to_currency": quote.ToCurrency,
+            "rate":        quote.Rate,
+            "spread":      quote.Spread,
+            "fees":        totalFees(tx.Fees),
+            "type":        "conversion",
+            "status":      "completed",
+        }
+        if err := s.auditLogger.LogAction(ctx, "transaction", tx.ID, "conversion", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return tx, nil
+}
+
+// TransferAll makes the given transfers, each with FromUserID, ToUserID,
+// Amount and Currency set, all-or-nothing: either every transfer is applied
+// or none is. An error caused by one transfer is a *batchItemError naming
+// its index.
+func (s *TransactionService) TransferAll(ctx context.Context, txs []*models.Transaction) error {
+    users := make(map[uint]*models.User)
+
+    getUser := func(id uint) (*models.User, error) {
+        if user, ok := users[id]; ok {
+            return user, nil
+        }
+
+        user, err := s.userRepo.GetByID(ctx, id)
+        if err != nil {
+            if err == repository.ErrNotFound {
+                return nil, fmt.Errorf("%w: %d", ErrUserNotFound, id)
+            }
+            return nil, fmt.Errorf("failed to get user: %w", err)
+        }
+
+        users[id] = user
+        return user, nil
+    }
+
+    for i, tx := range txs {
+        tx.Type = models.TransactionTypeTransfer
+        tx.Status = models.TransactionStatusPending
+        tx.CreatedAt = time.Now()
+
+        if err := tx.Validate(); err != nil {
+            return &batchItemError{i, err}
+        }
+
+        if err := s.checkAccounts(ctx, tx); err != nil {
+            return &batchItemError{i, err}
+        }
+
+        fromUser, err := getUser(tx.FromUserID)
+        if err != nil {
+            return &batchItemError{i, err}
+        }
+
+        if _, err := getUser(tx.ToUserID); err != nil {
+            return &batchItemError{i, err}
+        }
+
+        // Limits and funds are checked by the worker as the batch is
+        // applied, so earlier transfers count toward later ones.
+        s.chargeFees(tx, fromUser)
+
+        // A batch cannot be held in part, so transfers flagged for review
+        // are refused
+        if err := s.screenUnheld(ctx, tx, fromUser); err != nil {
+            return &batchItemError{i, err}
+        }
+    }
+
+    // Process the batch as one task
+    resultChan := make(chan error, 1)
+    err := s.workerPool.Submit(&Task{
+        Batch:      txs,
+        ResultChan: resultChan,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to submit batch: %w", err)
+    }
+
+    // Wait for processing
+    if err := <-resultChan; err != nil {
+        return err
+    }
+
+    // Log the audit
+    if s.auditLogger != nil {
+        for _, tx := range txs {
+            changes := map[string]interface{}{
+                "amount":      tx.Amount,
+                "currency":    tx.Currency,
+                "from_user":   tx.FromUserID,
+                "to_user":     tx.ToUserID,
+                "fees":        totalFees(tx.Fees),
+                "type":        "transfer",
+                "status":      "completed",
+            }
+            if err := s.auditLogger.LogAction(ctx, "transaction", tx.ID, "transfer", changes); err != nil {
+                log.Error().Err(err).Msg("Failed to log audit")
+            }
+        }
+    }
+
+    return nil
+}
+
+// PostInterest pays amount of interest to the user, or charges it when
+// amount is negative. Interest is not subject to limits or fees. then, if
+// not nil, runs in the storage transaction applying the interest, so that
+// what it records is kept only together with the interest.
+func (s *TransactionService) PostInterest(ctx context.Context, userID, accountID uint, amount float64, currency, description string, then func(ctx context.Context, tx *models.Transaction) error) (*models.Transaction, error) {
+    tx := &models.Transaction{
+        Amount:      amount,
+        Currency:    currency,
+        Description: description,
+        Type:        models.TransactionTypeInterest,
+        Status:      models.TransactionStatusPending,
+        CreatedAt:   time.Now(),
+    }
+
+    if amount < 0 {
+        tx.FromUserID = userID
+        tx.FromAccountID = accountID
+        tx.Amount = -amount
+    } else {
+        tx.ToUserID = userID
+        tx.ToAccountID = accountID
+    }
+
+    if err := tx.Validate(); err != nil {
+        return nil, err
+    }
+
+    if err := s.txRepo.Create(ctx, tx); err != nil {
+        return nil, fmt.Errorf("failed to create transaction: %w", err)
+    }
+
+    // Process transaction
+    resultChan := make(chan error, 1)
+    task := &Task{
+        Transaction: tx,
+        ResultChan:  resultChan,
+    }
+    if then != nil {
+        task.Then = func(ctx context.Context) error {
+            return then(ctx, tx)
+        }
+    }
+
+    if err := s.workerPool.Submit(task); err != nil {
+        return nil, fmt.Errorf("failed to submit transaction: %w", err)
+    }
+
+    // Wait for processing
+    if err := <-resultChan; err != nil {
+        return nil, fmt.Errorf("failed to process transaction: %w", err)
+    }
+
+    // Log the audit
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "amount":      amount,
+            "currency":    currency,
+            "user_id":     userID,
+            "account_id":  accountID,
+            "description": description,
+            "type":        "interest",
+            "status":      "completed",
+        }
+        if err := s.auditLogger.LogAction(ctx, "transaction", tx.ID, "interest", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return tx, nil
+}
+
+// GetUserTransactions returns a page of the user's ledger, newest first.
+func (s *TransactionService) GetUserTransactions(ctx context.Context, userID uint, limit, offset int) ([]models.Transaction, error) {
+    return s.txRepo.GetUserTransactions(ctx, userID, limit, offset)
+}
+
 func (s *TransactionService) Cleanup() {
     if s.workerPool != nil {
         s.workerPool.Stop()
@@ -7,18 +7,34 @@ import (
     "financial-service/internal/models"
     "financial-service/internal/repository"
     "fmt"
+    "github.com/rs/zerolog/log"
 )
 
+// ErrUserNotFound is returned, wrapped with the user's ID, for users that do
+// not exist.
+var ErrUserNotFound = errors.New("user not found")
+
 type UserService struct {
     userRepo    repository.UserRepository
+    accountRepo repository.AccountRepository
     balanceRepo repository.BalanceRepository
+    transactor  repository.Transactor
     auditLogger *AuditLogger
+    // defaultCurrency is the currency of the account opened at registration.
+    defaultCurrency string
+    // watchlist screens users against sanctions when they register.
+    watchlist *Watchlist
+    // tokenSecret signs the tokens LoginUser issues, valid for tokenTTL.
+    tokenSecret []byte
+    tokenTTL    time.Duration
 }
 
-func NewUserService(userRepo repository.UserRepository, balanceRepo repository.BalanceRepository) *UserService {
+func NewUserService(userRepo repository.UserRepository, accountRepo repository.AccountRepository, balanceRepo repository.BalanceRepository, defaultCurrency string) *UserService {
     return &UserService{
-        userRepo:    userRepo,
-        balanceRepo: balanceRepo,
+        userRepo:        userRepo,
+        accountRepo:     accountRepo,
+        balanceRepo:     balanceRepo,
+        defaultCurrency: defaultCurrency,
     }
 }
 
@@ -26,13 +42,29 @@ func (s *UserService) SetAuditLogger(logger *AuditLogger) {
     s.auditLogger = logger
 }
 
-// RegisterUser creates a new user with initial balance
+// SetTransactor makes registration save the user and open their account
+// atomically.
+func (s *UserService) SetTransactor(transactor repository.Transactor) {
+    s.transactor = transactor
+}
+
+func (s *UserService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+    if s.transactor == nil {
+        return fn(ctx)
+    }
+
+    return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// RegisterUser creates a new user with a checking account in the default
+// currency
 func (s *UserService) RegisterUser(ctx context.Context, username, email, password string) (*models.User, error) {
     // Create user
     user := &models.User{
         Username:  username,
         Email:     email,
         Role:      models.RoleUser,
+        Tier:      models.TierStandard,
         CreatedAt: time.Now(),
         UpdatedAt: time.Now(),
     }
@@ -47,20 +79,165 @@ func (s *UserService) RegisterUser(ctx context.Context, username, email, passwor
         return nil, err
     }
 
-    // Save user
-    if err := s.userRepo.Create(ctx, user); err != nil {
+    // Screen against sanctions
+    screening, err := s.screenRegistration(ctx, user)
+    if err != nil {
+        return nil, err
+    }
+
+    // Save user and open the initial account, so that no user is left
+    // without one
+    err = s.withinTransaction(ctx, func(ctx context.Context) error {
+        if err := s.userRepo.Create(ctx, user); err != nil {
+            return err
+        }
+
+        account := &models.Account{
+            UserID:   user.ID,
+            Type:     models.AccountTypeChecking,
+            Currency: s.defaultCurrency,
+        }
+
+        _, err := openAccount(ctx, s.accountRepo, s.balanceRepo, account)
+        return err
+    })
+
+    if err != nil {
         return nil, err
     }
 
-    // Create initial balance
-    balance := &models.Balance{
-        UserID:        user.ID,
-        Amount:        0,
-        LastUpdatedAt: time.Now(),
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "username": user.Username,
+            "email":    user.Email,
+            "role":     user.Role,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user", user.ID, "register", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
     }
 
-    if err := s.balanceRepo.CreateBalance(ctx, balance); err != nil {
-        return nil, err
+    if screening != nil {
+        auditScreening(ctx, s.auditLogger, user.ID, screening, map[string]interface{}{"action": "register"})
+    }
+
+    return user, nil
+}
+
+// PromoteToAdmin grants the admin role to an existing user
+func (s *UserService) PromoteToAdmin(ctx context.Context, userID uint) (*models.User, error) {
+    user, err := s.userRepo.GetByID(ctx, userID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    if user.Role == models.RoleAdmin {
+        return nil, fmt.Errorf("user %d is already an admin", userID)
+    }
+
+    previousRole := user.Role
+    user.Role = models.RoleAdmin
+    user.UpdatedAt = time.Now()
+
+    if err := s.userRepo.Update(ctx, user); err != nil {
+        return nil, fmt.Errorf("failed to update user: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "from_role": previousRole,
+            "to_role":   user.Role,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user", user.ID, "promote", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return user, nil
+}
+
+// SetTier moves a user to the fee tier tier
+func (s *UserService) SetTier(ctx context.Context, userID uint, tier string) (*models.User, error) {
+    if tier == "" {
+        return nil, errors.New("tier is required")
+    }
+
+    user, err := s.userRepo.GetByID(ctx, userID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    previousTier := user.Tier
+    user.Tier = tier
+    user.UpdatedAt = time.Now()
+
+    if err := s.userRepo.Update(ctx, user); err != nil {
+        return nil, fmt.Errorf("failed to update user: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        changes := map[string]interface{}{
+            "from_tier": previousTier,
+            "to_tier":   tier,
+        }
+        if err := s.auditLogger.LogAction(ctx, "user", user.ID, "set_tier", changes); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return user, nil
+}
+
+// ChangePassword replaces the user's password after checking the current
+// one, recording when it changed for fraud screening.
+func (s *UserService) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+    user, err := s.userRepo.GetByID(ctx, userID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return fmt.Errorf("failed to get user: %w", err)
+    }
+
+    if !user.CheckPassword(currentPassword) {
+        return errors.New("invalid credentials")
+    }
+
+    if err := user.SetPassword(newPassword); err != nil {
+        return err
+    }
+
+    now := time.Now()
+    user.PasswordChangedAt = &now
+    user.UpdatedAt = now
+
+    if err := s.userRepo.Update(ctx, user); err != nil {
+        return fmt.Errorf("failed to update user: %w", err)
+    }
+
+    if s.auditLogger != nil {
+        if err := s.auditLogger.LogAction(ctx, "user", user.ID, "change_password", map[string]interface{}{}); err != nil {
+            log.Error().Err(err).Msg("Failed to log audit")
+        }
+    }
+
+    return nil
+}
+
+// GetUser returns the user with the given ID.
+func (s *UserService) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+    user, err := s.userRepo.GetByID(ctx, userID)
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return nil, fmt.Errorf("%w: %d", ErrUserNotFound, userID)
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
     }
 
     return user, nil
@@ -88,5 +265,5 @@ func (s *UserService) LoginUser(ctx context.Context, email, password string) (st
         return "", err
     }
 
-    return fmt.Sprintf(`{"email": "%s", "id": %d}`, user.Email, user.ID), nil
+    return s.issueToken(user)
 }
\ No newline at end of file
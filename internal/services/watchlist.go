@@ -0,0 +1,340 @@
+package services
+
+import (
+    "encoding/csv"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "unicode"
+    "financial-service/internal/models"
+)
+
+// LoadWatchlist reads the sanctions watchlist from a CSV or XML file, told
+// apart by its extension.
+//
+// CSV files have a header row naming their columns: name is required, id,
+// aliases (separated by ";") and program are optional. XML files hold
+// <watchlist><entry id="..." program="..."><name>...</name><alias>...</alias></entry></watchlist>.
+func LoadWatchlist(path string) ([]models.WatchlistEntry, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read watchlist: %w", err)
+    }
+    defer file.Close()
+
+    var entries []models.WatchlistEntry
+
+    switch strings.ToLower(filepath.Ext(path)) {
+        case ".csv":
+            entries, err = parseWatchlistCSV(file)
+        case ".xml":
+            entries, err = parseWatchlistXML(file)
+        default:
+            return nil, fmt.Errorf("unsupported watchlist format: %s", path)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse watchlist: %w", err)
+    }
+
+    for i := range entries {
+        if strings.TrimSpace(entries[i].Name) == "" {
+            return nil, fmt.Errorf("watchlist entry %d: name is required", i+1)
+        }
+        if entries[i].ID == "" {
+            entries[i].ID = fmt.Sprint(i + 1)
+        }
+    }
+
+    return entries, nil
+}
+
+func parseWatchlistCSV(r io.Reader) ([]models.WatchlistEntry, error) {
+    reader := csv.NewReader(r)
+    reader.FieldsPerRecord = -1
+    reader.TrimLeadingSpace = true
+
+    header, err := reader.Read()
+    if err != nil {
+        return nil, fmt.Errorf("failed to read header: %w", err)
+    }
+
+    columns := make(map[string]int)
+    for i, name := range header {
+        columns[strings.ToLower(strings.TrimSpace(name))] = i
+    }
+
+    if _, ok := columns["name"]; !ok {
+        return nil, errors.New("missing name column")
+    }
+
+    field := func(record []string, name string) string {
+        i, ok := columns[name]
+        if !ok || i >= len(record) {
+            return ""
+        }
+        return strings.TrimSpace(record[i])
+    }
+
+    var entries []models.WatchlistEntry
+    for {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+
+        entry := models.WatchlistEntry{
+            ID:      field(record, "id"),
+            Name:    field(record, "name"),
+            Program: field(record, "program"),
+        }
+        for _, alias := range strings.Split(field(record, "aliases"), ";") {
+            if alias = strings.TrimSpace(alias); alias != "" {
+                entry.Aliases = append(entry.Aliases, alias)
+            }
+        }
+
+        entries = append(entries, entry)
+    }
+
+    return entries, nil
+}
+
+func parseWatchlistXML(r io.Reader) ([]models.WatchlistEntry, error) {
+    var doc struct {
+        Entries []struct {
+            ID      string   `xml:"id,attr"`
+            Program string   `xml:"program,attr"`
+            Name    string   `xml:"name"`
+            Aliases []string `xml:"alias"`
+        } `xml:"entry"`
+    }
+    if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+        return nil, err
+    }
+
+    entries := make([]models.WatchlistEntry, 0, len(doc.Entries))
+    for _, e := range doc.Entries {
+        entry := models.WatchlistEntry{
+            ID:      strings.TrimSpace(e.ID),
+            Name:    strings.TrimSpace(e.Name),
+            Program: strings.TrimSpace(e.Program),
+        }
+        for _, alias := range e.Aliases {
+            if alias = strings.TrimSpace(alias); alias != "" {
+                entry.Aliases = append(entry.Aliases, alias)
+            }
+        }
+        entries = append(entries, entry)
+    }
+
+    return entries, nil
+}
+
+type watchlistName struct {
+    entry  *models.WatchlistEntry
+    name   string
+    tokens []string
+}
+
+// Watchlist screens names against the sanctions watchlist. Names are
+// normalized and compared token by token, so word order, case, accents and
+// punctuation do not matter and small misspellings still score high. Names
+// scoring at least flagScore against an entry are flagged for review, and
+// blocked from blockScore.
+type Watchlist struct {
+    names      []watchlistName
+    flagScore  float64
+    blockScore float64
+}
+
+func NewWatchlist(entries []models.WatchlistEntry, flagScore, blockScore float64) (*Watchlist, error) {
+    if flagScore <= 0 || flagScore > blockScore || blockScore > 1 {
+        return nil, fmt.Errorf("watchlist scores must satisfy 0 < flag (%v) <= block (%v) <= 1", flagScore, blockScore)
+    }
+
+    w := &Watchlist{flagScore: flagScore, blockScore: blockScore}
+
+    for i := range entries {
+        entry := &entries[i]
+        for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+            if tokens := nameTokens(name); len(tokens) > 0 {
+                w.names = append(w.names, watchlistName{entry, name, tokens})
+            }
+        }
+    }
+
+    return w, nil
+}
+
+// Screen scores name against every entry and decides on the best match.
+func (w *Watchlist) Screen(name string) *models.ScreeningResult {
+    result := &models.ScreeningResult{Name: name, Outcome: models.FraudAllow}
+
+    tokens := nameTokens(name)
+    if len(tokens) == 0 {
+        return result
+    }
+
+    best := make(map[string]int)
+    for _, listed := range w.names {
+        score := nameScore(tokens, listed.tokens)
+        if score < w.flagScore {
+            continue
+        }
+
+        // Keep the best scoring name of each entry
+        if i, ok := best[listed.entry.ID]; ok {
+            if score > result.Matches[i].Score {
+                result.Matches[i].Name = listed.name
+                result.Matches[i].Score = score
+            }
+            continue
+        }
+
+        best[listed.entry.ID] = len(result.Matches)
+        result.Matches = append(result.Matches, models.WatchlistMatch{
+            EntryID: listed.entry.ID,
+            Name:    listed.name,
+            Program: listed.entry.Program,
+            Score:   score,
+        })
+    }
+
+    sort.SliceStable(result.Matches, func(i, j int) bool {
+        return result.Matches[i].Score > result.Matches[j].Score
+    })
+
+    if len(result.Matches) > 0 {
+        result.Outcome = models.FraudReview
+        if result.Matches[0].Score >= w.blockScore {
+            result.Outcome = models.FraudBlock
+        }
+    }
+
+    return result
+}
+
+// foldedLetters maps accented and special Latin letters to their plain
+// spelling.
+var foldedLetters = map[rune]string{
+    'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+    'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+    'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+    'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+    'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+    'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+    'ř': "r", 'ś': "s", 'ş': "s", 'š': "s", 'ť': "t", 'ţ': "t",
+    'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+    'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+    'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ð': "d",
+}
+
+// nameTokens normalizes name into its words: lower case, accents folded,
+// anything but letters separating words.
+func nameTokens(name string) []string {
+    var b strings.Builder
+    for _, r := range strings.ToLower(name) {
+        if folded, ok := foldedLetters[r]; ok {
+            b.WriteString(folded)
+        } else if unicode.IsLetter(r) {
+            b.WriteRune(r)
+        } else {
+            b.WriteRune(' ')
+        }
+    }
+
+    return strings.Fields(b.String())
+}
+
+// nameScore scores how alike two tokenized names are, from 0 to 1. Tokens
+// are paired best first whatever their order, and the pair similarities are
+// summed over the tokens of both names, so missing or extra words lower the
+// score. Names run together, as in usernames, are also compared as a whole.
+func nameScore(a, b []string) float64 {
+    sims := make([][]float64, len(a))
+    for i := range a {
+        sims[i] = make([]float64, len(b))
+        for j := range b {
+            sims[i][j] = tokenSimilarity(a[i], b[j])
+        }
+    }
+
+    usedA := make([]bool, len(a))
+    usedB := make([]bool, len(b))
+    total := 0.0
+
+    pairs := len(a)
+    if len(b) < pairs {
+        pairs = len(b)
+    }
+
+    for n := 0; n < pairs; n++ {
+        bestI, bestJ, best := -1, -1, -1.0
+        for i := range a {
+            if usedA[i] {
+                continue
+            }
+            for j := range b {
+                if !usedB[j] && sims[i][j] > best {
+                    bestI, bestJ, best = i, j, sims[i][j]
+                }
+            }
+        }
+        usedA[bestI], usedB[bestJ] = true, true
+        total += best
+    }
+
+    score := 2 * total / float64(len(a)+len(b))
+
+    if joined := tokenSimilarity(strings.Join(a, ""), strings.Join(b, "")); joined > score {
+        score = joined
+    }
+
+    return score
+}
+
+// tokenSimilarity is one minus the edit distance between a and b relative to
+// the longer of the two.
+func tokenSimilarity(a, b string) float64 {
+    ra, rb := []rune(a), []rune(b)
+
+    longest := len(ra)
+    if len(rb) > longest {
+        longest = len(rb)
+    }
+    if longest == 0 {
+        return 1
+    }
+
+    return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+    prev := make([]int, len(b)+1)
+    curr := make([]int, len(b)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+
+    for i := 1; i <= len(a); i++ {
+        curr[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+        }
+        prev, curr = curr, prev
+    }
+
+    return prev[len(b)]
+}
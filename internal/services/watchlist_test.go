@@ -0,0 +1,214 @@
+package services
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "financial-service/internal/models"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func TestWatchlistScreen(t *testing.T) {
+    watchlist, err := NewWatchlist([]models.WatchlistEntry{
+        {ID: "ivan", Name: "Ivan Petrov", Aliases: []string{"Ivan Petrovich"}, Program: "SDN"},
+        {ID: "jose", Name: "José García Márquez"},
+        {ID: "evil", Name: "Evil Corp"},
+    }, 0.8, 0.95)
+    require.NoError(t, err)
+
+    tests := []struct {
+        name      string
+        screened  string
+        want      models.FraudOutcome
+        // entry and matchName are those of the best match, if any
+        entry     string
+        matchName string
+    }{
+        {
+            name:      "exact name",
+            screened:  "Ivan Petrov",
+            want:      models.FraudBlock,
+            entry:     "ivan",
+            matchName: "Ivan Petrov",
+        },
+        {
+            name:      "word order, case and punctuation",
+            screened:  "PETROV, ivan",
+            want:      models.FraudBlock,
+            entry:     "ivan",
+            matchName: "Ivan Petrov",
+        },
+        {
+            name:      "alias",
+            screened:  "ivan petrovich",
+            want:      models.FraudBlock,
+            entry:     "ivan",
+            matchName: "Ivan Petrovich",
+        },
+        {
+            name:      "misspelling",
+            screened:  "Ivan Petrof",
+            want:      models.FraudReview,
+            entry:     "ivan",
+            matchName: "Ivan Petrov",
+        },
+        {
+            name:      "accents",
+            screened:  "Jose Garcia Marquez",
+            want:      models.FraudBlock,
+            entry:     "jose",
+            matchName: "José García Márquez",
+        },
+        {
+            name:      "name run together as a username",
+            screened:  "evilcorp",
+            want:      models.FraudBlock,
+            entry:     "evil",
+            matchName: "Evil Corp",
+        },
+        {
+            name:      "username with digits",
+            screened:  "evil_corp99",
+            want:      models.FraudBlock,
+            entry:     "evil",
+            matchName: "Evil Corp",
+        },
+        {
+            name:     "part of a name",
+            screened: "Ivan",
+            want:     models.FraudAllow,
+        },
+        {
+            name:     "unrelated name",
+            screened: "Maria Lopez",
+            want:     models.FraudAllow,
+        },
+        {
+            name:     "no letters",
+            screened: "12345",
+            want:     models.FraudAllow,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            result := watchlist.Screen(tt.screened)
+
+            assert.Equal(t, tt.screened, result.Name)
+            assert.Equal(t, tt.want, result.Outcome)
+
+            if tt.entry == "" {
+                assert.Empty(t, result.Matches)
+                return
+            }
+
+            require.NotEmpty(t, result.Matches)
+            assert.Equal(t, tt.entry, result.Matches[0].EntryID)
+            assert.Equal(t, tt.matchName, result.Matches[0].Name)
+
+            // An entry is reported once, under its best scoring name
+            seen := make(map[string]bool)
+            for i, match := range result.Matches {
+                assert.False(t, seen[match.EntryID], "entry %s matched twice", match.EntryID)
+                seen[match.EntryID] = true
+                if i > 0 {
+                    assert.LessOrEqual(t, match.Score, result.Matches[i-1].Score)
+                }
+            }
+        })
+    }
+}
+
+func TestNewWatchlistScores(t *testing.T) {
+    tests := []struct {
+        name    string
+        flag    float64
+        block   float64
+        wantErr bool
+    }{
+        {name: "flag below block", flag: 0.8, block: 0.95},
+        {name: "flag equal to block", flag: 0.9, block: 0.9},
+        {name: "zero flag", flag: 0, block: 0.9, wantErr: true},
+        {name: "flag above block", flag: 0.95, block: 0.8, wantErr: true},
+        {name: "block above one", flag: 0.8, block: 1.5, wantErr: true},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := NewWatchlist(nil, tt.flag, tt.block)
+            if tt.wantErr {
+                assert.Error(t, err)
+            } else {
+                assert.NoError(t, err)
+            }
+        })
+    }
+}
+
+func TestLoadWatchlist(t *testing.T) {
+    tests := []struct {
+        name    string
+        file    string
+        content string
+        want    []models.WatchlistEntry
+        wantErr string
+    }{
+        {
+            name:    "CSV",
+            file:    "list.csv",
+            content: "Name,ID,Aliases,Program\nIvan Petrov,sdn-1,Ivan Petrovich; I. Petrov,SDN\nEvil Corp,,,\n",
+            want: []models.WatchlistEntry{
+                {ID: "sdn-1", Name: "Ivan Petrov", Aliases: []string{"Ivan Petrovich", "I. Petrov"}, Program: "SDN"},
+                {ID: "2", Name: "Evil Corp"},
+            },
+        },
+        {
+            name: "XML",
+            file: "list.XML",
+            content: `<watchlist>
+  <entry id="sdn-1" program="SDN"><name>Ivan Petrov</name><alias>Ivan Petrovich</alias><alias> </alias></entry>
+  <entry><name> Evil Corp </name></entry>
+</watchlist>`,
+            want: []models.WatchlistEntry{
+                {ID: "sdn-1", Name: "Ivan Petrov", Aliases: []string{"Ivan Petrovich"}, Program: "SDN"},
+                {ID: "2", Name: "Evil Corp"},
+            },
+        },
+        {
+            name:    "CSV without a name column",
+            file:    "list.csv",
+            content: "id,program\n1,SDN\n",
+            wantErr: "missing name column",
+        },
+        {
+            name:    "entry without a name",
+            file:    "list.csv",
+            content: "name,id\nIvan Petrov,1\n ,2\n",
+            wantErr: "watchlist entry 2: name is required",
+        },
+        {
+            name:    "unsupported format",
+            file:    "list.json",
+            content: "[]",
+            wantErr: "unsupported watchlist format",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            path := filepath.Join(t.TempDir(), tt.file)
+            require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+            entries, err := LoadWatchlist(path)
+
+            if tt.wantErr != "" {
+                assert.ErrorContains(t, err, tt.wantErr)
+                return
+            }
+
+            require.NoError(t, err)
+            assert.Equal(t, tt.want, entries)
+        })
+    }
+}
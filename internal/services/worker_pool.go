@@ -19,12 +19,46 @@ type WorkerPool struct {
     cancel      context.CancelFunc
     txRepo      repository.TransactionRepository
     balanceRepo repository.BalanceRepository
+    accountRepo repository.AccountRepository
+    // rejectFrozenCredits makes frozen accounts refuse credits as well as
+    // debits.
+    rejectFrozenCredits bool
+    transactor  repository.Transactor
+    quoteRepo   repository.FXQuoteRepository
+    feeAccount  uint
+    limits      *LimitService
+    events      *BalanceEvents
     stats       *WorkerStats
 }
 
 type Task struct {
     Transaction *models.Transaction
-    ResultChan  chan error
+    // Batch, set instead of Transaction, holds unsaved transactions that are
+    // created and applied all-or-nothing in one storage transaction.
+    Batch      []*models.Transaction
+    // Then, if set, runs in the storage transaction that applies
+    // Transaction, so what it records is kept only if the transaction is.
+    Then       func(ctx context.Context) error
+    ResultChan chan error
+}
+
+// batchTimeout bounds how long a batch task may hold its storage
+// transaction.
+const batchTimeout = 30 * time.Second
+
+// batchItemError reports the transaction that made a batch fail; none of
+// the batch was applied.
+type batchItemError struct {
+    index int
+    err   error
+}
+
+func (e *batchItemError) Error() string {
+    return fmt.Sprintf("item %d: %v", e.index, e.err)
+}
+
+func (e *batchItemError) Unwrap() error {
+    return e.err
 }
 
 type WorkerStats struct {
@@ -51,6 +85,50 @@ func NewWorkerPool(numWorkers int, ctx context.Context, txRepo repository.Transa
     }
 }
 
+// SetTransactor makes the pool apply each transaction's balance changes and
+// status update atomically. It must be called before tasks are submitted.
+func (wp *WorkerPool) SetTransactor(transactor repository.Transactor) {
+    wp.transactor = transactor
+}
+
+// SetAccounts lets the pool book transactions naming only users to their
+// default accounts, opening one for a recipient who has none in the currency.
+// It must be called before tasks are submitted.
+func (wp *WorkerPool) SetAccounts(accountRepo repository.AccountRepository) {
+    wp.accountRepo = accountRepo
+}
+
+// SetRejectFrozenCredits makes frozen accounts refuse credits as well as
+// debits.
+func (wp *WorkerPool) SetRejectFrozenCredits(reject bool) {
+    wp.rejectFrozenCredits = reject
+}
+
+// SetFXQuotes lets the pool execute conversions, which claim their quote in
+// the same transaction as the balance changes.
+func (wp *WorkerPool) SetFXQuotes(quoteRepo repository.FXQuoteRepository) {
+    wp.quoteRepo = quoteRepo
+}
+
+// SetFeeAccount sets the house user that fees charged on transactions are
+// posted to.
+func (wp *WorkerPool) SetFeeAccount(houseUserID uint) {
+    wp.feeAccount = houseUserID
+}
+
+// SetLimits makes the pool check each transaction of a batch against the
+// user's limits inside the batch's storage transaction, so earlier
+// transactions of the batch count toward later ones.
+func (wp *WorkerPool) SetLimits(limits *LimitService) {
+    wp.limits = limits
+}
+
+// SetBalanceEvents makes the pool publish the users whose balances changed
+// after each successfully applied transaction.
+func (wp *WorkerPool) SetBalanceEvents(events *BalanceEvents) {
+    wp.events = events
+}
+
 func (wp *WorkerPool) Start() {
     wp.wg.Add(wp.numWorkers)
     
@@ -88,7 +166,12 @@ func (wp *WorkerPool) worker() {
                     return
                 }
 
-                err := wp.processTransaction(task.Transaction)
+                var err error
+                if task.Batch != nil {
+                    err = wp.processBatch(task.Batch)
+                } else {
+                    err = wp.processTransaction(task.Transaction, task.Then)
+                }
                 atomic.AddInt64(&wp.stats.ProcessedCount, 1)
 
                 if err != nil {
@@ -102,97 +185,371 @@ func (wp *WorkerPool) worker() {
     }
 }
 
-func (wp *WorkerPool) processTransaction(tx *models.Transaction) error {
+func (wp *WorkerPool) processTransaction(tx *models.Transaction, then func(ctx context.Context) error) error {
     ctx, cancel := context.WithTimeout(wp.ctx, 5*time.Second)
 
     defer cancel()
 
-    switch tx.Type {
-        case models.TransactionTypeTransfer:
-            // Debit from source account
-            fromBalance, err := wp.balanceRepo.GetBalance(ctx, tx.FromUserID)
+    var overdrafts overdraftChanges
+
+    apply := func(ctx context.Context) error {
+        overdrafts = nil
+
+        if err := wp.applyTransaction(ctx, tx, &overdrafts); err != nil {
+            return err
+        }
+
+        if err := wp.postFees(ctx, tx, &overdrafts); err != nil {
+            return err
+        }
+
+        if then != nil {
+            return then(ctx)
+        }
+        return nil
+    }
+
+    var err error
+
+    if wp.transactor == nil {
+        err = apply(ctx)
+    } else {
+        err = wp.transactor.WithinTransaction(ctx, apply)
+    }
+
+    if err != nil {
+        return err
+    }
+
+    wp.publish(tx)
+    wp.events.PublishOverdraft(overdrafts...)
+
+    return nil
+}
 
-            if err != nil {
-                return fmt.Errorf("failed to get source balance: %w", err)
+// processBatch creates and applies every transaction of a batch in a single
+// storage transaction. If one fails, none is kept and the error is a
+// *batchItemError.
+func (wp *WorkerPool) processBatch(txs []*models.Transaction) error {
+    if wp.transactor == nil {
+        return errors.New("batches need a transactor")
+    }
+
+    ctx, cancel := context.WithTimeout(wp.ctx, batchTimeout)
+
+    defer cancel()
+
+    var overdrafts overdraftChanges
+
+    err := wp.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+        overdrafts = nil
+
+        for i, tx := range txs {
+            if err := wp.limits.Check(ctx, tx); err != nil {
+                return &batchItemError{i, err}
             }
-            
-            if fromBalance.Amount < tx.Amount {
-                return errors.New("insufficient funds")
+
+            if err := wp.txRepo.Create(ctx, tx); err != nil {
+                return &batchItemError{i, fmt.Errorf("failed to create transaction: %w", err)}
             }
-            
-            fromBalance.Amount -= tx.Amount
 
-            if err := wp.balanceRepo.UpdateBalance(ctx, fromBalance); err != nil {
-                return fmt.Errorf("failed to update source balance: %w", err)
+            if err := wp.applyTransaction(ctx, tx, &overdrafts); err != nil {
+                return &batchItemError{i, err}
             }
 
-            // Credit to destination account
-            toBalance, err := wp.balanceRepo.GetBalance(ctx, tx.ToUserID)
-            if err != nil {
-                if err == repository.ErrNotFound {
-                    // Create new balance if it doesn't exist
-                    toBalance = &models.Balance{
-                        UserID: tx.ToUserID,
-                        Amount: 0,
-                    }
-                    if err := wp.balanceRepo.CreateBalance(ctx, toBalance); err != nil {
-                        return fmt.Errorf("failed to create destination balance: %w", err)
-                    }
-                } else {
-                    return fmt.Errorf("failed to get destination balance: %w", err)
-                }
+            if err := wp.postFees(ctx, tx, &overdrafts); err != nil {
+                return &batchItemError{i, err}
             }
+        }
+
+        return nil
+    })
 
-            toBalance.Amount += tx.Amount
-            if err := wp.balanceRepo.UpdateBalance(ctx, toBalance); err != nil {
-                return fmt.Errorf("failed to update destination balance: %w", err)
+    if err != nil {
+        // The IDs handed out belong to rolled back rows
+        for _, tx := range txs {
+            tx.ID = 0
+            for i := range tx.Fees {
+                tx.Fees[i].TransactionID = 0
             }
+        }
+        return err
+    }
 
-        case models.TransactionTypeCredit:
-            balance, err := wp.balanceRepo.GetBalance(ctx, tx.ToUserID)
-
-            if err != nil {
-                if err == repository.ErrNotFound {
-                    balance = &models.Balance{
-                        UserID: tx.ToUserID,
-                        Amount: 0,
-                    }
-                    if err := wp.balanceRepo.CreateBalance(ctx, balance); err != nil {
-                        return fmt.Errorf("failed to create balance: %w", err)
-                    }
-                } else {
-                    return fmt.Errorf("failed to get balance: %w", err)
+    for _, tx := range txs {
+        wp.publish(tx)
+    }
+
+    wp.events.PublishOverdraft(overdrafts...)
+
+    return nil
+}
+
+// publish announces the balances tx changed. It is called only once the
+// changes are committed so listeners never reload the previous balance.
+func (wp *WorkerPool) publish(tx *models.Transaction) {
+    if tx.FromUserID != 0 {
+        wp.events.Publish(tx.Currency, tx.FromUserID)
+    }
+
+    if tx.ToUserID != 0 {
+        wp.events.Publish(tx.CreditCurrency(), tx.ToUserID)
+    }
+
+    if len(tx.Fees) > 0 {
+        wp.events.Publish(tx.Currency, wp.feeAccount)
+    }
+}
+
+// overdraftChanges collects the balances that entered or left overdraft
+// while a transaction was applied, to be published once it commits.
+type overdraftChanges []OverdraftEvent
+
+func (c *overdraftChanges) track(previousAmount float64, balance *models.Balance) {
+    if (previousAmount < 0) == balance.Overdrawn() {
+        return
+    }
+
+    *c = append(*c, OverdraftEvent{
+        AccountID:   balance.AccountID,
+        UserID:      balance.UserID,
+        Currency:    balance.Currency,
+        Amount:      balance.Amount,
+        CreditLimit: balance.CreditLimit,
+        Overdrawn:   balance.Overdrawn(),
+    })
+}
+
+func (wp *WorkerPool) applyTransaction(ctx context.Context, tx *models.Transaction, overdrafts *overdraftChanges) error {
+    if err := wp.resolveAccounts(ctx, tx); err != nil {
+        return err
+    }
+
+    switch tx.Type {
+        case models.TransactionTypeTransfer, models.TransactionTypeFee:
+            if err := wp.debitBalance(ctx, tx.FromAccountID, tx.Amount, overdrafts); err != nil {
+                return fmt.Errorf("source: %w", err)
+            }
+
+            if err := wp.creditBalance(ctx, tx.ToAccountID, tx.Amount, overdrafts); err != nil {
+                return fmt.Errorf("destination: %w", err)
+            }
+
+        case models.TransactionTypeConversion:
+            // Claiming the quote first makes a concurrent second execution
+            // fail before it touches any balance.
+            if wp.quoteRepo == nil {
+                return errors.New("fx quotes are not configured")
+            }
+
+            if err := wp.quoteRepo.MarkExecuted(ctx, tx.QuoteID, tx.ID); err != nil {
+                if errors.Is(err, repository.ErrNotFound) {
+                    return models.ErrQuoteUsed
                 }
+                return fmt.Errorf("failed to claim quote: %w", err)
             }
-            
-            balance.Amount += tx.Amount
 
-            if err := wp.balanceRepo.UpdateBalance(ctx, balance); err != nil {
-                return fmt.Errorf("failed to update balance: %w", err)
+            if err := wp.debitBalance(ctx, tx.FromAccountID, tx.Amount, overdrafts); err != nil {
+                return fmt.Errorf("source: %w", err)
             }
 
-        case models.TransactionTypeDebit:
-            balance, err := wp.balanceRepo.GetBalance(ctx, tx.FromUserID)
+            if err := wp.creditBalance(ctx, tx.ToAccountID, tx.ToAmount, overdrafts); err != nil {
+                return fmt.Errorf("destination: %w", err)
+            }
 
-            if err != nil {
-                return fmt.Errorf("failed to get balance: %w", err)
+        case models.TransactionTypeCredit:
+            if err := wp.creditBalance(ctx, tx.ToAccountID, tx.Amount, overdrafts); err != nil {
+                return err
             }
-            
-            if balance.Amount < tx.Amount {
-                return errors.New("insufficient funds")
+
+        case models.TransactionTypeDebit:
+            if err := wp.debitBalance(ctx, tx.FromAccountID, tx.Amount, overdrafts); err != nil {
+                return err
             }
-            
-            balance.Amount -= tx.Amount
-            
-            if err := wp.balanceRepo.UpdateBalance(ctx, balance); err != nil {
-                return fmt.Errorf("failed to update balance: %w", err)
+
+        case models.TransactionTypeInterest:
+            if tx.ToUserID != 0 {
+                if err := wp.creditBalance(ctx, tx.ToAccountID, tx.Amount, overdrafts); err != nil {
+                    return err
+                }
+            } else {
+                // Overdraft interest is charged even past the credit limit
+                if err := wp.creditBalance(ctx, tx.FromAccountID, -tx.Amount, overdrafts); err != nil {
+                    return err
+                }
             }
     }
 
-    // Update transaction status
-    if err := wp.txRepo.UpdateStatus(ctx, tx.ID, models.TransactionStatusCompleted); err != nil {
+    // Point-in-time balances replay transactions by when they were booked
+    bookedAt := time.Now()
+    if err := wp.txRepo.Complete(ctx, tx.ID, bookedAt); err != nil {
         return fmt.Errorf("failed to update transaction status: %w", err)
     }
+    tx.BookedAt = &bookedAt
+
+    return nil
+}
+
+// resolveAccounts books each side of tx without an account to the user's
+// default account in its currency and records the accounts on tx. A
+// recipient without one gets a new checking account; a payer without one is
+// left without, which debitBalance reports as insufficient funds. Accounts
+// given explicitly must belong to the user and hold the currency, and
+// neither account's status may forbid the transaction.
+func (wp *WorkerPool) resolveAccounts(ctx context.Context, tx *models.Transaction) error {
+    fromAccountID, toAccountID := tx.FromAccountID, tx.ToAccountID
+
+    var from, to *models.Account
+    var err error
+
+    if tx.FromUserID != 0 {
+        if from, err = wp.account(ctx, tx.FromAccountID, tx.FromUserID, tx.Currency, false); err != nil {
+            return fmt.Errorf("source: %w", err)
+        }
+        if from != nil {
+            tx.FromAccountID = from.ID
+        }
+    }
+
+    if tx.ToUserID != 0 {
+        if to, err = wp.account(ctx, tx.ToAccountID, tx.ToUserID, tx.CreditCurrency(), true); err != nil {
+            return fmt.Errorf("destination: %w", err)
+        }
+        tx.ToAccountID = to.ID
+    }
+
+    if err := checkAccountStatus(tx, from, to, wp.rejectFrozenCredits); err != nil {
+        return err
+    }
+
+    if tx.FromAccountID == fromAccountID && tx.ToAccountID == toAccountID {
+        return nil
+    }
+
+    if err := wp.txRepo.SetAccounts(ctx, tx.ID, tx.FromAccountID, tx.ToAccountID); err != nil {
+        return fmt.Errorf("failed to record transaction accounts: %w", err)
+    }
+
+    return nil
+}
+
+// account loads the account with the given ID, or the user's default
+// account in currency when id is zero. A missing default account is opened
+// if open is set and otherwise returned as nil.
+func (wp *WorkerPool) account(ctx context.Context, id, userID uint, currency string, open bool) (*models.Account, error) {
+    if wp.accountRepo == nil {
+        return nil, errors.New("accounts are not configured")
+    }
+
+    account, err := findAccount(ctx, wp.accountRepo, id, userID, currency)
+    if err != nil || account != nil || !open {
+        return account, err
+    }
+
+    account = &models.Account{
+        UserID:   userID,
+        Type:     models.AccountTypeChecking,
+        Currency: currency,
+    }
+
+    if _, err := openAccount(ctx, wp.accountRepo, wp.balanceRepo, account); err != nil {
+        return nil, err
+    }
+
+    return account, nil
+}
+
+// postFees records each fee on tx as a fee transaction from the payer to the
+// house account and applies it.
+func (wp *WorkerPool) postFees(ctx context.Context, tx *models.Transaction, overdrafts *overdraftChanges) error {
+    if len(tx.Fees) == 0 {
+        return nil
+    }
+
+    if wp.feeAccount == 0 {
+        return errors.New("fees are charged but no house account is configured")
+    }
+
+    // A credit's fee comes out of the credited funds
+    payer, payerAccount := tx.FromUserID, tx.FromAccountID
+    if tx.Type == models.TransactionTypeCredit {
+        payer, payerAccount = tx.ToUserID, tx.ToAccountID
+    }
+
+    for i := range tx.Fees {
+        line := &tx.Fees[i]
+
+        fee := &models.Transaction{
+            FromUserID:    payer,
+            ToUserID:      wp.feeAccount,
+            FromAccountID: payerAccount,
+            Amount:        line.Amount,
+            Currency:      line.Currency,
+            ParentID:      tx.ID,
+            Description:   line.Rule,
+            Type:          models.TransactionTypeFee,
+            Status:        models.TransactionStatusPending,
+            CreatedAt:     time.Now(),
+        }
+
+        if err := wp.txRepo.Create(ctx, fee); err != nil {
+            return fmt.Errorf("failed to create fee transaction: %w", err)
+        }
+
+        if err := wp.applyTransaction(ctx, fee, overdrafts); err != nil {
+            return fmt.Errorf("fee %s: %w", line.Rule, err)
+        }
+
+        line.TransactionID = fee.ID
+    }
+
+    return nil
+}
+
+// debitBalance takes amount from the account's balance, which may go
+// negative as far as its credit limit allows.
+func (wp *WorkerPool) debitBalance(ctx context.Context, accountID uint, amount float64, overdrafts *overdraftChanges) error {
+    balance, err := wp.balanceRepo.GetAccountBalance(ctx, accountID)
+
+    if err != nil {
+        if err == repository.ErrNotFound {
+            return models.ErrInsufficientFunds
+        }
+        return fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    if balance.Available() < amount {
+        return models.ErrInsufficientFunds
+    }
+
+    previousAmount := balance.Amount
+    balance.Amount = models.RoundAmount(balance.Amount-amount, balance.Currency)
+
+    if err := wp.balanceRepo.UpdateBalance(ctx, balance); err != nil {
+        return fmt.Errorf("failed to update balance: %w", err)
+    }
+
+    overdrafts.track(previousAmount, balance)
+
+    return nil
+}
+
+// creditBalance adds amount to the account's balance.
+func (wp *WorkerPool) creditBalance(ctx context.Context, accountID uint, amount float64, overdrafts *overdraftChanges) error {
+    balance, err := wp.balanceRepo.GetAccountBalance(ctx, accountID)
+
+    if err != nil {
+        return fmt.Errorf("failed to get balance: %w", err)
+    }
+
+    previousAmount := balance.Amount
+    balance.Amount = models.RoundAmount(balance.Amount+amount, balance.Currency)
+
+    if err := wp.balanceRepo.UpdateBalance(ctx, balance); err != nil {
+        return fmt.Errorf("failed to update balance: %w", err)
+    }
+
+    overdrafts.track(previousAmount, balance)
 
     return nil
 }
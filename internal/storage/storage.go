@@ -0,0 +1,151 @@
+package storage
+
+import (
+    "database/sql"
+    "fmt"
+    "financial-service/internal/config"
+    "financial-service/internal/db"
+    "financial-service/internal/repository"
+    "financial-service/internal/repository/memory"
+    "financial-service/internal/repository/mysql"
+    "financial-service/internal/repository/sqlite"
+)
+
+// Storage bundles the repositories of the backend selected by
+// config.StorageDriver.
+type Storage struct {
+    Users        repository.UserRepository
+    Transactions repository.TransactionRepository
+    Accounts     repository.AccountRepository
+    Balances     repository.BalanceRepository
+    AuditLogs    repository.AuditLogRepository
+    Snapshots    repository.BalanceSnapshotRepository
+    FXRates      repository.FXRateRepository
+    FXQuotes     repository.FXQuoteRepository
+    Limits       repository.LimitRepository
+    Interest     repository.InterestRepository
+    Schedules    repository.ScheduledTransferRepository
+    Batches      repository.TransferBatchRepository
+    Imports      repository.ImportRepository
+    Approvals    repository.ApprovalRepository
+    Transactor   repository.Transactor
+
+    database *sql.DB
+}
+
+// Open connects to the configured backend. SQL backends are migrated to the
+// latest schema unless cfg.DBAutoMigrate is off.
+func Open(cfg *config.Config) (*Storage, error) {
+    switch cfg.StorageDriver {
+        case "mysql":
+            return openMySQL(cfg)
+        case "sqlite":
+            return openSQLite(cfg)
+        case "memory":
+            return openMemory(), nil
+        default:
+            return nil, fmt.Errorf("unknown storage driver: %q", cfg.StorageDriver)
+    }
+}
+
+func openMySQL(cfg *config.Config) (*Storage, error) {
+    database, err := openSQL(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Storage{
+        Users:        mysql.NewUserRepository(database),
+        Transactions: mysql.NewTransactionRepository(database),
+        Accounts:     mysql.NewAccountRepository(database),
+        Balances:     mysql.NewBalanceRepository(database),
+        AuditLogs:    mysql.NewAuditLogRepository(database),
+        Snapshots:    mysql.NewBalanceSnapshotRepository(database),
+        FXRates:      mysql.NewFXRateRepository(database),
+        FXQuotes:     mysql.NewFXQuoteRepository(database),
+        Limits:       mysql.NewLimitRepository(database),
+        Interest:     mysql.NewInterestRepository(database),
+        Schedules:    mysql.NewScheduledTransferRepository(database),
+        Batches:      mysql.NewTransferBatchRepository(database),
+        Imports:      mysql.NewImportRepository(database),
+        Approvals:    mysql.NewApprovalRepository(database),
+        Transactor:   mysql.NewTransactor(database),
+        database:     database,
+    }, nil
+}
+
+func openSQLite(cfg *config.Config) (*Storage, error) {
+    database, err := openSQL(cfg)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Storage{
+        Users:        sqlite.NewUserRepository(database),
+        Transactions: sqlite.NewTransactionRepository(database),
+        Accounts:     sqlite.NewAccountRepository(database),
+        Balances:     sqlite.NewBalanceRepository(database),
+        AuditLogs:    sqlite.NewAuditLogRepository(database),
+        Snapshots:    sqlite.NewBalanceSnapshotRepository(database),
+        FXRates:      sqlite.NewFXRateRepository(database),
+        FXQuotes:     sqlite.NewFXQuoteRepository(database),
+        Limits:       sqlite.NewLimitRepository(database),
+        Interest:     sqlite.NewInterestRepository(database),
+        Schedules:    sqlite.NewScheduledTransferRepository(database),
+        Batches:      sqlite.NewTransferBatchRepository(database),
+        Imports:      sqlite.NewImportRepository(database),
+        Approvals:    sqlite.NewApprovalRepository(database),
+        Transactor:   sqlite.NewTransactor(database),
+        database:     database,
+    }, nil
+}
+
+// openSQL connects to the configured SQL database and, unless disabled,
+// brings its schema up to date.
+func openSQL(cfg *config.Config) (*sql.DB, error) {
+    database, err := db.Open(cfg)
+    if err != nil {
+        return nil, fmt.Errorf("failed to connect to database: %w", err)
+    }
+
+    if !cfg.DBAutoMigrate {
+        return database, nil
+    }
+
+    if err := db.MigrateDB(database, cfg); err != nil {
+        database.Close()
+        return nil, fmt.Errorf("failed to run database migrations: %w", err)
+    }
+
+    return database, nil
+}
+
+func openMemory() *Storage {
+    store := memory.NewStore()
+
+    return &Storage{
+        Users:        memory.NewUserRepository(store),
+        Transactions: memory.NewTransactionRepository(store),
+        Accounts:     memory.NewAccountRepository(store),
+        Balances:     memory.NewBalanceRepository(store),
+        AuditLogs:    memory.NewAuditLogRepository(store),
+        Snapshots:    memory.NewBalanceSnapshotRepository(store),
+        FXRates:      memory.NewFXRateRepository(store),
+        FXQuotes:     memory.NewFXQuoteRepository(store),
+        Limits:       memory.NewLimitRepository(store),
+        Interest:     memory.NewInterestRepository(store),
+        Schedules:    memory.NewScheduledTransferRepository(store),
+        Batches:      memory.NewTransferBatchRepository(store),
+        Imports:      memory.NewImportRepository(store),
+        Approvals:    memory.NewApprovalRepository(store),
+        Transactor:   store,
+    }
+}
+
+func (s *Storage) Close() error {
+    if s.database == nil {
+        return nil
+    }
+
+    return s.database.Close()
+}
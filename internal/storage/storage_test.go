@@ -0,0 +1,2150 @@
+package storage
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+    "financial-service/internal/config"
+    "financial-service/internal/models"
+    "financial-service/internal/repository"
+    "financial-service/internal/services"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+var drivers = []string{"memory", "sqlite"}
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden compares got with the golden file testdata/name, or rewrites the
+// file with -update.
+func golden(t *testing.T, name string, got []byte) {
+    t.Helper()
+
+    path := filepath.Join("testdata", name)
+    if *update {
+        require.NoError(t, os.WriteFile(path, got, 0o644))
+    }
+
+    want, err := os.ReadFile(path)
+    require.NoError(t, err)
+    assert.Equal(t, string(want), string(got), "output differs from %s, rerun with -update if that is intended", path)
+}
+
+// openTest opens an empty store of driver, migrated to the latest schema.
+func openTest(t *testing.T, driver string) *Storage {
+    t.Helper()
+
+    store, err := Open(&config.Config{
+        StorageDriver: driver,
+        SQLitePath:    filepath.Join(t.TempDir(), "test.db"),
+        DBAutoMigrate: true,
+    })
+    require.NoError(t, err)
+    t.Cleanup(func() { store.Close() })
+
+    return store
+}
+
+type env struct {
+    store *Storage
+    users *services.UserService
+    txs   *services.TransactionService
+}
+
+// newEnv wires the services that move money onto store the way cmd does.
+func newEnv(t *testing.T, store *Storage) *env {
+    t.Helper()
+
+    auditLogger := services.NewAuditLogger(store.AuditLogs)
+
+    users := services.NewUserService(store.Users, store.Accounts, store.Balances, "USD")
+    users.SetAuditLogger(auditLogger)
+    users.SetTransactor(store.Transactor)
+
+    txs := services.NewTransactionService(store.Transactions, store.Balances, store.Users, 2)
+    t.Cleanup(txs.Cleanup)
+    txs.SetAuditLogger(auditLogger)
+    txs.SetTransactor(store.Transactor)
+    txs.SetAccounts(store.Accounts)
+
+    return &env{store: store, users: users, txs: txs}
+}
+
+func (e *env) register(t *testing.T, username string) *models.User {
+    t.Helper()
+
+    user, err := e.users.RegisterUser(context.Background(), username, username+"@example.com", "Passw0rd!23")
+    require.NoError(t, err)
+
+    return user
+}
+
+func (e *env) admin(t *testing.T, username string) *models.User {
+    t.Helper()
+
+    user := e.register(t, username)
+    admin, err := e.users.PromoteToAdmin(context.Background(), user.ID)
+    require.NoError(t, err)
+
+    return admin
+}
+
+func (e *env) balance(t *testing.T, userID uint) float64 {
+    t.Helper()
+
+    balance, err := e.store.Balances.GetBalance(context.Background(), userID, "USD")
+    require.NoError(t, err)
+
+    return balance.Amount
+}
+
+// tamper overwrites the stored balance of userID without touching the ledger.
+func (e *env) tamper(t *testing.T, userID uint, amount float64) {
+    t.Helper()
+
+    ctx := context.Background()
+
+    balance, err := e.store.Balances.GetBalance(ctx, userID, "USD")
+    require.NoError(t, err)
+
+    balance.Amount = amount
+    require.NoError(t, e.store.Balances.UpdateBalance(ctx, balance))
+}
+
+// within fails the test if fn has not returned in time, so a deadlock shows up
+// as a failure instead of a hung test run.
+func within(t *testing.T, fn func()) {
+    t.Helper()
+
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        fn()
+    }()
+
+    select {
+        case <-done:
+        case <-time.After(10 * time.Second):
+            t.Fatal("timed out, a transaction is probably waiting on itself")
+    }
+}
+
+func TestMoneyPaths(t *testing.T) {
+    tests := []struct {
+        name    string
+        run     func(ctx context.Context, e *env, alice, bob *models.User) error
+        wantErr error
+        // balances of alice and bob afterwards, alice starting with 100
+        alice   float64
+        bob     float64
+    }{
+        {
+            name: "credit",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Credit(ctx, bob.ID, 25, "USD")
+                return err
+            },
+            alice: 100,
+            bob:   25,
+        },
+        {
+            name: "debit",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Debit(ctx, alice.ID, 30, "USD")
+                return err
+            },
+            alice: 70,
+        },
+        {
+            name: "debit of the whole balance",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Debit(ctx, alice.ID, 100, "USD")
+                return err
+            },
+            alice: 0,
+        },
+        {
+            name: "debit above the balance",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Debit(ctx, alice.ID, 100.01, "USD")
+                return err
+            },
+            wantErr: models.ErrInsufficientFunds,
+            alice:   100,
+        },
+        {
+            name: "transfer",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Transfer(ctx, alice.ID, bob.ID, 40, "USD")
+                return err
+            },
+            alice: 60,
+            bob:   40,
+        },
+        {
+            name: "transfer above the balance",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Transfer(ctx, alice.ID, bob.ID, 150, "USD")
+                return err
+            },
+            wantErr: models.ErrInsufficientFunds,
+            alice:   100,
+        },
+        {
+            name: "transfers back and forth",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                if _, err := e.txs.Transfer(ctx, alice.ID, bob.ID, 80, "USD"); err != nil {
+                    return err
+                }
+                _, err := e.txs.Transfer(ctx, bob.ID, alice.ID, 30, "USD")
+                return err
+            },
+            alice: 50,
+            bob:   50,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                within(t, func() {
+                    _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                    require.NoError(t, err)
+
+                    err = tt.run(ctx, e, alice, bob)
+                    if tt.wantErr != nil {
+                        assert.ErrorIs(t, err, tt.wantErr)
+                    } else {
+                        assert.NoError(t, err)
+                    }
+                })
+
+                assert.Equal(t, tt.alice, e.balance(t, alice.ID))
+                assert.Equal(t, tt.bob, e.balance(t, bob.ID))
+            })
+        }
+    }
+}
+
+// TestCallOutsideTransaction pins down the invariant documented on
+// repository.Transactor: inside a transaction, a repository call made without
+// its ctx cannot run until the transaction ends.
+func TestCallOutsideTransaction(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            alice := e.register(t, "alice")
+            ctx := context.Background()
+
+            // A deadline keeps SQLite from waiting on its only connection for
+            // ever if the transaction never ends
+            outside, cancel := context.WithTimeout(ctx, 5*time.Second)
+            defer cancel()
+
+            read := make(chan error, 1)
+
+            within(t, func() {
+                err := e.store.Transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+                    go func() {
+                        _, err := e.store.Users.GetByID(outside, alice.ID)
+                        read <- err
+                    }()
+
+                    select {
+                        case <-read:
+                            t.Error("a call without the transaction's ctx ran inside it")
+                        case <-time.After(50 * time.Millisecond):
+                    }
+
+                    _, err := e.store.Users.GetByID(txCtx, alice.ID)
+                    return err
+                })
+                require.NoError(t, err)
+            })
+
+            select {
+                case err := <-read:
+                    assert.NoError(t, err)
+                case <-time.After(5 * time.Second):
+                    t.Fatal("the call did not run once the transaction ended")
+            }
+        })
+    }
+}
+
+func TestFees(t *testing.T) {
+    schedule := &services.FeeSchedule{Rules: []services.FeeRule{
+        {Name: "transfer", Type: models.TransactionTypeTransfer, Kind: services.FeeKindFlat, Flat: 1},
+        {Name: "credit", Type: models.TransactionTypeCredit, Kind: services.FeeKindPercentage, Percent: 2},
+    }}
+
+    tests := []struct {
+        name    string
+        run     func(ctx context.Context, e *env, alice, bob *models.User) error
+        wantErr error
+        // balances of alice, bob and the fee account afterwards, alice
+        // starting with 100 credited and 2 taken in fees
+        alice   float64
+        bob     float64
+        house   float64
+    }{
+        {
+            name: "credit fee is taken from the credited funds",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Credit(ctx, bob.ID, 50, "USD")
+                return err
+            },
+            alice: 98,
+            bob:   49,
+            house: 3,
+        },
+        {
+            name: "transfer fee is paid by the payer",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Transfer(ctx, alice.ID, bob.ID, 40, "USD")
+                return err
+            },
+            alice: 57,
+            bob:   40,
+            house: 3,
+        },
+        {
+            name: "transfer and fee above the balance",
+            run: func(ctx context.Context, e *env, alice, bob *models.User) error {
+                _, err := e.txs.Transfer(ctx, alice.ID, bob.ID, 97.5, "USD")
+                return err
+            },
+            wantErr: models.ErrInsufficientFunds,
+            alice:   98,
+            house:   2,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                house := e.register(t, "house")
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                e.txs.SetFeeSchedule(schedule, house.ID)
+                ctx := context.Background()
+
+                within(t, func() {
+                    _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                    require.NoError(t, err)
+
+                    err = tt.run(ctx, e, alice, bob)
+                    if tt.wantErr != nil {
+                        assert.ErrorIs(t, err, tt.wantErr)
+                    } else {
+                        assert.NoError(t, err)
+                    }
+                })
+
+                assert.Equal(t, tt.alice, e.balance(t, alice.ID))
+                assert.Equal(t, tt.bob, e.balance(t, bob.ID))
+                assert.Equal(t, tt.house, e.balance(t, house.ID))
+            })
+        }
+    }
+}
+
+func TestReconciliation(t *testing.T) {
+    tests := []struct {
+        name    string
+        // setup runs after alice is credited 100 and bob 40
+        setup   func(ctx context.Context, t *testing.T, e *env, alice, bob *models.User)
+        opts    func(alice, bob *models.User) services.ReconciliationOptions
+        // drift is alice's drift, zero if none is reported
+        drift   float64
+        balance float64
+    }{
+        {
+            name:    "ledger and balances agree",
+            balance: 100,
+        },
+        {
+            name: "drift is reported",
+            setup: func(ctx context.Context, t *testing.T, e *env, alice, bob *models.User) {
+                e.tamper(t, alice.ID, 150)
+            },
+            drift:   50,
+            balance: 150,
+        },
+        {
+            name: "drift is repaired",
+            setup: func(ctx context.Context, t *testing.T, e *env, alice, bob *models.User) {
+                e.tamper(t, alice.ID, 90.5)
+            },
+            opts: func(alice, bob *models.User) services.ReconciliationOptions {
+                return services.ReconciliationOptions{Repair: true}
+            },
+            drift:   -9.5,
+            balance: 100,
+        },
+        {
+            name: "only the selected users are checked",
+            setup: func(ctx context.Context, t *testing.T, e *env, alice, bob *models.User) {
+                e.tamper(t, alice.ID, 150)
+            },
+            opts: func(alice, bob *models.User) services.ReconciliationOptions {
+                return services.ReconciliationOptions{UserIDs: []uint{bob.ID}}
+            },
+            balance: 150,
+        },
+        {
+            name: "ledger spanning several pages",
+            setup: func(ctx context.Context, t *testing.T, e *env, alice, bob *models.User) {
+                account, err := e.store.Accounts.GetDefault(ctx, alice.ID, "USD")
+                require.NoError(t, err)
+
+                // Booked straight to the ledger, so the stored balance misses
+                // them
+                err = e.store.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+                    for i := 0; i < 1203; i++ {
+                        err := e.store.Transactions.Create(ctx, &models.Transaction{
+                            ToUserID:    alice.ID,
+                            ToAccountID: account.ID,
+                            Amount:      0.25,
+                            Currency:    "USD",
+                            Type:        models.TransactionTypeCredit,
+                            Status:      models.TransactionStatusCompleted,
+                            CreatedAt:   time.Now(),
+                        })
+                        if err != nil {
+                            return err
+                        }
+                    }
+                    return nil
+                })
+                require.NoError(t, err)
+            },
+            drift:   -300.75,
+            balance: 100,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                reconciler := services.NewReconciliationService(e.store.Balances, e.store.Transactions, e.store.Transactor)
+                reconciler.SetAuditLogger(services.NewAuditLogger(e.store.AuditLogs))
+
+                var opts services.ReconciliationOptions
+                if tt.opts != nil {
+                    opts = tt.opts(alice, bob)
+                }
+
+                var report *services.ReconciliationReport
+                within(t, func() {
+                    _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                    require.NoError(t, err)
+                    _, err = e.txs.Credit(ctx, bob.ID, 40, "USD")
+                    require.NoError(t, err)
+
+                    if tt.setup != nil {
+                        tt.setup(ctx, t, e, alice, bob)
+                    }
+
+                    report, err = reconciler.Run(ctx, opts)
+                    require.NoError(t, err)
+                })
+
+                if len(opts.UserIDs) > 0 {
+                    assert.Equal(t, len(opts.UserIDs), report.BalancesChecked)
+                } else {
+                    assert.Equal(t, 2, report.BalancesChecked)
+                }
+
+                if tt.drift == 0 {
+                    assert.Empty(t, report.Drifts)
+                } else {
+                    require.Len(t, report.Drifts, 1)
+                    assert.Equal(t, alice.ID, report.Drifts[0].UserID)
+                    assert.Equal(t, tt.drift, report.Drifts[0].Drift)
+                    assert.Equal(t, opts.Repair, report.Drifts[0].Repaired)
+                }
+
+                assert.Equal(t, tt.balance, e.balance(t, alice.ID))
+
+                logs, err := e.store.AuditLogs.GetByEntityID(ctx, "balance", alice.ID)
+                require.NoError(t, err)
+                repairs := 0
+                for _, entry := range logs {
+                    if entry.Action == "reconcile_repair" {
+                        repairs++
+                    }
+                }
+                assert.Equal(t, report.Repaired, repairs)
+            })
+        }
+    }
+}
+
+func TestImport(t *testing.T) {
+    type row struct {
+        status models.ImportRowStatus
+        err    string
+    }
+
+    tests := []struct {
+        name   string
+        // file is the CSV to import, given the IDs of alice and bob
+        file   func(alice, bob uint) string
+        dryRun bool
+        status models.ImportStatus
+        rows   []row
+        alice  float64
+        bob    float64
+    }{
+        {
+            name: "rows are executed in order",
+            file: func(alice, bob uint) string {
+                return fmt.Sprintf("type,amount,user_id,from_user_id,to_user_id\ncredit,100,%d,,\ntransfer,60,,%d,%d\n", alice, alice, bob)
+            },
+            status: models.ImportStatusCompleted,
+            rows:   []row{{status: models.ImportRowCompleted}, {status: models.ImportRowCompleted}},
+            alice:  40,
+            bob:    60,
+        },
+        {
+            name: "rows that would overdraw or name no user are refused",
+            file: func(alice, bob uint) string {
+                return fmt.Sprintf("type,amount,user_id,from_user_id,to_user_id\ncredit,50,%d,,\ntransfer,60,,%d,%d\ncredit,5,999,,\ntransfer,50,,%d,%d\n", alice, alice, bob, alice, bob)
+            },
+            status: models.ImportStatusCompletedWithErrors,
+            rows: []row{
+                {status: models.ImportRowCompleted},
+                {status: models.ImportRowInvalid, err: "insufficient funds"},
+                {status: models.ImportRowInvalid, err: "user not found: 999"},
+                {status: models.ImportRowCompleted},
+            },
+            bob: 50,
+        },
+        {
+            name: "no row is valid",
+            file: func(alice, bob uint) string {
+                return fmt.Sprintf("type,amount,from_user_id,to_user_id\ntransfer,1,%d,%d\ndebit,1,%d,\n", alice, bob, alice)
+            },
+            status: models.ImportStatusFailed,
+            rows: []row{
+                {status: models.ImportRowInvalid, err: "insufficient funds"},
+                {status: models.ImportRowInvalid, err: `unsupported type "debit"`},
+            },
+        },
+        {
+            name: "dry run executes nothing",
+            file: func(alice, bob uint) string {
+                return fmt.Sprintf("type,amount,user_id\ncredit,100,%d\ncredit,0,%d\n", alice, bob)
+            },
+            dryRun: true,
+            status: models.ImportStatusValidated,
+            rows: []row{
+                {status: models.ImportRowValid},
+                {status: models.ImportRowInvalid, err: "amount"},
+            },
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                imports := services.NewImportService(e.store.Imports, e.store.Users, e.store.Balances, e.txs, e.store.Transactor, "USD", 100)
+                data := []byte(tt.file(alice.ID, bob.ID))
+
+                within(t, func() {
+                    imp, created, err := imports.ImportCSV(ctx, "payouts.csv", data, tt.dryRun)
+                    require.NoError(t, err)
+                    assert.True(t, created)
+                    assert.Equal(t, tt.status, imp.Status)
+
+                    require.Len(t, imp.Rows, len(tt.rows))
+                    for i, want := range tt.rows {
+                        assert.Equal(t, want.status, imp.Rows[i].Status, "line %d", imp.Rows[i].Line)
+                        assert.Contains(t, imp.Rows[i].Error, want.err, "line %d", imp.Rows[i].Line)
+                    }
+
+                    // A file is only ever executed once
+                    again, created, err := imports.ImportCSV(ctx, "payouts-again.csv", data, tt.dryRun)
+                    require.NoError(t, err)
+                    assert.Equal(t, tt.dryRun, created)
+                    if !tt.dryRun {
+                        assert.Equal(t, imp.ID, again.ID)
+                        assert.Equal(t, imp.Status, again.Status)
+                    }
+                })
+
+                assert.Equal(t, tt.alice, e.balance(t, alice.ID))
+                assert.Equal(t, tt.bob, e.balance(t, bob.ID))
+            })
+        }
+    }
+}
+
+func TestApprovals(t *testing.T) {
+    credits := services.ApprovalPolicy{ManualCredits: true, TTL: time.Hour}
+    debits := services.ApprovalPolicy{
+        Thresholds: []models.ApprovalThreshold{{Type: models.TransactionTypeDebit, Amount: 30}},
+        TTL:        time.Hour,
+    }
+
+    // heldCredit holds a credit of 50 to alice made by maker
+    heldCredit := func(ctx context.Context, t *testing.T, e *env, maker, alice *models.User) uint {
+        tx, err := e.txs.Credit(services.WithActorUser(ctx, maker), alice.ID, 50, "USD")
+        require.NoError(t, err)
+        require.Equal(t, models.TransactionStatusAwaitingApproval, tx.Status)
+        return tx.ID
+    }
+
+    // heldDebit credits alice 50 and holds a debit of 40 from her made by
+    // maker
+    heldDebit := func(ctx context.Context, t *testing.T, e *env, maker, alice *models.User) uint {
+        _, err := e.txs.Credit(ctx, alice.ID, 50, "USD")
+        require.NoError(t, err)
+
+        tx, err := e.txs.Debit(services.WithActorUser(ctx, maker), alice.ID, 40, "USD")
+        require.NoError(t, err)
+        require.Equal(t, models.TransactionStatusAwaitingApproval, tx.Status)
+        return tx.ID
+    }
+
+    approvedByChecker := func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+        _, err := e.txs.Approve(services.WithActorUser(ctx, checker), id)
+        return err
+    }
+
+    approvedByMaker := func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+        _, err := e.txs.Approve(services.WithActorUser(ctx, maker), id)
+        return err
+    }
+
+    tests := []struct {
+        name    string
+        policy  services.ApprovalPolicy
+        hold    func(ctx context.Context, t *testing.T, e *env, maker, alice *models.User) uint
+        // before runs between the hold and the decision
+        before  func(ctx context.Context, t *testing.T, e *env, alice *models.User)
+        decide  func(ctx context.Context, e *env, maker, checker *models.User, id uint) error
+        wantErr error
+        status  models.TransactionStatus
+        balance float64
+    }{
+        {
+            name:    "credit approved by another admin",
+            policy:  credits,
+            hold:    heldCredit,
+            decide:  approvedByChecker,
+            status:  models.TransactionStatusCompleted,
+            balance: 50,
+        },
+        {
+            name:    "credit approved by its initiator",
+            policy:  credits,
+            hold:    heldCredit,
+            decide:  approvedByMaker,
+            wantErr: models.ErrSelfApproval,
+            status:  models.TransactionStatusAwaitingApproval,
+        },
+        {
+            name:   "credit approved by an operator who is not an admin user",
+            policy: credits,
+            hold:   heldCredit,
+            decide: func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+                _, err := e.txs.Approve(services.WithActor(ctx, checker.Username), id)
+                return err
+            },
+            wantErr: models.ErrApproverUnknown,
+            status:  models.TransactionStatusAwaitingApproval,
+        },
+        {
+            name:   "credit approved by a user who is not an admin",
+            policy: credits,
+            hold:   heldCredit,
+            decide: func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+                user, err := e.users.RegisterUser(ctx, "mallory", "mallory@example.com", "Passw0rd!23")
+                if err != nil {
+                    return err
+                }
+                _, err = e.txs.Approve(services.WithActorUser(ctx, user), id)
+                return err
+            },
+            wantErr: models.ErrApproverUnknown,
+            status:  models.TransactionStatusAwaitingApproval,
+        },
+        {
+            name:   "credit rejected by another admin",
+            policy: credits,
+            hold:   heldCredit,
+            decide: func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+                _, err := e.txs.Reject(services.WithReason(services.WithActorUser(ctx, checker), "duplicate"), id)
+                return err
+            },
+            status: models.TransactionStatusRejected,
+        },
+        {
+            name:   "credit decided twice",
+            policy: credits,
+            hold:   heldCredit,
+            decide: func(ctx context.Context, e *env, maker, checker *models.User, id uint) error {
+                if _, err := e.txs.Approve(services.WithActorUser(ctx, checker), id); err != nil {
+                    return err
+                }
+                _, err := e.txs.Reject(services.WithReason(services.WithActorUser(ctx, checker), "late"), id)
+                return err
+            },
+            wantErr: models.ErrNotAwaitingApproval,
+            status:  models.TransactionStatusCompleted,
+            balance: 50,
+        },
+        {
+            name:    "debit above the threshold approved",
+            policy:  debits,
+            hold:    heldDebit,
+            decide:  approvedByChecker,
+            status:  models.TransactionStatusCompleted,
+            balance: 10,
+        },
+        {
+            name:   "debit approved after the funds were spent",
+            policy: debits,
+            hold:   heldDebit,
+            before: func(ctx context.Context, t *testing.T, e *env, alice *models.User) {
+                _, err := e.txs.Debit(ctx, alice.ID, 20, "USD")
+                require.NoError(t, err)
+            },
+            decide:  approvedByChecker,
+            wantErr: models.ErrInsufficientFunds,
+            status:  models.TransactionStatusAwaitingApproval,
+            balance: 30,
+        },
+        {
+            name:   "debit approved after its limit was lowered",
+            policy: debits,
+            hold:   heldDebit,
+            before: func(ctx context.Context, t *testing.T, e *env, alice *models.User) {
+                limits := services.NewLimitService(nil, e.store.Limits, e.store.Transactions, e.store.Users)
+                require.NoError(t, limits.SetUserLimit(ctx, &models.TransactionLimit{
+                    UserID:         alice.ID,
+                    Type:           models.TransactionTypeDebit,
+                    PerTransaction: 25,
+                }))
+                e.txs.SetLimits(limits)
+            },
+            decide:  approvedByChecker,
+            wantErr: models.ErrLimitExceeded,
+            status:  models.TransactionStatusAwaitingApproval,
+            balance: 50,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                policy := tt.policy
+                e.txs.SetApprovals(&policy, e.store.Approvals)
+
+                maker := e.admin(t, "maker")
+                checker := e.admin(t, "checker")
+                alice := e.register(t, "alice")
+                ctx := context.Background()
+
+                within(t, func() {
+                    id := tt.hold(ctx, t, e, maker, alice)
+
+                    if tt.before != nil {
+                        tt.before(ctx, t, e, alice)
+                    }
+
+                    err := tt.decide(ctx, e, maker, checker, id)
+                    if tt.wantErr != nil {
+                        assert.ErrorIs(t, err, tt.wantErr)
+                    } else {
+                        assert.NoError(t, err)
+                    }
+
+                    stored, err := e.store.Transactions.GetByID(ctx, id)
+                    require.NoError(t, err)
+                    assert.Equal(t, tt.status, stored.Status)
+
+                    approval, err := e.store.Approvals.GetByTransactionID(ctx, id)
+                    require.NoError(t, err)
+                    assert.Equal(t, maker.ID, approval.InitiatedByID)
+                    if tt.status != models.TransactionStatusAwaitingApproval {
+                        assert.Equal(t, checker.ID, approval.DecidedByID)
+                    }
+                })
+
+                assert.Equal(t, tt.balance, e.balance(t, alice.ID))
+            })
+        }
+    }
+}
+
+func TestImportHeldCredits(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            e.txs.SetApprovals(&services.ApprovalPolicy{ManualCredits: true, TTL: time.Hour}, e.store.Approvals)
+            maker := e.admin(t, "maker")
+            alice := e.register(t, "alice")
+            ctx := services.WithActorUser(context.Background(), maker)
+
+            imports := services.NewImportService(e.store.Imports, e.store.Users, e.store.Balances, e.txs, e.store.Transactor, "USD", 100)
+
+            within(t, func() {
+                imp, _, err := imports.ImportCSV(ctx, "credits.csv", []byte(fmt.Sprintf("type,amount,user_id\ncredit,10,%d\n", alice.ID)), false)
+                require.NoError(t, err)
+
+                require.Len(t, imp.Rows, 1)
+                assert.Equal(t, models.ImportRowAwaitingApproval, imp.Rows[0].Status)
+                assert.NotZero(t, imp.Rows[0].TransactionID)
+            })
+
+            assert.Equal(t, 0.0, e.balance(t, alice.ID))
+        })
+    }
+}
+
+func TestApprovalsWithoutInitiator(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            e.txs.SetApprovals(&services.ApprovalPolicy{ManualCredits: true, TTL: time.Hour}, e.store.Approvals)
+            checker := e.admin(t, "checker")
+            alice := e.register(t, "alice")
+            ctx := context.Background()
+
+            within(t, func() {
+                // A credit nobody can be held to account for is not held
+                _, err := e.txs.Credit(ctx, alice.ID, 50, "USD")
+                assert.ErrorIs(t, err, models.ErrInitiatorUnknown)
+
+                pending, err := e.txs.ListAwaitingApproval(ctx, "")
+                require.NoError(t, err)
+                assert.Empty(t, pending)
+
+                // Nor is one held before initiators were recorded approved
+                tx := &models.Transaction{
+                    ToUserID:  alice.ID,
+                    Amount:    50,
+                    Currency:  "USD",
+                    Type:      models.TransactionTypeCredit,
+                    Status:    models.TransactionStatusAwaitingApproval,
+                    CreatedAt: time.Now(),
+                }
+                err = e.store.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+                    if err := e.store.Transactions.Create(ctx, tx); err != nil {
+                        return err
+                    }
+                    return e.store.Approvals.Create(ctx, &models.Approval{
+                        TransactionID: tx.ID,
+                        Kind:          models.ApprovalKindApproval,
+                        Status:        models.ApprovalPending,
+                        InitiatedBy:   "ops",
+                        Reason:        "manual credit",
+                        ExpiresAt:     time.Now().Add(time.Hour),
+                        CreatedAt:     time.Now(),
+                    })
+                })
+                require.NoError(t, err)
+
+                _, err = e.txs.Approve(services.WithActorUser(ctx, checker), tx.ID)
+                assert.ErrorIs(t, err, models.ErrInitiatorUnknown)
+            })
+
+            assert.Equal(t, 0.0, e.balance(t, alice.ID))
+        })
+    }
+}
+
+func TestApprovedAfterSnapshot(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            e.txs.SetApprovals(&services.ApprovalPolicy{ManualCredits: true, TTL: time.Hour}, e.store.Approvals)
+            history := services.NewBalanceHistoryService(e.store.Balances, e.store.Transactions, e.store.Snapshots)
+            maker := e.admin(t, "maker")
+            checker := e.admin(t, "checker")
+            alice := e.register(t, "alice")
+            ctx := context.Background()
+
+            var held, snapshot time.Time
+            within(t, func() {
+                tx, err := e.txs.Credit(services.WithActorUser(ctx, maker), alice.ID, 50, "USD")
+                require.NoError(t, err)
+                held = tx.CreatedAt
+
+                // The snapshot is taken while the credit waits
+                snapshot = time.Now()
+                _, err = history.TakeSnapshots(ctx, snapshot)
+                require.NoError(t, err)
+
+                _, err = e.txs.Approve(services.WithActorUser(ctx, checker), tx.ID)
+                require.NoError(t, err)
+            })
+
+            for _, at := range []struct {
+                asOf time.Time
+                want float64
+            }{
+                {held, 0},
+                {snapshot, 0},
+                {time.Now(), 50},
+            } {
+                balance, err := history.GetBalanceAsOf(ctx, alice.ID, "USD", at.asOf)
+                require.NoError(t, err)
+                assert.Equal(t, at.want, balance.Amount, "as of %s", at.asOf)
+            }
+
+            entries, err := history.GetHistory(ctx, alice.ID, "USD", held, time.Now())
+            require.NoError(t, err)
+            require.Len(t, entries.Entries, 1)
+            assert.False(t, entries.Entries[0].BookedAt.Before(snapshot))
+            assert.Equal(t, 50.0, entries.ClosingBalance)
+        })
+    }
+}
+
+func TestFraudScreening(t *testing.T) {
+    rules := []models.FraudRuleConfig{
+        {Name: "large", Kind: models.FraudRuleAmount, Amount: 500, Outcome: models.FraudReview},
+        {Name: "huge", Kind: models.FraudRuleAmount, Amount: 1000, Outcome: models.FraudBlock},
+    }
+
+    tests := []struct {
+        name      string
+        approvals bool
+        amount    float64
+        wantErr   error
+        status    models.TransactionStatus
+        // alice's balance afterwards, starting from 2000
+        balance   float64
+    }{
+        {
+            name:    "allowed",
+            amount:  500,
+            status:  models.TransactionStatusCompleted,
+            balance: 1500,
+        },
+        {
+            name:      "flagged and held for review",
+            approvals: true,
+            amount:    600,
+            status:    models.TransactionStatusAwaitingApproval,
+            balance:   2000,
+        },
+        {
+            name:    "flagged without approvals to review it",
+            amount:  600,
+            wantErr: models.ErrFraudBlocked,
+            balance: 2000,
+        },
+        {
+            name:      "blocked",
+            approvals: true,
+            amount:    1200,
+            wantErr:   models.ErrFraudBlocked,
+            balance:   2000,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                within(t, func() {
+                    _, err := e.txs.Credit(ctx, alice.ID, 2000, "USD")
+                    require.NoError(t, err)
+                })
+
+                engine, err := services.NewFraudEngineFromConfig(rules, e.store.Transactions)
+                require.NoError(t, err)
+                e.txs.SetFraudEngine(engine)
+                if tt.approvals {
+                    e.txs.SetApprovals(&services.ApprovalPolicy{TTL: time.Hour}, e.store.Approvals)
+                }
+
+                within(t, func() {
+                    // alice initiates the transfer, so any admin may review it
+                    tx, err := e.txs.Transfer(services.WithActorUser(ctx, alice), alice.ID, bob.ID, tt.amount, "USD")
+                    if tt.wantErr != nil {
+                        assert.ErrorIs(t, err, tt.wantErr)
+                        return
+                    }
+                    require.NoError(t, err)
+
+                    stored, err := e.store.Transactions.GetByID(ctx, tx.ID)
+                    require.NoError(t, err)
+                    assert.Equal(t, tt.status, stored.Status)
+
+                    if tt.status == models.TransactionStatusAwaitingApproval {
+                        approval, err := e.store.Approvals.GetByTransactionID(ctx, tx.ID)
+                        require.NoError(t, err)
+                        assert.Equal(t, models.ApprovalKindReview, approval.Kind)
+                    }
+                })
+
+                assert.Equal(t, tt.balance, e.balance(t, alice.ID))
+
+                logs, err := e.store.AuditLogs.GetByEntityID(ctx, "user", alice.ID)
+                require.NoError(t, err)
+                blocked := false
+                for _, entry := range logs {
+                    blocked = blocked || entry.Action == "fraud_block"
+                }
+                assert.Equal(t, tt.wantErr != nil, blocked)
+            })
+        }
+    }
+}
+
+func TestSanctionsScreening(t *testing.T) {
+    entries := []models.WatchlistEntry{{ID: "1", Name: "Evil Corp"}}
+
+    // screenings returns the outcomes of the sanctions screenings on the
+    // audit log of userID
+    screenings := func(t *testing.T, e *env, userID uint) []string {
+        logs, err := e.store.AuditLogs.GetByEntityID(context.Background(), "user", userID)
+        require.NoError(t, err)
+
+        var outcomes []string
+        for _, entry := range logs {
+            if entry.Action != "sanctions_screen" {
+                continue
+            }
+            var changes struct {
+                Outcome string `json:"outcome"`
+            }
+            require.NoError(t, json.Unmarshal([]byte(entry.Changes), &changes))
+            outcomes = append(outcomes, changes.Outcome)
+        }
+        return outcomes
+    }
+
+    tests := []struct {
+        name     string
+        // payee is the username of the user alice pays
+        payee    string
+        status   models.TransactionStatus
+        // screened are the outcomes audited for the payee, registration
+        // included
+        screened []string
+    }{
+        {
+            name:     "clear parties",
+            payee:    "bob",
+            status:   models.TransactionStatusCompleted,
+            screened: []string{"allow", "allow"},
+        },
+        {
+            name:     "possible match held for review",
+            payee:    "evilcorq",
+            status:   models.TransactionStatusAwaitingApproval,
+            screened: []string{"review", "review"},
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                watchlist, err := services.NewWatchlist(entries, 0.8, 0.95)
+                require.NoError(t, err)
+                e.users.SetWatchlist(watchlist)
+                e.txs.SetWatchlist(watchlist)
+                e.txs.SetApprovals(&services.ApprovalPolicy{TTL: time.Hour}, e.store.Approvals)
+
+                alice := e.register(t, "alice")
+                payee := e.register(t, tt.payee)
+                ctx := context.Background()
+
+                within(t, func() {
+                    _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                    require.NoError(t, err)
+
+                    tx, err := e.txs.Transfer(services.WithActorUser(ctx, alice), alice.ID, payee.ID, 10, "USD")
+                    require.NoError(t, err)
+
+                    stored, err := e.store.Transactions.GetByID(ctx, tx.ID)
+                    require.NoError(t, err)
+                    assert.Equal(t, tt.status, stored.Status)
+                })
+
+                // Registration, the credit and the transfer
+                assert.Equal(t, []string{"allow", "allow", "allow"}, screenings(t, e, alice.ID))
+                assert.Equal(t, tt.screened, screenings(t, e, payee.ID))
+            })
+        }
+    }
+}
+
+func TestSanctionsScreeningRefusesRegistration(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            watchlist, err := services.NewWatchlist([]models.WatchlistEntry{{ID: "1", Name: "Evil Corp"}}, 0.8, 0.95)
+            require.NoError(t, err)
+            e.users.SetWatchlist(watchlist)
+
+            _, err = e.users.RegisterUser(context.Background(), "evilcorp", "evil@example.com", "Passw0rd!23")
+            assert.ErrorIs(t, err, models.ErrSanctionsMatch)
+
+            _, err = e.store.Users.GetByEmail(context.Background(), "evil@example.com")
+            assert.ErrorIs(t, err, repository.ErrNotFound)
+
+            // The refusal is audited without a user
+            logs, err := e.store.AuditLogs.GetByEntityID(context.Background(), "user", 0)
+            require.NoError(t, err)
+            require.Len(t, logs, 1)
+            assert.Equal(t, "sanctions_screen", logs[0].Action)
+        })
+    }
+}
+
+func TestScheduledTransfers(t *testing.T) {
+    transfers := &services.ApprovalPolicy{
+        Thresholds: []models.ApprovalThreshold{{Type: models.TransactionTypeTransfer, Amount: 5}},
+        TTL:        time.Hour,
+    }
+
+    // held returns the transaction held by the schedule's only run
+    held := func(ctx context.Context, t *testing.T, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer) uint {
+        runs, err := schedules.ListRuns(ctx, schedule.ID)
+        require.NoError(t, err)
+        require.Len(t, runs, 1)
+        require.Equal(t, models.ScheduledRunAwaitingApproval, runs[0].Status)
+        return runs[0].TransactionID
+    }
+
+    tests := []struct {
+        name                string
+        policy              *services.ApprovalPolicy
+        onInsufficientFunds string
+        funds               float64
+        // act runs the scheduler, and whatever else happens, for the
+        // schedule's only occurrence at base
+        act func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time)
+        // status, attempts and err describe the occurrence's run
+        status   models.ScheduledRunStatus
+        attempts int
+        err      string
+        schedule models.ScheduleStatus
+        // received is what the payee got
+        received float64
+    }{
+        {
+            name:  "occurrence is transferred once",
+            funds: 100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+                require.NoError(t, schedules.Run(ctx, base))
+            },
+            status:   models.ScheduledRunCompleted,
+            attempts: 1,
+            schedule: models.ScheduleStatusCompleted,
+            received: 10,
+        },
+        {
+            name:  "occurrence run before the schedule moved on is not run again",
+            funds: 100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, e.store.Schedules.CreateRun(ctx, &models.ScheduledTransferRun{
+                    ScheduleID: schedule.ID,
+                    Occurrence: schedule.NextRunAt,
+                    Status:     models.ScheduledRunCompleted,
+                    Attempts:   1,
+                    UpdatedAt:  base,
+                }))
+                require.NoError(t, schedules.Run(ctx, base))
+            },
+            status:   models.ScheduledRunCompleted,
+            attempts: 1,
+            schedule: models.ScheduleStatusCompleted,
+        },
+        {
+            name: "insufficient funds wait for the retry",
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+                // Not due again yet
+                require.NoError(t, schedules.Run(ctx, base.Add(30*time.Minute)))
+            },
+            status:   models.ScheduledRunRetrying,
+            attempts: 1,
+            err:      "insufficient funds",
+            schedule: models.ScheduleStatusActive,
+        },
+        {
+            name: "retry completes once funded",
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+
+                alice, err := e.store.Users.GetByID(ctx, schedule.FromUserID)
+                require.NoError(t, err)
+                _, err = e.txs.Credit(ctx, alice.ID, 100, "USD")
+                require.NoError(t, err)
+
+                require.NoError(t, schedules.Run(ctx, base.Add(time.Hour)))
+            },
+            status:   models.ScheduledRunCompleted,
+            attempts: 2,
+            schedule: models.ScheduleStatusCompleted,
+            received: 10,
+        },
+        {
+            name: "occurrence is skipped once the retries are used up",
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                for i := 0; i < 4; i++ {
+                    require.NoError(t, schedules.Run(ctx, base.Add(time.Duration(i)*time.Hour)))
+                }
+            },
+            status:   models.ScheduledRunSkipped,
+            attempts: 3,
+            err:      "insufficient funds",
+            schedule: models.ScheduleStatusCompleted,
+        },
+        {
+            name:                "schedule that skips does not retry",
+            onInsufficientFunds: models.OnInsufficientFundsSkip,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+            },
+            status:   models.ScheduledRunSkipped,
+            attempts: 1,
+            err:      "insufficient funds",
+            schedule: models.ScheduleStatusCompleted,
+        },
+        {
+            name:   "held occurrence awaits approval",
+            policy: transfers,
+            funds:  100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+
+                approval, err := e.store.Approvals.GetByTransactionID(ctx, held(ctx, t, schedules, schedule))
+                require.NoError(t, err)
+                assert.Equal(t, schedule.FromUserID, approval.InitiatedByID)
+            },
+            status:   models.ScheduledRunAwaitingApproval,
+            attempts: 1,
+            schedule: models.ScheduleStatusCompleted,
+        },
+        {
+            name:   "held occurrence completes when approved",
+            policy: transfers,
+            funds:  100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+                checker := e.admin(t, "checker")
+
+                _, err := e.txs.Approve(services.WithActorUser(ctx, checker), held(ctx, t, schedules, schedule))
+                require.NoError(t, err)
+            },
+            status:   models.ScheduledRunCompleted,
+            attempts: 1,
+            schedule: models.ScheduleStatusCompleted,
+            received: 10,
+        },
+        {
+            name:   "held occurrence fails when rejected",
+            policy: transfers,
+            funds:  100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+                checker := e.admin(t, "checker")
+
+                _, err := e.txs.Reject(services.WithReason(services.WithActorUser(ctx, checker), "payee unknown"), held(ctx, t, schedules, schedule))
+                require.NoError(t, err)
+            },
+            status:   models.ScheduledRunFailed,
+            attempts: 1,
+            err:      "payee unknown",
+            schedule: models.ScheduleStatusCompleted,
+        },
+        {
+            name:   "held occurrence fails when not approved in time",
+            policy: transfers,
+            funds:  100,
+            act: func(ctx context.Context, t *testing.T, e *env, schedules *services.ScheduledTransferService, schedule *models.ScheduledTransfer, base time.Time) {
+                require.NoError(t, schedules.Run(ctx, base))
+                held(ctx, t, schedules, schedule)
+
+                expired, err := e.txs.ExpireApprovals(ctx, time.Now().Add(2*time.Hour))
+                require.NoError(t, err)
+                assert.Equal(t, 1, expired)
+            },
+            status:   models.ScheduledRunFailed,
+            attempts: 1,
+            err:      "not approved in time",
+            schedule: models.ScheduleStatusCompleted,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                schedules := services.NewScheduledTransferService(e.store.Schedules, e.store.Users, e.txs, 2, time.Hour)
+                e.txs.SetDecisionListener(schedules.Resolve)
+
+                if tt.funds > 0 {
+                    _, err := e.txs.Credit(ctx, alice.ID, tt.funds, "USD")
+                    require.NoError(t, err)
+                }
+
+                if tt.policy != nil {
+                    e.txs.SetApprovals(tt.policy, e.store.Approvals)
+                }
+
+                base := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+
+                schedule := &models.ScheduledTransfer{
+                    FromUserID:          alice.ID,
+                    ToUserID:            bob.ID,
+                    Amount:              10,
+                    Currency:            "USD",
+                    Frequency:           models.FrequencyOnce,
+                    StartAt:             base,
+                    OnInsufficientFunds: tt.onInsufficientFunds,
+                }
+                require.NoError(t, schedules.Create(ctx, schedule))
+
+                within(t, func() {
+                    tt.act(ctx, t, e, schedules, schedule, base)
+                })
+
+                runs, err := schedules.ListRuns(ctx, schedule.ID)
+                require.NoError(t, err)
+                require.Len(t, runs, 1)
+                assert.Equal(t, tt.status, runs[0].Status)
+                assert.Equal(t, tt.attempts, runs[0].Attempts)
+                if tt.err == "" {
+                    assert.Empty(t, runs[0].Error)
+                } else {
+                    assert.Contains(t, runs[0].Error, tt.err)
+                }
+
+                stored, err := schedules.Get(ctx, schedule.ID)
+                require.NoError(t, err)
+                assert.Equal(t, tt.schedule, stored.Status)
+
+                assert.Equal(t, tt.received, e.balance(t, bob.ID))
+            })
+        }
+    }
+}
+
+// failingBalances fails to create or update balances in currency, so
+// opening an account in it, or whatever leg of a transaction moves money in
+// it, fails.
+type failingBalances struct {
+    repository.BalanceRepository
+    currency string
+}
+
+var errBalanceUpdate = errors.New("balance update failed")
+
+func (r failingBalances) CreateBalance(ctx context.Context, balance *models.Balance) error {
+    if balance.Currency == r.currency {
+        return errBalanceUpdate
+    }
+    return r.BalanceRepository.CreateBalance(ctx, balance)
+}
+
+func (r failingBalances) UpdateBalance(ctx context.Context, balance *models.Balance) error {
+    if balance.Currency == r.currency {
+        return errBalanceUpdate
+    }
+    return r.BalanceRepository.UpdateBalance(ctx, balance)
+}
+
+func TestCurrencyConversion(t *testing.T) {
+    tests := []struct {
+        name       string
+        toCurrency string
+        amount     float64
+        ttl        time.Duration
+        // failing is the currency whose balance updates fail
+        failing string
+        // again executes the quote a second time
+        again   bool
+        wantErr error
+        // quoted is the quote's converted amount
+        quoted float64
+        // left is what alice has left of her 100 USD, received what bob got
+        left     float64
+        received float64
+    }{
+        {
+            name:       "both legs are applied",
+            toCurrency: "EUR",
+            amount:     50,
+            quoted:     44.55,
+            left:       50,
+            received:   44.55,
+        },
+        {
+            name:       "converted amount is rounded to whole yen",
+            toCurrency: "JPY",
+            amount:     10,
+            quoted:     1499,
+            left:       90,
+            received:   1499,
+        },
+        {
+            name:       "converted amount is rounded to three decimals",
+            toCurrency: "BHD",
+            amount:     10,
+            quoted:     3.722,
+            left:       90,
+            received:   3.722,
+        },
+        {
+            name:       "quote is executed once",
+            toCurrency: "EUR",
+            amount:     50,
+            again:      true,
+            wantErr:    models.ErrQuoteUsed,
+            quoted:     44.55,
+            left:       50,
+            received:   44.55,
+        },
+        {
+            name:       "expired quote is refused",
+            toCurrency: "EUR",
+            amount:     50,
+            ttl:        -time.Second,
+            wantErr:    models.ErrQuoteExpired,
+            quoted:     44.55,
+            left:       100,
+        },
+        {
+            name:       "failed credit leg undoes the debit",
+            toCurrency: "EUR",
+            amount:     50,
+            failing:    "EUR",
+            wantErr:    errBalanceUpdate,
+            quoted:     44.55,
+            left:       100,
+        },
+        {
+            name:       "failed debit leg credits nothing",
+            toCurrency: "EUR",
+            amount:     50,
+            failing:    "USD",
+            wantErr:    errBalanceUpdate,
+            quoted:     44.55,
+            left:       100,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                store := openTest(t, driver)
+                e := newEnv(t, store)
+                e.txs.SetFXQuotes(store.FXQuotes)
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                require.NoError(t, err)
+
+                for quote, rate := range map[string]float64{"EUR": 0.9, "JPY": 151.37, "BHD": 0.376} {
+                    require.NoError(t, store.FXRates.SetRate(ctx, &models.FXRate{
+                        BaseCurrency:  "USD",
+                        QuoteCurrency: quote,
+                        Rate:          rate,
+                        UpdatedAt:     time.Now(),
+                    }))
+                }
+
+                ttl := tt.ttl
+                if ttl == 0 {
+                    ttl = time.Minute
+                }
+                fx := services.NewFXService(services.NewStoredRateProvider(store.FXRates, 0), store.FXQuotes, store.Users, 0.01, ttl)
+
+                quote, err := fx.CreateQuote(ctx, alice.ID, "USD", tt.toCurrency, tt.amount)
+                require.NoError(t, err)
+                assert.Equal(t, tt.quoted, quote.ToAmount)
+
+                if tt.failing != "" {
+                    // Only the conversion runs against the failing balances
+                    store.Balances = failingBalances{store.Balances, tt.failing}
+                    e = newEnv(t, store)
+                    e.txs.SetFXQuotes(store.FXQuotes)
+                }
+
+                within(t, func() {
+                    _, err = e.txs.ConvertTransfer(ctx, alice.ID, bob.ID, quote.ID)
+                    if tt.again {
+                        require.NoError(t, err)
+                        _, err = e.txs.ConvertTransfer(ctx, alice.ID, bob.ID, quote.ID)
+                    }
+
+                    if tt.wantErr != nil {
+                        assert.ErrorIs(t, err, tt.wantErr)
+                    } else {
+                        assert.NoError(t, err)
+                    }
+                })
+
+                stored, err := store.FXQuotes.GetByID(ctx, quote.ID)
+                require.NoError(t, err)
+                assert.Equal(t, tt.received != 0, stored.TransactionID != 0, "quote executed")
+
+                assert.Equal(t, tt.left, e.balance(t, alice.ID))
+
+                received, err := store.Balances.GetBalance(ctx, bob.ID, tt.toCurrency)
+                if tt.received == 0 {
+                    assert.ErrorIs(t, err, repository.ErrNotFound, "bob has no %s account", tt.toCurrency)
+                } else {
+                    require.NoError(t, err)
+                    assert.Equal(t, tt.received, received.Amount)
+                }
+            })
+        }
+    }
+}
+
+func TestOverdrafts(t *testing.T) {
+    type op struct {
+        kind   string
+        amount float64
+    }
+
+    tests := []struct {
+        name  string
+        limit float64
+        // ops run in turn on alice, who starts with 50 USD; only the last
+        // may fail
+        ops     []op
+        wantErr error
+        balance float64
+        // overdrawn lists the Overdrawn flag of each overdraft event
+        overdrawn []bool
+    }{
+        {
+            name:    "debit within the balance",
+            limit:   100,
+            ops:     []op{{"debit", 30}},
+            balance: 20,
+        },
+        {
+            name:      "debit goes negative within the limit",
+            limit:     100,
+            ops:       []op{{"debit", 120}},
+            balance:   -70,
+            overdrawn: []bool{true},
+        },
+        {
+            name:      "debit uses up the limit exactly",
+            limit:     100,
+            ops:       []op{{"debit", 150}},
+            balance:   -100,
+            overdrawn: []bool{true},
+        },
+        {
+            name:    "debit past the limit is refused",
+            limit:   100,
+            ops:     []op{{"debit", 150.01}},
+            wantErr: models.ErrInsufficientFunds,
+            balance: 50,
+        },
+        {
+            name:    "without a limit the balance cannot go negative",
+            ops:     []op{{"debit", 50.01}},
+            wantErr: models.ErrInsufficientFunds,
+            balance: 50,
+        },
+        {
+            name:      "transfer draws on the limit",
+            limit:     100,
+            ops:       []op{{"transfer", 80}},
+            balance:   -30,
+            overdrawn: []bool{true},
+        },
+        {
+            name:      "further debits in overdraft raise no event",
+            limit:     100,
+            ops:       []op{{"debit", 60}, {"debit", 10}},
+            balance:   -20,
+            overdrawn: []bool{true},
+        },
+        {
+            name:      "credit back above zero ends the overdraft",
+            limit:     100,
+            ops:       []op{{"debit", 120}, {"credit", 100}},
+            balance:   30,
+            overdrawn: []bool{true, false},
+        },
+        {
+            name:      "lowered limit keeps the overdraft but refuses debits",
+            limit:     100,
+            ops:       []op{{"debit", 120}, {"limit", 50}, {"debit", 1}},
+            wantErr:   models.ErrInsufficientFunds,
+            balance:   -70,
+            overdrawn: []bool{true},
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                alice := e.register(t, "alice")
+                bob := e.register(t, "bob")
+                ctx := context.Background()
+
+                balances := services.NewBalanceService(e.store.Balances, e.store.Accounts, e.store.Transactions, 100, time.Minute)
+
+                _, err := e.txs.Credit(ctx, alice.ID, 50, "USD")
+                require.NoError(t, err)
+
+                _, err = balances.SetCreditLimit(ctx, alice.ID, "USD", tt.limit)
+                require.NoError(t, err)
+
+                var overdrawn []bool
+                events := services.NewBalanceEvents()
+                events.SubscribeOverdraft(func(event services.OverdraftEvent) {
+                    if event.UserID == alice.ID {
+                        overdrawn = append(overdrawn, event.Overdrawn)
+                    }
+                })
+                e.txs.SetBalanceEvents(events)
+
+                within(t, func() {
+                    for i, op := range tt.ops {
+                        var err error
+                        switch op.kind {
+                            case "debit":
+                                _, err = e.txs.Debit(ctx, alice.ID, op.amount, "USD")
+                            case "credit":
+                                _, err = e.txs.Credit(ctx, alice.ID, op.amount, "USD")
+                            case "transfer":
+                                _, err = e.txs.Transfer(ctx, alice.ID, bob.ID, op.amount, "USD")
+                            case "limit":
+                                _, err = balances.SetCreditLimit(ctx, alice.ID, "USD", op.amount)
+                        }
+
+                        if i == len(tt.ops)-1 && tt.wantErr != nil {
+                            assert.ErrorIs(t, err, tt.wantErr)
+                        } else {
+                            require.NoError(t, err, "op %d", i)
+                        }
+                    }
+                })
+
+                assert.Equal(t, tt.balance, e.balance(t, alice.ID))
+                assert.Equal(t, tt.overdrawn, overdrawn)
+            })
+        }
+    }
+}
+
+func TestBulkTransfers(t *testing.T) {
+    type item struct {
+        to     string
+        amount float64
+    }
+
+    tests := []struct {
+        name string
+        mode models.BatchMode
+        // items pay from alice, who has 100, to bob, carol or nobody
+        items   []item
+        status  models.BatchStatus
+        results []models.BatchItemStatus
+        // balances of alice, bob and carol afterwards
+        balances [3]float64
+    }{
+        {
+            name:     "all-or-nothing batch is applied",
+            mode:     models.BatchModeAllOrNothing,
+            items:    []item{{"bob", 30}, {"carol", 20}},
+            status:   models.BatchStatusCompleted,
+            results:  []models.BatchItemStatus{models.BatchItemCompleted, models.BatchItemCompleted},
+            balances: [3]float64{50, 30, 20},
+        },
+        {
+            name:     "all-or-nothing batch rolls back when a transfer finds insufficient funds",
+            mode:     models.BatchModeAllOrNothing,
+            items:    []item{{"bob", 30}, {"carol", 80}, {"bob", 20}},
+            status:   models.BatchStatusFailed,
+            results:  []models.BatchItemStatus{models.BatchItemNotExecuted, models.BatchItemFailed, models.BatchItemNotExecuted},
+            balances: [3]float64{100, 0, 0},
+        },
+        {
+            name:     "all-or-nothing batch with an unknown payee executes nothing",
+            mode:     models.BatchModeAllOrNothing,
+            items:    []item{{"bob", 30}, {"nobody", 20}},
+            status:   models.BatchStatusFailed,
+            results:  []models.BatchItemStatus{models.BatchItemNotExecuted, models.BatchItemFailed},
+            balances: [3]float64{100, 0, 0},
+        },
+        {
+            name:     "best-effort batch keeps the transfers that went through",
+            mode:     models.BatchModeBestEffort,
+            items:    []item{{"bob", 30}, {"carol", 200}},
+            status:   models.BatchStatusPartiallyCompleted,
+            results:  []models.BatchItemStatus{models.BatchItemCompleted, models.BatchItemFailed},
+            balances: [3]float64{70, 30, 0},
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                e := newEnv(t, openTest(t, driver))
+                users := map[string]*models.User{
+                    "alice":  e.register(t, "alice"),
+                    "bob":    e.register(t, "bob"),
+                    "carol":  e.register(t, "carol"),
+                    "nobody": {ID: 9999},
+                }
+                ctx := context.Background()
+
+                _, err := e.txs.Credit(ctx, users["alice"].ID, 100, "USD")
+                require.NoError(t, err)
+
+                bulk := services.NewBulkTransferService(e.store.Batches, e.txs, e.store.Transactor, 10)
+
+                batch := &models.TransferBatch{ClientBatchID: "batch-1", Mode: tt.mode}
+                for _, it := range tt.items {
+                    batch.Items = append(batch.Items, &models.TransferBatchItem{
+                        FromUserID: users["alice"].ID,
+                        ToUserID:   users[it.to].ID,
+                        Amount:     it.amount,
+                        Currency:   "USD",
+                    })
+                }
+
+                _, created, err := bulk.Submit(ctx, batch)
+                require.NoError(t, err)
+                require.True(t, created)
+
+                var result *models.TransferBatch
+                require.Eventually(t, func() bool {
+                    result, err = bulk.Get(ctx, "batch-1")
+                    return err == nil && result.Status != models.BatchStatusProcessing
+                }, 10*time.Second, 10*time.Millisecond)
+
+                assert.Equal(t, tt.status, result.Status)
+                require.Len(t, result.Items, len(tt.results))
+                for i, want := range tt.results {
+                    assert.Equal(t, want, result.Items[i].Status, "item %d", i)
+                    if want != models.BatchItemCompleted {
+                        assert.Zero(t, result.Items[i].TransactionID, "item %d", i)
+                    }
+                }
+
+                for i, name := range []string{"alice", "bob", "carol"} {
+                    assert.Equal(t, tt.balances[i], e.balance(t, users[name].ID), name)
+                }
+
+                // A rolled back batch leaves nothing in the ledger
+                if tt.status == models.BatchStatusFailed {
+                    txs, err := e.store.Transactions.GetUserTransactions(ctx, users["alice"].ID, 10, 0)
+                    require.NoError(t, err)
+                    assert.Len(t, txs, 1, "only the credit")
+                }
+            })
+        }
+    }
+}
+
+func TestRegisterUserOpensAccount(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            store := openTest(t, driver)
+            e := newEnv(t, store)
+            accounts := services.NewAccountService(store.Accounts, store.Balances, store.Users, store.Transactor)
+            ctx := context.Background()
+
+            within(t, func() {
+                alice := e.register(t, "alice")
+
+                list, err := accounts.ListByUser(ctx, alice.ID)
+                require.NoError(t, err)
+                require.Len(t, list, 1)
+                assert.Equal(t, models.AccountTypeChecking, list[0].Type)
+                assert.Equal(t, "USD", list[0].Currency)
+                assert.Equal(t, models.AccountStatusActive, list[0].Status)
+                require.NotNil(t, list[0].Balance)
+                assert.Equal(t, 0.0, list[0].Balance.Amount)
+            })
+
+            // A user whose account cannot be opened is not kept either
+            store.Balances = failingBalances{store.Balances, "USD"}
+            e = newEnv(t, store)
+
+            within(t, func() {
+                _, err := e.users.RegisterUser(ctx, "bob", "bob@example.com", "Passw0rd!23")
+                assert.ErrorIs(t, err, errBalanceUpdate)
+            })
+
+            _, err := store.Users.GetByEmail(ctx, "bob@example.com")
+            assert.ErrorIs(t, err, repository.ErrNotFound)
+        })
+    }
+}
+
+func TestDefaultAccounts(t *testing.T) {
+    type open struct {
+        accountType models.AccountType
+        currency    string
+    }
+
+    tests := []struct {
+        name string
+        // opened are the accounts alice opens after registering with a USD
+        // checking account, which is account 0; closed are closed in order
+        opened []open
+        closed []int
+        // want is the account a USD credit to alice is booked to, or -1 if
+        // it opens a new one
+        want int
+    }{
+        {
+            name: "registration account",
+            want: 0,
+        },
+        {
+            name:   "oldest account stays the default",
+            opened: []open{{models.AccountTypeSavings, "USD"}},
+            want:   0,
+        },
+        {
+            name:   "account in another currency is not the default",
+            opened: []open{{models.AccountTypeChecking, "EUR"}},
+            want:   0,
+        },
+        {
+            name:   "closing the default makes the next oldest the default",
+            opened: []open{{models.AccountTypeChecking, "EUR"}, {models.AccountTypeSavings, "USD"}, {models.AccountTypeChecking, "USD"}},
+            closed: []int{0},
+            want:   2,
+        },
+        {
+            name:   "closing every account opens a new one",
+            opened: []open{{models.AccountTypeSavings, "USD"}},
+            closed: []int{0, 1},
+            want:   -1,
+        },
+    }
+
+    for _, driver := range drivers {
+        for _, tt := range tests {
+            t.Run(driver+"/"+tt.name, func(t *testing.T) {
+                store := openTest(t, driver)
+                e := newEnv(t, store)
+                accounts := services.NewAccountService(store.Accounts, store.Balances, store.Users, store.Transactor)
+                alice := e.register(t, "alice")
+                ctx := services.WithReason(context.Background(), "no longer needed")
+
+                list, err := accounts.ListByUser(ctx, alice.ID)
+                require.NoError(t, err)
+                ids := []uint{list[0].ID}
+
+                within(t, func() {
+                    for _, o := range tt.opened {
+                        account, err := accounts.Create(ctx, alice.ID, o.accountType, o.currency)
+                        require.NoError(t, err)
+                        ids = append(ids, account.ID)
+                    }
+
+                    for _, i := range tt.closed {
+                        _, err := accounts.Close(ctx, ids[i])
+                        require.NoError(t, err)
+                    }
+
+                    _, err := e.txs.Credit(ctx, alice.ID, 25, "USD")
+                    require.NoError(t, err)
+                })
+
+                balance, err := store.Balances.GetBalance(ctx, alice.ID, "USD")
+                require.NoError(t, err)
+                assert.Equal(t, 25.0, balance.Amount)
+
+                if tt.want < 0 {
+                    assert.NotContains(t, ids, balance.AccountID)
+                } else {
+                    assert.Equal(t, ids[tt.want], balance.AccountID)
+                }
+
+                // Every other account is left untouched
+                for _, id := range ids {
+                    if id == balance.AccountID {
+                        continue
+                    }
+                    other, err := store.Balances.GetAccountBalance(ctx, id)
+                    require.NoError(t, err)
+                    assert.Equal(t, 0.0, other.Amount, "account %d", id)
+                }
+            })
+        }
+    }
+}
+
+func TestAccountLifecycle(t *testing.T) {
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            house := e.register(t, "house")
+            alice := e.register(t, "alice")
+            e.txs.SetFeeSchedule(&services.FeeSchedule{Rules: []services.FeeRule{
+                {Name: "credit", Type: models.TransactionTypeCredit, Kind: services.FeeKindFlat, Flat: 1},
+            }}, house.ID)
+
+            events := services.NewBalanceEvents()
+            accounts := services.NewAccountService(e.store.Accounts, e.store.Balances, e.store.Users, e.store.Transactor)
+            accounts.SetBalanceEvents(events)
+            balances := services.NewBalanceService(e.store.Balances, e.store.Accounts, e.store.Transactions, 100, time.Minute)
+            balances.SetBalanceEvents(events)
+
+            ctx := context.Background()
+            withReason := services.WithReason(ctx, "customer request")
+
+            list, err := accounts.ListByUser(ctx, alice.ID)
+            require.NoError(t, err)
+            id := list[0].ID
+
+            within(t, func() {
+                _, err := e.txs.Credit(ctx, alice.ID, 51, "USD")
+                require.NoError(t, err)
+
+                // Freezing and closing need a reason, closing an empty account
+                _, err = accounts.Freeze(ctx, id)
+                assert.ErrorIs(t, err, services.ErrReasonRequired)
+
+                _, err = accounts.Close(ctx, id)
+                assert.ErrorIs(t, err, services.ErrReasonRequired)
+
+                _, err = accounts.Close(withReason, id)
+                assert.ErrorIs(t, err, services.ErrAccountNotEmpty)
+
+                // A frozen account pays nothing out but is still paid
+                // interest and charged fees
+                _, err = accounts.Freeze(withReason, id)
+                require.NoError(t, err)
+
+                _, err = e.txs.Debit(ctx, alice.ID, 10, "USD")
+                assert.ErrorIs(t, err, models.ErrAccountFrozen)
+
+                _, err = e.txs.PostInterest(ctx, alice.ID, id, 2, "USD", "interest", nil)
+                require.NoError(t, err)
+
+                _, err = e.txs.PostInterest(ctx, alice.ID, id, -1, "USD", "overdraft interest", nil)
+                require.NoError(t, err)
+
+                _, err = e.txs.Credit(ctx, alice.ID, 10, "USD")
+                require.NoError(t, err)
+                assert.Equal(t, 60.0, e.balance(t, alice.ID))
+                assert.Equal(t, 2.0, e.balance(t, house.ID))
+
+                e.txs.SetRejectFrozenCredits(true)
+                _, err = e.txs.Credit(ctx, alice.ID, 10, "USD")
+                assert.ErrorIs(t, err, models.ErrAccountFrozen)
+
+                _, err = e.txs.PostInterest(ctx, alice.ID, id, 1, "USD", "interest", nil)
+                require.NoError(t, err)
+
+                _, err = accounts.Unfreeze(withReason, id)
+                require.NoError(t, err)
+
+                _, err = e.txs.Debit(ctx, alice.ID, 61, "USD")
+                require.NoError(t, err)
+            })
+
+            // The cached balance of the account being closed is dropped, so
+            // the next one resolves to the new default account
+            second, err := accounts.Create(ctx, alice.ID, models.AccountTypeSavings, "USD")
+            require.NoError(t, err)
+
+            balance, err := balances.GetBalance(ctx, alice.ID, "USD")
+            require.NoError(t, err)
+            assert.Equal(t, id, balance.AccountID)
+
+            closed, err := accounts.Close(withReason, id)
+            require.NoError(t, err)
+            assert.Equal(t, models.AccountStatusClosed, closed.Status)
+
+            balance, err = balances.GetBalance(ctx, alice.ID, "USD")
+            require.NoError(t, err)
+            assert.Equal(t, second.ID, balance.AccountID)
+
+            _, err = accounts.Close(withReason, id)
+            assert.ErrorIs(t, err, models.ErrAccountClosed)
+
+            _, err = accounts.Freeze(withReason, id)
+            assert.ErrorIs(t, err, services.ErrInvalidAccountStatus)
+
+            // Closed accounts are paid no interest
+            _, err = e.txs.PostInterest(ctx, alice.ID, id, 1, "USD", "interest", nil)
+            assert.ErrorIs(t, err, models.ErrAccountClosed)
+        })
+    }
+}
+
+func TestBalanceHistoryAtSnapshot(t *testing.T) {
+    midnight := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            history := services.NewBalanceHistoryService(e.store.Balances, e.store.Transactions, e.store.Snapshots)
+            alice := e.register(t, "alice")
+            ctx := context.Background()
+
+            balance, err := e.store.Balances.GetBalance(ctx, alice.ID, "USD")
+            require.NoError(t, err)
+
+            // Credits booked before, exactly at and after the snapshot
+            for _, credit := range []struct {
+                amount   float64
+                bookedAt time.Time
+            }{
+                {10, midnight.Add(-time.Hour)},
+                {20, midnight},
+                {40, midnight.Add(time.Hour)},
+            } {
+                tx := &models.Transaction{
+                    ToUserID:    alice.ID,
+                    ToAccountID: balance.AccountID,
+                    Amount:      credit.amount,
+                    Currency:    "USD",
+                    Type:        models.TransactionTypeCredit,
+                    Status:      models.TransactionStatusPending,
+                    CreatedAt:   credit.bookedAt,
+                }
+                require.NoError(t, e.store.Transactions.Create(ctx, tx))
+                require.NoError(t, e.store.Transactions.Complete(ctx, tx.ID, credit.bookedAt))
+            }
+
+            // The snapshot leaves out what was booked at its own time...
+            _, err = history.TakeSnapshots(ctx, midnight)
+            require.NoError(t, err)
+
+            snapshot, err := e.store.Snapshots.GetLatest(ctx, balance.AccountID, midnight)
+            require.NoError(t, err)
+            assert.True(t, snapshot.AsOf.Equal(midnight))
+            assert.Equal(t, 10.0, snapshot.Amount)
+
+            // ...which the replay from it adds once, asOf being exclusive
+            for _, at := range []struct {
+                asOf time.Time
+                want float64
+            }{
+                {midnight.Add(-time.Hour), 0},
+                {midnight, 10},
+                {midnight.Add(time.Minute), 30},
+                {midnight.Add(time.Hour), 30},
+                {midnight.Add(time.Hour + time.Minute), 70},
+            } {
+                balance, err := history.GetBalanceAsOf(ctx, alice.ID, "USD", at.asOf)
+                require.NoError(t, err)
+                assert.Equal(t, at.want, balance.Amount, "as of %s", at.asOf)
+            }
+
+            before, err := history.GetHistory(ctx, alice.ID, "USD", midnight.Add(-2*time.Hour), midnight)
+            require.NoError(t, err)
+            require.Len(t, before.Entries, 1)
+            assert.Equal(t, 10.0, before.ClosingBalance)
+
+            after, err := history.GetHistory(ctx, alice.ID, "USD", midnight, midnight.Add(2*time.Hour))
+            require.NoError(t, err)
+            assert.Equal(t, 10.0, after.OpeningBalance)
+            require.Len(t, after.Entries, 2)
+            assert.True(t, after.Entries[0].BookedAt.Equal(midnight))
+            assert.Equal(t, 30.0, after.Entries[0].Balance)
+            assert.Equal(t, 70.0, after.ClosingBalance)
+        })
+    }
+}
+
+func TestPaymentInitiation(t *testing.T) {
+    data, err := os.ReadFile(filepath.Join("testdata", "pain001.xml"))
+    require.NoError(t, err)
+
+    for _, driver := range drivers {
+        t.Run(driver, func(t *testing.T) {
+            e := newEnv(t, openTest(t, driver))
+            alice := e.register(t, "alice")
+            bob := e.register(t, "bob")
+            carol := e.register(t, "carol")
+            ctx := context.Background()
+
+            // The message pays from and to accounts 1 to 3
+            require.Equal(t, []uint{1, 2, 3}, []uint{alice.ID, bob.ID, carol.ID})
+
+            imports := services.NewImportService(e.store.Imports, e.store.Users, e.store.Balances, e.txs, e.store.Transactor, "USD", 100)
+            payments := services.NewPaymentInitiationService(imports)
+
+            within(t, func() {
+                _, err := e.txs.Credit(ctx, alice.ID, 100, "USD")
+                require.NoError(t, err)
+
+                // A dry run only validates
+                report, _, _, err := payments.ImportPain001(ctx, "", data, true)
+                require.NoError(t, err)
+                report.Report.GroupHeader.CreationDateTime = "2024-03-01T09:30:00Z"
+                body, err := report.Marshal()
+                require.NoError(t, err)
+                golden(t, "pain002_dryrun.xml", body)
+
+                report, imp, created, err := payments.ImportPain001(ctx, "", data, false)
+                require.NoError(t, err)
+                assert.True(t, created)
+                assert.Equal(t, "PAYROLL-2024-03", imp.Filename)
+                report.Report.GroupHeader.CreationDateTime = "2024-03-01T09:30:00Z"
+                body, err = report.Marshal()
+                require.NoError(t, err)
+                golden(t, "pain002.xml", body)
+
+                // The message is not executed again, its outcome is reported
+                again, _, created, err := payments.ImportPain001(ctx, "", data, false)
+                require.NoError(t, err)
+                assert.False(t, created)
+                again.Report.GroupHeader.CreationDateTime = report.Report.GroupHeader.CreationDateTime
+                assert.Equal(t, report, again)
+            })
+
+            assert.Equal(t, 49.5, e.balance(t, alice.ID))
+            assert.Equal(t, 30.0, e.balance(t, bob.ID))
+            assert.Equal(t, 20.5, e.balance(t, carol.ID))
+        })
+    }
+}